@@ -0,0 +1,156 @@
+package configr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/bashfulrobot/configr/internal/pkg"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	adoptInto   string
+	adoptCopy   bool
+	adoptName   string
+	adoptDryRun bool
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <file>",
+	Short: "Bring an existing file under management",
+	Long: `Adopt moves an existing file into the config repository and replaces it with a
+symlink to the moved file. With --copy the original stays in place and a copy is
+added to the repository instead.
+
+A files: entry for the file is added to the configuration file, which is edited in
+place so comments and formatting are preserved, and the file is recorded in state
+so it's managed right away.
+
+The file is stored in the --into directory (relative to the configuration file),
+named after the original without its leading dot.`,
+	Example: `  configr adopt ~/.gitconfig                       # Move to dotfiles/gitconfig and symlink
+  configr adopt ~/.config/starship.toml --copy     # Keep the original, deploy as a copy
+  configr adopt ~/.bashrc --into shell --name bash # Store as shell/bashrc in files.bash
+  configr --config work.yaml adopt ~/.npmrc        # Add the entry to work.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdopt,
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+
+	adoptCmd.Flags().StringVar(&adoptInto, "into", "dotfiles", "directory in the config repository to store the file in")
+	adoptCmd.Flags().BoolVar(&adoptCopy, "copy", false, "leave the original in place and manage it as a copy")
+	adoptCmd.Flags().StringVar(&adoptName, "name", "", "name of the files: entry (default: derived from the file name)")
+	adoptCmd.Flags().BoolVar(&adoptDryRun, "dry-run", false, "show what would be done without making changes")
+}
+
+func runAdopt(cmd *cobra.Command, args []string) error {
+	logger := newPackagesLogger()
+
+	destPath, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", args[0], err)
+	}
+
+	configPath := viper.GetString("config")
+	if configPath == "" {
+		if configPath, err = findConfigFile(); err != nil {
+			return fmt.Errorf("failed to find config file: %w", err)
+		}
+	}
+	// Symlinks point at the absolute source path
+	if configPath, err = filepath.Abs(configPath); err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", configPath, err)
+	}
+	configDir := filepath.Dir(configPath)
+
+	name := adoptName
+	if name == "" {
+		name = adoptedFileName(destPath)
+	}
+
+	sourcePath := adoptInto
+	if !filepath.IsAbs(sourcePath) {
+		sourcePath = filepath.Join(configDir, sourcePath)
+	}
+	sourcePath = filepath.Join(sourcePath, strings.TrimPrefix(filepath.Base(destPath), "."))
+
+	entry := config.File{
+		Source:      configRelativePath(configDir, sourcePath),
+		Destination: homeRelativePath(destPath),
+		Copy:        adoptCopy,
+	}
+	if info, err := os.Stat(destPath); err == nil && adoptCopy && info.Mode().Perm() != 0644 {
+		entry.Mode = fmt.Sprintf("%o", info.Mode().Perm())
+	}
+
+	// Check the entry can be added before touching the file
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", configPath, err)
+	}
+	if _, added, err := config.AddFileToYAML(data, name, entry); err != nil {
+		return fmt.Errorf("failed to add files.%s to %s: %w", name, configPath, err)
+	} else if !added {
+		return fmt.Errorf("files.%s already exists in %s; choose another name with --name", name, configPath)
+	}
+
+	fileManager := pkg.NewFileManager(logger, adoptDryRun, configDir)
+	managedFile, err := fileManager.AdoptFile(name, destPath, sourcePath, adoptCopy)
+	if err != nil {
+		return err
+	}
+
+	if adoptDryRun {
+		logger.Info("DRY RUN: Would add file to configuration", "name", name, "config", configPath)
+		return nil
+	}
+
+	if _, err := config.AddFileToFile(configPath, name, entry); err != nil {
+		return err
+	}
+
+	stateManager := pkg.NewStateManager(logger)
+	if err := stateManager.AddManagedFile(managedFile); err != nil {
+		return fmt.Errorf("failed to record adopted file in state: %w", err)
+	}
+
+	config.Success("Added files.%s to %s", name, configPath)
+	return nil
+}
+
+// adoptedFileName derives a files: entry name from a path (~/.gitconfig -> gitconfig)
+func adoptedFileName(path string) string {
+	name := strings.TrimPrefix(filepath.Base(path), ".")
+	name = regexp.MustCompile(`[^A-Za-z0-9_-]+`).ReplaceAllString(name, "_")
+	if name == "" {
+		return "file"
+	}
+	return name
+}
+
+// configRelativePath returns path relative to the config directory, or absolute if it lies outside of it
+func configRelativePath(configDir, path string) string {
+	if rel, err := filepath.Rel(configDir, path); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+		return rel
+	}
+	return path
+}
+
+// homeRelativePath writes paths under the home directory with ~/, so the entry works for other users
+func homeRelativePath(path string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(homeDir, path); err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, "../") {
+		return "~/" + rel
+	}
+	return path
+}
@@ -2,8 +2,10 @@ package configr
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/bashfulrobot/configr/internal/config"
 	"github.com/bashfulrobot/configr/internal/pkg"
@@ -29,6 +31,7 @@ This command will:
 - Remove packages no longer in configuration (if --remove-packages=true)
 - Add APT and Flatpak repositories
 - Deploy and symlink files to their destinations
+- Decrypt age-encrypted files and vars (identity from CONFIGR_AGE_IDENTITY or ~/.config/configr/age.key)
 - Download and deploy binaries from remote repositories
 - Install APT, Flatpak, and Snap packages
 - Apply dconf settings for desktop configuration
@@ -38,6 +41,7 @@ This command will:
 
 Interactive features include:
 - Conflict resolution prompts for existing files and binaries
+- Three-way merge of local edits to copied files with the new version
 - File diff preview before replacement
 - Interactive permission and ownership configuration
 
@@ -194,11 +198,42 @@ func runApply(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to apply repository configurations: %w", err)
 	}
 
+	// Expand directory trees into individual files, so they are deployed, tracked and pruned like files
+	if len(cfg.Directories) > 0 {
+		if err := expandDirectories(cfg, configDir, logger, dryRun); err != nil {
+			return err
+		}
+	}
+
+	// Decrypt age-encrypted vars; decrypted values are redacted from all log output from here on
+	secretManager := pkg.NewSecretManager(logger)
+	templateVars, err := secretManager.DecryptVars(cfg.Vars)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt vars: %w", err)
+	}
+	logger.SetOutput(secretManager.RedactingWriter(os.Stderr))
+
+	// Replaced and edited files are backed up to the central backup store
+	backupStore := pkg.NewBackupStore(logger)
+	backupStore.SetCompression(cfg.BackupPolicy.Compress)
+
 	// Apply file configurations
 	var deployedFiles []pkg.ManagedFile
 	if len(cfg.Files) > 0 {
 		logger.Info("Applying file configurations")
 		fileManager := pkg.NewFileManager(logger, dryRun, configDir)
+		fileManager.SetSecretManager(secretManager)
+		fileManager.SetTemplateVars(templateVars)
+		fileManager.SetBackupStore(backupStore)
+
+		// Keep deployed copies, so local edits can be merged with new versions in interactive mode
+		contentStore := pkg.NewContentStore(logger)
+		fileManager.SetContentStore(contentStore)
+		if state, err := pkg.NewStateManager(logger).LoadState(); err == nil {
+			fileManager.SetPreviousFiles(state.Files)
+		} else {
+			logger.Warn("Could not load previously deployed files", "error", err)
+		}
 		
 		// Enable interactive mode on all files if global flag is set
 		if interactiveMode {
@@ -215,6 +250,44 @@ func runApply(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("failed to deploy files: %w", err)
 		}
+
+		if !dryRun {
+			if err := contentStore.Prune(deployedFiles); err != nil {
+				logger.Warn("Could not prune deployed content", "error", err)
+			}
+		}
+	}
+
+	// Apply line and block edits to files configr doesn't own
+	editBackups := make(map[string]string)
+	if len(cfg.Lines) > 0 || len(cfg.Blocks) > 0 {
+		logger.Info("Applying line and block edits")
+		fileManager := pkg.NewFileManager(logger, dryRun, configDir)
+		fileManager.SetBackupStore(backupStore)
+		if err := fileManager.EnsureLines(cfg.Lines); err != nil {
+			return fmt.Errorf("failed to apply lines: %w", err)
+		}
+		if err := fileManager.EnsureBlocks(cfg.Blocks); err != nil {
+			return fmt.Errorf("failed to apply blocks: %w", err)
+		}
+		maps.Copy(editBackups, fileManager.EditBackups())
+	}
+
+	// Apply key-level edits to settings files that applications also write
+	var appliedSettings []pkg.ManagedSetting
+	if len(cfg.SettingsFiles) > 0 {
+		logger.Info("Applying settings files")
+		previousSettings, err := pkg.NewStateManager(logger).GetManagedSettings()
+		if err != nil {
+			logger.Warn("Could not load previously managed settings", "error", err)
+		}
+		fileManager := pkg.NewFileManager(logger, dryRun, configDir)
+		fileManager.SetBackupStore(backupStore)
+		appliedSettings, err = fileManager.ApplySettingsFiles(cfg.SettingsFiles, previousSettings)
+		if err != nil {
+			return fmt.Errorf("failed to apply settings files: %w", err)
+		}
+		maps.Copy(editBackups, fileManager.EditBackups())
 	}
 
 	// Apply binary configurations
@@ -222,6 +295,7 @@ func runApply(cmd *cobra.Command, args []string) error {
 	if len(cfg.Binaries) > 0 {
 		logger.Info("Applying binary configurations")
 		binaryManager := pkg.NewBinaryManager(logger, dryRun, configDir)
+		binaryManager.SetBackupStore(backupStore)
 		
 		// Enable interactive mode on all binaries if global flag is set
 		if interactiveMode {
@@ -241,7 +315,7 @@ func runApply(cmd *cobra.Command, args []string) error {
 	}
 
 	// Apply package configurations
-	if err := applyPackageConfigurations(cfg, deployedFiles, deployedBinaries, logger, dryRun, useOptimization, configDir); err != nil {
+	if err := applyPackageConfigurations(cfg, deployedFiles, deployedBinaries, appliedSettings, editBackups, logger, dryRun, useOptimization, configDir); err != nil {
 		return fmt.Errorf("failed to apply package configurations: %w", err)
 	}
 
@@ -261,14 +335,15 @@ func runApply(cmd *cobra.Command, args []string) error {
 	}
 
 	// Apply backup policy if configured (only in non-dry-run mode)
-	if !dryRun && len(deployedFiles) > 0 {
-		// Load current state to get all managed files for policy enforcement
+	if !dryRun && cfg.BackupPolicy.AutoCleanup {
+		// Load current state to get the backups managed files and binaries still refer to
 		stateManager := pkg.NewStateManager(logger)
 		state, err := stateManager.LoadState()
-		if err == nil && cfg.BackupPolicy.AutoCleanup {
+		if err == nil {
 			logger.Debug("Applying backup policy")
 			fileManager := pkg.NewFileManager(logger, dryRun, configDir)
-			if err := fileManager.ApplyBackupPolicy(state.Files, cfg.BackupPolicy); err != nil {
+			fileManager.SetBackupStore(backupStore)
+			if err := fileManager.ApplyBackupPolicy(state, cfg.BackupPolicy); err != nil {
 				logger.Warn("Backup policy enforcement failed", "error", err)
 				// Don't fail the entire operation for backup policy issues
 			}
@@ -284,6 +359,25 @@ func runApply(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// expandDirectories adds the files of every directories: entry to cfg.Files
+func expandDirectories(cfg *config.Config, configDir string, logger *log.Logger, dryRun bool) error {
+	expanded, err := pkg.NewFileManager(logger, dryRun, configDir).ExpandDirectories(cfg.Directories)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Files == nil {
+		cfg.Files = make(map[string]config.File)
+	}
+	for name, file := range expanded {
+		if _, exists := cfg.Files[name]; exists {
+			return fmt.Errorf("file '%s' conflicts with a file of directory '%s'", name, strings.SplitN(name, "/", 2)[0])
+		}
+		cfg.Files[name] = file
+	}
+	return nil
+}
+
 // enableInteractiveModeOnFiles enables interactive features on all files
 func enableInteractiveModeOnFiles(files map[string]config.File) map[string]config.File {
 	for name, file := range files {
@@ -331,10 +425,45 @@ func findConfigFile() (string, error) {
 }
 
 // applyPackageConfigurations handles package management for all supported package managers
-func applyPackageConfigurations(cfg *config.Config, deployedFiles []pkg.ManagedFile, deployedBinaries []pkg.ManagedBinary, logger *log.Logger, dryRun bool, useOptimization bool, configDir string) error {
+func applyPackageConfigurations(cfg *config.Config, deployedFiles []pkg.ManagedFile, deployedBinaries []pkg.ManagedBinary, appliedSettings []pkg.ManagedSetting, editBackups map[string]string, logger *log.Logger, dryRun bool, useOptimization bool, configDir string) error {
 	// Initialize state manager for package removal tracking
 	stateManager := pkg.NewStateManager(logger)
 	
+	// Pick a concrete package for each logical package in packages.any; the choice becomes a
+	// regular entry of its manager, so state tracking and removal target the right manager
+	if len(cfg.Packages.Any) > 0 {
+		previous, err := stateManager.GetLogicalPackageChoices()
+		if err != nil {
+			logger.Warn("Could not load previous logical package choices", "error", err)
+			previous = map[string]string{}
+		}
+		if err := pkg.NewLogicalPackageManager(logger, dryRun).Resolve(cfg, previous); err != nil {
+			return fmt.Errorf("failed to resolve logical packages: %w", err)
+		}
+	}
+	
+	// Skip packages of managers the current distribution lacks (e.g., packages.apt on Fedora);
+	// they are cleared so they are neither installed nor tracked in state
+	skipUnsupportedPackageManagers(cfg, logger)
+	
+	// Resolve remote .deb URLs, Flatpak bundles and .flatpakref files to real package names
+	// before any state comparison, so installed detection, state tracking and removal use them
+	for _, manager := range pkg.RegisteredPackageManagers() {
+		packages := cfg.Packages.Get(manager)
+		if len(packages) == 0 {
+			continue
+		}
+		packageManager, err := pkg.NewPackageManager(manager, pkg.ManagerOptions{Logger: logger, DryRun: dryRun, Settings: cfg.PackageSettings[manager]})
+		if err != nil {
+			return err
+		}
+		resolved, err := pkg.ResolveEntries(packageManager, packages, configDir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s packages: %w", config.PackageManagerDisplayName(manager), err)
+		}
+		cfg.Packages.Set(manager, resolved)
+	}
+	
 	// Get packages to remove (packages in previous state but not in current config)
 	packagesToRemove, err := stateManager.GetPackagesToRemove(cfg)
 	if err != nil {
@@ -359,71 +488,94 @@ func applyPackageConfigurations(cfg *config.Config, deployedFiles []pkg.ManagedF
 		binariesToRemove = []pkg.ManagedBinary{}
 	}
 	
+	// Get blocks to remove (blocks in previous state but not in current config)
+	blocksToRemove, err := stateManager.GetBlocksToRemove(cfg)
+	if err != nil {
+		logger.Warn("Could not determine blocks to remove", "error", err)
+		blocksToRemove = []pkg.ManagedBlock{}
+	}
+	
+	// Get settings to revert (settings in previous state but not in current config)
+	settingsToRevert, err := stateManager.GetSettingsToRevert(cfg)
+	if err != nil {
+		logger.Warn("Could not determine settings to revert", "error", err)
+		settingsToRevert = []pkg.ManagedSetting{}
+	}
+	
+	// Get Flatpak overrides to reset (overrides in previous state but no longer managed)
+	overridesToReset, err := stateManager.GetFlatpakOverridesToReset(cfg)
+	if err != nil {
+		logger.Warn("Could not determine Flatpak overrides to reset", "error", err)
+		overridesToReset = []pkg.ManagedFlatpakOverride{}
+	}
+	
+	// Overrides are reset even with --remove-packages=false: dropping an overrides: block revokes them,
+	// just like an empty one does
+	if err := resetFlatpakOverridesNotInConfig(overridesToReset, logger, dryRun); err != nil {
+		return fmt.Errorf("failed to reset Flatpak overrides: %w", err)
+	}
+	
 	// Remove packages, files, and binaries that are no longer in configuration (if enabled)
 	if removePackages {
-		if err := removePackagesNotInConfig(packagesToRemove, logger, dryRun); err != nil {
+		if err := removePackagesNotInConfig(packagesToRemove, cfg.PackageSettings, logger, dryRun); err != nil {
 			return fmt.Errorf("failed to remove packages: %w", err)
 		}
-		if err := removeFilesNotInConfig(filesToRemove, configDir, logger, dryRun); err != nil {
+		keptFiles, err := removeFilesNotInConfig(filesToRemove, configDir, logger, dryRun)
+		if err != nil {
 			return fmt.Errorf("failed to remove files: %w", err)
 		}
+		// Edited secret files left in place are still managed, so a later apply can remove them
+		deployedFiles = append(deployedFiles, keptFiles...)
 		if err := removeBinariesNotInConfig(binariesToRemove, logger, dryRun); err != nil {
 			return fmt.Errorf("failed to remove binaries: %w", err)
 		}
+		if err := pkg.NewFileManager(logger, dryRun, configDir).RemoveBlocks(blocksToRemove); err != nil {
+			return fmt.Errorf("failed to remove blocks: %w", err)
+		}
+		if err := pkg.NewFileManager(logger, dryRun, configDir).RevertSettings(settingsToRevert); err != nil {
+			return fmt.Errorf("failed to revert settings: %w", err)
+		}
 	} else {
 		logger.Debug("Package, file, and binary removal disabled by --remove-packages=false flag")
 	}
-	// Handle APT packages
-	if len(cfg.Packages.Apt) > 0 {
-		logger.Debug("Applying APT package configurations", "count", len(cfg.Packages.Apt))
-		
-		if useOptimization {
-			cacheManager := pkg.NewCacheManager(logger)
-			aptManager := pkg.NewOptimizedAptManager(logger, dryRun, cacheManager)
-			if err := aptManager.InstallPackagesOptimized(cfg.Packages.Apt, cfg.PackageDefaults); err != nil {
-				return fmt.Errorf("APT package installation failed: %w", err)
-			}
-		} else {
-			aptManager := pkg.NewAptManager(logger, dryRun)
-			if err := aptManager.InstallPackages(cfg.Packages.Apt, cfg.PackageDefaults); err != nil {
-				return fmt.Errorf("APT package installation failed: %w", err)
-			}
-		}
+	
+	// Preseed debconf answers before any APT install so packages never stop on prompts
+	if err := applyDebconfSelections(cfg, logger, dryRun); err != nil {
+		return fmt.Errorf("debconf preseeding failed: %w", err)
 	}
-
-	// Handle Flatpak packages
-	if len(cfg.Packages.Flatpak) > 0 {
-		logger.Debug("Applying Flatpak package configurations", "count", len(cfg.Packages.Flatpak))
-		flatpakManager := pkg.NewFlatpakManager(logger, dryRun)
-		
-		// Validate Flatpak package names
-		if err := flatpakManager.ValidatePackageNames(cfg.Packages.Flatpak); err != nil {
-			return fmt.Errorf("Flatpak package validation failed: %w", err)
+	
+	// Install packages for every registered package manager
+	for _, manager := range pkg.RegisteredPackageManagers() {
+		packages := cfg.Packages.Get(manager)
+		if len(packages) == 0 {
+			continue
 		}
 		
-		if err := flatpakManager.InstallPackages(cfg.Packages.Flatpak, cfg.PackageDefaults); err != nil {
-			return fmt.Errorf("Flatpak package installation failed: %w", err)
+		displayName := config.PackageManagerDisplayName(manager)
+		logger.Debug(fmt.Sprintf("Applying %s package configurations", displayName), "count", len(packages))
+		
+		packageManager, err := pkg.NewPackageManager(manager, pkg.ManagerOptions{
+			Logger:          logger,
+			DryRun:          dryRun,
+			UseOptimization: useOptimization,
+			Settings:        cfg.PackageSettings[manager],
+		})
+		if err != nil {
+			return err
 		}
-	}
-
-	// Handle Snap packages
-	if len(cfg.Packages.Snap) > 0 {
-		logger.Debug("Applying Snap package configurations", "count", len(cfg.Packages.Snap))
-		snapManager := pkg.NewSnapManager(logger, dryRun)
 		
-		// Validate Snap package names
-		if err := snapManager.ValidatePackageNames(cfg.Packages.Snap); err != nil {
-			return fmt.Errorf("Snap package validation failed: %w", err)
+		if err := pkg.ValidatePackageNames(packageManager, packages); err != nil {
+			return fmt.Errorf("%s package validation failed: %w", displayName, err)
 		}
 		
-		if err := snapManager.InstallPackages(cfg.Packages.Snap, cfg.PackageDefaults); err != nil {
-			return fmt.Errorf("Snap package installation failed: %w", err)
+		if err := packageManager.InstallPackages(packages, cfg.PackageDefaults); err != nil {
+			return fmt.Errorf("%s package installation failed: %w", displayName, err)
 		}
 	}
 
 	// Update state file with current configuration (only if not dry-run)
 	if !dryRun {
-		if err := stateManager.UpdateStateWithBinaries(cfg, deployedFiles, deployedBinaries); err != nil {
+		if err := stateManager.UpdateStateWithSettings(cfg, deployedFiles, deployedBinaries, appliedSettings, editBackups); err != nil {
 			logger.Warn("Failed to update state", "error", err)
 			// Don't fail the entire operation for state tracking issues
 		}
@@ -433,60 +585,102 @@ func applyPackageConfigurations(cfg *config.Config, deployedFiles []pkg.ManagedF
 }
 
 // removePackagesNotInConfig removes packages that are no longer in the configuration
-func removePackagesNotInConfig(packagesToRemove *pkg.ManagedPackages, logger *log.Logger, dryRun bool) error {
-	// Remove APT packages
-	if len(packagesToRemove.Apt) > 0 {
-		logger.Info("Removing APT packages no longer in configuration", "count", len(packagesToRemove.Apt))
-		aptManager := pkg.NewAptManager(logger, dryRun)
-		if err := aptManager.RemovePackages(packagesToRemove.Apt); err != nil {
-			return fmt.Errorf("APT package removal failed: %w", err)
+func removePackagesNotInConfig(packagesToRemove *pkg.ManagedPackages, packageSettings map[string]map[string]string, logger *log.Logger, dryRun bool) error {
+	for _, manager := range packagesToRemove.ManagerNames() {
+		packages := packagesToRemove.Get(manager)
+		if len(packages) == 0 {
+			continue
+		}
+		
+		displayName := config.PackageManagerDisplayName(manager)
+		packageManager, err := pkg.NewPackageManager(manager, pkg.ManagerOptions{Logger: logger, DryRun: dryRun, Settings: packageSettings[manager]})
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Cannot remove %s packages: package manager is no longer supported", displayName), "packages", packages)
+			continue
+		}
+		
+		logger.Info(fmt.Sprintf("Removing %s packages no longer in configuration", displayName), "count", len(packages))
+		if err := packageManager.RemovePackages(packages); err != nil {
+			return fmt.Errorf("%s package removal failed: %w", displayName, err)
 		}
 	}
 
-	// Remove Flatpak packages
-	if len(packagesToRemove.Flatpak) > 0 {
-		logger.Info("Removing Flatpak packages no longer in configuration", "count", len(packagesToRemove.Flatpak))
-		flatpakManager := pkg.NewFlatpakManager(logger, dryRun)
-		if err := flatpakManager.RemovePackages(packagesToRemove.Flatpak); err != nil {
-			return fmt.Errorf("Flatpak package removal failed: %w", err)
-		}
+	return nil
+}
+
+// resetFlatpakOverridesNotInConfig revokes Flatpak overrides that are no longer managed by the configuration
+func resetFlatpakOverridesNotInConfig(overridesToReset []pkg.ManagedFlatpakOverride, logger *log.Logger, dryRun bool) error {
+	if len(overridesToReset) == 0 {
+		return nil
 	}
 
-	// Remove Snap packages
-	if len(packagesToRemove.Snap) > 0 {
-		logger.Info("Removing Snap packages no longer in configuration", "count", len(packagesToRemove.Snap))
-		snapManager := pkg.NewSnapManager(logger, dryRun)
-		if err := snapManager.RemovePackages(packagesToRemove.Snap); err != nil {
-			return fmt.Errorf("Snap package removal failed: %w", err)
+	flatpakManager := pkg.NewFlatpakManager(logger, dryRun)
+	for _, override := range overridesToReset {
+		if err := flatpakManager.ResetOverrides(override.App, override.Scope, override.Args); err != nil {
+			return fmt.Errorf("failed to reset overrides for %s: %w", override.App, err)
 		}
 	}
 
 	return nil
 }
 
+// applyDebconfSelections feeds top-level and per-package debconf answers to debconf-set-selections
+func applyDebconfSelections(cfg *config.Config, logger *log.Logger, dryRun bool) error {
+	selections, err := config.GetDebconfSelections(cfg)
+	if err != nil {
+		return err
+	}
+
+	return pkg.NewDebconfManager(logger, dryRun).ApplySelections(selections)
+}
+
 // removeFilesNotInConfig removes files that are no longer in the configuration
-func removeFilesNotInConfig(filesToRemove []pkg.ManagedFile, configDir string, logger *log.Logger, dryRun bool) error {
+// It returns the edited secret files that were left in place
+func removeFilesNotInConfig(filesToRemove []pkg.ManagedFile, configDir string, logger *log.Logger, dryRun bool) ([]pkg.ManagedFile, error) {
 	if len(filesToRemove) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	logger.Info("Removing files no longer in configuration", "count", len(filesToRemove))
 	fileManager := pkg.NewFileManager(logger, dryRun, configDir)
 	
-	return fileManager.RemoveFiles(filesToRemove)
+	if err := fileManager.RemoveFiles(filesToRemove); err != nil {
+		return nil, err
+	}
+	return fileManager.KeptFiles(), nil
+}
+
+// skipUnsupportedPackageManagers clears the packages of managers unavailable on the detected distribution
+func skipUnsupportedPackageManagers(cfg *config.Config, logger *log.Logger) {
+	osRelease, err := config.DetectOSRelease()
+	if err != nil {
+		logger.Debug("Could not detect distribution, applying all package managers", "error", err)
+		return
+	}
+	
+	for _, manager := range cfg.Packages.ManagerNames() {
+		packages := cfg.Packages.Get(manager)
+		if len(packages) == 0 || config.PackageManagerSupported(manager, osRelease) {
+			continue
+		}
+		logger.Warn(fmt.Sprintf("Skipping %s packages: not available on this distribution", config.PackageManagerDisplayName(manager)),
+			"distro", osRelease.DisplayName(), "count", len(packages))
+		cfg.Packages.Set(manager, nil)
+	}
 }
 
 // applyRepositoryConfigurations handles repository management for all supported repository types
 func applyRepositoryConfigurations(cfg *config.Config, logger *log.Logger, dryRun bool) error {
 	// Check if there are any repositories to process
-	if len(cfg.Repositories.Apt) == 0 && len(cfg.Repositories.Flatpak) == 0 {
+	if len(cfg.Repositories.Apt) == 0 && len(cfg.Repositories.Flatpak) == 0 && len(cfg.Repositories.Dnf) == 0 {
 		logger.Debug("No repositories to process")
 		return nil
 	}
 
 	logger.Debug("Applying repository configurations", 
 		"apt_count", len(cfg.Repositories.Apt), 
-		"flatpak_count", len(cfg.Repositories.Flatpak))
+		"flatpak_count", len(cfg.Repositories.Flatpak),
+		"dnf_count", len(cfg.Repositories.Dnf))
 	
 	repoManager := pkg.NewRepositoryManager(logger, dryRun)
 	if err := repoManager.AddRepositories(cfg.Repositories); err != nil {
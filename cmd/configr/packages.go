@@ -0,0 +1,265 @@
+package configr
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/bashfulrobot/configr/internal/pkg"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	packagesTypes []string
+	searchLimit   int
+	infoAdd       bool
+)
+
+var packagesCmd = &cobra.Command{
+	Use:   "packages",
+	Short: "Search and inspect packages across package managers",
+	Long: `Package management operations for APT, DNF, Flatpak, and Snap packages.
+
+Search every installed package manager at once, show detailed information about
+a package, and add it to your configuration file.
+
+Upgrades are handled by 'configr upgrade'.`,
+	Example: `  configr packages search gimp                     # Search all package managers
+  configr packages search editor --type apt,snap   # Search APT and Snap only
+  configr packages info org.gimp.GIMP              # Show package details
+  configr packages info ripgrep --type apt --add   # Add ripgrep to packages.apt`,
+}
+
+var packagesSearchCmd = &cobra.Command{
+	Use:   "search <search-term>",
+	Short: "Search for packages across all package managers",
+	Long: `Search every supported package manager that is installed and show the
+results side by side, one column per package manager.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPackagesSearch,
+}
+
+var packagesInfoCmd = &cobra.Command{
+	Use:   "info <package-name>",
+	Short: "Get detailed information about a package",
+	Long: `Show each package manager's description of a package.
+
+With --add the package is added to the packages section of the package manager that
+provides it. The configuration file is edited in place, so comments and formatting
+are preserved. When several package managers provide the package, choose one with --type.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPackagesInfo,
+}
+
+func init() {
+	rootCmd.AddCommand(packagesCmd)
+
+	// Add subcommands
+	packagesCmd.AddCommand(packagesSearchCmd)
+	packagesCmd.AddCommand(packagesInfoCmd)
+
+	packagesCmd.PersistentFlags().StringSliceVar(&packagesTypes, "type", nil, "package manager type (default: all supported and installed)")
+	packagesSearchCmd.Flags().IntVar(&searchLimit, "limit", 20, "maximum results shown per package manager (0 for no limit)")
+	packagesInfoCmd.Flags().BoolVar(&infoAdd, "add", false, "add the package to the configuration file")
+}
+
+// newPackagesLogger creates the logger shared by the packages subcommands
+func newPackagesLogger() *log.Logger {
+	logger := log.NewWithOptions(os.Stderr, log.Options{
+		ReportCaller:    false,
+		ReportTimestamp: false,
+		Prefix:          "configr",
+	})
+	if viper.GetBool("verbose") {
+		logger.SetLevel(log.DebugLevel)
+	}
+	return logger
+}
+
+// resolveSearchers returns the package managers to query, in registration order, with their searchers
+// Managers that aren't installed are skipped unless they were requested with --type
+func resolveSearchers(logger *log.Logger) ([]string, map[string]pkg.PackageSearcher, error) {
+	supported := pkg.SearchableManagers()
+	managers := supported
+	explicit := len(packagesTypes) > 0
+	if explicit {
+		managers = nil
+		for _, manager := range packagesTypes {
+			manager = strings.TrimSpace(manager)
+			if !containsString(supported, manager) {
+				return nil, nil, fmt.Errorf("package manager '%s' does not support search (supported: %s)", manager, strings.Join(supported, ", "))
+			}
+			managers = append(managers, manager)
+		}
+	}
+
+	searchManager := pkg.NewSearchManager(logger)
+	searchers := make(map[string]pkg.PackageSearcher)
+	var available []string
+	for _, manager := range managers {
+		searcher, err := searchManager.Searcher(manager)
+		if err != nil {
+			if explicit {
+				return nil, nil, err
+			}
+			logger.Debug("Skipping package manager", "manager", manager, "reason", err)
+			continue
+		}
+		searchers[manager] = searcher
+		available = append(available, manager)
+	}
+
+	if len(available) == 0 {
+		return nil, nil, fmt.Errorf("no supported package manager is installed (supported: %s)", strings.Join(supported, ", "))
+	}
+	return available, searchers, nil
+}
+
+func runPackagesSearch(cmd *cobra.Command, args []string) error {
+	logger := newPackagesLogger()
+	searchTerm := args[0]
+
+	managers, searchers, err := resolveSearchers(logger)
+	if err != nil {
+		return err
+	}
+
+	results := make([][]string, len(managers))
+	var failures []string
+	for i, manager := range managers {
+		packages, err := searchers[manager].SearchPackages(searchTerm)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", config.PackageManagerDisplayName(manager), err))
+		}
+		results[i] = packages
+	}
+
+	fmt.Printf("Search results for %q\n\n", searchTerm)
+	printSearchColumns(managers, results)
+
+	if len(failures) > 0 {
+		fmt.Println()
+		for _, failure := range failures {
+			fmt.Printf("✗ %s\n", failure)
+		}
+	}
+
+	fmt.Printf("\nUse 'configr packages info <package-name> --add' to add a package to your configuration.\n")
+	return nil
+}
+
+// printSearchColumns prints search results side by side, one column per package manager
+func printSearchColumns(managers []string, results [][]string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+
+	headers := make([]string, len(managers))
+	rules := make([]string, len(managers))
+	rows := 0
+	for i, manager := range managers {
+		headers[i] = fmt.Sprintf("%s (%d)", config.PackageManagerDisplayName(manager), len(results[i]))
+		rules[i] = strings.Repeat("-", len(headers[i]))
+		shown := len(results[i])
+		if searchLimit > 0 && shown > searchLimit {
+			shown = searchLimit + 1 // Room for the "more" line
+		}
+		if shown == 0 {
+			shown = 1 // Room for the "no results" line
+		}
+		if shown > rows {
+			rows = shown
+		}
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	fmt.Fprintln(w, strings.Join(rules, "\t"))
+
+	for row := 0; row < rows; row++ {
+		cells := make([]string, len(managers))
+		for i, packages := range results {
+			cells[i] = searchCell(packages, row)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+}
+
+// searchCell returns the text of one search result cell, honoring --limit
+func searchCell(packages []string, row int) string {
+	switch {
+	case len(packages) == 0 && row == 0:
+		return "(no results)"
+	case searchLimit > 0 && row == searchLimit && len(packages) > searchLimit:
+		return fmt.Sprintf("… %d more", len(packages)-searchLimit)
+	case row < len(packages) && (searchLimit <= 0 || row < searchLimit):
+		return packages[row]
+	default:
+		return ""
+	}
+}
+
+func runPackagesInfo(cmd *cobra.Command, args []string) error {
+	logger := newPackagesLogger()
+	packageName := args[0]
+
+	managers, searchers, err := resolveSearchers(logger)
+	if err != nil {
+		return err
+	}
+
+	var found []string
+	for _, manager := range managers {
+		info, err := searchers[manager].GetPackageInfo(packageName)
+		if err != nil {
+			logger.Debug("Package not found", "manager", manager, "package", packageName, "error", err)
+			continue
+		}
+		found = append(found, manager)
+
+		displayName := config.PackageManagerDisplayName(manager)
+		fmt.Printf("%s\n%s\n", displayName, strings.Repeat("=", len(displayName)))
+		fmt.Printf("%s\n\n", strings.TrimSpace(info))
+	}
+
+	if len(found) == 0 {
+		return fmt.Errorf("package '%s' not found (searched: %s)", packageName, strings.Join(managers, ", "))
+	}
+
+	if !infoAdd {
+		return nil
+	}
+	if len(found) > 1 {
+		return fmt.Errorf("package '%s' is provided by %s; choose one with --type", packageName, strings.Join(found, ", "))
+	}
+	return addPackageToConfig(found[0], packageName)
+}
+
+// addPackageToConfig adds a package to packages.<manager> of the configuration file
+func addPackageToConfig(manager, packageName string) error {
+	if spec, registered := config.LookupPackageManager(manager); registered && spec.ValidateName != nil && !spec.ValidateName(packageName) {
+		return fmt.Errorf("%s: %s (%s)", spec.NameMessage, packageName, spec.NameHelp)
+	}
+
+	configPath := viper.GetString("config")
+	if configPath == "" {
+		var err error
+		configPath, err = findConfigFile()
+		if err != nil {
+			return fmt.Errorf("failed to find config file: %w", err)
+		}
+	}
+
+	added, err := config.AddPackageToFile(configPath, manager, packageName)
+	if err != nil {
+		return err
+	}
+	if !added {
+		config.Info("%s is already in packages.%s of %s", packageName, manager, configPath)
+		return nil
+	}
+
+	config.Success("Added %s to packages.%s in %s", packageName, manager, configPath)
+	return nil
+}
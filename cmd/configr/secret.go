@@ -0,0 +1,70 @@
+package configr
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/pkg"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+)
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage age-encrypted secrets",
+	Long: `Secret management commands for age-encrypted file sources.
+
+Secrets are decrypted with the age identity from $CONFIGR_AGE_IDENTITY (a key file
+path or the key itself), or ~/.config/configr/age.key by default.`,
+	Example: `  configr secret edit secrets/npmrc.age    # Edit an encrypted file`,
+}
+
+var secretEditCmd = &cobra.Command{
+	Use:   "edit <file>",
+	Short: "Edit an age-encrypted file",
+	Long: `Decrypt an age-encrypted file, open it in $EDITOR, and re-encrypt it on save.
+
+The plaintext is kept in a private temporary file (in /dev/shm where available) that is
+removed once the editor exits.
+
+The file is encrypted to the recipients in the nearest .age-recipients file, in its
+directory or a parent directory. Without one, a missing file is created encrypted to
+your identity, and an existing file is left alone so other recipients aren't locked out.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSecretEdit,
+}
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+
+	// Add subcommands
+	secretCmd.AddCommand(secretEditCmd)
+}
+
+func runSecretEdit(cmd *cobra.Command, args []string) error {
+	logger := log.NewWithOptions(os.Stderr, log.Options{
+		ReportCaller:    false,
+		ReportTimestamp: false,
+		Prefix:          "configr",
+	})
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+
+	secretManager := pkg.NewSecretManager(logger)
+	changed, err := secretManager.EditEncryptedFile(args[0], editor)
+	if err != nil {
+		return fmt.Errorf("failed to edit secret: %w", err)
+	}
+
+	if !changed {
+		logger.Info("No changes", "file", args[0])
+		return nil
+	}
+
+	logger.Info("✓ Secret saved", "file", args[0])
+	return nil
+}
@@ -0,0 +1,218 @@
+package configr
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/bashfulrobot/configr/internal/pkg"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	upgradeManagers    []string
+	upgradeOnlyManaged bool
+	upgradeDryRun      bool
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade installed packages across package managers",
+	Long: `Upgrade lists the available updates of every supported package manager
+(APT, DNF, Flatpak and Snap) and upgrades them.
+
+By default all packages are upgraded, exactly as the package manager itself would.
+With --only-managed, only packages that configr installed (tracked in its state file)
+are upgraded.
+
+Held packages are never upgraded:
+- APT: packages held with 'apt-mark hold' (APT preferences pins are applied by apt)
+- Flatpak: refs matching a 'flatpak mask' pattern
+- Snap: snaps held with 'snap refresh --hold'
+- DNF: packages locked with 'dnf versionlock' are not offered by dnf
+
+A summary is printed for each package manager.`,
+	Example: `  configr upgrade                          # Upgrade everything
+  configr upgrade --dry-run                # List what would be upgraded
+  configr upgrade --manager apt,flatpak    # Only upgrade APT and Flatpak packages
+  configr upgrade --only-managed           # Only upgrade packages configr manages`,
+	Args: cobra.NoArgs,
+	RunE: runUpgrade,
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+
+	upgradeCmd.Flags().StringSliceVar(&upgradeManagers, "manager", nil, "package managers to upgrade (default: all supported and installed)")
+	upgradeCmd.Flags().BoolVar(&upgradeOnlyManaged, "only-managed", false, "only upgrade packages managed by configr")
+	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "list upgrades without applying them")
+}
+
+// upgradeSummary is the outcome of upgrading one package manager
+type upgradeSummary struct {
+	manager string
+	plan    *pkg.UpgradePlan
+	skipped string // Reason the manager was skipped
+	err     error
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	logger := log.NewWithOptions(os.Stderr, log.Options{
+		ReportCaller:    false,
+		ReportTimestamp: false,
+		Prefix:          "configr",
+	})
+	if viper.GetBool("verbose") {
+		logger.SetLevel(log.DebugLevel)
+	}
+
+	supported := pkg.UpgradableManagers()
+	managers := supported
+	explicit := len(upgradeManagers) > 0
+	if explicit {
+		managers = nil
+		for _, manager := range upgradeManagers {
+			manager = strings.TrimSpace(manager)
+			if !containsString(supported, manager) {
+				return fmt.Errorf("package manager '%s' does not support upgrades (supported: %s)", manager, strings.Join(supported, ", "))
+			}
+			managers = append(managers, manager)
+		}
+	}
+
+	var managed *pkg.ManagedPackages
+	if upgradeOnlyManaged {
+		state, err := pkg.NewStateManager(logger).LoadState()
+		if err != nil {
+			return fmt.Errorf("failed to load package state: %w", err)
+		}
+		managed = &state.Packages
+	}
+
+	upgradeManager := pkg.NewUpgradeManager(logger, upgradeDryRun)
+	var summaries []upgradeSummary
+
+	for _, manager := range managers {
+		summary := upgradeSummary{manager: manager}
+		displayName := config.PackageManagerDisplayName(manager)
+
+		upgrader, err := upgradeManager.Upgrader(manager)
+		if err != nil {
+			// Managers that aren't installed are only an error when requested explicitly
+			if explicit {
+				summary.err = err
+			} else {
+				summary.skipped = err.Error()
+			}
+			summaries = append(summaries, summary)
+			continue
+		}
+
+		var managedPackages []string
+		if managed != nil {
+			managedPackages = managed.Get(manager)
+			if len(managedPackages) == 0 {
+				summary.skipped = "no managed packages"
+				summaries = append(summaries, summary)
+				continue
+			}
+		}
+
+		plan, err := upgradeManager.Plan(manager, upgrader, managedPackages, upgradeOnlyManaged)
+		if err != nil {
+			summary.err = err
+			summaries = append(summaries, summary)
+			continue
+		}
+		summary.plan = plan
+		printUpgradePlan(displayName, plan)
+
+		if !upgradeDryRun {
+			summary.err = upgradeManager.Apply(upgrader, plan, upgradeOnlyManaged)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return printUpgradeSummary(summaries)
+}
+
+// printUpgradePlan lists the upgrades and held packages of a package manager
+func printUpgradePlan(displayName string, plan *pkg.UpgradePlan) {
+	fmt.Printf("\n%s: %d upgradable", displayName, len(plan.Upgrades))
+	if len(plan.Held) > 0 {
+		fmt.Printf(", %d held", len(plan.Held))
+	}
+	if plan.Unmanaged > 0 {
+		fmt.Printf(", %d not managed by configr", plan.Unmanaged)
+	}
+	fmt.Println()
+
+	for _, candidate := range plan.Upgrades {
+		fmt.Printf("  • %s %s\n", candidate.Name, formatVersionChange(candidate))
+	}
+	for _, candidate := range plan.Held {
+		fmt.Printf("  ⏸ %s %s (held)\n", candidate.Name, formatVersionChange(candidate))
+	}
+}
+
+// formatVersionChange formats "old → new" with whichever versions are known
+func formatVersionChange(candidate pkg.UpgradeCandidate) string {
+	switch {
+	case candidate.CurrentVersion != "" && candidate.NewVersion != "":
+		return fmt.Sprintf("%s → %s", candidate.CurrentVersion, candidate.NewVersion)
+	case candidate.NewVersion != "":
+		return "→ " + candidate.NewVersion
+	default:
+		return ""
+	}
+}
+
+// printUpgradeSummary prints one line per package manager and returns an error if any manager failed
+func printUpgradeSummary(summaries []upgradeSummary) error {
+	fmt.Printf("\nUpgrade Summary\n")
+	fmt.Printf("===============\n")
+
+	var failed []string
+	for _, summary := range summaries {
+		displayName := config.PackageManagerDisplayName(summary.manager)
+		switch {
+		case summary.err != nil:
+			fmt.Printf("✗ %s: %v\n", displayName, summary.err)
+			failed = append(failed, displayName)
+		case summary.skipped != "":
+			fmt.Printf("- %s: skipped (%s)\n", displayName, summary.skipped)
+		case len(summary.plan.Upgrades) == 0:
+			fmt.Printf("✓ %s: up to date%s\n", displayName, heldSuffix(summary.plan))
+		case upgradeDryRun:
+			fmt.Printf("• %s: %d would be upgraded%s\n", displayName, len(summary.plan.Upgrades), heldSuffix(summary.plan))
+		default:
+			fmt.Printf("✓ %s: %d upgraded%s\n", displayName, len(summary.plan.Upgrades), heldSuffix(summary.plan))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("upgrade failed for: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// heldSuffix describes held packages for the summary line
+func heldSuffix(plan *pkg.UpgradePlan) string {
+	if len(plan.Held) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", %d held", len(plan.Held))
+}
+
+// containsString reports whether a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
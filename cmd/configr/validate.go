@@ -5,6 +5,8 @@ import (
 	"os"
 
 	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/bashfulrobot/configr/internal/pkg"
+	"github.com/charmbracelet/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -13,10 +15,15 @@ var validateCmd = &cobra.Command{
 	Use:   "validate [config-file]",
 	Short: "Validate configuration file",
 	Long: `Validate the configuration file for syntax errors, missing files, 
-and other issues without making any changes to the system.`,
+and other issues without making any changes to the system.
+
+With --online, APT, Flatpak and Snap packages are also looked up in their package
+sources (APT including the repositories in the configuration), so misspelled or
+unavailable packages are reported before apply. Results are cached for a day.`,
 	Example: `  configr validate                    # Validate default config
   configr validate my-config.yaml     # Validate specific file
-  configr validate --verbose          # Show detailed validation info`,
+  configr validate --verbose          # Show detailed validation info
+  configr validate --online           # Also check that packages exist`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Set verbose mode if requested
@@ -46,6 +53,20 @@ and other issues without making any changes to the system.`,
 		// Validate configuration
 		result := config.Validate(cfg, viper.ConfigFileUsed())
 
+		// Check package availability against the package managers' sources
+		if online, _ := cmd.Flags().GetBool("online"); online {
+			logger := log.NewWithOptions(os.Stderr, log.Options{
+				ReportCaller:    false,
+				ReportTimestamp: false,
+				Prefix:          "configr",
+			})
+			if verbose {
+				logger.SetLevel(log.DebugLevel)
+			}
+			config.Info("Checking package availability online...")
+			pkg.NewOnlineValidator(logger, pkg.NewCacheManager(logger)).Validate(cfg, viper.ConfigFileUsed(), result)
+		}
+
 		// Show results
 		if result.HasErrors() {
 			fmt.Print(config.FormatValidationResultSimple(result))
@@ -61,7 +82,7 @@ and other issues without making any changes to the system.`,
 		
 		if verbose {
 			config.Debug("Found %d package definitions", 
-				len(cfg.Packages.Apt)+len(cfg.Packages.Flatpak)+len(cfg.Packages.Snap))
+				cfg.Packages.Count())
 			config.Debug("Found %d file definitions", len(cfg.Files))
 			config.Debug("Found %d dconf settings", len(cfg.DConf.Settings))
 		}
@@ -71,4 +92,5 @@ and other issues without making any changes to the system.`,
 func init() {
 	rootCmd.AddCommand(validateCmd)
 	validateCmd.Flags().BoolP("verbose", "v", false, "Show detailed validation information")
+	validateCmd.Flags().Bool("online", false, "Check that packages are available from their package sources")
 }
\ No newline at end of file
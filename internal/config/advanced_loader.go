@@ -16,15 +16,18 @@ type AdvancedLoader struct {
 	visited   map[string]bool
 	hostname  string
 	osName    string
+	osRelease OSRelease
 }
 
 // NewAdvancedLoader creates a new advanced configuration loader
 func NewAdvancedLoader() *AdvancedLoader {
 	hostname, _ := os.Hostname()
+	osRelease, _ := DetectOSRelease() // Unknown distribution when /etc/os-release is missing
 	return &AdvancedLoader{
-		visited:  make(map[string]bool),
-		hostname: hostname,
-		osName:   runtime.GOOS,
+		visited:   make(map[string]bool),
+		hostname:  hostname,
+		osName:    runtime.GOOS,
+		osRelease: osRelease,
 	}
 }
 
@@ -191,6 +194,17 @@ func (al *AdvancedLoader) evaluateCondition(condition IncludeCondition) bool {
 		actualValue = al.osName
 	case "hostname":
 		actualValue = al.hostname
+	case "distro":
+		// equals/not_equals also match derived distributions (ID_LIKE), e.g. "debian" on Ubuntu
+		switch operator {
+		case "equals":
+			return al.osRelease.Is(condition.Value)
+		case "not_equals":
+			return !al.osRelease.Is(condition.Value)
+		}
+		actualValue = al.osRelease.ID
+	case "distro_version":
+		actualValue = al.osRelease.VersionID
 	case "env":
 		// For env conditions, the Value should be "VAR_NAME=expected_value"
 		if strings.Contains(condition.Value, "=") {
@@ -261,7 +275,7 @@ func (al *AdvancedLoader) validateCondition(condition IncludeCondition) error {
 		return fmt.Errorf("condition type is required")
 	}
 
-	validTypes := []string{"os", "hostname", "env", "file_exists", "dir_exists"}
+	validTypes := []string{"os", "distro", "distro_version", "hostname", "env", "file_exists", "dir_exists"}
 	typeValid := false
 	for _, validType := range validTypes {
 		if condition.Type == validType {
@@ -302,6 +316,9 @@ func (al *AdvancedLoader) GetSystemInfo() map[string]string {
 	info := make(map[string]string)
 	info["os"] = al.osName
 	info["hostname"] = al.hostname
+	info["distro"] = al.osRelease.ID
+	info["distro_like"] = strings.Join(al.osRelease.IDLike, " ")
+	info["distro_version"] = al.osRelease.VersionID
 	info["goos"] = runtime.GOOS
 	info["goarch"] = runtime.GOARCH
 	
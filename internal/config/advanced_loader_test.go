@@ -486,8 +486,8 @@ includes:
 	
 	// Verify packages were merged
 	expectedPackages := []string{"curl", "git", "vim"}
-	if len(config.Packages.Apt) != len(expectedPackages) {
-		t.Errorf("Expected %d APT packages, got %d", len(expectedPackages), len(config.Packages.Apt))
+	if len(config.Packages.Get("apt")) != len(expectedPackages) {
+		t.Errorf("Expected %d APT packages, got %d", len(expectedPackages), len(config.Packages.Get("apt")))
 	}
 	
 	// Verify paths were tracked
@@ -652,8 +652,8 @@ files:
 	}
 	
 	// Verify packages were merged from glob includes
-	if len(config.Packages.Apt) < 3 { // base + at least packages from glob
-		t.Errorf("Expected at least 3 APT packages, got %d", len(config.Packages.Apt))
+	if len(config.Packages.Get("apt")) < 3 { // base + at least packages from glob
+		t.Errorf("Expected at least 3 APT packages, got %d", len(config.Packages.Get("apt")))
 	}
 	
 	// Verify paths tracking
@@ -755,7 +755,7 @@ packages:
 			basePackageFound := false
 			conditionalPackageFound := false
 			
-			for _, pkg := range config.Packages.Apt {
+			for _, pkg := range config.Packages.Get("apt") {
 				if pkg.Name == "base-package" {
 					basePackageFound = true
 				}
@@ -764,7 +764,7 @@ packages:
 				}
 			}
 			
-			for _, pkg := range config.Packages.Flatpak {
+			for _, pkg := range config.Packages.Get("flatpak") {
 				if pkg.Name == "org.example.HostnameApp" {
 					conditionalPackageFound = true
 				}
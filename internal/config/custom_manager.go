@@ -0,0 +1,197 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// CustomManager defines a package manager declaratively with command templates
+// Templates are rendered with text/template and run with sh -c; available fields:
+//
+//	{{.Name}}    package name (without "@version")
+//	{{.Version}} version from a "name@version" entry (empty if not pinned)
+//	{{.Flags}}   resolved flags joined with spaces
+//	{{.Names}}   all packages of a batch, with "@version" when pinned (install and remove only; enables batching)
+//
+// Every value is shell-quoted, so templates must not quote the fields again
+type CustomManager struct {
+	DisplayName  string   `yaml:"display_name,omitempty" mapstructure:"display_name,omitempty"`   // Human-readable name (defaults to the key)
+	Install      string   `yaml:"install" mapstructure:"install"`                                 // Installs a package (or a batch with {{.Names}})
+	Remove       string   `yaml:"remove" mapstructure:"remove"`                                   // Removes a package (or a batch with {{.Names}})
+	IsInstalled  string   `yaml:"is_installed" mapstructure:"is_installed"`                       // Exits 0 if the package is installed
+	List         string   `yaml:"list,omitempty" mapstructure:"list,omitempty"`                   // Optional: prints "name [version]" per installed package
+	DefaultFlags []string `yaml:"default_flags,omitempty" mapstructure:"default_flags,omitempty"` // Built-in default flags (tier 1)
+	NamePattern  string   `yaml:"name_pattern,omitempty" mapstructure:"name_pattern,omitempty"`   // Optional regular expression for package names
+}
+
+// customManagerNamePattern matches valid custom manager names (keys under custom_managers:)
+var customManagerNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// defaultCustomPackagePattern matches package names when a custom manager defines no name_pattern
+// Names are substituted into shell commands, so shell metacharacters are not allowed
+var defaultCustomPackagePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+:/-]*(@[A-Za-z0-9._+-]+)?$`)
+
+// customVersionPattern matches the version part of a "name@version" entry
+// Other "@" suffixes stay part of the name (e.g., GNOME extension UUIDs like "dash-to-panel@jderose9.github.com")
+var customVersionPattern = regexp.MustCompile(`^(v?[0-9][A-Za-z0-9._+-]*|latest)$`)
+
+// customManagerNames records managers registered from custom_managers, which may be redefined
+var customManagerNames = make(map[string]bool)
+
+// SplitCustomPackage splits a "name@version" entry into name and version
+func SplitCustomPackage(spec string) (string, string) {
+	if index := strings.LastIndex(spec, "@"); index > 0 && customVersionPattern.MatchString(spec[index+1:]) {
+		return spec[:index], spec[index+1:]
+	}
+	return spec, ""
+}
+
+// CustomManagerSpec returns the configuration spec of a custom manager
+func CustomManagerSpec(name string, manager CustomManager) PackageManagerSpec {
+	pattern := defaultCustomPackagePattern
+	if manager.NamePattern != "" {
+		if compiled, err := regexp.Compile(manager.NamePattern); err == nil {
+			pattern = compiled
+		}
+	}
+
+	displayName := manager.DisplayName
+	if displayName == "" {
+		displayName = name
+	}
+
+	definition := manager
+	return PackageManagerSpec{
+		Name:         name,
+		DisplayName:  displayName,
+		DefaultFlags: manager.DefaultFlags,
+		ValidateName: func(packageName string) bool {
+			return pattern.MatchString(packageName) && !strings.ContainsAny(packageName, " \t\n;&|`$<>\\\"'")
+		},
+		NameMessage: fmt.Sprintf("%s package name contains invalid characters", displayName),
+		NameHelp:    "use letters, numbers, dots, hyphens, underscores, plus signs, colons and slashes, with an optional @version",
+		Custom:      &definition,
+	}
+}
+
+// RegisterCustomManagers registers the managers defined under custom_managers so their
+// packages.<name> lists are decoded, validated and applied like built-in managers
+// Built-in manager names cannot be redefined
+func RegisterCustomManagers(cfg *Config) {
+	names := make([]string, 0, len(cfg.CustomManagers))
+	for name := range cfg.CustomManagers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, registered := LookupPackageManager(name); registered && !customManagerNames[name] {
+			continue
+		}
+		if !customManagerNamePattern.MatchString(name) || name == "any" {
+			continue
+		}
+		RegisterPackageManager(CustomManagerSpec(name, cfg.CustomManagers[name]))
+		customManagerNames[name] = true
+	}
+}
+
+// validateCustomManagers validates the custom_managers section
+func validateCustomManagers(managers map[string]CustomManager, result *ValidationResult) {
+	names := make([]string, 0, len(managers))
+	for name := range managers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		manager := managers[name]
+		field := fmt.Sprintf("custom_managers.%s", name)
+
+		if !customManagerNamePattern.MatchString(name) || name == "any" {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "invalid custom manager name",
+				Field:   field,
+				Value:   name,
+				Message: "custom manager names must be lowercase letters, numbers, hyphens and underscores",
+				Help:    "use a name like 'gext' or 'helm-plugins' ('any' is reserved for logical packages)",
+			})
+			continue
+		}
+
+		if _, registered := LookupPackageManager(name); registered && !customManagerNames[name] {
+			result.Add(ValidationError{
+				Type:       "error",
+				Title:      "custom manager conflicts with built-in",
+				Field:      field,
+				Value:      name,
+				Message:    fmt.Sprintf("'%s' is a built-in package manager and cannot be redefined", name),
+				Suggestion: "choose a different name for this custom manager",
+			})
+			continue
+		}
+
+		commands := []struct {
+			key      string
+			template string
+			required bool
+		}{
+			{"install", manager.Install, true},
+			{"remove", manager.Remove, true},
+			{"is_installed", manager.IsInstalled, true},
+			{"list", manager.List, false},
+		}
+		for _, command := range commands {
+			if strings.TrimSpace(command.template) == "" {
+				if command.required {
+					result.Add(ValidationError{
+						Type:    "error",
+						Title:   "missing custom manager command",
+						Field:   fmt.Sprintf("%s.%s", field, command.key),
+						Message: fmt.Sprintf("custom manager '%s' must define a '%s' command", name, command.key),
+						Help:    "commands are templates like 'gext install {{.Name}}'",
+					})
+				}
+				continue
+			}
+			if _, err := template.New(command.key).Option("missingkey=error").Parse(command.template); err != nil {
+				result.Add(ValidationError{
+					Type:    "error",
+					Title:   "invalid command template",
+					Field:   fmt.Sprintf("%s.%s", field, command.key),
+					Value:   command.template,
+					Message: err.Error(),
+					Help:    "available fields: {{.Name}}, {{.Version}}, {{.Flags}}, {{.Names}}",
+				})
+			}
+		}
+
+		if strings.Contains(manager.IsInstalled, ".Names") {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "invalid command template",
+				Field:   fmt.Sprintf("%s.is_installed", field),
+				Value:   manager.IsInstalled,
+				Message: "is_installed checks one package and cannot use {{.Names}}",
+				Help:    "use {{.Name}} instead",
+			})
+		}
+
+		if manager.NamePattern != "" {
+			if _, err := regexp.Compile(manager.NamePattern); err != nil {
+				result.Add(ValidationError{
+					Type:    "error",
+					Title:   "invalid name pattern",
+					Field:   fmt.Sprintf("%s.name_pattern", field),
+					Value:   manager.NamePattern,
+					Message: err.Error(),
+					Help:    "name_pattern must be a Go regular expression like '^[a-z0-9-]+$'",
+				})
+			}
+		}
+	}
+}
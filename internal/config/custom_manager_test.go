@@ -0,0 +1,163 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// restoreCustomManagers undoes custom manager registrations made during a test
+func restoreCustomManagers(t *testing.T) {
+	t.Helper()
+	savedSpecs := append([]PackageManagerSpec{}, packageManagerSpecs...)
+	savedFlags := make(map[string][]string)
+	for name, flags := range DefaultPackageFlags {
+		savedFlags[name] = flags
+	}
+	t.Cleanup(func() {
+		packageManagerSpecs = savedSpecs
+		DefaultPackageFlags = savedFlags
+		customManagerNames = make(map[string]bool)
+	})
+}
+
+func TestCustomManagers_DecodeAndValidate(t *testing.T) {
+	restoreCustomManagers(t)
+
+	yamlData := `
+version: "1.0"
+custom_managers:
+  gext:
+    display_name: GNOME Extensions
+    install: "gext install {{.Flags}} {{.Name}}"
+    remove: "gext uninstall {{.Name}}"
+    is_installed: "gext list --only-uuid | grep -qx {{.Name}}"
+    default_flags: ["--no-prompt"]
+packages:
+  gext:
+    - dash-to-panel@jderose9.github.com
+    - "appindicatorsupport@rgcjonas.gmail.com":
+        flags: ["--user"]
+`
+
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(yamlData), &cfg); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	result := Validate(&cfg, "test.yaml")
+	if result.HasErrors() {
+		t.Fatalf("expected custom manager config to be valid, got %+v", result.Errors)
+	}
+
+	if PackageManagerDisplayName("gext") != "GNOME Extensions" {
+		t.Errorf("expected display name GNOME Extensions, got %s", PackageManagerDisplayName("gext"))
+	}
+	if flags := GetDefaultFlags("gext"); len(flags) != 1 || flags[0] != "--no-prompt" {
+		t.Errorf("expected default flags [--no-prompt], got %v", flags)
+	}
+	if len(cfg.Packages.Get("gext")) != 2 {
+		t.Errorf("expected 2 gext packages, got %v", cfg.Packages.Get("gext"))
+	}
+}
+
+func TestValidate_CustomManagers(t *testing.T) {
+	valid := CustomManager{
+		Install:     "mise use -g {{.Name}}@{{.Version}}",
+		Remove:      "mise uninstall {{.Name}}",
+		IsInstalled: "mise where {{.Name}}",
+	}
+
+	tests := []struct {
+		name          string
+		managers      map[string]CustomManager
+		packages      []PackageEntry
+		expectedTitle string
+	}{
+		{
+			name:     "valid manager and packages",
+			managers: map[string]CustomManager{"mise": valid},
+			packages: []PackageEntry{{Name: "node@20.11.0"}, {Name: "python"}},
+		},
+		{
+			name:          "missing command",
+			managers:      map[string]CustomManager{"mise": {Install: valid.Install, Remove: valid.Remove}},
+			expectedTitle: "missing custom manager command",
+		},
+		{
+			name:          "invalid template",
+			managers:      map[string]CustomManager{"mise": {Install: "mise use {{.Name", Remove: valid.Remove, IsInstalled: valid.IsInstalled}},
+			expectedTitle: "invalid command template",
+		},
+		{
+			name:          "built-in name",
+			managers:      map[string]CustomManager{"apt": valid},
+			expectedTitle: "custom manager conflicts with built-in",
+		},
+		{
+			name:          "invalid manager name",
+			managers:      map[string]CustomManager{"My Tools": valid},
+			expectedTitle: "invalid custom manager name",
+		},
+		{
+			name:          "shell metacharacters in package name",
+			managers:      map[string]CustomManager{"mise": valid},
+			packages:      []PackageEntry{{Name: "node; rm -rf ~"}},
+			expectedTitle: "invalid package name",
+		},
+		{
+			name:          "custom name pattern",
+			managers:      map[string]CustomManager{"mise": {Install: valid.Install, Remove: valid.Remove, IsInstalled: valid.IsInstalled, NamePattern: "^[a-z]+$"}},
+			packages:      []PackageEntry{{Name: "Node"}},
+			expectedTitle: "invalid package name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restoreCustomManagers(t)
+
+			cfg := &Config{Version: "1.0", CustomManagers: tt.managers}
+			if tt.packages != nil {
+				cfg.Packages.Set("mise", tt.packages)
+			}
+			result := Validate(cfg, "test.yaml")
+
+			if tt.expectedTitle == "" {
+				if result.HasErrors() {
+					t.Errorf("expected no errors, got %+v", result.Errors)
+				}
+				return
+			}
+			found := false
+			for _, err := range result.Errors {
+				if err.Title == tt.expectedTitle {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected error %q, got %+v", tt.expectedTitle, result.Errors)
+			}
+		})
+	}
+}
+
+func TestSplitCustomPackage(t *testing.T) {
+	tests := []struct {
+		spec    string
+		name    string
+		version string
+	}{
+		{"node", "node", ""},
+		{"node@20.11.0", "node", "20.11.0"},
+		{"kubectl-ns@v0.9.5", "kubectl-ns", "v0.9.5"},
+		{"dash-to-panel@jderose9.github.com", "dash-to-panel@jderose9.github.com", ""},
+	}
+
+	for _, tt := range tests {
+		name, version := SplitCustomPackage(tt.spec)
+		if name != tt.name || version != tt.version {
+			t.Errorf("SplitCustomPackage(%q) = (%q, %q), expected (%q, %q)", tt.spec, name, version, tt.name, tt.version)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DebconfSelection represents a single debconf answer in debconf-set-selections format:
+//
+//	<owner> <question> <type> <value>
+type DebconfSelection struct {
+	Owner    string // Package that owns the question (e.g., "wireshark-common")
+	Question string // Question name (e.g., "wireshark-common/install-setuid")
+	Type     string // Question type (e.g., "boolean", "select", "string")
+	Value    string // Answer value (may contain spaces or be empty)
+}
+
+// debconfSelectionPattern splits a selection line into owner, question, type and the remaining value
+var debconfSelectionPattern = regexp.MustCompile(`^\s*(\S+)\s+(\S+)\s+(\S+)(?:\s+(.*?))?\s*$`)
+
+// debconfTypes lists the question types understood by debconf
+var debconfTypes = map[string]bool{
+	"boolean":     true,
+	"string":      true,
+	"select":      true,
+	"multiselect": true,
+	"note":        true,
+	"text":        true,
+	"password":    true,
+	"title":       true,
+	"error":       true,
+	"seen":        true,
+}
+
+// ParseDebconfSelection parses a line in debconf-set-selections format
+func ParseDebconfSelection(line string) (DebconfSelection, error) {
+	matches := debconfSelectionPattern.FindStringSubmatch(line)
+	if matches == nil {
+		return DebconfSelection{}, fmt.Errorf("selection must have the form '<package> <question> <type> <value>'")
+	}
+
+	selection := DebconfSelection{
+		Owner:    matches[1],
+		Question: matches[2],
+		Type:     matches[3],
+		Value:    matches[4],
+	}
+
+	if err := selection.Validate(); err != nil {
+		return DebconfSelection{}, err
+	}
+
+	return selection, nil
+}
+
+// ParseDebconfAnswer parses a per-package debconf answer ("<type> <value>") for the given question
+func ParseDebconfAnswer(owner, question, answer string) (DebconfSelection, error) {
+	return ParseDebconfSelection(fmt.Sprintf("%s %s %s", owner, question, answer))
+}
+
+// Validate checks that the selection can be fed to debconf-set-selections
+func (ds DebconfSelection) Validate() error {
+	if !isValidPackageName(ds.Owner) {
+		return fmt.Errorf("invalid package name '%s'", ds.Owner)
+	}
+
+	if !strings.Contains(ds.Question, "/") {
+		return fmt.Errorf("question '%s' must be namespaced (e.g., '%s/question')", ds.Question, ds.Owner)
+	}
+
+	if !debconfTypes[ds.Type] {
+		return fmt.Errorf("unknown question type '%s' (expected boolean, string, select, multiselect, note, text, password, title, error or seen)", ds.Type)
+	}
+
+	if ds.Type == "boolean" && ds.Value != "true" && ds.Value != "false" {
+		return fmt.Errorf("boolean question '%s' must be 'true' or 'false', got '%s'", ds.Question, ds.Value)
+	}
+
+	return nil
+}
+
+// String returns the selection in debconf-set-selections format
+func (ds DebconfSelection) String() string {
+	return fmt.Sprintf("%s %s %s %s", ds.Owner, ds.Question, ds.Type, ds.Value)
+}
+
+// GetDebconfSelections collects the top-level debconf_selections and the debconf answers
+// declared on APT package entries, in that order
+func GetDebconfSelections(cfg *Config) ([]DebconfSelection, error) {
+	var selections []DebconfSelection
+
+	for _, line := range cfg.DebconfSelections {
+		selection, err := ParseDebconfSelection(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid debconf selection '%s': %w", line, err)
+		}
+		selections = append(selections, selection)
+	}
+
+	for _, pkg := range cfg.Packages.Get("apt") {
+		// Sort questions so the selections are applied in a stable order
+		questions := make([]string, 0, len(pkg.Debconf))
+		for question := range pkg.Debconf {
+			questions = append(questions, question)
+		}
+		sort.Strings(questions)
+
+		for _, question := range questions {
+			selection, err := ParseDebconfAnswer(pkg.Name, question, pkg.Debconf[question])
+			if err != nil {
+				return nil, fmt.Errorf("invalid debconf answer for %s: %w", pkg.Name, err)
+			}
+			selections = append(selections, selection)
+		}
+	}
+
+	return selections, nil
+}
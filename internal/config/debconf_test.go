@@ -0,0 +1,107 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseDebconfSelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		expected  DebconfSelection
+		expectErr bool
+	}{
+		{
+			name:     "boolean selection",
+			line:     "wireshark-common wireshark-common/install-setuid boolean true",
+			expected: DebconfSelection{Owner: "wireshark-common", Question: "wireshark-common/install-setuid", Type: "boolean", Value: "true"},
+		},
+		{
+			name:     "value with spaces",
+			line:     "postfix postfix/main_mailer_type select Internet Site",
+			expected: DebconfSelection{Owner: "postfix", Question: "postfix/main_mailer_type", Type: "select", Value: "Internet Site"},
+		},
+		{
+			name:     "empty value",
+			line:     "postfix postfix/relayhost string",
+			expected: DebconfSelection{Owner: "postfix", Question: "postfix/relayhost", Type: "string", Value: ""},
+		},
+		{
+			name:      "missing type",
+			line:      "postfix postfix/relayhost",
+			expectErr: true,
+		},
+		{
+			name:      "unknown type",
+			line:      "postfix postfix/relayhost bool true",
+			expectErr: true,
+		},
+		{
+			name:      "invalid boolean",
+			line:      "ttf-mscorefonts-installer msttcorefonts/accepted-mscorefonts-eula boolean yes",
+			expectErr: true,
+		},
+		{
+			name:      "question without namespace",
+			line:      "postfix mailname string host",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selection, err := ParseDebconfSelection(tt.line)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("expected error for %q, got %+v", tt.line, selection)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if selection != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, selection)
+			}
+		})
+	}
+}
+
+func TestGetDebconfSelections(t *testing.T) {
+	yamlContent := `
+version: "1.0"
+debconf_selections:
+  - "postfix postfix/main_mailer_type select Internet Site"
+packages:
+  apt:
+    - "wireshark-common":
+        debconf:
+          "wireshark-common/install-setuid": "boolean true"
+    - curl
+`
+
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(yamlContent), &cfg); err != nil {
+		t.Fatalf("failed to unmarshal config: %v", err)
+	}
+
+	selections, err := GetDebconfSelections(&cfg)
+	if err != nil {
+		t.Fatalf("GetDebconfSelections failed: %v", err)
+	}
+
+	expected := []string{
+		"postfix postfix/main_mailer_type select Internet Site",
+		"wireshark-common wireshark-common/install-setuid boolean true",
+	}
+	if len(selections) != len(expected) {
+		t.Fatalf("expected %d selections, got %d", len(expected), len(selections))
+	}
+	for i, line := range expected {
+		if selections[i].String() != line {
+			t.Errorf("selection %d: expected %q, got %q", i, line, selections[i].String())
+		}
+	}
+}
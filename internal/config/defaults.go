@@ -2,16 +2,8 @@ package config
 
 // DefaultPackageFlags contains the built-in default flags for each package manager
 // These are used when the user doesn't specify their own defaults
-var DefaultPackageFlags = map[string][]string{
-	// APT defaults: non-interactive and don't install recommended packages
-	"apt": {"-y", "--no-install-recommends"},
-	
-	// Snap defaults: empty - snaps are interactive by design and most don't need special flags
-	"snap": {},
-	
-	// Flatpak defaults: install system-wide and assume yes for prompts
-	"flatpak": {"--system", "--assumeyes"},
-}
+// Entries are filled in by RegisterPackageManager (see registry.go)
+var DefaultPackageFlags = map[string][]string{}
 
 // GetDefaultFlags returns the default flags for a package manager
 func GetDefaultFlags(manager string) []string {
@@ -30,11 +22,7 @@ func HasDefaultFlags(manager string) bool {
 	return exists
 }
 
-// GetSupportedPackageManagers returns all supported package managers
+// GetSupportedPackageManagers returns all supported package managers in registration order
 func GetSupportedPackageManagers() []string {
-	managers := make([]string, 0, len(DefaultPackageFlags))
-	for manager := range DefaultPackageFlags {
-		managers = append(managers, manager)
-	}
-	return managers
-}
\ No newline at end of file
+	return RegisteredPackageManagers()
+}
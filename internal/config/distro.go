@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// OSRelease holds the distribution fields of /etc/os-release used to select package managers
+type OSRelease struct {
+	ID         string   // Distribution ID (e.g., "ubuntu", "fedora")
+	IDLike     []string // Related distribution IDs (e.g., ["debian"] on Ubuntu)
+	VersionID  string   // Distribution version (e.g., "24.04", "40")
+	PrettyName string   // Human-readable name (e.g., "Fedora Linux 40 (Workstation Edition)")
+}
+
+// osReleasePath is the os-release file read by DetectOSRelease (overridden in tests)
+var osReleasePath = "/etc/os-release"
+
+// ParseOSRelease parses the KEY=value lines of an os-release file
+func ParseOSRelease(content string) OSRelease {
+	var release OSRelease
+	for _, line := range strings.Split(content, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found || strings.HasPrefix(key, "#") {
+			continue
+		}
+		value = strings.Trim(value, "\"'")
+
+		switch key {
+		case "ID":
+			release.ID = strings.ToLower(value)
+		case "ID_LIKE":
+			release.IDLike = strings.Fields(strings.ToLower(value))
+		case "VERSION_ID":
+			release.VersionID = value
+		case "PRETTY_NAME":
+			release.PrettyName = value
+		}
+	}
+	return release
+}
+
+// DetectOSRelease reads the distribution of the current system from /etc/os-release
+func DetectOSRelease() (OSRelease, error) {
+	content, err := os.ReadFile(osReleasePath)
+	if err != nil {
+		return OSRelease{}, fmt.Errorf("failed to read %s: %w", osReleasePath, err)
+	}
+	return ParseOSRelease(string(content)), nil
+}
+
+// Is reports whether the system is the given distribution or derived from it (ID or ID_LIKE)
+func (o OSRelease) Is(distro string) bool {
+	distro = strings.ToLower(distro)
+	if o.ID == distro {
+		return true
+	}
+	for _, like := range o.IDLike {
+		if like == distro {
+			return true
+		}
+	}
+	return false
+}
+
+// DisplayName returns a human-readable distribution name for messages
+func (o OSRelease) DisplayName() string {
+	if o.PrettyName != "" {
+		return o.PrettyName
+	}
+	return o.ID
+}
+
+// SupportsDistros reports whether the system matches any of the distributions
+// An empty list or an unknown system (no ID) matches everything
+func (o OSRelease) SupportsDistros(distros []string) bool {
+	if len(distros) == 0 || o.ID == "" {
+		return true
+	}
+	for _, distro := range distros {
+		if o.Is(distro) {
+			return true
+		}
+	}
+	return false
+}
+
+// PackageManagerSupported reports whether a package manager exists on the distribution
+func PackageManagerSupported(manager string, release OSRelease) bool {
+	spec, registered := LookupPackageManager(manager)
+	if !registered {
+		return true
+	}
+	return release.SupportsDistros(spec.Distros)
+}
+
+// validateDistroSupport warns about packages and repositories for managers the current distribution lacks
+func validateDistroSupport(config *Config, result *ValidationResult) {
+	release, err := DetectOSRelease()
+	if err != nil || release.ID == "" {
+		return
+	}
+
+	for _, manager := range config.Packages.ManagerNames() {
+		if len(config.Packages.Get(manager)) == 0 || PackageManagerSupported(manager, release) {
+			continue
+		}
+		spec, _ := LookupPackageManager(manager)
+		result.Add(ValidationError{
+			Type:    "warning",
+			Title:   "package manager not available on this distribution",
+			Field:   fmt.Sprintf("packages.%s", manager),
+			Value:   manager,
+			Message: fmt.Sprintf("%s packages will be skipped on %s", spec.DisplayName, release.DisplayName()),
+			Help:    fmt.Sprintf("%s is available on: %s", spec.DisplayName, strings.Join(spec.Distros, ", ")),
+			Note:    "move distribution-specific packages to an include with a 'distro' condition",
+		})
+	}
+
+	repositories := []struct {
+		manager string
+		count   int
+	}{
+		{"apt", len(config.Repositories.Apt)},
+		{"dnf", len(config.Repositories.Dnf)},
+	}
+	for _, repos := range repositories {
+		if repos.count == 0 || PackageManagerSupported(repos.manager, release) {
+			continue
+		}
+		result.Add(ValidationError{
+			Type:    "warning",
+			Title:   "repository type not available on this distribution",
+			Field:   fmt.Sprintf("repositories.%s", repos.manager),
+			Value:   repos.manager,
+			Message: fmt.Sprintf("%s repositories will be skipped on %s", PackageManagerDisplayName(repos.manager), release.DisplayName()),
+			Note:    "move distribution-specific repositories to an include with a 'distro' condition",
+		})
+	}
+}
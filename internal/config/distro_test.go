@@ -0,0 +1,200 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const fedoraOSRelease = `NAME="Fedora Linux"
+VERSION="40 (Workstation Edition)"
+ID=fedora
+VERSION_ID=40
+PRETTY_NAME="Fedora Linux 40 (Workstation Edition)"
+`
+
+const ubuntuOSRelease = `PRETTY_NAME="Ubuntu 24.04 LTS"
+NAME="Ubuntu"
+VERSION_ID="24.04"
+ID=ubuntu
+ID_LIKE=debian
+`
+
+// useOSRelease points DetectOSRelease at a temporary os-release file for the test
+func useOSRelease(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "os-release")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write os-release: %v", err)
+	}
+	original := osReleasePath
+	osReleasePath = path
+	t.Cleanup(func() { osReleasePath = original })
+}
+
+func TestParseOSRelease(t *testing.T) {
+	release := ParseOSRelease(ubuntuOSRelease)
+
+	if release.ID != "ubuntu" || release.VersionID != "24.04" || release.PrettyName != "Ubuntu 24.04 LTS" {
+		t.Errorf("unexpected release: %+v", release)
+	}
+	if len(release.IDLike) != 1 || release.IDLike[0] != "debian" {
+		t.Errorf("expected ID_LIKE [debian], got %v", release.IDLike)
+	}
+
+	rhel := ParseOSRelease("ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\n")
+	for _, distro := range []string{"rocky", "rhel", "fedora"} {
+		if !rhel.Is(distro) {
+			t.Errorf("expected Rocky Linux to match %q", distro)
+		}
+	}
+	if rhel.Is("debian") {
+		t.Error("expected Rocky Linux not to match debian")
+	}
+}
+
+func TestPackageManagerSupported(t *testing.T) {
+	fedora := ParseOSRelease(fedoraOSRelease)
+	ubuntu := ParseOSRelease(ubuntuOSRelease)
+	debian := OSRelease{ID: "debian"}
+
+	tests := []struct {
+		manager  string
+		release  OSRelease
+		expected bool
+	}{
+		{"apt", ubuntu, true},
+		{"apt", debian, true},
+		{"apt", fedora, false},
+		{"flatpak", fedora, true},
+		{"apt", OSRelease{}, true}, // unknown distribution
+		{"unknown", fedora, true},  // unregistered manager
+	}
+
+	for _, tt := range tests {
+		if got := PackageManagerSupported(tt.manager, tt.release); got != tt.expected {
+			t.Errorf("PackageManagerSupported(%q, %q) = %v, expected %v", tt.manager, tt.release.ID, got, tt.expected)
+		}
+	}
+}
+
+func TestEvaluateCondition_Distro(t *testing.T) {
+	loader := NewAdvancedLoader()
+	loader.osRelease = ParseOSRelease(ubuntuOSRelease)
+
+	tests := []struct {
+		condition IncludeCondition
+		want      bool
+	}{
+		{IncludeCondition{Type: "distro", Value: "ubuntu"}, true},
+		{IncludeCondition{Type: "distro", Value: "debian"}, true}, // ID_LIKE
+		{IncludeCondition{Type: "distro", Value: "fedora"}, false},
+		{IncludeCondition{Type: "distro", Value: "fedora", Operator: "not_equals"}, true},
+		{IncludeCondition{Type: "distro", Value: "debian", Operator: "not_equals"}, false},
+		{IncludeCondition{Type: "distro_version", Value: "24.04"}, true},
+		{IncludeCondition{Type: "distro_version", Value: "^2[4-9]\\.", Operator: "matches"}, true},
+		{IncludeCondition{Type: "distro_version", Value: "22.04"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := loader.evaluateCondition(tt.condition); got != tt.want {
+			t.Errorf("evaluateCondition(%+v) = %v, want %v", tt.condition, got, tt.want)
+		}
+		if err := loader.validateCondition(tt.condition); err != nil {
+			t.Errorf("validateCondition(%+v) failed: %v", tt.condition, err)
+		}
+	}
+}
+
+func TestValidate_DistroSupport(t *testing.T) {
+	useOSRelease(t, fedoraOSRelease)
+
+	cfg := &Config{Version: "1.0"}
+	cfg.Packages.Set("apt", []PackageEntry{{Name: "git"}})
+	cfg.Packages.Set("flatpak", []PackageEntry{{Name: "org.mozilla.firefox"}})
+	cfg.Repositories.Apt = []AptRepository{{Name: "python", PPA: "deadsnakes/ppa"}}
+
+	result := Validate(cfg, "test.yaml")
+
+	if result.HasErrors() {
+		t.Fatalf("expected no errors, got %+v", result.Errors)
+	}
+
+	fields := make(map[string]string)
+	for _, warning := range result.Warnings {
+		fields[warning.Field] = warning.Title
+	}
+	if fields["packages.apt"] != "package manager not available on this distribution" {
+		t.Errorf("expected a distro warning for packages.apt, got %+v", result.Warnings)
+	}
+	if fields["repositories.apt"] != "repository type not available on this distribution" {
+		t.Errorf("expected a distro warning for repositories.apt, got %+v", result.Warnings)
+	}
+	if _, warned := fields["packages.flatpak"]; warned {
+		t.Errorf("expected no warning for packages.flatpak, got %+v", result.Warnings)
+	}
+
+	// The same config is fine on Ubuntu
+	useOSRelease(t, ubuntuOSRelease)
+	result = Validate(cfg, "test.yaml")
+	for _, warning := range result.Warnings {
+		if warning.Field == "packages.apt" || warning.Field == "repositories.apt" {
+			t.Errorf("expected no distro warnings on Ubuntu, got %+v", warning)
+		}
+	}
+}
+
+func TestDnfRepositories_UnmarshalAndValidate(t *testing.T) {
+	data := `
+dnf:
+  vscode:
+    description: Visual Studio Code
+    baseurl: https://packages.microsoft.com/yumrepos/vscode
+    gpgkey: https://packages.microsoft.com/keys/microsoft.asc
+  insecure:
+    baseurl: http://example.com/repo
+    gpgkey: http://example.com/key.asc
+    gpgcheck: false
+  empty: {}
+`
+	var repos RepositoryManagement
+	if err := yaml.Unmarshal([]byte(data), &repos); err != nil {
+		t.Fatalf("failed to unmarshal repositories: %v", err)
+	}
+	if len(repos.Dnf) != 3 {
+		t.Fatalf("expected 3 DNF repositories, got %d", len(repos.Dnf))
+	}
+	vscode := repos.Dnf[0]
+	if vscode.Name != "vscode" || vscode.Description != "Visual Studio Code" || vscode.GPGCheck != nil {
+		t.Errorf("unexpected repository: %+v", vscode)
+	}
+	if repos.Dnf[1].GPGCheck == nil || *repos.Dnf[1].GPGCheck {
+		t.Errorf("expected gpgcheck false, got %v", repos.Dnf[1].GPGCheck)
+	}
+
+	result := &ValidationResult{Valid: true}
+	validateDnfRepositories(repos.Dnf, result, nil, "test.yaml")
+
+	issues := make(map[string]string)
+	for _, issue := range append(result.Errors, result.Warnings...) {
+		issues[issue.Field] = issue.Title
+	}
+	expected := map[string]string{
+		"repositories.dnf.insecure.gpgkey":   "insecure GPG key URL",
+		"repositories.dnf.insecure.gpgcheck": "signature checking disabled",
+		"repositories.dnf.empty.baseurl":     "missing repository URL",
+	}
+	for field, title := range expected {
+		if issues[field] != title {
+			t.Errorf("expected %q for %s, got %+v", title, field, issues)
+		}
+	}
+	for field := range issues {
+		if strings.HasPrefix(field, "repositories.dnf.vscode") {
+			t.Errorf("expected no issues for vscode, got %s: %s", field, issues[field])
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AddPackageToFile adds a package to packages.<manager> of a configuration file
+// The file is edited in place line by line, so comments and formatting are preserved
+// Returns false when the package is already listed
+func AddPackageToFile(configPath, manager, packageName string) (bool, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read config file %s: %w", configPath, err)
+	}
+
+	updated, added, err := AddPackageToYAML(data, manager, packageName)
+	if err != nil {
+		return false, fmt.Errorf("failed to add %s to packages.%s in %s: %w", packageName, manager, configPath, err)
+	}
+	if !added {
+		return false, nil
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat config file %s: %w", configPath, err)
+	}
+	if err := os.WriteFile(configPath, updated, info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("failed to write config file %s: %w", configPath, err)
+	}
+	return true, nil
+}
+
+// AddPackageToYAML inserts "- packageName" into the packages.<manager> list of a YAML document
+// Missing packages: and manager sections are created; block lists keep their indentation
+func AddPackageToYAML(data []byte, manager, packageName string) ([]byte, bool, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, false, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	content := string(data)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	var lines []string
+	if content != "" {
+		lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+
+	// Empty document: start the packages section
+	if len(root.Content) == 0 {
+		lines = append(lines, "packages:", "  "+manager+":", "    - "+yamlScalar(packageName))
+		return joinLines(lines), true, nil
+	}
+
+	document := root.Content[0]
+	if document.Kind != yaml.MappingNode {
+		return nil, false, fmt.Errorf("configuration must be a mapping")
+	}
+
+	packagesKey, packagesNode := findMapEntry(document, "packages")
+	switch {
+	case packagesNode == nil:
+		lines = append(lines, "packages:", "  "+manager+":", "    - "+yamlScalar(packageName))
+		return joinLines(lines), true, nil
+	case isEmptyNode(packagesNode):
+		indent := lineIndent(lines[packagesKey.Line-1])
+		lines = clearValue(lines, packagesKey, packagesNode)
+		lines = insertLines(lines, packagesKey.Line, indent+"  "+manager+":", indent+"    - "+yamlScalar(packageName))
+		return joinLines(lines), true, nil
+	case packagesNode.Kind != yaml.MappingNode:
+		return nil, false, fmt.Errorf("packages must be a mapping")
+	case packagesNode.Style&yaml.FlowStyle != 0:
+		return nil, false, fmt.Errorf("packages is written in flow style; add the package manually")
+	}
+
+	managerKey, listNode := findMapEntry(packagesNode, manager)
+	switch {
+	case listNode == nil:
+		indent := strings.Repeat(" ", packagesNode.Content[0].Column-1)
+		lines = insertLines(lines, nodeEndLine(&root, packagesNode, lines), indent+manager+":", indent+"  - "+yamlScalar(packageName))
+		return joinLines(lines), true, nil
+	case isEmptyNode(listNode):
+		indent := lineIndent(lines[managerKey.Line-1])
+		lines = clearValue(lines, managerKey, listNode)
+		lines = insertLines(lines, managerKey.Line, indent+"  - "+yamlScalar(packageName))
+		return joinLines(lines), true, nil
+	case listNode.Kind != yaml.SequenceNode:
+		return nil, false, fmt.Errorf("packages.%s must be a list", manager)
+	}
+
+	for _, item := range listNode.Content {
+		if packageEntryName(item) == packageName {
+			return data, false, nil
+		}
+	}
+
+	if listNode.Style&yaml.FlowStyle != 0 {
+		return nil, false, fmt.Errorf("packages.%s is written in flow style; add the package manually", manager)
+	}
+
+	// Match the indentation of the existing "- " items
+	firstLine := lines[listNode.Content[0].Line-1]
+	indent := firstLine[:strings.Index(firstLine, "-")]
+	lines = insertLines(lines, nodeEndLine(&root, listNode, lines), indent+"- "+yamlScalar(packageName))
+	return joinLines(lines), true, nil
+}
+
+// AddFileToFile adds a files.<name> entry to a configuration file, preserving comments and formatting
+// Returns false when an entry with the name already exists
+func AddFileToFile(configPath, name string, file File) (bool, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read config file %s: %w", configPath, err)
+	}
+
+	updated, added, err := AddFileToYAML(data, name, file)
+	if err != nil {
+		return false, fmt.Errorf("failed to add files.%s to %s: %w", name, configPath, err)
+	}
+	if !added {
+		return false, nil
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat config file %s: %w", configPath, err)
+	}
+	if err := os.WriteFile(configPath, updated, info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("failed to write config file %s: %w", configPath, err)
+	}
+	return true, nil
+}
+
+// AddFileToYAML appends a files.<name> entry to a YAML document
+// A missing files: section is created; entries match the indentation of the existing ones
+func AddFileToYAML(data []byte, name string, file File) ([]byte, bool, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, false, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	content := string(data)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	var lines []string
+	if content != "" {
+		lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+
+	// Empty document or no files section: start one at the end
+	if len(root.Content) == 0 {
+		lines = append(lines, "files:")
+		lines = append(lines, fileEntryLines(name, file, "  ", "  ")...)
+		return joinLines(lines), true, nil
+	}
+
+	document := root.Content[0]
+	if document.Kind != yaml.MappingNode {
+		return nil, false, fmt.Errorf("configuration must be a mapping")
+	}
+
+	filesKey, filesNode := findMapEntry(document, "files")
+	switch {
+	case filesNode == nil:
+		lines = append(lines, "files:")
+		lines = append(lines, fileEntryLines(name, file, "  ", "  ")...)
+		return joinLines(lines), true, nil
+	case isEmptyNode(filesNode):
+		indent := lineIndent(lines[filesKey.Line-1])
+		lines = clearValue(lines, filesKey, filesNode)
+		lines = insertLines(lines, filesKey.Line, fileEntryLines(name, file, indent+"  ", "  ")...)
+		return joinLines(lines), true, nil
+	case filesNode.Kind != yaml.MappingNode:
+		return nil, false, fmt.Errorf("files must be a mapping")
+	case filesNode.Style&yaml.FlowStyle != 0:
+		return nil, false, fmt.Errorf("files is written in flow style; add the file manually")
+	}
+
+	if existing, _ := findMapEntry(filesNode, name); existing != nil {
+		return data, false, nil
+	}
+
+	// Match the indentation of the existing entries and of their options
+	indent := strings.Repeat(" ", filesNode.Content[0].Column-1)
+	step := "  "
+	if options := filesNode.Content[1]; options.Kind == yaml.MappingNode && len(options.Content) > 0 && options.Style&yaml.FlowStyle == 0 {
+		if width := options.Content[0].Column - filesNode.Content[0].Column; width > 0 {
+			step = strings.Repeat(" ", width)
+		}
+	}
+	lines = insertLines(lines, nodeEndLine(&root, filesNode, lines), fileEntryLines(name, file, indent, step)...)
+	return joinLines(lines), true, nil
+}
+
+// fileEntryLines formats a files: entry with the source, destination and deployment options of an adopted file
+func fileEntryLines(name string, file File, indent, step string) []string {
+	lines := []string{
+		indent + yamlScalar(name) + ":",
+		indent + step + "source: " + strconv.Quote(file.Source),
+		indent + step + "destination: " + strconv.Quote(file.Destination),
+	}
+	if file.Mode != "" {
+		lines = append(lines, indent+step+"mode: "+strconv.Quote(file.Mode))
+	}
+	if file.Copy {
+		lines = append(lines, indent+step+"copy: true")
+	}
+	if file.Backup {
+		lines = append(lines, indent+step+"backup: true")
+	}
+	return lines
+}
+
+// findMapEntry returns the key and value nodes of a mapping entry
+func findMapEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// packageEntryName returns the name of a package entry written as "- name" or "- name: {options}"
+func packageEntryName(node *yaml.Node) string {
+	switch {
+	case node.Kind == yaml.ScalarNode:
+		return node.Value
+	case node.Kind == yaml.MappingNode && len(node.Content) > 0:
+		return node.Content[0].Value
+	}
+	return ""
+}
+
+// isEmptyNode reports whether a node is null ("", "~", "null") or an empty list or mapping ("[]", "{}")
+func isEmptyNode(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Tag == "!!null"
+	case yaml.SequenceNode, yaml.MappingNode:
+		return len(node.Content) == 0
+	}
+	return false
+}
+
+// nodeEndLine returns the last line (1-based) holding content of a node or its children
+// A node ends where the next node of the document starts, less the blank lines and comments before it,
+// which covers multi-line scalars of every style (literal, folded, quoted and plain)
+func nodeEndLine(root, node *yaml.Node, lines []string) int {
+	last := lastNodeLine(node)
+	next := len(lines) + 1
+	var findNext func(n *yaml.Node)
+	findNext = func(n *yaml.Node) {
+		if n.Line > last && n.Line < next {
+			next = n.Line
+		}
+		for _, child := range n.Content {
+			findNext(child)
+		}
+	}
+	findNext(root)
+
+	// Comments indented deeper than the node may be lines of a scalar, so only shallower ones are skipped
+	indent := node.Column - 1
+	end := next - 1
+	for end > last {
+		line := lines[end-1]
+		trimmed := strings.TrimSpace(line)
+		isComment := strings.HasPrefix(trimmed, "#") && len(lineIndent(line)) <= indent
+		if trimmed != "" && !isComment && trimmed != "---" && trimmed != "..." {
+			break
+		}
+		end--
+	}
+	return end
+}
+
+// lastNodeLine returns the line (1-based) of the last node starting in a node or its children
+func lastNodeLine(node *yaml.Node) int {
+	last := node.Line
+	for _, child := range node.Content {
+		last = max(last, lastNodeLine(child))
+	}
+	return last
+}
+
+// yamlScalar formats a string as a YAML scalar, quoted only if it wouldn't read back as the same string
+func yamlScalar(value string) string {
+	out, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+	if err != nil {
+		return strconv.Quote(value)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// clearValue removes an empty value ("~", "null", "[]", "{}") written after its key, keeping comments
+func clearValue(lines []string, key, value *yaml.Node) []string {
+	if value.Line != key.Line {
+		return lines // Implicit null: nothing written after the colon
+	}
+
+	line := lines[value.Line-1]
+	start := value.Column - 1
+	if start >= len(line) {
+		return lines
+	}
+	for _, token := range []string{"[]", "{}", "null", "Null", "NULL", "~"} {
+		if strings.HasPrefix(line[start:], token) {
+			lines[value.Line-1] = strings.TrimRight(line[:start], " ") + line[start+len(token):]
+			break
+		}
+	}
+	return lines
+}
+
+// lineIndent returns the leading whitespace of a line
+func lineIndent(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// insertLines inserts lines after the given 1-based line number
+func insertLines(lines []string, after int, inserted ...string) []string {
+	result := make([]string, 0, len(lines)+len(inserted))
+	result = append(result, lines[:after]...)
+	result = append(result, inserted...)
+	return append(result, lines[after:]...)
+}
+
+// joinLines joins lines into file content with a trailing newline
+func joinLines(lines []string) []byte {
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
@@ -0,0 +1,425 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAddPackageToYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		manager  string
+		pkg      string
+		expected string
+	}{
+		{
+			name: "append to existing list",
+			input: `version: "1.0"
+# Packages for the workstation
+packages:
+  apt:
+    - git # version control
+    - curl:
+        flags: [-y]
+  flatpak:
+    - org.mozilla.firefox
+
+files: {}
+`,
+			manager: "apt",
+			pkg:     "ripgrep",
+			expected: `version: "1.0"
+# Packages for the workstation
+packages:
+  apt:
+    - git # version control
+    - curl:
+        flags: [-y]
+    - ripgrep
+  flatpak:
+    - org.mozilla.firefox
+
+files: {}
+`,
+		},
+		{
+			name: "unindented list",
+			input: `packages:
+  snap:
+  - code
+`,
+			manager: "snap",
+			pkg:     "discord",
+			expected: `packages:
+  snap:
+  - code
+  - discord
+`,
+		},
+		{
+			name: "new manager section",
+			input: `packages:
+  apt:
+    - git
+# trailing comment
+`,
+			manager: "flatpak",
+			pkg:     "org.gimp.GIMP",
+			expected: `packages:
+  apt:
+    - git
+  flatpak:
+    - org.gimp.GIMP
+# trailing comment
+`,
+		},
+		{
+			name: "empty manager list",
+			input: `packages:
+  apt: [] # nothing yet
+  snap:
+`,
+			manager: "apt",
+			pkg:     "git",
+			expected: `packages:
+  apt: # nothing yet
+    - git
+  snap:
+`,
+		},
+		{
+			name: "after a folded scalar",
+			input: `packages:
+  apt:
+    - postfix:
+        debconf:
+          postfix/mailname: >
+            string
+            mail.example.com
+
+  # Desktop applications
+  flatpak:
+    - org.mozilla.firefox
+`,
+			manager: "apt",
+			pkg:     "mutt",
+			expected: `packages:
+  apt:
+    - postfix:
+        debconf:
+          postfix/mailname: >
+            string
+            mail.example.com
+    - mutt
+
+  # Desktop applications
+  flatpak:
+    - org.mozilla.firefox
+`,
+		},
+		{
+			name: "after a multi-line quoted scalar",
+			input: `packages:
+  apt:
+    - postfix:
+        debconf:
+          postfix/mailname: "string
+            mail.example.com"
+`,
+			manager: "apt",
+			pkg:     "mutt",
+			expected: `packages:
+  apt:
+    - postfix:
+        debconf:
+          postfix/mailname: "string
+            mail.example.com"
+    - mutt
+`,
+		},
+		{
+			name: "name needing quotes",
+			input: `packages:
+  npm:
+    - typescript
+`,
+			manager: "npm",
+			pkg:     "@angular/cli",
+			expected: `packages:
+  npm:
+    - typescript
+    - '@angular/cli'
+`,
+		},
+		{
+			name:    "no packages section",
+			input:   "version: \"1.0\"",
+			manager: "snap",
+			pkg:     "code",
+			expected: `version: "1.0"
+packages:
+  snap:
+    - code
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, added, err := AddPackageToYAML([]byte(tt.input), tt.manager, tt.pkg)
+			if err != nil {
+				t.Fatalf("AddPackageToYAML failed: %v", err)
+			}
+			if !added {
+				t.Fatal("expected the package to be added")
+			}
+			if string(updated) != tt.expected {
+				t.Errorf("unexpected result:\nexpected:\n%s\ngot:\n%s", tt.expected, updated)
+			}
+
+			// The package reads back as added
+			var cfg Config
+			if err := yaml.Unmarshal(updated, &cfg); err != nil {
+				t.Fatalf("result is not valid YAML: %v", err)
+			}
+			if !containsPackageEntry(cfg.Packages.Get(tt.manager), tt.pkg) {
+				t.Errorf("expected %s in packages.%s, got %+v", tt.pkg, tt.manager, cfg.Packages.Get(tt.manager))
+			}
+		})
+	}
+}
+
+// containsPackageEntry reports whether a package list has an entry with a name
+func containsPackageEntry(entries []PackageEntry, name string) bool {
+	for _, entry := range entries {
+		if entry.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAddPackageToYAML_AlreadyListed(t *testing.T) {
+	input := `packages:
+  apt:
+    - git
+    - curl:
+        flags: [-y]
+`
+	for _, name := range []string{"git", "curl"} {
+		updated, added, err := AddPackageToYAML([]byte(input), "apt", name)
+		if err != nil {
+			t.Fatalf("AddPackageToYAML failed: %v", err)
+		}
+		if added || string(updated) != input {
+			t.Errorf("expected %s not to be added again, got:\n%s", name, updated)
+		}
+	}
+}
+
+func TestAddPackageToYAML_FlowList(t *testing.T) {
+	if _, _, err := AddPackageToYAML([]byte("packages:\n  apt: [git, vim]\n"), "apt", "curl"); err == nil {
+		t.Error("expected an error for a flow-style list")
+	}
+}
+
+func TestAddPackageToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "configr.yaml")
+	if err := os.WriteFile(path, []byte("packages:\n  apt:\n    - git\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	added, err := AddPackageToFile(path, "apt", "vim")
+	if err != nil || !added {
+		t.Fatalf("expected vim to be added, got added=%v err=%v", added, err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if string(content) != "packages:\n  apt:\n    - git\n    - vim\n" {
+		t.Errorf("unexpected content:\n%s", content)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("expected permissions to be preserved, got %v", info.Mode().Perm())
+	}
+}
+
+func TestAddFileToYAML(t *testing.T) {
+	gitconfig := File{Source: "dotfiles/gitconfig", Destination: "~/.gitconfig"}
+
+	tests := []struct {
+		name     string
+		input    string
+		entry    string
+		file     File
+		expected string
+	}{
+		{
+			name: "append to existing files",
+			input: `version: "1.0"
+# Dotfiles
+files:
+  vimrc:
+    source: "dotfiles/vimrc" # editor
+    destination: "~/.vimrc"
+  motd:
+    destination: "/etc/motd"
+    content: |
+      Welcome
+
+      Have fun
+
+# Packages
+packages:
+  apt:
+    - git
+`,
+			entry: "gitconfig",
+			file:  gitconfig,
+			expected: `version: "1.0"
+# Dotfiles
+files:
+  vimrc:
+    source: "dotfiles/vimrc" # editor
+    destination: "~/.vimrc"
+  motd:
+    destination: "/etc/motd"
+    content: |
+      Welcome
+
+      Have fun
+  gitconfig:
+    source: "dotfiles/gitconfig"
+    destination: "~/.gitconfig"
+
+# Packages
+packages:
+  apt:
+    - git
+`,
+		},
+		{
+			name: "wider indentation",
+			input: `files:
+    vimrc:
+        source: "dotfiles/vimrc"
+        destination: "~/.vimrc"
+`,
+			entry: "npmrc",
+			file:  File{Source: "dotfiles/npmrc", Destination: "~/.npmrc", Mode: "600", Copy: true},
+			expected: `files:
+    vimrc:
+        source: "dotfiles/vimrc"
+        destination: "~/.vimrc"
+    npmrc:
+        source: "dotfiles/npmrc"
+        destination: "~/.npmrc"
+        mode: "600"
+        copy: true
+`,
+		},
+		{
+			name: "after a folded scalar",
+			input: `files:
+  motd:
+    destination: "/etc/motd"
+    content: >
+      Welcome to the
+      workstation
+  # Shell
+packages: {}
+`,
+			entry: "gitconfig",
+			file:  gitconfig,
+			expected: `files:
+  motd:
+    destination: "/etc/motd"
+    content: >
+      Welcome to the
+      workstation
+  gitconfig:
+    source: "dotfiles/gitconfig"
+    destination: "~/.gitconfig"
+  # Shell
+packages: {}
+`,
+		},
+		{
+			name:  "name needing quotes",
+			input: "files: {}\n",
+			entry: "@work: notes",
+			file:  File{Source: "dotfiles/notes", Destination: "~/notes"},
+			expected: `files:
+  '@work: notes':
+    source: "dotfiles/notes"
+    destination: "~/notes"
+`,
+		},
+		{
+			name:  "empty files section",
+			input: "files: {} # none yet\npackages: {}\n",
+			entry: "gitconfig",
+			file:  gitconfig,
+			expected: `files: # none yet
+  gitconfig:
+    source: "dotfiles/gitconfig"
+    destination: "~/.gitconfig"
+packages: {}
+`,
+		},
+		{
+			name:  "no files section",
+			input: "version: \"1.0\"",
+			entry: "gitconfig",
+			file:  gitconfig,
+			expected: `version: "1.0"
+files:
+  gitconfig:
+    source: "dotfiles/gitconfig"
+    destination: "~/.gitconfig"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, added, err := AddFileToYAML([]byte(tt.input), tt.entry, tt.file)
+			if err != nil {
+				t.Fatalf("AddFileToYAML failed: %v", err)
+			}
+			if !added {
+				t.Fatal("expected the file to be added")
+			}
+			if string(updated) != tt.expected {
+				t.Errorf("unexpected result:\nexpected:\n%s\ngot:\n%s", tt.expected, updated)
+			}
+
+			// The result must still parse as a configuration holding the new entry
+			var cfg Config
+			if err := yaml.Unmarshal(updated, &cfg); err != nil {
+				t.Fatalf("result doesn't parse: %v", err)
+			}
+			if _, exists := cfg.Files[tt.entry]; !exists {
+				t.Errorf("expected files.%s in the result, got %+v", tt.entry, cfg.Files)
+			}
+		})
+	}
+}
+
+func TestAddFileToYAML_Existing(t *testing.T) {
+	input := "files:\n  gitconfig:\n    source: \"gitconfig\"\n    destination: \"~/.gitconfig\"\n"
+	updated, added, err := AddFileToYAML([]byte(input), "gitconfig", File{Source: "dotfiles/gitconfig", Destination: "~/.gitconfig"})
+	if err != nil {
+		t.Fatalf("AddFileToYAML failed: %v", err)
+	}
+	if added || string(updated) != input {
+		t.Errorf("expected the existing entry to be kept, got:\n%s", updated)
+	}
+
+	if _, _, err := AddFileToYAML([]byte("files: {vimrc: {source: vimrc, destination: ~/.vimrc}}\n"), "gitconfig", File{}); err == nil {
+		t.Error("expected an error for a flow-style files section")
+	}
+}
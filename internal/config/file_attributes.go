@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	aclQualifierPattern = regexp.MustCompile(`^([a-z_][a-z0-9_.-]*\$?|[0-9]+)$`)
+	xattrNamePattern    = regexp.MustCompile(`^(user|trusted|security|system)\.[^\s=]+$`)
+)
+
+// aclTags maps the tags setfacl accepts, including abbreviations, to the ones getfacl prints
+var aclTags = map[string]string{
+	"u": "user", "user": "user",
+	"g": "group", "group": "group",
+	"m": "mask", "mask": "mask",
+	"o": "other", "other": "other",
+}
+
+// NormalizeACLEntry checks an ACL entry in setfacl syntax (tag:qualifier:perms) and returns it the way
+// getfacl prints it: tags spelled out and permissions as rwx with dashes ("g:devs:rx" -> "group:devs:r-x")
+func NormalizeACLEntry(entry string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(entry), ":")
+	if len(parts) > 0 && (parts[0] == "d" || parts[0] == "default") {
+		return "", fmt.Errorf("default entries only apply to directories")
+	}
+	if len(parts) != 3 {
+		return "", fmt.Errorf("expected tag:qualifier:permissions, e.g. group:devs:r-x")
+	}
+
+	tag, ok := aclTags[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("unknown tag %q, expected user, group, mask or other", parts[0])
+	}
+
+	qualifier := parts[1]
+	switch {
+	case qualifier != "" && (tag == "mask" || tag == "other"):
+		return "", fmt.Errorf("%s entries don't take a user or group", tag)
+	case qualifier != "" && !aclQualifierPattern.MatchString(qualifier):
+		return "", fmt.Errorf("invalid %s name %q", tag, qualifier)
+	}
+
+	perms, err := normalizeACLPerms(parts[2])
+	if err != nil {
+		return "", err
+	}
+	return tag + ":" + qualifier + ":" + perms, nil
+}
+
+// normalizeACLPerms returns permissions given as letters ("rx", "r-x") or an octal digit ("5") as "r-x"
+func normalizeACLPerms(perms string) (string, error) {
+	if len(perms) == 1 && perms[0] >= '0' && perms[0] <= '7' {
+		digit := perms[0] - '0'
+		result := []byte("---")
+		for i, letter := range "rwx" {
+			if digit&(4>>i) != 0 {
+				result[i] = byte(letter)
+			}
+		}
+		return string(result), nil
+	}
+
+	if perms == "" {
+		return "", fmt.Errorf("missing permissions")
+	}
+	result := []byte("---")
+	for _, c := range perms {
+		switch c {
+		case 'r':
+			result[0] = 'r'
+		case 'w':
+			result[1] = 'w'
+		case 'x':
+			result[2] = 'x'
+		case '-':
+		default:
+			return "", fmt.Errorf("invalid permission %q, use r, w, x and - or an octal digit", c)
+		}
+	}
+	return string(result), nil
+}
+
+// IsValidXattrName reports whether an extended attribute name has a namespace (user., trusted., security. or system.)
+func IsValidXattrName(name string) bool {
+	return xattrNamePattern.MatchString(name)
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"text/template"
+)
+
+// FileTemplateFuncs returns the functions available to templated files (template: true)
+func FileTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// env returns an environment variable, or "" when it is unset
+		"env": os.Getenv,
+		// default returns fallback when value is empty: {{ index .Vars "proxy" | default "none" }}
+		"default": func(fallback, value interface{}) interface{} {
+			if value == nil || value == "" {
+				return fallback
+			}
+			return value
+		},
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"trim":  strings.TrimSpace,
+		// replace takes the string last so it can be piped: {{ .Host.Hostname | replace "-" "_" }}
+		"replace": func(old, new, s string) string {
+			return strings.ReplaceAll(s, old, new)
+		},
+	}
+}
+
+// ParseFileTemplate parses the content of a templated file
+// Templates are strict: referencing an undefined variable fails when the template is rendered
+func ParseFileTemplate(name, content string) (*template.Template, error) {
+	return template.New(name).
+		Option("missingkey=error").
+		Funcs(FileTemplateFuncs()).
+		Parse(content)
+}
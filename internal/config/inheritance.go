@@ -85,6 +85,10 @@ func (cim *ConfigInheritanceManager) GetBuiltinInheritanceRules() InheritanceCon
 			// Repositories: Merge (combine repositories from all levels)
 			{Section: "repositories.apt", Pattern: InheritanceMerge, Priority: 1},
 			{Section: "repositories.flatpak", Pattern: InheritanceMerge, Priority: 1},
+			{Section: "repositories.dnf", Pattern: InheritanceMerge, Priority: 1},
+			
+			// Template variables: Merge (child values win)
+			{Section: "vars", Pattern: InheritanceMerge, Priority: 1},
 			
 			// Backup policy: Override (most specific config wins)
 			{Section: "backup_policy", Pattern: InheritanceOverride, Priority: 2},
@@ -109,10 +113,83 @@ func (cim *ConfigInheritanceManager) MergeConfigsWithInheritance(parent, child *
 		return nil, fmt.Errorf("failed to inherit package defaults: %w", err)
 	}
 	
+	// Package settings: child takes precedence, missing managers and keys are inherited
+	for manager, parentSettings := range parent.PackageSettings {
+		if result.PackageSettings == nil {
+			result.PackageSettings = make(map[string]map[string]string)
+		}
+		if result.PackageSettings[manager] == nil {
+			result.PackageSettings[manager] = make(map[string]string)
+		}
+		for key, value := range parentSettings {
+			if _, exists := result.PackageSettings[manager][key]; !exists {
+				result.PackageSettings[manager][key] = value
+			}
+		}
+	}
+	
+	// Custom managers: child definitions take precedence
+	for name, manager := range parent.CustomManagers {
+		if result.CustomManagers == nil {
+			result.CustomManagers = make(map[string]CustomManager)
+		}
+		if _, exists := result.CustomManagers[name]; !exists {
+			result.CustomManagers[name] = manager
+		}
+	}
+	
+	// Template variables: child values take precedence
+	for name, value := range parent.Vars {
+		if result.Vars == nil {
+			result.Vars = make(map[string]interface{})
+		}
+		if _, exists := result.Vars[name]; !exists {
+			result.Vars[name] = value
+		}
+	}
+	
 	if err := cim.inheritFiles(&result.Files, parent.Files); err != nil {
 		return nil, fmt.Errorf("failed to inherit files: %w", err)
 	}
 	
+	// Directories: child definitions take precedence
+	for name, directory := range parent.Directories {
+		if result.Directories == nil {
+			result.Directories = make(map[string]Directory)
+		}
+		if _, exists := result.Directories[name]; !exists {
+			result.Directories[name] = directory
+		}
+	}
+	
+	// Lines and blocks: child definitions take precedence
+	for name, line := range parent.Lines {
+		if result.Lines == nil {
+			result.Lines = make(map[string]LineInFile)
+		}
+		if _, exists := result.Lines[name]; !exists {
+			result.Lines[name] = line
+		}
+	}
+	for name, block := range parent.Blocks {
+		if result.Blocks == nil {
+			result.Blocks = make(map[string]Block)
+		}
+		if _, exists := result.Blocks[name]; !exists {
+			result.Blocks[name] = block
+		}
+	}
+	
+	// Settings files: child definitions take precedence
+	for name, settingsFile := range parent.SettingsFiles {
+		if result.SettingsFiles == nil {
+			result.SettingsFiles = make(map[string]SettingsFile)
+		}
+		if _, exists := result.SettingsFiles[name]; !exists {
+			result.SettingsFiles[name] = settingsFile
+		}
+	}
+	
 	if err := cim.inheritDConfSettings(&result.DConf.Settings, parent.DConf.Settings); err != nil {
 		return nil, fmt.Errorf("failed to inherit dconf settings: %w", err)
 	}
@@ -134,43 +211,22 @@ func (cim *ConfigInheritanceManager) MergeConfigsWithInheritance(parent, child *
 
 // inheritPackages handles package inheritance
 func (cim *ConfigInheritanceManager) inheritPackages(child, parent *PackageManagement) error {
-	// APT packages
-	aptRule := cim.getRule("packages.apt")
-	switch aptRule.Pattern {
-	case InheritanceAppend:
-		child.Apt = cim.appendUniquePackages(parent.Apt, child.Apt)
-	case InheritancePrepend:
-		child.Apt = cim.appendUniquePackages(child.Apt, parent.Apt)
-	case InheritanceOverride:
-		// Child already has precedence, nothing to do
-	case InheritanceMerge:
-		child.Apt = cim.mergePackages(parent.Apt, child.Apt)
-	}
-	
-	// Flatpak packages
-	flatpakRule := cim.getRule("packages.flatpak")
-	switch flatpakRule.Pattern {
-	case InheritanceAppend:
-		child.Flatpak = cim.appendUniquePackages(parent.Flatpak, child.Flatpak)
-	case InheritancePrepend:
-		child.Flatpak = cim.appendUniquePackages(child.Flatpak, parent.Flatpak)
-	case InheritanceOverride:
-		// Child already has precedence, nothing to do
-	case InheritanceMerge:
-		child.Flatpak = cim.mergePackages(parent.Flatpak, child.Flatpak)
-	}
-	
-	// Snap packages
-	snapRule := cim.getRule("packages.snap")
-	switch snapRule.Pattern {
-	case InheritanceAppend:
-		child.Snap = cim.appendUniquePackages(parent.Snap, child.Snap)
-	case InheritancePrepend:
-		child.Snap = cim.appendUniquePackages(child.Snap, parent.Snap)
-	case InheritanceOverride:
-		// Child already has precedence, nothing to do
-	case InheritanceMerge:
-		child.Snap = cim.mergePackages(parent.Snap, child.Snap)
+	// Apply the inheritance rule of each package manager section (packages.apt, packages.flatpak, ...)
+	for _, manager := range parent.ManagerNames() {
+		parentPackages := parent.Get(manager)
+		childPackages := child.Get(manager)
+		
+		rule := cim.getRule("packages." + manager)
+		switch rule.Pattern {
+		case InheritanceAppend:
+			child.Set(manager, cim.appendUniquePackages(parentPackages, childPackages))
+		case InheritancePrepend:
+			child.Set(manager, cim.appendUniquePackages(childPackages, parentPackages))
+		case InheritanceOverride:
+			// Child already has precedence, nothing to do
+		case InheritanceMerge:
+			child.Set(manager, cim.mergePackages(parentPackages, childPackages))
+		}
 	}
 	
 	return nil
@@ -272,6 +328,13 @@ func (cim *ConfigInheritanceManager) inheritFiles(child *map[string]File, parent
 				merged := parentFile
 				if childFile.Source != "" {
 					merged.Source = childFile.Source
+					merged.Content = ""
+					merged.SHA256 = childFile.SHA256
+				}
+				if childFile.Content != "" {
+					merged.Content = childFile.Content
+					merged.Source = ""
+					merged.SHA256 = ""
 				}
 				if childFile.Destination != "" {
 					merged.Destination = childFile.Destination
@@ -295,6 +358,9 @@ func (cim *ConfigInheritanceManager) inheritFiles(child *map[string]File, parent
 				if childFile.Interactive {
 					merged.Interactive = childFile.Interactive
 				}
+				if childFile.Template {
+					merged.Template = childFile.Template
+				}
 				(*child)[key] = merged
 			} else {
 				(*child)[key] = parentFile
@@ -340,6 +406,7 @@ func (cim *ConfigInheritanceManager) inheritDConfSettings(child *map[string]stri
 func (cim *ConfigInheritanceManager) inheritRepositories(child, parent *RepositoryManagement) error {
 	aptRule := cim.getRule("repositories.apt")
 	flatpakRule := cim.getRule("repositories.flatpak")
+	dnfRule := cim.getRule("repositories.dnf")
 	
 	// Initialize child slices if nil
 	if child.Apt == nil {
@@ -348,6 +415,9 @@ func (cim *ConfigInheritanceManager) inheritRepositories(child, parent *Reposito
 	if child.Flatpak == nil {
 		child.Flatpak = make([]FlatpakRepository, 0)
 	}
+	if child.Dnf == nil {
+		child.Dnf = make([]DnfRepository, 0)
+	}
 	
 	// Inherit APT repositories
 	switch aptRule.Pattern {
@@ -413,6 +483,23 @@ func (cim *ConfigInheritanceManager) inheritRepositories(child, parent *Reposito
 		}
 	}
 	
+	// Inherit DNF repositories (all patterns add parent repos that don't exist in child)
+	switch dnfRule.Pattern {
+	case InheritanceOverride, InheritanceMerge, InheritanceAppend, InheritancePrepend:
+		for _, parentRepo := range parent.Dnf {
+			found := false
+			for _, childRepo := range child.Dnf {
+				if childRepo.Name == parentRepo.Name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				child.Dnf = append(child.Dnf, parentRepo)
+			}
+		}
+	}
+	
 	return nil
 }
 
@@ -438,6 +525,9 @@ func (cim *ConfigInheritanceManager) inheritBackupPolicy(child *BackupPolicy, pa
 		if child.PreserveRecent == 0 && parent.PreserveRecent > 0 {
 			child.PreserveRecent = parent.PreserveRecent
 		}
+		if !child.Compress && parent.Compress {
+			child.Compress = parent.Compress
+		}
 	case InheritanceMerge:
 		// Merge policies (child takes precedence for set values)
 		if !child.AutoCleanup {
@@ -455,6 +545,9 @@ func (cim *ConfigInheritanceManager) inheritBackupPolicy(child *BackupPolicy, pa
 		if child.PreserveRecent == 0 {
 			child.PreserveRecent = parent.PreserveRecent
 		}
+		if !child.Compress {
+			child.Compress = parent.Compress
+		}
 	}
 	
 	return nil
@@ -565,19 +658,18 @@ func (cim *ConfigInheritanceManager) deepCopyConfig(original *Config) *Config {
 		Repositories: RepositoryManagement{
 			Apt:     make([]AptRepository, 0),
 			Flatpak: make([]FlatpakRepository, 0),
+			Dnf:     make([]DnfRepository, 0),
 		},
 		Packages: PackageManagement{
-			Apt:     make([]PackageEntry, len(original.Packages.Apt)),
-			Flatpak: make([]PackageEntry, len(original.Packages.Flatpak)),
-			Snap:    make([]PackageEntry, len(original.Packages.Snap)),
+			Managers: make(map[string][]PackageEntry, len(original.Packages.Managers)),
 		},
 		Includes: make([]IncludeSpec, len(original.Includes)),
 	}
 	
 	// Deep copy slices and maps
-	copy(result.Packages.Apt, original.Packages.Apt)
-	copy(result.Packages.Flatpak, original.Packages.Flatpak)
-	copy(result.Packages.Snap, original.Packages.Snap)
+	for manager, packages := range original.Packages.Managers {
+		result.Packages.Managers[manager] = append([]PackageEntry{}, packages...)
+	}
 	copy(result.Includes, original.Includes)
 	
 	for k, v := range original.PackageDefaults {
@@ -585,20 +677,85 @@ func (cim *ConfigInheritanceManager) deepCopyConfig(original *Config) *Config {
 		copy(result.PackageDefaults[k], v)
 	}
 	
+	for name, manager := range original.CustomManagers {
+		if result.CustomManagers == nil {
+			result.CustomManagers = make(map[string]CustomManager)
+		}
+		result.CustomManagers[name] = manager
+	}
+	
+	for name, value := range original.Vars {
+		if result.Vars == nil {
+			result.Vars = make(map[string]interface{})
+		}
+		result.Vars[name] = value
+	}
+	
+	for manager, settings := range original.PackageSettings {
+		if result.PackageSettings == nil {
+			result.PackageSettings = make(map[string]map[string]string)
+		}
+		result.PackageSettings[manager] = make(map[string]string, len(settings))
+		for k, v := range settings {
+			result.PackageSettings[manager][k] = v
+		}
+	}
+	
 	for k, v := range original.Files {
 		result.Files[k] = v
 	}
 	
+	for k, v := range original.Directories {
+		if result.Directories == nil {
+			result.Directories = make(map[string]Directory)
+		}
+		result.Directories[k] = v
+	}
+	
+	for k, v := range original.Lines {
+		if result.Lines == nil {
+			result.Lines = make(map[string]LineInFile)
+		}
+		result.Lines[k] = v
+	}
+	
+	for k, v := range original.Blocks {
+		if result.Blocks == nil {
+			result.Blocks = make(map[string]Block)
+		}
+		result.Blocks[k] = v
+	}
+	
+	for k, v := range original.SettingsFiles {
+		if result.SettingsFiles == nil {
+			result.SettingsFiles = make(map[string]SettingsFile)
+		}
+		result.SettingsFiles[k] = v
+	}
+	
 	for k, v := range original.DConf.Settings {
 		result.DConf.Settings[k] = v
 	}
 	
+	if original.Packages.Any != nil {
+		result.Packages.Any = make(map[string][]string)
+		for k, v := range original.Packages.Any {
+			result.Packages.Any[k] = append([]string{}, v...)
+		}
+	}
+	
+	result.DebconfSelections = make([]string, len(original.DebconfSelections))
+	copy(result.DebconfSelections, original.DebconfSelections)
+	
 	result.Repositories.Apt = make([]AptRepository, len(original.Repositories.Apt))
 	copy(result.Repositories.Apt, original.Repositories.Apt)
 	
 	result.Repositories.Flatpak = make([]FlatpakRepository, len(original.Repositories.Flatpak))
 	copy(result.Repositories.Flatpak, original.Repositories.Flatpak)
 	
+	result.Repositories.Dnf = make([]DnfRepository, len(original.Repositories.Dnf))
+	copy(result.Repositories.Dnf, original.Repositories.Dnf)
+	
 	return result
 }
 
@@ -631,18 +788,20 @@ func (cim *ConfigInheritanceManager) CreateInheritanceChain(configs []*Config) (
 // ValidateInheritanceRules validates inheritance rules
 func (cim *ConfigInheritanceManager) ValidateInheritanceRules(config InheritanceConfig) error {
 	validSections := map[string]bool{
-		"packages.apt":        true,
-		"packages.flatpak":    true,
-		"packages.snap":       true,
 		"package_defaults":    true,
 		"files":               true,
 		"dconf.settings":      true,
 		"repositories.apt":    true,
 		"repositories.flatpak": true,
+		"repositories.dnf":    true,
 		"backup_policy":       true,
 		"version":             true,
 	}
 	
+	for _, manager := range RegisteredPackageManagers() {
+		validSections["packages."+manager] = true
+	}
+	
 	for _, rule := range config.Rules {
 		if !validSections[rule.Section] {
 			return fmt.Errorf("invalid section in inheritance rule: %s", rule.Section)
@@ -684,19 +843,15 @@ func (cim *ConfigInheritanceManager) GetInheritanceReport(configs []*Config, res
 	report.WriteString(fmt.Sprintf("Allow Override: %t\n\n", cim.allowOverride))
 	
 	// Summary of merged configuration
-	aptCount := len(result.Packages.Apt)
-	flatpakCount := len(result.Packages.Flatpak)
-	snapCount := len(result.Packages.Snap)
-	totalPackages := aptCount + flatpakCount + snapCount
-	
 	report.WriteString("Final Configuration Summary:\n")
 	report.WriteString(fmt.Sprintf("  Version: %s\n", result.Version))
-	report.WriteString(fmt.Sprintf("  Total Packages: %d (APT: %d, Flatpak: %d, Snap: %d)\n", 
-		totalPackages, aptCount, flatpakCount, snapCount))
+	report.WriteString(fmt.Sprintf("  Total Packages: %d (%s)\n", 
+		result.Packages.Count(), result.Packages.Summary()))
 	report.WriteString(fmt.Sprintf("  Files: %d\n", len(result.Files)))
 	report.WriteString(fmt.Sprintf("  DConf Settings: %d\n", len(result.DConf.Settings)))
 	report.WriteString(fmt.Sprintf("  APT Repositories: %d\n", len(result.Repositories.Apt)))
 	report.WriteString(fmt.Sprintf("  Flatpak Repositories: %d\n", len(result.Repositories.Flatpak)))
+	report.WriteString(fmt.Sprintf("  DNF Repositories: %d\n", len(result.Repositories.Dnf)))
 	
 	return report.String()
 }
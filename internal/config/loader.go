@@ -45,7 +45,7 @@ func loadConfigRecursive(configPath string, visited map[string]bool) (*Config, e
 		return nil, fmt.Errorf("failed to unmarshal config file %s: %w", configPath, err)
 	}
 
-	// Set ConfigDir for all file and binary entries in this config
+	// Set ConfigDir for all file, directory and binary entries in this config
 	configDir := filepath.Dir(configPath)
 	for name, file := range config.Files {
 		file.ConfigDir = configDir
@@ -55,6 +55,10 @@ func loadConfigRecursive(configPath string, visited map[string]bool) (*Config, e
 		binary.ConfigDir = configDir
 		config.Binaries[name] = binary
 	}
+	for name, directory := range config.Directories {
+		directory.ConfigDir = configDir
+		config.Directories[name] = directory
+	}
 
 	// Process includes
 	if len(config.Includes) > 0 {
@@ -136,10 +140,18 @@ func resolveIncludePath(baseDir, includePath string) (string, error) {
 
 // mergeConfigs merges src config into dst config
 func mergeConfigs(dst, src *Config) error {
-	// Merge packages (remove duplicates)
-	dst.Packages.Apt = removeDuplicatePackages(append(dst.Packages.Apt, src.Packages.Apt...))
-	dst.Packages.Flatpak = removeDuplicatePackages(append(dst.Packages.Flatpak, src.Packages.Flatpak...))
-	dst.Packages.Snap = removeDuplicatePackages(append(dst.Packages.Snap, src.Packages.Snap...))
+	// Merge packages of every package manager (remove duplicates)
+	for _, manager := range src.Packages.ManagerNames() {
+		dst.Packages.Set(manager, removeDuplicatePackages(append(dst.Packages.Get(manager), src.Packages.Get(manager)...)))
+	}
+
+	// Merge logical packages (src overwrites dst if same name)
+	if len(src.Packages.Any) > 0 && dst.Packages.Any == nil {
+		dst.Packages.Any = make(map[string][]string)
+	}
+	for name, candidates := range src.Packages.Any {
+		dst.Packages.Any[name] = candidates
+	}
 
 	// Merge files (src overwrites dst if same key)
 	if dst.Files == nil {
@@ -149,9 +161,40 @@ func mergeConfigs(dst, src *Config) error {
 		dst.Files[key] = file
 	}
 
+	// Merge directories (src overwrites dst if same key)
+	if len(src.Directories) > 0 && dst.Directories == nil {
+		dst.Directories = make(map[string]Directory)
+	}
+	for key, directory := range src.Directories {
+		dst.Directories[key] = directory
+	}
+
+	// Merge lines and blocks (src overwrites dst if same key)
+	if len(src.Lines) > 0 && dst.Lines == nil {
+		dst.Lines = make(map[string]LineInFile)
+	}
+	for key, line := range src.Lines {
+		dst.Lines[key] = line
+	}
+	if len(src.Blocks) > 0 && dst.Blocks == nil {
+		dst.Blocks = make(map[string]Block)
+	}
+	for key, block := range src.Blocks {
+		dst.Blocks[key] = block
+	}
+
+	// Merge settings files (src overwrites dst if same key)
+	if len(src.SettingsFiles) > 0 && dst.SettingsFiles == nil {
+		dst.SettingsFiles = make(map[string]SettingsFile)
+	}
+	for key, settingsFile := range src.SettingsFiles {
+		dst.SettingsFiles[key] = settingsFile
+	}
+
 	// Merge repositories (append without duplicates by name)
 	dst.Repositories.Apt = removeDuplicateRepositories(append(dst.Repositories.Apt, src.Repositories.Apt...))
 	dst.Repositories.Flatpak = removeDuplicateFlatpakRepositories(append(dst.Repositories.Flatpak, src.Repositories.Flatpak...))
+	dst.Repositories.Dnf = removeDuplicateDnfRepositories(append(dst.Repositories.Dnf, src.Repositories.Dnf...))
 
 	// Merge binaries (src overwrites dst if same key)
 	if dst.Binaries == nil {
@@ -161,6 +204,25 @@ func mergeConfigs(dst, src *Config) error {
 		dst.Binaries[key] = binary
 	}
 
+	// Merge custom managers (src overwrites dst if same name)
+	if len(src.CustomManagers) > 0 && dst.CustomManagers == nil {
+		dst.CustomManagers = make(map[string]CustomManager)
+	}
+	for name, manager := range src.CustomManagers {
+		dst.CustomManagers[name] = manager
+	}
+
+	// Merge template variables (src overwrites dst if same name)
+	if len(src.Vars) > 0 && dst.Vars == nil {
+		dst.Vars = make(map[string]interface{})
+	}
+	for name, value := range src.Vars {
+		dst.Vars[name] = value
+	}
+
+	// Merge debconf selections (append without duplicates)
+	dst.DebconfSelections = removeDuplicates(append(dst.DebconfSelections, src.DebconfSelections...))
+
 	// Merge dconf settings (src overwrites dst if same key)
 	if dst.DConf.Settings == nil {
 		dst.DConf.Settings = make(map[string]string)
@@ -233,4 +295,18 @@ func removeDuplicateFlatpakRepositories(slice []FlatpakRepository) []FlatpakRepo
 	}
 	
 	return result
-}
\ No newline at end of file
+}
+// removeDuplicateDnfRepositories removes duplicate DNF repositories by name while preserving order
+func removeDuplicateDnfRepositories(slice []DnfRepository) []DnfRepository {
+	seen := make(map[string]bool)
+	result := make([]DnfRepository, 0, len(slice))
+	
+	for _, item := range slice {
+		if !seen[item.Name] {
+			seen[item.Name] = true
+			result = append(result, item)
+		}
+	}
+	
+	return result
+}
@@ -43,12 +43,12 @@ packages:
 		t.Errorf("expected version '1.0', got '%s'", config.Version)
 	}
 	
-	if len(config.Packages.Apt) != 2 {
-		t.Errorf("expected 2 apt packages, got %d", len(config.Packages.Apt))
+	if len(config.Packages.Get("apt")) != 2 {
+		t.Errorf("expected 2 apt packages, got %d", len(config.Packages.Get("apt")))
 	}
 	
 	expectedPackages := []string{"git", "curl"}
-	for i, pkg := range config.Packages.Apt {
+	for i, pkg := range config.Packages.Get("apt") {
 		if pkg.Name != expectedPackages[i] {
 			t.Errorf("expected package '%s', got '%s'", expectedPackages[i], pkg.Name)
 		}
@@ -100,17 +100,17 @@ packages:
 	}
 	
 	// Verify merged config
-	if len(config.Packages.Apt) != 3 {
-		t.Errorf("expected 3 apt packages after merge, got %d", len(config.Packages.Apt))
+	if len(config.Packages.Get("apt")) != 3 {
+		t.Errorf("expected 3 apt packages after merge, got %d", len(config.Packages.Get("apt")))
 	}
 	
-	if len(config.Packages.Snap) != 1 {
-		t.Errorf("expected 1 snap package, got %d", len(config.Packages.Snap))
+	if len(config.Packages.Get("snap")) != 1 {
+		t.Errorf("expected 1 snap package, got %d", len(config.Packages.Get("snap")))
 	}
 	
 	// Check that packages were merged correctly
-	aptNames := make([]string, len(config.Packages.Apt))
-	for i, pkg := range config.Packages.Apt {
+	aptNames := make([]string, len(config.Packages.Get("apt")))
+	for i, pkg := range config.Packages.Get("apt") {
 		aptNames[i] = pkg.Name
 	}
 	
@@ -176,8 +176,8 @@ includes:
 	}
 	
 	// Verify loaded config
-	if len(config.Packages.Apt) != 2 {
-		t.Errorf("expected 2 apt packages, got %d", len(config.Packages.Apt))
+	if len(config.Packages.Get("apt")) != 2 {
+		t.Errorf("expected 2 apt packages, got %d", len(config.Packages.Get("apt")))
 	}
 }
 
@@ -358,7 +358,9 @@ func TestRemoveDuplicatePackages(t *testing.T) {
 func TestMergeConfigs(t *testing.T) {
 	dst := &Config{
 		Packages: PackageManagement{
-			Apt: []PackageEntry{{Name: "git"}},
+			Managers: map[string][]PackageEntry{
+				"apt": {{Name: "git"}},
+			},
 		},
 		Files: map[string]File{
 			"file1": {Source: "src1", Destination: "dest1"},
@@ -368,12 +370,17 @@ func TestMergeConfigs(t *testing.T) {
 				"/setting1": "'value1'",
 			},
 		},
+		Vars: map[string]interface{}{
+			"email": "me@example.com",
+		},
 	}
 	
 	src := &Config{
 		Packages: PackageManagement{
-			Apt:  []PackageEntry{{Name: "curl"}},
-			Snap: []PackageEntry{{Name: "discord"}},
+			Managers: map[string][]PackageEntry{
+				"apt":  {{Name: "curl"}},
+				"snap": {{Name: "discord"}},
+			},
 		},
 		Files: map[string]File{
 			"file1": {Source: "new_src1", Destination: "new_dest1"}, // Override
@@ -385,6 +392,10 @@ func TestMergeConfigs(t *testing.T) {
 				"/setting2": "'value2'",     // New
 			},
 		},
+		Vars: map[string]interface{}{
+			"email": "work@example.com", // Override
+			"scale": 2,                  // New
+		},
 	}
 	
 	err := mergeConfigs(dst, src)
@@ -393,12 +404,12 @@ func TestMergeConfigs(t *testing.T) {
 	}
 	
 	// Check packages were merged
-	if len(dst.Packages.Apt) != 2 {
-		t.Errorf("expected 2 apt packages after merge, got %d", len(dst.Packages.Apt))
+	if len(dst.Packages.Get("apt")) != 2 {
+		t.Errorf("expected 2 apt packages after merge, got %d", len(dst.Packages.Get("apt")))
 	}
 	
-	if len(dst.Packages.Snap) != 1 {
-		t.Errorf("expected 1 snap package after merge, got %d", len(dst.Packages.Snap))
+	if len(dst.Packages.Get("snap")) != 1 {
+		t.Errorf("expected 1 snap package after merge, got %d", len(dst.Packages.Get("snap")))
 	}
 	
 	// Check files were merged (src should override dst)
@@ -418,6 +429,11 @@ func TestMergeConfigs(t *testing.T) {
 	if dst.DConf.Settings["/setting1"] != "'new_value1'" {
 		t.Errorf("setting1 should be overridden by src config")
 	}
+	
+	// Check template variables were merged (src should override dst)
+	if len(dst.Vars) != 2 || dst.Vars["email"] != "work@example.com" {
+		t.Errorf("expected vars to be merged with src overriding dst, got %v", dst.Vars)
+	}
 }
 
 func TestConfigFileDiscoveryOrder(t *testing.T) {
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PackageCandidate represents one way of installing a logical package from packages.any
+// Format: "<manager>:<package> [flags...]" (e.g., "snap:code --classic")
+type PackageCandidate struct {
+	Manager string   // Registered package manager (e.g., "apt", "flatpak", "snap")
+	Name    string   // Package name for that manager
+	Flags   []string // Optional flags used when installing this candidate
+}
+
+// ParsePackageCandidate parses a logical package candidate specification
+func ParsePackageCandidate(spec string) (PackageCandidate, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return PackageCandidate{}, fmt.Errorf("candidate cannot be empty")
+	}
+
+	manager, name, found := strings.Cut(fields[0], ":")
+	if !found || manager == "" || name == "" {
+		return PackageCandidate{}, fmt.Errorf("candidate '%s' must have the form '<manager>:<package>'", spec)
+	}
+
+	if _, registered := LookupPackageManager(manager); !registered {
+		return PackageCandidate{}, fmt.Errorf("unsupported package manager '%s' (expected one of: %s)", manager, strings.Join(RegisteredPackageManagers(), ", "))
+	}
+
+	return PackageCandidate{
+		Manager: manager,
+		Name:    name,
+		Flags:   fields[1:],
+	}, nil
+}
+
+// Entry returns the package entry used to install this candidate
+func (pc PackageCandidate) Entry() PackageEntry {
+	entry := PackageEntry{Name: pc.Name}
+	if len(pc.Flags) > 0 {
+		entry.Flags = append([]string{}, pc.Flags...)
+	}
+	return entry
+}
+
+// String returns the candidate in "<manager>:<package>" form (flags omitted)
+func (pc PackageCandidate) String() string {
+	return pc.Manager + ":" + pc.Name
+}
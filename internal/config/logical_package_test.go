@@ -0,0 +1,40 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePackageCandidate(t *testing.T) {
+	tests := []struct {
+		spec      string
+		expected  PackageCandidate
+		expectErr bool
+	}{
+		{spec: "apt:code", expected: PackageCandidate{Manager: "apt", Name: "code", Flags: []string{}}},
+		{spec: "snap:code --classic", expected: PackageCandidate{Manager: "snap", Name: "code", Flags: []string{"--classic"}}},
+		{spec: "flatpak:com.visualstudio.code", expected: PackageCandidate{Manager: "flatpak", Name: "com.visualstudio.code", Flags: []string{}}},
+		{spec: "code", expectErr: true},
+		{spec: "brew:code", expectErr: true},
+		{spec: "apt:", expectErr: true},
+		{spec: "", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			candidate, err := ParsePackageCandidate(tt.spec)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("expected error for %q, got %+v", tt.spec, candidate)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(candidate, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, candidate)
+			}
+		})
+	}
+}
@@ -40,12 +40,45 @@ func (pe *PackageEntry) UnmarshalYAML(node *yaml.Node) error {
 		// If the value is a mapping, parse the configuration
 		if configNode.Kind == yaml.MappingNode {
 			var config struct {
-				Flags []string `yaml:"flags,omitempty"`
+				Flags     []string          `yaml:"flags,omitempty"`
+				SHA256    string            `yaml:"sha256,omitempty"`
+				Debconf   map[string]string `yaml:"debconf,omitempty"`
+				Remote    string            `yaml:"remote,omitempty"`
+				Scope     string            `yaml:"scope,omitempty"`
+				Branch    string            `yaml:"branch,omitempty"`
+				Overrides *FlatpakOverrides `yaml:"overrides,omitempty"`
 			}
 			if err := configNode.Decode(&config); err != nil {
 				return fmt.Errorf("failed to decode package configuration for %s: %w", pe.Name, err)
 			}
 			pe.Flags = config.Flags
+			pe.SHA256 = config.SHA256
+			pe.Debconf = config.Debconf
+			pe.Remote = config.Remote
+			pe.Scope = config.Scope
+			pe.Branch = config.Branch
+			pe.Overrides = config.Overrides
+
+			// An empty "overrides:" key still means the overrides are managed
+			if pe.Overrides == nil && hasMappingKey(configNode, "overrides") {
+				pe.Overrides = &FlatpakOverrides{}
+			}
+
+			// Keep any other keys as manager-specific options
+			for i := 0; i+1 < len(configNode.Content); i += 2 {
+				key := configNode.Content[i].Value
+				if packageEntryFields[key] {
+					continue
+				}
+				var value interface{}
+				if err := configNode.Content[i+1].Decode(&value); err != nil {
+					return fmt.Errorf("failed to decode option %s for %s: %w", key, pe.Name, err)
+				}
+				if pe.Options == nil {
+					pe.Options = make(map[string]interface{})
+				}
+				pe.Options[key] = value
+			}
 		}
 
 		return nil
@@ -54,22 +87,98 @@ func (pe *PackageEntry) UnmarshalYAML(node *yaml.Node) error {
 	return fmt.Errorf("package entry must be either a string or a mapping")
 }
 
+// packageEntryFields lists the package entry keys decoded into typed fields
+var packageEntryFields = map[string]bool{
+	"flags":     true,
+	"sha256":    true,
+	"debconf":   true,
+	"remote":    true,
+	"scope":     true,
+	"branch":    true,
+	"overrides": true,
+}
+
+// hasMappingKey reports whether a YAML mapping node contains the given key
+func hasMappingKey(node *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
+
 // MarshalYAML implements custom marshaling for PackageEntry
-// Outputs simple format if no flags, complex format if flags exist
+// Outputs simple format if no options are set, complex format otherwise
 func (pe PackageEntry) MarshalYAML() (interface{}, error) {
-	// Simple format if no flags
-	if len(pe.Flags) == 0 {
+	// Simple format if no flags or options
+	if len(pe.Flags) == 0 && pe.SHA256 == "" && len(pe.Debconf) == 0 && len(pe.Options) == 0 && !pe.HasFlatpakOptions() {
 		return pe.Name, nil
 	}
 
-	// Complex format with flags
+	// Complex format with flags and options
+	options := map[string]interface{}{}
+	for key, value := range pe.Options {
+		options[key] = value
+	}
+	if len(pe.Flags) > 0 {
+		options["flags"] = pe.Flags
+	}
+	if pe.SHA256 != "" {
+		options["sha256"] = pe.SHA256
+	}
+	if len(pe.Debconf) > 0 {
+		options["debconf"] = pe.Debconf
+	}
+	if pe.Remote != "" {
+		options["remote"] = pe.Remote
+	}
+	if pe.Scope != "" {
+		options["scope"] = pe.Scope
+	}
+	if pe.Branch != "" {
+		options["branch"] = pe.Branch
+	}
+	if pe.Overrides != nil {
+		options["overrides"] = pe.Overrides
+	}
+
 	return map[string]interface{}{
-		pe.Name: map[string]interface{}{
-			"flags": pe.Flags,
-		},
+		pe.Name: options,
 	}, nil
 }
 
+// OptionString returns a manager-specific string option, or "" if it is not set
+func (pe *PackageEntry) OptionString(key string) string {
+	if value, ok := pe.Options[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// OptionStrings returns a manager-specific list option (a single string is treated as a one-item list)
+func (pe *PackageEntry) OptionStrings(key string) []string {
+	switch value := pe.Options[key].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			result = append(result, fmt.Sprint(item))
+		}
+		return result
+	case []string:
+		return value
+	default:
+		return nil
+	}
+}
+
+// HasFlatpakOptions returns true if any Flatpak-specific option is set
+func (pe *PackageEntry) HasFlatpakOptions() bool {
+	return pe.Remote != "" || pe.Scope != "" || pe.Branch != "" || pe.Overrides != nil
+}
+
 // GetEffectiveFlags returns the flags that should be used for this package
 // considering the three-tier hierarchy: internal defaults -> user defaults -> package flags
 func (pe *PackageEntry) GetEffectiveFlags(packageManager string, userDefaults map[string][]string) []string {
@@ -248,4 +248,125 @@ func TestPackageEntry_RoundTrip(t *testing.T) {
 			t.Errorf("package %d flags mismatch: expected %v, got %v", i, originalFlags, pkgFlags)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestPackageEntry_UnmarshalYAML_FlatpakOptions(t *testing.T) {
+	yamlData := `
+- "com.visualstudio.code":
+    remote: flathub
+    scope: user
+    branch: stable
+    overrides:
+      filesystem: ["~/Projects", "xdg-download:ro"]
+      socket: ["ssh-auth"]
+      env:
+        GTK_THEME: "Adwaita:dark"
+      talk_name: ["org.freedesktop.secrets"]
+- "org.gimp.GIMP":
+    overrides:
+`
+
+	var packages []PackageEntry
+	if err := yaml.Unmarshal([]byte(yamlData), &packages); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if len(packages) != 2 {
+		t.Fatalf("expected 2 packages, got %d", len(packages))
+	}
+
+	code := packages[0]
+	if code.Remote != "flathub" || code.Scope != "user" || code.Branch != "stable" {
+		t.Errorf("unexpected remote/scope/branch: %q %q %q", code.Remote, code.Scope, code.Branch)
+	}
+	if code.Overrides == nil {
+		t.Fatal("expected overrides to be set")
+	}
+	if !reflect.DeepEqual(code.Overrides.Filesystems, []string{"~/Projects", "xdg-download:ro"}) {
+		t.Errorf("unexpected filesystems: %v", code.Overrides.Filesystems)
+	}
+	if !reflect.DeepEqual(code.Overrides.Sockets, []string{"ssh-auth"}) {
+		t.Errorf("unexpected sockets: %v", code.Overrides.Sockets)
+	}
+	if code.Overrides.Env["GTK_THEME"] != "Adwaita:dark" {
+		t.Errorf("unexpected env: %v", code.Overrides.Env)
+	}
+	if !reflect.DeepEqual(code.Overrides.TalkNames, []string{"org.freedesktop.secrets"}) {
+		t.Errorf("unexpected talk names: %v", code.Overrides.TalkNames)
+	}
+
+	// An empty overrides key still marks the overrides as managed
+	if packages[1].Overrides == nil {
+		t.Error("expected empty overrides block to be treated as managed")
+	}
+}
+
+func TestPackageEntry_FlatpakOptions_RoundTrip(t *testing.T) {
+	original := []PackageEntry{
+		{Name: "org.mozilla.Firefox"},
+		{Name: "com.spotify.Client", Remote: "flathub", Scope: "system", Branch: "stable"},
+		{Name: "org.gimp.GIMP", Overrides: &FlatpakOverrides{Filesystems: []string{"home"}}},
+	}
+
+	data, err := yaml.Marshal(original)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	var unmarshaled []PackageEntry
+	if err := yaml.Unmarshal(data, &unmarshaled); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if len(unmarshaled) != len(original) {
+		t.Fatalf("expected %d packages after round trip, got %d", len(original), len(unmarshaled))
+	}
+	if unmarshaled[1].Remote != "flathub" || unmarshaled[1].Scope != "system" || unmarshaled[1].Branch != "stable" {
+		t.Errorf("flatpak options lost in round trip: %+v", unmarshaled[1])
+	}
+	if unmarshaled[2].Overrides == nil || !reflect.DeepEqual(unmarshaled[2].Overrides.Filesystems, []string{"home"}) {
+		t.Errorf("overrides lost in round trip: %+v", unmarshaled[2].Overrides)
+	}
+}
+
+func TestPackageEntry_UnmarshalYAML_ManagerOptions(t *testing.T) {
+	yamlData := `
+- "poetry==1.8.3":
+    backend: uv
+    inject: ["poetry-plugin-export"]
+- "ruff":
+    inject: ruff-lsp
+`
+
+	var packages []PackageEntry
+	if err := yaml.Unmarshal([]byte(yamlData), &packages); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if len(packages) != 2 {
+		t.Fatalf("expected 2 packages, got %d", len(packages))
+	}
+	if packages[0].Name != "poetry==1.8.3" {
+		t.Errorf("expected name poetry==1.8.3, got %q", packages[0].Name)
+	}
+	if packages[0].OptionString("backend") != "uv" {
+		t.Errorf("expected backend uv, got %v", packages[0].Options)
+	}
+	if !reflect.DeepEqual(packages[0].OptionStrings("inject"), []string{"poetry-plugin-export"}) {
+		t.Errorf("unexpected inject list: %v", packages[0].OptionStrings("inject"))
+	}
+	if !reflect.DeepEqual(packages[1].OptionStrings("inject"), []string{"ruff-lsp"}) {
+		t.Errorf("expected single inject value to be accepted, got %v", packages[1].OptionStrings("inject"))
+	}
+
+	data, err := yaml.Marshal(packages)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var unmarshaled []PackageEntry
+	if err := yaml.Unmarshal(data, &unmarshaled); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if unmarshaled[0].OptionString("backend") != "uv" {
+		t.Errorf("options lost in round trip: %+v", unmarshaled[0])
+	}
+}
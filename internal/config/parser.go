@@ -47,10 +47,10 @@ func (cp *ConfigWithPosition) FindFieldPosition(fieldPath string) (line, column
 
 	// Split field path (e.g., "files.vimrc.source")
 	parts := strings.Split(fieldPath, ".")
-	
+
 	// Start with the document root
 	node := cp.YAMLNode.Content[0] // Document node
-	
+
 	for _, part := range parts {
 		node = findMapValue(node, part)
 		if node == nil {
@@ -105,4 +105,23 @@ func (cp *ConfigWithPosition) ValidateFieldExists(fieldPath string) bool {
 	}
 
 	return true
-}
\ No newline at end of file
+}
+
+// FindPackagePosition finds the line and column of a package entry in packages.<manager>
+func (cp *ConfigWithPosition) FindPackagePosition(manager, packageName string) (line, column int) {
+	if cp.YAMLNode == nil || len(cp.YAMLNode.Content) == 0 {
+		return 0, 0
+	}
+
+	list := findMapValue(findMapValue(cp.YAMLNode.Content[0], "packages"), manager)
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return 0, 0
+	}
+
+	for _, item := range list.Content {
+		if packageEntryName(item) == packageName {
+			return item.Line, item.Column
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PackageManagerSpec describes a package manager to configuration decoding, validation and defaults
+// Built-in managers are registered below; additional managers register themselves from internal/pkg
+type PackageManagerSpec struct {
+	Name         string              // Key under packages: and package_defaults: (e.g., "apt")
+	DisplayName  string              // Human-readable name (e.g., "APT")
+	DefaultFlags []string            // Built-in default flags (tier 1 of the flag hierarchy)
+	ValidateName func(string) bool   // Reports whether a package name is valid for this manager
+	SanitizeName func(string) string // Optional: suggests a valid name for an invalid one
+	NameMessage  string              // Validation message for invalid package names
+	NameHelp     string              // Validation help for invalid package names
+
+	// Options lists the manager-specific package entry options this manager accepts (see PackageEntry.Options)
+	Options []string
+	// ValidateEntry performs additional manager-specific validation of a package entry (optional)
+	ValidateEntry func(pkg PackageEntry, field string) []ValidationError
+	// Settings lists the manager-level settings accepted under package_settings: (e.g., npm "prefix")
+	Settings []string
+	// Distros lists the distribution IDs (matched against os-release ID and ID_LIKE) that provide
+	// this manager; empty means the manager may exist on any distribution
+	Distros []string
+	// Custom holds the command templates of a manager defined under custom_managers: (nil for built-ins)
+	Custom *CustomManager
+}
+
+// packageManagerSpecs holds registered managers in registration order
+var packageManagerSpecs []PackageManagerSpec
+
+// RegisterPackageManager registers (or replaces) a package manager specification
+func RegisterPackageManager(spec PackageManagerSpec) {
+	if spec.DisplayName == "" {
+		spec.DisplayName = spec.Name
+	}
+	if spec.DefaultFlags == nil {
+		spec.DefaultFlags = []string{}
+	}
+	DefaultPackageFlags[spec.Name] = spec.DefaultFlags
+
+	for i, existing := range packageManagerSpecs {
+		if existing.Name == spec.Name {
+			packageManagerSpecs[i] = spec
+			return
+		}
+	}
+	packageManagerSpecs = append(packageManagerSpecs, spec)
+}
+
+// LookupPackageManager returns the specification of a registered package manager
+func LookupPackageManager(name string) (PackageManagerSpec, bool) {
+	for _, spec := range packageManagerSpecs {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return PackageManagerSpec{}, false
+}
+
+// PackageManagerDisplayName returns the human-readable name of a package manager (e.g., "APT")
+func PackageManagerDisplayName(name string) string {
+	if spec, registered := LookupPackageManager(name); registered {
+		return spec.DisplayName
+	}
+	return name
+}
+
+// RegisteredPackageManagers returns the names of all registered package managers in registration order
+func RegisteredPackageManagers() []string {
+	names := make([]string, 0, len(packageManagerSpecs))
+	for _, spec := range packageManagerSpecs {
+		names = append(names, spec.Name)
+	}
+	return names
+}
+
+func init() {
+	RegisterPackageManager(PackageManagerSpec{
+		Name:        "apt",
+		DisplayName: "APT",
+		// APT defaults: non-interactive and don't install recommended packages
+		DefaultFlags: []string{"-y", "--no-install-recommends"},
+		Distros:      []string{"debian", "ubuntu"},
+		ValidateName: func(name string) bool {
+			// Check if it's a remote .deb file (HTTPS URL)
+			if strings.HasPrefix(name, "https://") || strings.HasPrefix(name, "http://") {
+				return isValidRemoteDebURL(name)
+			}
+			// Check if it's a local .deb file
+			if strings.HasSuffix(name, ".deb") {
+				// If it ends with .deb, it must be a valid file path
+				return isValidDebFilePath(name)
+			}
+			// APT package names: lowercase, numbers, hyphens, dots, plus signs
+			matched, _ := regexp.MatchString(`^[a-z0-9][a-z0-9\-\.\+]*$`, name)
+			return matched
+		},
+		SanitizeName: func(name string) string {
+			return strings.ToLower(regexp.MustCompile(`[^a-z0-9\-\.\+]`).ReplaceAllString(name, "-"))
+		},
+		NameMessage: "APT package name or .deb file path contains invalid characters",
+		NameHelp:    "use only lowercase letters, numbers, hyphens, dots, and plus signs",
+	})
+
+	RegisterPackageManager(PackageManagerSpec{
+		Name:        "flatpak",
+		DisplayName: "Flatpak",
+		// Flatpak defaults: install system-wide and assume yes for prompts
+		DefaultFlags: []string{"--system", "--assumeyes"},
+		ValidateName: func(name string) bool {
+			// Check if it's a .flatpakref file or .flatpak bundle (local path or HTTPS URL)
+			if (strings.HasSuffix(name, ".flatpakref") || strings.HasSuffix(name, ".flatpak")) && strings.Contains(name, "/") {
+				return isValidFlatpakBundlePath(name)
+			}
+			// Flatpak app IDs: reverse domain notation with dots, letters, numbers
+			matched, _ := regexp.MatchString(`^[a-zA-Z0-9][a-zA-Z0-9\-\._]*[a-zA-Z0-9]$`, name)
+			return matched
+		},
+		SanitizeName: func(name string) string {
+			// For flatpak, preserve case and dots, replace invalid chars with dots
+			return regexp.MustCompile(`[^a-zA-Z0-9\-\._]`).ReplaceAllString(name, ".")
+		},
+		NameMessage: "Flatpak app ID or bundle path contains invalid characters",
+		NameHelp:    "use reverse domain notation like org.app.Name, or a path/HTTPS URL to a .flatpakref or .flatpak file",
+	})
+
+	RegisterPackageManager(PackageManagerSpec{
+		Name:        "snap",
+		DisplayName: "Snap",
+		// Snap defaults: empty - snaps are interactive by design and most don't need special flags
+		DefaultFlags: []string{},
+		ValidateName: func(name string) bool {
+			// Snap package names: lowercase, numbers, hyphens
+			matched, _ := regexp.MatchString(`^[a-z0-9][a-z0-9\-]*$`, name)
+			return matched
+		},
+		SanitizeName: func(name string) string {
+			return strings.ToLower(regexp.MustCompile(`[^a-z0-9\-]`).ReplaceAllString(name, "-"))
+		},
+		NameMessage: "Snap package name contains invalid characters",
+		NameHelp:    "use only lowercase letters, numbers, and hyphens",
+	})
+}
+
+// Get returns the packages configured for a package manager
+func (pm *PackageManagement) Get(manager string) []PackageEntry {
+	return pm.Managers[manager]
+}
+
+// Set replaces the packages configured for a package manager
+func (pm *PackageManagement) Set(manager string, packages []PackageEntry) {
+	if pm.Managers == nil {
+		pm.Managers = make(map[string][]PackageEntry)
+	}
+	pm.Managers[manager] = packages
+}
+
+// ManagerNames returns the registered package managers followed by any other manager keys
+// found in the configuration (sorted), so unknown managers can be reported
+func (pm *PackageManagement) ManagerNames() []string {
+	names := RegisteredPackageManagers()
+
+	var unknown []string
+	for name := range pm.Managers {
+		if _, registered := LookupPackageManager(name); !registered {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+
+	return append(names, unknown...)
+}
+
+// Count returns the total number of packages across all package managers
+func (pm *PackageManagement) Count() int {
+	total := 0
+	for _, manager := range pm.ManagerNames() {
+		total += len(pm.Get(manager))
+	}
+	return total
+}
+
+// Summary returns the package count per manager, e.g. "APT: 3, Flatpak: 1, Snap: 0"
+func (pm *PackageManagement) Summary() string {
+	parts := make([]string, 0, len(packageManagerSpecs))
+	for _, manager := range pm.ManagerNames() {
+		parts = append(parts, fmt.Sprintf("%s: %d", PackageManagerDisplayName(manager), len(pm.Get(manager))))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// UnmarshalYAML implements custom unmarshaling for PackageManagement
+// Every key except "any" is a package manager holding a list of package entries
+func (pm *PackageManagement) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("packages must be a mapping")
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		valueNode := node.Content[i+1]
+
+		if keyNode.Kind != yaml.ScalarNode {
+			continue
+		}
+
+		if keyNode.Value == "any" {
+			if err := valueNode.Decode(&pm.Any); err != nil {
+				return fmt.Errorf("failed to decode logical packages: %w", err)
+			}
+			continue
+		}
+
+		var packages []PackageEntry
+		if err := valueNode.Decode(&packages); err != nil {
+			return fmt.Errorf("failed to decode %s packages: %w", keyNode.Value, err)
+		}
+		pm.Set(keyNode.Value, packages)
+	}
+
+	return nil
+}
+
+// MarshalYAML implements custom marshaling for PackageManagement
+func (pm PackageManagement) MarshalYAML() (interface{}, error) {
+	result := make(map[string]interface{}, len(pm.Managers)+1)
+	for manager, packages := range pm.Managers {
+		result[manager] = packages
+	}
+	if len(pm.Any) > 0 {
+		result["any"] = pm.Any
+	}
+	return result, nil
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// registerTestPackageManager registers a package manager for the duration of a test
+func registerTestPackageManager(t *testing.T, spec PackageManagerSpec) {
+	t.Helper()
+	savedSpecs := append([]PackageManagerSpec{}, packageManagerSpecs...)
+	t.Cleanup(func() {
+		packageManagerSpecs = savedSpecs
+		delete(DefaultPackageFlags, spec.Name)
+	})
+	RegisterPackageManager(spec)
+}
+
+func TestRegisteredPackageManagers_BuiltIn(t *testing.T) {
+	expected := []string{"apt", "flatpak", "snap"}
+	managers := RegisteredPackageManagers()
+
+	if len(managers) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, managers)
+	}
+	for i, name := range expected {
+		if managers[i] != name {
+			t.Errorf("expected manager %d to be %s, got %s", i, name, managers[i])
+		}
+	}
+
+	if PackageManagerDisplayName("apt") != "APT" {
+		t.Errorf("expected display name APT, got %s", PackageManagerDisplayName("apt"))
+	}
+}
+
+func TestRegisterPackageManager_DecodeAndValidate(t *testing.T) {
+	registerTestPackageManager(t, PackageManagerSpec{
+		Name:         "testpm",
+		DisplayName:  "TestPM",
+		DefaultFlags: []string{"--quiet"},
+		ValidateName: func(name string) bool { return name != "bad" },
+		NameMessage:  "TestPM package name is invalid",
+	})
+
+	yamlContent := `
+packages:
+  apt:
+    - curl
+  testpm:
+    - good
+    - bad
+  unknownpm:
+    - something
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(yamlContent), &cfg); err != nil {
+		t.Fatalf("failed to unmarshal config: %v", err)
+	}
+	cfg.Version = "1.0"
+
+	if len(cfg.Packages.Get("apt")) != 1 || len(cfg.Packages.Get("testpm")) != 2 {
+		t.Fatalf("unexpected packages: apt=%v testpm=%v", cfg.Packages.Get("apt"), cfg.Packages.Get("testpm"))
+	}
+	if flags := GetDefaultFlags("testpm"); len(flags) != 1 || flags[0] != "--quiet" {
+		t.Errorf("expected registered default flags, got %v", flags)
+	}
+	if summary := cfg.Packages.Summary(); summary != "APT: 1, Flatpak: 0, Snap: 0, TestPM: 2, unknownpm: 1" {
+		t.Errorf("unexpected summary: %s", summary)
+	}
+
+	result := Validate(&cfg, filepath.Join(t.TempDir(), "config.yaml"))
+
+	titles := make(map[string]int)
+	for _, err := range result.Errors {
+		titles[err.Title]++
+	}
+	if titles["invalid package name"] != 1 {
+		t.Errorf("expected 1 invalid package name error, got %d", titles["invalid package name"])
+	}
+	if titles["unsupported package manager"] != 1 {
+		t.Errorf("expected 1 unsupported package manager error, got %d", titles["unsupported package manager"])
+	}
+}
+
+func TestMergeConfigs_RegisteredManagers(t *testing.T) {
+	registerTestPackageManager(t, PackageManagerSpec{Name: "testpm"})
+
+	dst := &Config{}
+	dst.Packages.Set("testpm", []PackageEntry{{Name: "one"}})
+	src := &Config{}
+	src.Packages.Set("testpm", []PackageEntry{{Name: "one"}, {Name: "two"}})
+
+	if err := mergeConfigs(dst, src); err != nil {
+		t.Fatalf("mergeConfigs failed: %v", err)
+	}
+
+	packages := dst.Packages.Get("testpm")
+	if len(packages) != 2 || packages[0].Name != "one" || packages[1].Name != "two" {
+		t.Errorf("expected merged packages [one two], got %v", packages)
+	}
+}
+
+func TestValidate_PackageSettings(t *testing.T) {
+	registerTestPackageManager(t, PackageManagerSpec{
+		Name:         "testpm",
+		DisplayName:  "TestPM",
+		ValidateName: func(name string) bool { return true },
+		Settings:     []string{"prefix"},
+	})
+
+	tests := []struct {
+		name          string
+		settings      map[string]map[string]string
+		expectedTitle string
+	}{
+		{"known setting", map[string]map[string]string{"testpm": {"prefix": "~/.local"}}, ""},
+		{"unknown setting", map[string]map[string]string{"testpm": {"root": "/opt"}}, "unknown package setting"},
+		{"unknown manager", map[string]map[string]string{"nopm": {"prefix": "/opt"}}, "unsupported package manager"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Version: "1.0", PackageSettings: tt.settings}
+			result := Validate(cfg, "test.yaml")
+
+			issues := append(result.Errors, result.Warnings...)
+			if tt.expectedTitle == "" {
+				if len(issues) > 0 {
+					t.Errorf("expected no issues, got %+v", issues)
+				}
+				return
+			}
+			found := false
+			for _, issue := range issues {
+				if issue.Title == tt.expectedTitle {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected issue %q, got %+v", tt.expectedTitle, issues)
+			}
+		})
+	}
+}
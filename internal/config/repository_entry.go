@@ -16,6 +16,7 @@ func (rm *RepositoryManagement) UnmarshalYAML(node *yaml.Node) error {
 	// Initialize slices
 	rm.Apt = []AptRepository{}
 	rm.Flatpak = []FlatpakRepository{}
+	rm.Dnf = []DnfRepository{}
 
 	// Process each key-value pair
 	for i := 0; i < len(node.Content); i += 2 {
@@ -35,6 +36,10 @@ func (rm *RepositoryManagement) UnmarshalYAML(node *yaml.Node) error {
 			if err := rm.unmarshalFlatpakRepositories(valueNode); err != nil {
 				return fmt.Errorf("failed to unmarshal flatpak repositories: %w", err)
 			}
+		case "dnf":
+			if err := rm.unmarshalDnfRepositories(valueNode); err != nil {
+				return fmt.Errorf("failed to unmarshal dnf repositories: %w", err)
+			}
 		}
 	}
 
@@ -134,4 +139,30 @@ func (rm *RepositoryManagement) unmarshalFlatpakRepositories(node *yaml.Node) er
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+func (rm *RepositoryManagement) unmarshalDnfRepositories(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("dnf repositories must be a mapping")
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		nameNode := node.Content[i]
+		configNode := node.Content[i+1]
+
+		if nameNode.Kind != yaml.ScalarNode {
+			continue
+		}
+
+		var repo DnfRepository
+		if configNode.Kind == yaml.MappingNode {
+			if err := configNode.Decode(&repo); err != nil {
+				return fmt.Errorf("failed to decode dnf repository %s: %w", nameNode.Value, err)
+			}
+		}
+		repo.Name = nameNode.Value
+
+		rm.Dnf = append(rm.Dnf, repo)
+	}
+
+	return nil
+}
@@ -59,57 +59,33 @@ func (cs *ConfigSplitter) splitByPackageManager(config *Config) (map[string]*Con
 	baseConfig := &Config{
 		Version:         config.Version,
 		PackageDefaults: config.PackageDefaults,
+		PackageSettings: config.PackageSettings,
+		CustomManagers:  config.CustomManagers,
+		Vars:            config.Vars,
 		BackupPolicy:    config.BackupPolicy,
 		Includes:        []IncludeSpec{},
 	}
 
-	// Split APT packages
-	if len(config.Packages.Apt) > 0 {
-		aptConfig := &Config{
-			Version: config.Version,
-			Packages: PackageManagement{
-				Apt: config.Packages.Apt,
-			},
-		}
-		result["packages/apt.yaml"] = aptConfig
-		baseConfig.Includes = append(baseConfig.Includes, IncludeSpec{
-			Path:        "packages/apt.yaml",
-			Description: "APT package management",
-		})
-	}
-
-	// Split Flatpak packages
-	if len(config.Packages.Flatpak) > 0 {
-		flatpakConfig := &Config{
-			Version: config.Version,
-			Packages: PackageManagement{
-				Flatpak: config.Packages.Flatpak,
-			},
+	// Split packages into one file per package manager
+	for _, manager := range config.Packages.ManagerNames() {
+		packages := config.Packages.Get(manager)
+		if len(packages) == 0 {
+			continue
 		}
-		result["packages/flatpak.yaml"] = flatpakConfig
-		baseConfig.Includes = append(baseConfig.Includes, IncludeSpec{
-			Path:        "packages/flatpak.yaml",
-			Description: "Flatpak package management",
-		})
-	}
-
-	// Split Snap packages
-	if len(config.Packages.Snap) > 0 {
-		snapConfig := &Config{
-			Version: config.Version,
-			Packages: PackageManagement{
-				Snap: config.Packages.Snap,
-			},
-		}
-		result["packages/snap.yaml"] = snapConfig
+		
+		managerConfig := &Config{Version: config.Version}
+		managerConfig.Packages.Set(manager, packages)
+		
+		fileName := fmt.Sprintf("packages/%s.yaml", manager)
+		result[fileName] = managerConfig
 		baseConfig.Includes = append(baseConfig.Includes, IncludeSpec{
-			Path:        "packages/snap.yaml",
-			Description: "Snap package management",
+			Path:        fileName,
+			Description: fmt.Sprintf("%s package management", PackageManagerDisplayName(manager)),
 		})
 	}
 
 	// Split repositories
-	if len(config.Repositories.Apt) > 0 || len(config.Repositories.Flatpak) > 0 {
+	if len(config.Repositories.Apt) > 0 || len(config.Repositories.Flatpak) > 0 || len(config.Repositories.Dnf) > 0 {
 		repoConfig := &Config{
 			Version:      config.Version,
 			Repositories: config.Repositories,
@@ -159,13 +135,16 @@ func (cs *ConfigSplitter) splitByDomain(config *Config) (map[string]*Config, err
 	baseConfig := &Config{
 		Version:         config.Version,
 		PackageDefaults: config.PackageDefaults,
+		PackageSettings: config.PackageSettings,
+		CustomManagers:  config.CustomManagers,
+		Vars:            config.Vars,
 		BackupPolicy:    config.BackupPolicy,
 		Includes:        []IncludeSpec{},
 	}
 
 	// Development tools domain
 	devPackages := cs.filterPackagesByDomain(config.Packages, "development")
-	if len(devPackages.Apt) > 0 || len(devPackages.Flatpak) > 0 || len(devPackages.Snap) > 0 {
+	if devPackages.Count() > 0 {
 		devConfig := &Config{
 			Version:  config.Version,
 			Packages: devPackages,
@@ -179,7 +158,7 @@ func (cs *ConfigSplitter) splitByDomain(config *Config) (map[string]*Config, err
 
 	// Media domain
 	mediaPackages := cs.filterPackagesByDomain(config.Packages, "media")
-	if len(mediaPackages.Apt) > 0 || len(mediaPackages.Flatpak) > 0 || len(mediaPackages.Snap) > 0 {
+	if mediaPackages.Count() > 0 {
 		mediaConfig := &Config{
 			Version:  config.Version,
 			Packages: mediaPackages,
@@ -193,7 +172,7 @@ func (cs *ConfigSplitter) splitByDomain(config *Config) (map[string]*Config, err
 
 	// System utilities domain
 	systemPackages := cs.filterPackagesByDomain(config.Packages, "system")
-	if len(systemPackages.Apt) > 0 || len(systemPackages.Flatpak) > 0 || len(systemPackages.Snap) > 0 {
+	if systemPackages.Count() > 0 {
 		systemConfig := &Config{
 			Version:  config.Version,
 			Packages: systemPackages,
@@ -217,6 +196,9 @@ func (cs *ConfigSplitter) splitByEnvironment(config *Config) (map[string]*Config
 	baseConfig := &Config{
 		Version:         config.Version,
 		PackageDefaults: config.PackageDefaults,
+		PackageSettings: config.PackageSettings,
+		CustomManagers:  config.CustomManagers,
+		Vars:            config.Vars,
 		BackupPolicy:    config.BackupPolicy,
 		Repositories:    config.Repositories,
 		Includes: []IncludeSpec{
@@ -263,7 +245,7 @@ func (cs *ConfigSplitter) splitByEnvironment(config *Config) (map[string]*Config
 
 	// Development-specific packages
 	devPackages := cs.getDevelopmentPackages(config.Packages)
-	if len(devPackages.Apt) > 0 || len(devPackages.Flatpak) > 0 || len(devPackages.Snap) > 0 {
+	if devPackages.Count() > 0 {
 		devConfig := &Config{
 			Version:  config.Version,
 			Packages: devPackages,
@@ -273,7 +255,7 @@ func (cs *ConfigSplitter) splitByEnvironment(config *Config) (map[string]*Config
 
 	// Production-specific packages (minimal)
 	prodPackages := cs.getProductionPackages(config.Packages)
-	if len(prodPackages.Apt) > 0 || len(prodPackages.Flatpak) > 0 || len(prodPackages.Snap) > 0 {
+	if prodPackages.Count() > 0 {
 		prodConfig := &Config{
 			Version:  config.Version,
 			Packages: prodPackages,
@@ -293,6 +275,9 @@ func (cs *ConfigSplitter) splitByHost(config *Config) (map[string]*Config, error
 	baseConfig := &Config{
 		Version:         config.Version,
 		PackageDefaults: config.PackageDefaults,
+		PackageSettings: config.PackageSettings,
+		CustomManagers:  config.CustomManagers,
+		Vars:            config.Vars,
 		BackupPolicy:    config.BackupPolicy,
 		Repositories:    config.Repositories,
 		Includes: []IncludeSpec{
@@ -368,6 +353,9 @@ func (cs *ConfigSplitter) splitByFunction(config *Config) (map[string]*Config, e
 	baseConfig := &Config{
 		Version:         config.Version,
 		PackageDefaults: config.PackageDefaults,
+		PackageSettings: config.PackageSettings,
+		CustomManagers:  config.CustomManagers,
+		Vars:            config.Vars,
 		BackupPolicy:    config.BackupPolicy,
 		Includes: []IncludeSpec{
 			{Path: "functions/repositories.yaml", Description: "Repository management"},
@@ -433,30 +421,7 @@ func (cs *ConfigSplitter) filterPackagesByDomain(packages PackageManagement, dom
 		return PackageManagement{}
 	}
 
-	result := PackageManagement{}
-	
-	// Filter APT packages
-	for _, pkg := range packages.Apt {
-		if contains(packageList, pkg.Name) {
-			result.Apt = append(result.Apt, pkg)
-		}
-	}
-
-	// Filter Flatpak packages
-	for _, pkg := range packages.Flatpak {
-		if contains(packageList, pkg.Name) {
-			result.Flatpak = append(result.Flatpak, pkg)
-		}
-	}
-
-	// Filter Snap packages
-	for _, pkg := range packages.Snap {
-		if contains(packageList, pkg.Name) {
-			result.Snap = append(result.Snap, pkg)
-		}
-	}
-
-	return result
+	return cs.filterPackagesByNames(packages, packageList)
 }
 
 func (cs *ConfigSplitter) getCommonPackages(packages PackageManagement) PackageManagement {
@@ -493,21 +458,15 @@ func (cs *ConfigSplitter) getWorkstationPackages(packages PackageManagement) Pac
 func (cs *ConfigSplitter) filterPackagesByNames(packages PackageManagement, names []string) PackageManagement {
 	result := PackageManagement{}
 
-	for _, pkg := range packages.Apt {
-		if contains(names, pkg.Name) {
-			result.Apt = append(result.Apt, pkg)
+	for _, manager := range packages.ManagerNames() {
+		var filtered []PackageEntry
+		for _, pkg := range packages.Get(manager) {
+			if contains(names, pkg.Name) {
+				filtered = append(filtered, pkg)
+			}
 		}
-	}
-
-	for _, pkg := range packages.Flatpak {
-		if contains(names, pkg.Name) {
-			result.Flatpak = append(result.Flatpak, pkg)
-		}
-	}
-
-	for _, pkg := range packages.Snap {
-		if contains(names, pkg.Name) {
-			result.Snap = append(result.Snap, pkg)
+		if len(filtered) > 0 {
+			result.Set(manager, filtered)
 		}
 	}
 
@@ -561,14 +520,9 @@ func (cs *ConfigSplitter) GenerateSplitReport(configs map[string]*Config) string
 			report.WriteString(fmt.Sprintf("  Includes: %d files\n", len(config.Includes)))
 		}
 		
-		aptCount := len(config.Packages.Apt)
-		flatpakCount := len(config.Packages.Flatpak)
-		snapCount := len(config.Packages.Snap)
-		totalPackages := aptCount + flatpakCount + snapCount
-		
-		if totalPackages > 0 {
-			report.WriteString(fmt.Sprintf("  Packages: %d total (APT: %d, Flatpak: %d, Snap: %d)\n", 
-				totalPackages, aptCount, flatpakCount, snapCount))
+		if totalPackages := config.Packages.Count(); totalPackages > 0 {
+			report.WriteString(fmt.Sprintf("  Packages: %d total (%s)\n", 
+				totalPackages, config.Packages.Summary()))
 		}
 		
 		if len(config.Files) > 0 {
@@ -579,9 +533,9 @@ func (cs *ConfigSplitter) GenerateSplitReport(configs map[string]*Config) string
 			report.WriteString(fmt.Sprintf("  DConf Settings: %d\n", len(config.DConf.Settings)))
 		}
 		
-		if len(config.Repositories.Apt) > 0 || len(config.Repositories.Flatpak) > 0 {
-			report.WriteString(fmt.Sprintf("  Repositories: APT: %d, Flatpak: %d\n", 
-				len(config.Repositories.Apt), len(config.Repositories.Flatpak)))
+		if len(config.Repositories.Apt) > 0 || len(config.Repositories.Flatpak) > 0 || len(config.Repositories.Dnf) > 0 {
+			report.WriteString(fmt.Sprintf("  Repositories: APT: %d, Flatpak: %d, DNF: %d\n", 
+				len(config.Repositories.Apt), len(config.Repositories.Flatpak), len(config.Repositories.Dnf)))
 		}
 		
 		report.WriteString("\n")
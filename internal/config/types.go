@@ -1,16 +1,30 @@
 package config
 
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
 // Config represents the main configuration structure
 type Config struct {
 	Version         string                    `yaml:"version" mapstructure:"version"`
 	Includes        []IncludeSpec             `yaml:"includes,omitempty" mapstructure:"includes,omitempty"`
 	PackageDefaults map[string][]string       `yaml:"package_defaults,omitempty" mapstructure:"package_defaults,omitempty"`
+	PackageSettings map[string]map[string]string `yaml:"package_settings,omitempty" mapstructure:"package_settings,omitempty"` // Manager-level settings (e.g., npm prefix)
 	BackupPolicy    BackupPolicy              `yaml:"backup_policy,omitempty" mapstructure:"backup_policy,omitempty"`
 	Repositories    RepositoryManagement      `yaml:"repositories,omitempty" mapstructure:"repositories,omitempty"`
 	Packages        PackageManagement         `yaml:"packages" mapstructure:"packages"`
 	Files           map[string]File           `yaml:"files" mapstructure:"files"`
+	Directories     map[string]Directory      `yaml:"directories,omitempty" mapstructure:"directories,omitempty"` // Directory trees mirrored into a destination
+	Lines           map[string]LineInFile     `yaml:"lines,omitempty" mapstructure:"lines,omitempty"` // Lines that must exist in files configr doesn't own
+	Blocks          map[string]Block          `yaml:"blocks,omitempty" mapstructure:"blocks,omitempty"` // Marker-delimited blocks managed in files configr doesn't own
+	SettingsFiles   map[string]SettingsFile   `yaml:"settings_files,omitempty" mapstructure:"settings_files,omitempty"` // Keys managed in JSON, YAML, TOML and INI files applications also write
 	Binaries        map[string]Binary         `yaml:"binaries,omitempty" mapstructure:"binaries,omitempty"`
 	DConf           DConfConfig               `yaml:"dconf" mapstructure:"dconf"`
+	DebconfSelections []string                `yaml:"debconf_selections,omitempty" mapstructure:"debconf_selections,omitempty"` // Lines in debconf-set-selections format
+	CustomManagers  map[string]CustomManager  `yaml:"custom_managers,omitempty" mapstructure:"custom_managers,omitempty"` // Package managers defined with command templates
+	Vars            map[string]interface{}    `yaml:"vars,omitempty" mapstructure:"vars,omitempty"` // Variables available to templated files as .Vars
 }
 
 // IncludeSpec represents an include specification with conditional logic and glob support
@@ -30,9 +44,13 @@ type IncludeCondition struct {
 
 // PackageManagement contains all package manager configurations
 type PackageManagement struct {
-	Apt     []PackageEntry `yaml:"apt" mapstructure:"apt"`
-	Flatpak []PackageEntry `yaml:"flatpak" mapstructure:"flatpak"`
-	Snap    []PackageEntry `yaml:"snap" mapstructure:"snap"`
+	// Managers holds the packages of each package manager, keyed by manager name (e.g., "apt")
+	// Use Get/Set to access packages of any manager uniformly
+	Managers map[string][]PackageEntry `yaml:"-" mapstructure:"-"`
+
+	// Any maps a logical package name to ordered candidates ("apt:code", "snap:code --classic", ...)
+	// The first installed candidate wins; otherwise the first available one is installed
+	Any map[string][]string `yaml:"any,omitempty" mapstructure:"any,omitempty"`
 }
 
 // PackageEntry represents a package with optional configuration
@@ -43,11 +61,46 @@ type PackageManagement struct {
 type PackageEntry struct {
 	Name  string   `yaml:"-" mapstructure:"-"`                           // Package name (from YAML key or string value)
 	Flags []string `yaml:"flags,omitempty" mapstructure:"flags,omitempty"` // Optional flags for this package
+
+	// Source is set when the entry was given as a file, URL or requirement spec (e.g., a Flatpak bundle
+	// or "poetry==1.8.3") and has been resolved: Name then holds the real package name and Source
+	// what to install from
+	Source string `yaml:"-" mapstructure:"-"`
+
+	// Logical is set when the entry was chosen for a logical package from packages.any
+	Logical string `yaml:"-" mapstructure:"-"`
+
+	// SHA256 is the expected checksum of a downloaded package file (remote .deb URLs)
+	SHA256 string `yaml:"sha256,omitempty" mapstructure:"sha256,omitempty"`
+
+	// Debconf answers preseeded before an APT install, keyed by question (value: "<type> <value>")
+	// Example: "wireshark-common/install-setuid": "boolean true"
+	Debconf map[string]string `yaml:"debconf,omitempty" mapstructure:"debconf,omitempty"`
+
+	// Flatpak-only options
+	Remote    string            `yaml:"remote,omitempty" mapstructure:"remote,omitempty"`       // Remote to install from (e.g., "flathub")
+	Scope     string            `yaml:"scope,omitempty" mapstructure:"scope,omitempty"`         // Installation scope: "user" or "system"
+	Branch    string            `yaml:"branch,omitempty" mapstructure:"branch,omitempty"`       // Branch to install (e.g., "stable", "beta")
+	Overrides *FlatpakOverrides `yaml:"overrides,omitempty" mapstructure:"overrides,omitempty"` // Sandbox permission overrides
+
+	// Options holds manager-specific options declared by registered package managers (e.g., pipx "inject")
+	Options map[string]interface{} `yaml:"-" mapstructure:"-"`
+}
+
+// FlatpakOverrides represents sandbox permission overrides applied with `flatpak override`
+// An empty overrides block is meaningful: it resets any previously applied overrides
+type FlatpakOverrides struct {
+	Filesystems []string          `yaml:"filesystem,omitempty" mapstructure:"filesystem,omitempty"` // --filesystem entries (e.g., "~/Documents", "xdg-download:ro")
+	Sockets     []string          `yaml:"socket,omitempty" mapstructure:"socket,omitempty"`         // --socket entries (e.g., "wayland", "ssh-auth")
+	Env         map[string]string `yaml:"env,omitempty" mapstructure:"env,omitempty"`               // --env VAR=VALUE entries
+	TalkNames   []string          `yaml:"talk_name,omitempty" mapstructure:"talk_name,omitempty"`   // --talk-name D-Bus names
 }
 
 // File represents a file to be managed (dotfile, system file, etc.)
 type File struct {
-	Source           string `yaml:"source" mapstructure:"source"`
+	Source           string `yaml:"source,omitempty" mapstructure:"source,omitempty"`                           // Path relative to the config file, or an https:// URL
+	Content          string `yaml:"content,omitempty" mapstructure:"content,omitempty"`                       // Inline file content (instead of source)
+	SHA256           string `yaml:"sha256,omitempty" mapstructure:"sha256,omitempty"`                         // Expected checksum of a downloaded source
 	Destination      string `yaml:"destination" mapstructure:"destination"`
 	Owner            string `yaml:"owner,omitempty" mapstructure:"owner,omitempty"`
 	Group            string `yaml:"group,omitempty" mapstructure:"group,omitempty"`
@@ -57,7 +110,165 @@ type File struct {
 	Interactive      bool   `yaml:"interactive,omitempty" mapstructure:"interactive,omitempty"`           // Prompt for conflicts
 	PromptPermissions bool  `yaml:"prompt_permissions,omitempty" mapstructure:"prompt_permissions,omitempty"` // Prompt for permissions
 	PromptOwnership  bool   `yaml:"prompt_ownership,omitempty" mapstructure:"prompt_ownership,omitempty"`     // Prompt for ownership
+	Template         bool   `yaml:"template,omitempty" mapstructure:"template,omitempty"`                     // Render the source with text/template and deploy the result as a copy
+	ACL              []string          `yaml:"acl,omitempty" mapstructure:"acl,omitempty"`                 // POSIX ACL entries in setfacl syntax, e.g. "group:devs:rw-"
+	Xattrs           map[string]string `yaml:"xattrs,omitempty" mapstructure:"xattrs,omitempty"`           // Extended attributes, e.g. "user.origin": "configr"
+	Immutable        bool   `yaml:"immutable,omitempty" mapstructure:"immutable,omitempty"`                   // Set the immutable flag (chattr +i)
+	AppendOnly       bool   `yaml:"append_only,omitempty" mapstructure:"append_only,omitempty"`               // Set the append-only flag (chattr +a)
 	ConfigDir        string `yaml:"-" mapstructure:"-"`                                                       // Directory of the config file that defined this file (for relative path resolution)
+	DirectoryRoot    string `yaml:"-" mapstructure:"-"`                                                       // Destination of the directories: entry this file was expanded from
+}
+
+// IsInline reports whether the file content is given inline with content:
+func (f File) IsInline() bool {
+	return f.Content != ""
+}
+
+// IsRemote reports whether the file is downloaded from a URL
+func (f File) IsRemote() bool {
+	return strings.HasPrefix(f.Source, "https://") || strings.HasPrefix(f.Source, "http://")
+}
+
+// IsEncrypted reports whether the file source is age-encrypted (a .age file)
+func (f File) IsEncrypted() bool {
+	return !f.IsInline() && strings.HasSuffix(f.Source, ".age")
+}
+
+// DeploysAsCopy reports whether the file is deployed as a copy rather than a symlink
+// Inline, remote, templated and encrypted files have no local source to link to, so they are always copied
+func (f File) DeploysAsCopy() bool {
+	return f.Copy || f.Template || f.IsInline() || f.IsRemote() || f.IsEncrypted()
+}
+
+// HasExtendedAttributes reports whether ACL entries, extended attributes or attribute flags are configured
+func (f File) HasExtendedAttributes() bool {
+	return len(f.ACL) > 0 || len(f.Xattrs) > 0 || f.Immutable || f.AppendOnly
+}
+
+// DisplaySource describes where the file content comes from, for previews and logs
+func (f File) DisplaySource() string {
+	if f.IsInline() {
+		return "(inline content)"
+	}
+	return f.Source
+}
+
+// Directory deployment methods
+const (
+	DirectoryMethodSymlink = "symlink"     // One symlink per file; destination directories are real (default)
+	DirectoryMethodLinkDir = "symlink_dir" // A single symlink to the whole source directory
+	DirectoryMethodCopy    = "copy"        // Copies of every file
+)
+
+// Directory represents a directory tree mirrored from the config repository into a destination (stow-style)
+// Every deployed file is tracked in state, so files deleted from the source are pruned on the next apply
+type Directory struct {
+	Source      string   `yaml:"source" mapstructure:"source"`                               // Source directory, relative to the config file
+	Destination string   `yaml:"destination" mapstructure:"destination"`                     // Destination directory
+	Method      string   `yaml:"method,omitempty" mapstructure:"method,omitempty"`           // "symlink" (default), "symlink_dir" or "copy"
+	Include     []string `yaml:"include,omitempty" mapstructure:"include,omitempty"`         // Only deploy files matching these globs
+	Exclude     []string `yaml:"exclude,omitempty" mapstructure:"exclude,omitempty"`         // Skip files matching these globs (added to .configrignore)
+	Backup      bool     `yaml:"backup,omitempty" mapstructure:"backup,omitempty"`           // Backup existing files before replacement
+	Interactive bool     `yaml:"interactive,omitempty" mapstructure:"interactive,omitempty"` // Prompt for conflicts
+	ConfigDir   string   `yaml:"-" mapstructure:"-"`                                         // Directory of the config file that defined this directory (for relative path resolution)
+}
+
+// DeployMethod returns the deployment method, defaulting to per-file symlinks
+func (d Directory) DeployMethod() string {
+	if d.Method == "" {
+		return DirectoryMethodSymlink
+	}
+	return d.Method
+}
+
+// LineInFile ensures a line exists in a file that configr doesn't own (e.g., /etc/hosts)
+// With Regexp, the last matching line is replaced; otherwise, or without a match, the line is appended
+type LineInFile struct {
+	Path   string `yaml:"path" mapstructure:"path"`                         // Target file (supports ~)
+	Line   string `yaml:"line" mapstructure:"line"`                         // Line that must exist
+	Regexp string `yaml:"regexp,omitempty" mapstructure:"regexp,omitempty"` // Replace the last line matching this expression
+	Create bool   `yaml:"create,omitempty" mapstructure:"create,omitempty"` // Create the target file if it doesn't exist
+	Backup bool   `yaml:"backup,omitempty" mapstructure:"backup,omitempty"` // Backup the target file before changing it
+}
+
+// Block manages a marker-delimited block of lines in a file that configr doesn't own (e.g., ~/.bashrc)
+// The block is removed again when it leaves the configuration
+type Block struct {
+	Path    string `yaml:"path" mapstructure:"path"`                         // Target file (supports ~)
+	Content string `yaml:"content" mapstructure:"content"`                   // Lines between the markers
+	Marker  string `yaml:"marker,omitempty" mapstructure:"marker,omitempty"` // Marker line; {mark} becomes BEGIN or END
+	Create  bool   `yaml:"create,omitempty" mapstructure:"create,omitempty"` // Create the target file if it doesn't exist
+	Backup  bool   `yaml:"backup,omitempty" mapstructure:"backup,omitempty"` // Backup the target file before changing it
+}
+
+// DefaultBlockMarker is the marker of blocks without one; {name} is the name of the block
+const DefaultBlockMarker = "# {mark} CONFIGR MANAGED BLOCK: {name}"
+
+// Markers returns the begin and end marker lines of a block
+func (b Block) Markers(name string) (string, string) {
+	marker := b.Marker
+	if marker == "" {
+		marker = strings.ReplaceAll(DefaultBlockMarker, "{name}", name)
+	}
+	return strings.ReplaceAll(marker, "{mark}", "BEGIN"), strings.ReplaceAll(marker, "{mark}", "END")
+}
+
+// Settings file formats
+const (
+	SettingsFormatJSON = "json"
+	SettingsFormatYAML = "yaml"
+	SettingsFormatTOML = "toml"
+	SettingsFormatINI  = "ini"
+)
+
+// SettingsFile manages individual keys of a settings file that an application also writes (e.g., VS Code's settings.json)
+// All other keys are left untouched; previous values are restored when a key leaves the configuration
+type SettingsFile struct {
+	Path     string                 `yaml:"path" mapstructure:"path"`                         // Target file (supports ~)
+	Format   string                 `yaml:"format,omitempty" mapstructure:"format,omitempty"` // json, yaml, toml or ini (default: from the file extension)
+	Settings map[string]interface{} `yaml:"settings" mapstructure:"settings"`                 // Dotted path or JSON pointer -> value
+	Create   bool                   `yaml:"create,omitempty" mapstructure:"create,omitempty"` // Create the target file if it doesn't exist
+	Backup   bool                   `yaml:"backup,omitempty" mapstructure:"backup,omitempty"` // Backup the target file before changing it
+}
+
+// FileFormat returns the configured format, or the format matching the file extension ("" if unknown)
+func (s SettingsFile) FileFormat() string {
+	if s.Format != "" {
+		return strings.ToLower(s.Format)
+	}
+	switch strings.ToLower(filepath.Ext(s.Path)) {
+	case ".json":
+		return SettingsFormatJSON
+	case ".yaml", ".yml":
+		return SettingsFormatYAML
+	case ".toml":
+		return SettingsFormatTOML
+	case ".ini":
+		return SettingsFormatINI
+	}
+	return ""
+}
+
+// ParseSettingsKey splits a settings key into its path segments
+// Keys starting with "/" are JSON pointers (RFC 6901), so keys containing dots can be addressed
+// ("/editor.fontSize"); other keys are dotted paths ("editor.font.size")
+func ParseSettingsKey(key string) ([]string, error) {
+	var segments []string
+	if strings.HasPrefix(key, "/") {
+		for _, segment := range strings.Split(key[1:], "/") {
+			segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+			segments = append(segments, segment)
+		}
+	} else {
+		segments = strings.Split(key, ".")
+	}
+
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("empty path segment in key %q", key)
+		}
+	}
+	return segments, nil
 }
 
 // Binary represents a binary to be downloaded and installed from a remote source
@@ -81,6 +292,7 @@ type BackupPolicy struct {
 	MaxCount         int    `yaml:"max_count,omitempty" mapstructure:"max_count,omitempty"`                 // Maximum number of backups per file
 	CleanupOrphaned  bool   `yaml:"cleanup_orphaned,omitempty" mapstructure:"cleanup_orphaned,omitempty"`   // Remove orphaned backups
 	PreserveRecent   int    `yaml:"preserve_recent,omitempty" mapstructure:"preserve_recent,omitempty"`     // Always preserve N most recent backups
+	Compress         bool   `yaml:"compress,omitempty" mapstructure:"compress,omitempty"`                   // Store new backups zstd-compressed
 }
 
 // DConfConfig manages dconf settings
@@ -92,6 +304,7 @@ type DConfConfig struct {
 type RepositoryManagement struct {
 	Apt     []AptRepository     `yaml:"apt,omitempty" mapstructure:"apt,omitempty"`
 	Flatpak []FlatpakRepository `yaml:"flatpak,omitempty" mapstructure:"flatpak,omitempty"`
+	Dnf     []DnfRepository     `yaml:"dnf,omitempty" mapstructure:"dnf,omitempty"`
 }
 
 // AptRepository represents an APT repository configuration using DEB822 format
@@ -120,4 +333,17 @@ type FlatpakRepository struct {
 	Name string `yaml:"-" mapstructure:"-"`                           // Remote name (from YAML key)
 	URL  string `yaml:"url" mapstructure:"url"`                       // Repository URL (required)
 	User bool   `yaml:"user,omitempty" mapstructure:"user,omitempty"` // Install for user only (default: system-wide)
+}
+
+// DnfRepository represents a DNF repository configuration
+// Creates <name>.repo files in /etc/yum.repos.d/ (Fedora and RHEL-based distributions)
+type DnfRepository struct {
+	Name        string `yaml:"-" mapstructure:"-"`                                         // Repository ID (from YAML key)
+	Description string `yaml:"description,omitempty" mapstructure:"description,omitempty"` // Human-readable name (defaults to the ID)
+	BaseURL     string `yaml:"baseurl,omitempty" mapstructure:"baseurl,omitempty"`         // Repository base URL ($releasever/$basearch are expanded by dnf)
+	Metalink    string `yaml:"metalink,omitempty" mapstructure:"metalink,omitempty"`       // Metalink URL (alternative to baseurl)
+	MirrorList  string `yaml:"mirrorlist,omitempty" mapstructure:"mirrorlist,omitempty"`   // Mirror list URL (alternative to baseurl)
+	GPGKey      string `yaml:"gpgkey,omitempty" mapstructure:"gpgkey,omitempty"`           // GPG key URL, imported with rpm --import
+	GPGCheck    *bool  `yaml:"gpgcheck,omitempty" mapstructure:"gpgcheck,omitempty"`       // Verify package signatures (default: true)
+	Enabled     *bool  `yaml:"enabled,omitempty" mapstructure:"enabled,omitempty"`         // Enable the repository (default: true)
 }
\ No newline at end of file
@@ -3,8 +3,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -72,6 +74,9 @@ func (vr *ValidationResult) HasErrors() bool {
 func Validate(config *Config, configPath string) *ValidationResult {
 	result := &ValidationResult{Valid: true}
 	
+	// Managers from custom_managers must be known before packages.<name> lists are validated
+	RegisterCustomManagers(config)
+	
 	// Parse with position information for better error reporting
 	configWithPos, err := ParseConfigWithPosition(configPath)
 	if err != nil {
@@ -81,8 +86,15 @@ func Validate(config *Config, configPath string) *ValidationResult {
 		validateRepositories(config, result, nil, configPath)
 		validatePackages(config, result, nil, configPath)
 		validateFiles(config, configPath, result, nil, configPath)
+		validateDirectories(config, configPath, result)
+		validateLines(config, result)
+		validateBlocks(config, result)
+	validateSettingsFiles(config, result)
+		validateSettingsFiles(config, result)
 		validateBinaries(config, result, nil, configPath)
 		validateDConf(config, result, nil, configPath)
+		validateDebconfSelections(config, result, nil, configPath)
+		validateDistroSupport(config, result)
 		return result
 	}
 	
@@ -92,8 +104,13 @@ func Validate(config *Config, configPath string) *ValidationResult {
 	validateRepositories(config, result, configWithPos, configPath)
 	validatePackages(config, result, configWithPos, configPath)
 	validateFiles(config, configPath, result, configWithPos, configPath)
+	validateDirectories(config, configPath, result)
+	validateLines(config, result)
+	validateBlocks(config, result)
 	validateBinaries(config, result, configWithPos, configPath)
 	validateDConf(config, result, configWithPos, configPath)
+	validateDebconfSelections(config, result, configWithPos, configPath)
+	validateDistroSupport(config, result)
 	
 	return result
 }
@@ -188,7 +205,7 @@ func validateIncludes(config *Config, result *ValidationResult, configPos *Confi
 					Field:   conditionField,
 					Message: err.Error(),
 					Help:    "fix the condition specification",
-					Note:    "valid condition types: os, hostname, env, file_exists, dir_exists",
+					Note:    "valid condition types: os, distro, distro_version, hostname, env, file_exists, dir_exists",
 				})
 			}
 		}
@@ -262,6 +279,9 @@ func validateRepositories(config *Config, result *ValidationResult, configPos *C
 	
 	// Validate Flatpak repositories
 	validateFlatpakRepositories(config.Repositories.Flatpak, result, configPos, configPath)
+	
+	// Validate DNF repositories
+	validateDnfRepositories(config.Repositories.Dnf, result, configPos, configPath)
 }
 
 // validateAptRepositories validates APT repository configurations using DEB822 format
@@ -593,116 +613,758 @@ func validateFlatpakRepositories(repos []FlatpakRepository, result *ValidationRe
 	}
 }
 
+// validateDnfRepositories validates DNF repository configurations
+func validateDnfRepositories(repos []DnfRepository, result *ValidationResult, configPos *ConfigWithPosition, configPath string) {
+	for _, repo := range repos {
+		fieldPrefix := fmt.Sprintf("repositories.dnf.%s", repo.Name)
+		
+		// Repository IDs become file names under /etc/yum.repos.d/
+		if !isValidDnfRepositoryID(repo.Name) {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "invalid repository id",
+				Field:   fieldPrefix,
+				Value:   repo.Name,
+				Message: "DNF repository id contains invalid characters",
+				Help:    "use only letters, numbers, dots, hyphens, underscores, and colons",
+			})
+		}
+		
+		// One source of packages is required
+		if repo.BaseURL == "" && repo.Metalink == "" && repo.MirrorList == "" {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "missing repository URL",
+				Field:   fieldPrefix + ".baseurl",
+				Message: "DNF repository requires baseurl, metalink, or mirrorlist",
+				Help:    "specify the repository location",
+				Note:    "example: 'https://packages.microsoft.com/yumrepos/vscode'",
+			})
+		}
+		
+		urls := []struct {
+			key   string
+			value string
+		}{
+			{"baseurl", repo.BaseURL},
+			{"metalink", repo.Metalink},
+			{"mirrorlist", repo.MirrorList},
+		}
+		for _, url := range urls {
+			if url.value != "" && !strings.HasPrefix(url.value, "https://") && !strings.HasPrefix(url.value, "http://") && !strings.HasPrefix(url.value, "file://") {
+				result.Add(ValidationError{
+					Type:    "error",
+					Title:   "invalid repository URL",
+					Field:   fieldPrefix + "." + url.key,
+					Value:   url.value,
+					Message: fmt.Sprintf("DNF repository %s must be an http(s) or file:// URL", url.key),
+					Help:    "use an HTTPS URL for security",
+				})
+			}
+		}
+		
+		// GPG keys are imported into the RPM database, so they must come from a trusted location
+		if repo.GPGKey != "" && !strings.HasPrefix(repo.GPGKey, "https://") && !strings.HasPrefix(repo.GPGKey, "file://") {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "insecure GPG key URL",
+				Field:   fieldPrefix + ".gpgkey",
+				Value:   repo.GPGKey,
+				Message: "GPG key must be an HTTPS or file:// URL",
+				Help:    "use HTTPS for key downloads to prevent tampering",
+			})
+		}
+		
+		gpgCheck := repo.GPGCheck == nil || *repo.GPGCheck
+		if !gpgCheck {
+			result.Add(ValidationError{
+				Type:    "warning",
+				Title:   "signature checking disabled",
+				Field:   fieldPrefix + ".gpgcheck",
+				Message: "packages from this repository will not be verified",
+				Help:    "enable gpgcheck and provide a gpgkey when the repository signs its packages",
+			})
+		} else if repo.GPGKey == "" {
+			result.Add(ValidationError{
+				Type:    "warning",
+				Title:   "missing GPG key",
+				Field:   fieldPrefix + ".gpgkey",
+				Message: "gpgcheck is enabled but no gpgkey is configured",
+				Help:    "add the repository's signing key URL unless it is already imported",
+			})
+		}
+	}
+}
+
 // validatePackages checks package manager configurations
 func validatePackages(config *Config, result *ValidationResult, configPos *ConfigWithPosition, configPath string) {
 	// Check for duplicate packages across managers
 	allPackages := make(map[string]string) // package -> manager
 	
-	// Validate apt packages
-	validatePackageEntries(config.Packages.Apt, "apt", allPackages, result, configPos, configPath)
+	// Validate managers defined with command templates
+	validateCustomManagers(config.CustomManagers, result)
 	
-	// Validate flatpak packages
-	validatePackageEntries(config.Packages.Flatpak, "flatpak", allPackages, result, configPos, configPath)
+	// Validate the packages of every package manager
+	for _, manager := range config.Packages.ManagerNames() {
+		if _, registered := LookupPackageManager(manager); !registered {
+			result.Add(ValidationError{
+				Type:       "error",
+				Title:      "unsupported package manager",
+				Field:      fmt.Sprintf("packages.%s", manager),
+				Value:      manager,
+				Message:    fmt.Sprintf("'%s' is not a supported package manager", manager),
+				Help:       fmt.Sprintf("use one of: %v", RegisteredPackageManagers()),
+				Suggestion: "remove this section or check for typos",
+			})
+			continue
+		}
+		validatePackageEntries(config.Packages.Get(manager), manager, allPackages, result, configPos, configPath)
+	}
 	
-	// Validate snap packages
-	validatePackageEntries(config.Packages.Snap, "snap", allPackages, result, configPos, configPath)
+	// Validate logical packages with cross-manager candidates
+	validateLogicalPackages(config.Packages.Any, result)
 	
 	// Validate package_defaults if present
 	if config.PackageDefaults != nil {
 		validatePackageDefaults(config.PackageDefaults, result, configPos, configPath)
 	}
+	
+	// Validate package_settings if present
+	validatePackageSettings(config.PackageSettings, result)
+}
+
+// validateLogicalPackages checks the candidates of each logical package in packages.any
+func validateLogicalPackages(logical map[string][]string, result *ValidationResult) {
+	for name, candidates := range logical {
+		field := fmt.Sprintf("packages.any[\"%s\"]", name)
+		
+		if len(candidates) == 0 {
+			result.Add(ValidationError{
+				Type:       "error",
+				Title:      "logical package without candidates",
+				Field:      field,
+				Value:      name,
+				Message:    fmt.Sprintf("logical package '%s' has no candidates", name),
+				Help:       "list one or more candidates in '<manager>:<package>' form, in order of preference",
+				Suggestion: fmt.Sprintf("[\"apt:%s\", \"snap:%s\"]", name, name),
+			})
+			continue
+		}
+		
+		for i, spec := range candidates {
+			candidate, err := ParsePackageCandidate(spec)
+			if err != nil {
+				result.Add(ValidationError{
+					Type:    "error",
+					Title:   "invalid package candidate",
+					Field:   fmt.Sprintf("%s[%d]", field, i),
+					Value:   spec,
+					Message: err.Error(),
+					Help:    fmt.Sprintf("candidates use the form '<manager>:<package> [flags...]' with one of: %v", RegisteredPackageManagers()),
+				})
+				continue
+			}
+			
+			if !isValidPackageNameForManager(candidate.Name, candidate.Manager) {
+				result.Add(ValidationError{
+					Type:    "error",
+					Title:   "invalid package name",
+					Field:   fmt.Sprintf("%s[%d]", field, i),
+					Value:   candidate.Name,
+					Message: getPackageNameValidationMessage(candidate.Manager),
+					Help:    getPackageNameValidationHelp(candidate.Manager),
+				})
+			}
+			
+			validateFlagSafety(candidate.Flags, candidate.Name, candidate.Manager, result)
+		}
+	}
+}
+
+// validateFiles checks file configurations
+func validateFiles(config *Config, configPath string, result *ValidationResult, configPos *ConfigWithPosition, configFile string) {
+	configDir := filepath.Dir(configPath)
+	
+	for name, file := range config.Files {
+		fieldPrefix := fmt.Sprintf("files.%s", name)
+		
+		// Validate required fields
+		if file.Source == "" && !file.IsInline() {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "missing source path",
+				Field:   fieldPrefix + ".source",
+				Message: "source file path or content is required",
+				Help:    "specify the path to your source file, or the file content with 'content:'",
+				Note:    "source paths are relative to your config file",
+			})
+			continue
+		}
+		
+		if file.Source != "" && file.IsInline() {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "conflicting file source",
+				Field:   fieldPrefix,
+				Message: "source and content cannot both be set",
+				Help:    "remove either 'source' or 'content'",
+				Note:    "content is deployed as the file's content; source points to a file or https:// URL",
+			})
+			continue
+		}
+		
+		if file.Destination == "" {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "missing destination path",
+				Field:   fieldPrefix + ".destination",
+				Message: "destination path is required", 
+				Help:    "specify where the file should be placed",
+				Note:    "use ~ for home directory (e.g., ~/.vimrc)",
+			})
+			continue
+		}
+		
+		validateFileSource(file, fieldPrefix, configDir, result)
+
+		// Validate file mode if provided
+		if file.Mode != "" {
+			if !isValidFileMode(file.Mode) {
+				result.Add(ValidationError{
+					Type:       "error",
+					Title:      "invalid file mode",
+					Field:      fieldPrefix + ".mode",
+					Value:      file.Mode,
+					Message:    "file mode must be valid octal (e.g., '644', '755')",
+					Help:       "use '644' for regular files, '755' for executables",
+					Suggestion: "mode: \"644\"",
+				})
+			} else if isOverlyPermissive(file.Mode) {
+				result.Add(ValidationError{
+					Type:    "warning",
+					Title:   "overly permissive mode",
+					Field:   fieldPrefix + ".mode",
+					Value:   file.Mode,
+					Message: "file mode allows write access for others",
+					Help:    "consider using '644' for better security",
+					Note:    "mode '777' or '666' can be security risks",
+				})
+			}
+		}
+		
+		validateFileAttributes(file, fieldPrefix, result)
+
+		// Validate destination path
+		if strings.Contains(file.Destination, "..") {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "unsafe destination path",
+				Field:   fieldPrefix + ".destination",
+				Value:   file.Destination,
+				Message: "destination path contains '..' which is not allowed",
+				Help:    "use absolute paths or paths relative to home (~)",
+				Note:    "this prevents accidental file overwrites outside intended directories",
+			})
+		}
+	}
+}
+
+// validateFileAttributes checks ACL entries, extended attribute names and attribute flags
+func validateFileAttributes(file File, fieldPrefix string, result *ValidationResult) {
+	for i, entry := range file.ACL {
+		if _, err := NormalizeACLEntry(entry); err != nil {
+			result.Add(ValidationError{
+				Type:       "error",
+				Title:      "invalid ACL entry",
+				Field:      fmt.Sprintf("%s.acl[%d]", fieldPrefix, i),
+				Value:      entry,
+				Message:    err.Error(),
+				Help:       "use setfacl syntax: user:<name>:<perms>, group:<name>:<perms>, mask::<perms> or other::<perms>",
+				Suggestion: "acl: [\"group:developers:rw-\"]",
+			})
+		}
+	}
+
+	for name := range file.Xattrs {
+		if !IsValidXattrName(name) {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "invalid extended attribute name",
+				Field:   fieldPrefix + ".xattrs",
+				Value:   name,
+				Message: "extended attribute names need a namespace",
+				Help:    fmt.Sprintf("prefix the name with 'user.', e.g. user.%s", name),
+				Note:    "namespaces are user., trusted., security. and system.; only user. can be set without root",
+			})
+		}
+	}
+
+	if file.HasExtendedAttributes() && !file.DeploysAsCopy() {
+		result.Add(ValidationError{
+			Type:    "error",
+			Title:   "extended attributes need a copy",
+			Field:   fieldPrefix,
+			Message: "acl, xattrs, immutable and append_only can't be set on a symlink",
+			Help:    "add 'copy: true' to deploy the file as a copy",
+			Note:    "a symlink's attributes are those of its source in the config repository",
+		})
+	}
+}
+
+// validateFileSource checks where a file's content comes from: inline content, an https:// URL or a local file
+func validateFileSource(file File, fieldPrefix, configDir string, result *ValidationResult) {
+	if file.SHA256 != "" && !file.IsRemote() {
+		result.Add(ValidationError{
+			Type:    "warning",
+			Title:   "unused checksum",
+			Field:   fieldPrefix + ".sha256",
+			Value:   file.SHA256,
+			Message: "sha256 is only used for sources downloaded from a URL",
+			Help:    "remove the sha256 option or use an https:// URL as the source",
+		})
+	}
+	
+	switch {
+	case file.IsInline():
+		if file.Template {
+			validateFileTemplate(file.Content, fieldPrefix+".content", "", result)
+		}
+		
+	case file.IsRemote():
+		if !strings.HasPrefix(file.Source, "https://") {
+			result.Add(ValidationError{
+				Type:       "error",
+				Title:      "insecure source URL",
+				Field:      fieldPrefix + ".source",
+				Value:      file.Source,
+				Message:    "source URL must use HTTPS for security",
+				Help:       "change http:// to https://",
+				Suggestion: fmt.Sprintf("source: \"%s\"", strings.Replace(file.Source, "http://", "https://", 1)),
+			})
+		} else if !isValidURL(file.Source) {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "invalid URL format",
+				Field:   fieldPrefix + ".source",
+				Value:   file.Source,
+				Message: "source URL format is invalid",
+				Help:    "ensure the URL is properly formatted",
+			})
+		}
+		
+		if file.SHA256 != "" && !regexp.MustCompile(`^[a-fA-F0-9]{64}$`).MatchString(file.SHA256) {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "invalid sha256 checksum",
+				Field:   fieldPrefix + ".sha256",
+				Value:   file.SHA256,
+				Message: "sha256 must be 64 hexadecimal characters",
+				Help:    "generate the checksum with 'sha256sum <file>'",
+			})
+		}
+		
+	default:
+		if file.IsEncrypted() && file.Mode != "" && strings.TrimLeft(file.Mode, "0") != "600" {
+			result.Add(ValidationError{
+				Type:    "warning",
+				Title:   "secret file mode ignored",
+				Field:   fieldPrefix + ".mode",
+				Value:   file.Mode,
+				Message: "decrypted files are always deployed with mode 600",
+				Help:    "remove the mode, or set it to \"600\"",
+			})
+		}
+		if file.IsEncrypted() && file.Backup {
+			result.Add(ValidationError{
+				Type:    "warning",
+				Title:   "secret file backup ignored",
+				Field:   fieldPrefix + ".backup",
+				Message: "decrypted files are never backed up, so plaintext is only written to the destination",
+				Help:    "remove 'backup: true'; the encrypted source in the repository is the backup",
+			})
+		}
+		
+		// Check if source file exists
+		sourcePath := file.Source
+		if !filepath.IsAbs(sourcePath) {
+			sourcePath = filepath.Join(configDir, file.Source)
+		}
+		
+		content, err := os.ReadFile(sourcePath)
+		if os.IsNotExist(err) {
+			// Try to suggest alternatives
+			suggestion := suggestAlternativeFile(sourcePath)
+			
+			result.Add(ValidationError{
+				Type:       "error",
+				Title:      "source file not found",
+				Field:      fieldPrefix + ".source",
+				Value:      file.Source,
+				Message:    "source file does not exist",
+				Help:       "create the file or check the path",
+				Note:       fmt.Sprintf("looked for: %s", sourcePath),
+				Suggestion: suggestion,
+			})
+			return
+		}
+		
+		// Encrypted templates can only be checked once decrypted during apply
+		if file.Template && !file.IsEncrypted() {
+			if err != nil {
+				result.Add(ValidationError{
+					Type:    "error",
+					Title:   "unreadable template",
+					Field:   fieldPrefix + ".source",
+					Value:   file.Source,
+					Message: fmt.Sprintf("failed to read template: %v", err),
+					Help:    "check the permissions of the source file",
+				})
+				return
+			}
+			validateFileTemplate(string(content), fieldPrefix+".source", file.Source, result)
+		}
+	}
+}
+
+// validateFileTemplate checks that the content of a templated file parses
+// Undefined variables can only be detected when the template is rendered during apply
+func validateFileTemplate(content, field, value string, result *ValidationResult) {
+	if _, err := ParseFileTemplate(field, content); err != nil {
+		result.Add(ValidationError{
+			Type:    "error",
+			Title:   "invalid template",
+			Field:   field,
+			Value:   value,
+			Message: err.Error(),
+			Help:    "fix the template syntax, or remove 'template: true' to deploy the file as is",
+			Note:    "templates use Go text/template syntax, e.g. {{ .Vars.email }} or {{ .Host.Hostname }}",
+		})
+	}
+}
+
+// validateDirectories checks directory tree configurations
+func validateDirectories(config *Config, configPath string, result *ValidationResult) {
+	for name, directory := range config.Directories {
+		fieldPrefix := fmt.Sprintf("directories.%s", name)
+		
+		if directory.Source == "" {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "missing source path",
+				Field:   fieldPrefix + ".source",
+				Message: "source directory path is required",
+				Help:    "specify the directory to mirror",
+				Note:    "source paths are relative to your config file",
+			})
+			continue
+		}
+		
+		if directory.Destination == "" {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "missing destination path",
+				Field:   fieldPrefix + ".destination",
+				Message: "destination path is required",
+				Help:    "specify where the directory should be placed",
+				Note:    "use ~ for home directory (e.g., ~/.config/nvim)",
+			})
+			continue
+		}
+		
+		configDir := directory.ConfigDir
+		if configDir == "" {
+			configDir = filepath.Dir(configPath)
+		}
+		sourcePath := directory.Source
+		if !filepath.IsAbs(sourcePath) {
+			sourcePath = filepath.Join(configDir, directory.Source)
+		}
+		
+		if info, err := os.Stat(sourcePath); err != nil {
+			result.Add(ValidationError{
+				Type:       "error",
+				Title:      "source directory not found",
+				Field:      fieldPrefix + ".source",
+				Value:      directory.Source,
+				Message:    "source directory does not exist",
+				Help:       "create the directory or check the path",
+				Note:       fmt.Sprintf("looked for: %s", sourcePath),
+				Suggestion: suggestAlternativeFile(sourcePath),
+			})
+		} else if !info.IsDir() {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "source is not a directory",
+				Field:   fieldPrefix + ".source",
+				Value:   directory.Source,
+				Message: "source must be a directory",
+				Help:    "use the files section for single files",
+			})
+		}
+		
+		if strings.Contains(directory.Destination, "..") {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "unsafe destination path",
+				Field:   fieldPrefix + ".destination",
+				Value:   directory.Destination,
+				Message: "destination path contains '..' which is not allowed",
+				Help:    "use absolute paths or paths relative to home (~)",
+			})
+		}
+		
+		switch directory.DeployMethod() {
+		case DirectoryMethodSymlink, DirectoryMethodCopy:
+		case DirectoryMethodLinkDir:
+			if len(directory.Include) > 0 || len(directory.Exclude) > 0 {
+				result.Add(ValidationError{
+					Type:    "warning",
+					Title:   "unused filters",
+					Field:   fieldPrefix,
+					Message: "include and exclude are ignored when the whole directory is linked",
+					Help:    "use method 'symlink' or 'copy' to filter files",
+				})
+			}
+		default:
+			result.Add(ValidationError{
+				Type:       "error",
+				Title:      "invalid directory method",
+				Field:      fieldPrefix + ".method",
+				Value:      directory.Method,
+				Message:    fmt.Sprintf("unknown method '%s'", directory.Method),
+				Help:       "use 'symlink' (one symlink per file), 'symlink_dir' (one symlink for the directory) or 'copy'",
+				Suggestion: "method: symlink",
+			})
+		}
+		
+		for field, patterns := range map[string][]string{"include": directory.Include, "exclude": directory.Exclude} {
+			for _, pattern := range patterns {
+				if _, err := path.Match(pattern, ""); err != nil {
+					result.Add(ValidationError{
+						Type:    "error",
+						Title:   "invalid glob pattern",
+						Field:   fmt.Sprintf("%s.%s", fieldPrefix, field),
+						Value:   pattern,
+						Message: fmt.Sprintf("invalid glob pattern: %v", err),
+						Help:    "use shell-style globs such as '*.lua' or 'plugin/*'",
+					})
+				}
+			}
+		}
+	}
+}
+
+// validateLines checks line-in-file configurations
+func validateLines(config *Config, result *ValidationResult) {
+	for name, line := range config.Lines {
+		fieldPrefix := fmt.Sprintf("lines.%s", name)
+		
+		if line.Path == "" {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "missing target path",
+				Field:   fieldPrefix + ".path",
+				Message: "path of the file to edit is required",
+				Help:    "specify the file the line must exist in (e.g., /etc/hosts)",
+			})
+		}
+		
+		if line.Line == "" || strings.Contains(line.Line, "\n") {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "invalid line",
+				Field:   fieldPrefix + ".line",
+				Value:   line.Line,
+				Message: "line must be a single non-empty line",
+				Help:    "use the blocks section to manage several lines",
+			})
+		}
+		
+		if line.Regexp != "" {
+			if _, err := regexp.Compile(line.Regexp); err != nil {
+				result.Add(ValidationError{
+					Type:    "error",
+					Title:   "invalid regular expression",
+					Field:   fieldPrefix + ".regexp",
+					Value:   line.Regexp,
+					Message: err.Error(),
+					Help:    "use Go regular expression syntax (e.g., '^127\\.0\\.1\\.1\\s')",
+				})
+			}
+		}
+	}
+}
+
+// validateBlocks checks managed block configurations
+func validateBlocks(config *Config, result *ValidationResult) {
+	markers := make(map[string]string) // path + begin marker -> block name
+	
+	for name, block := range config.Blocks {
+		fieldPrefix := fmt.Sprintf("blocks.%s", name)
+		
+		if block.Path == "" {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "missing target path",
+				Field:   fieldPrefix + ".path",
+				Message: "path of the file to edit is required",
+				Help:    "specify the file the block is managed in (e.g., ~/.bashrc)",
+			})
+			continue
+		}
+		
+		if block.Marker != "" && !strings.Contains(block.Marker, "{mark}") {
+			result.Add(ValidationError{
+				Type:       "error",
+				Title:      "invalid block marker",
+				Field:      fieldPrefix + ".marker",
+				Value:      block.Marker,
+				Message:    "marker must contain {mark}, which becomes BEGIN and END",
+				Help:       "use the comment syntax of the target file",
+				Suggestion: fmt.Sprintf("marker: \"# {mark} %s\"", name),
+			})
+			continue
+		}
+		
+		begin, end := block.Markers(name)
+		for _, line := range strings.Split(block.Content, "\n") {
+			if line == begin || line == end {
+				result.Add(ValidationError{
+					Type:    "error",
+					Title:   "marker in block content",
+					Field:   fieldPrefix + ".content",
+					Value:   line,
+					Message: "block content must not contain its own markers",
+					Help:    "remove the marker lines from the content",
+				})
+				break
+			}
+		}
+		
+		key := block.Path + "\x00" + begin
+		if other, exists := markers[key]; exists {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "duplicate block marker",
+				Field:   fieldPrefix + ".marker",
+				Value:   begin,
+				Message: fmt.Sprintf("blocks '%s' and '%s' use the same marker in %s", other, name, block.Path),
+				Help:    "give each block in a file a unique marker",
+			})
+		}
+		markers[key] = name
+	}
 }
 
-// validateFiles checks file configurations
-func validateFiles(config *Config, configPath string, result *ValidationResult, configPos *ConfigWithPosition, configFile string) {
-	configDir := filepath.Dir(configPath)
-	
-	for name, file := range config.Files {
-		fieldPrefix := fmt.Sprintf("files.%s", name)
+// validateSettingsFiles checks settings file configurations
+func validateSettingsFiles(config *Config, result *ValidationResult) {
+	for name, settingsFile := range config.SettingsFiles {
+		fieldPrefix := fmt.Sprintf("settings_files.%s", name)
 		
-		// Validate required fields
-		if file.Source == "" {
+		if settingsFile.Path == "" {
 			result.Add(ValidationError{
 				Type:    "error",
-				Title:   "missing source path",
-				Field:   fieldPrefix + ".source",
-				Message: "source file path is required",
-				Help:    "specify the path to your source file",
-				Note:    "source paths are relative to your config file",
+				Title:   "missing target path",
+				Field:   fieldPrefix + ".path",
+				Message: "path of the settings file is required",
+				Help:    "specify the file whose keys configr manages (e.g., ~/.config/Code/User/settings.json)",
 			})
 			continue
 		}
 		
-		if file.Destination == "" {
+		format := settingsFile.FileFormat()
+		switch format {
+		case SettingsFormatJSON, SettingsFormatYAML, SettingsFormatTOML, SettingsFormatINI:
+		default:
 			result.Add(ValidationError{
 				Type:    "error",
-				Title:   "missing destination path",
-				Field:   fieldPrefix + ".destination",
-				Message: "destination path is required", 
-				Help:    "specify where the file should be placed",
-				Note:    "use ~ for home directory (e.g., ~/.vimrc)",
+				Title:   "unsupported settings format",
+				Field:   fieldPrefix + ".format",
+				Value:   settingsFile.Format,
+				Message: fmt.Sprintf("cannot determine the format of %s", settingsFile.Path),
+				Help:    "set format to json, yaml, toml or ini",
 			})
 			continue
 		}
 		
-		// Check if source file exists
-		sourcePath := file.Source
-		if !filepath.IsAbs(sourcePath) {
-			sourcePath = filepath.Join(configDir, file.Source)
-		}
-		
-		if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-			// Try to suggest alternatives
-			suggestion := suggestAlternativeFile(sourcePath)
-			
+		if len(settingsFile.Settings) == 0 {
 			result.Add(ValidationError{
-				Type:       "error",
-				Title:      "source file not found",
-				Field:      fieldPrefix + ".source",
-				Value:      file.Source,
-				Message:    "source file does not exist",
-				Help:       "create the file or check the path",
-				Note:       fmt.Sprintf("looked for: %s", sourcePath),
-				Suggestion: suggestion,
+				Type:    "warning",
+				Title:   "no settings",
+				Field:   fieldPrefix + ".settings",
+				Message: "settings file has no keys to manage",
+				Help:    "add keys under settings or remove the entry",
 			})
+			continue
 		}
 		
-		// Validate file mode if provided
-		if file.Mode != "" {
-			if !isValidFileMode(file.Mode) {
+		paths := make(map[string][]string)
+		for key, value := range settingsFile.Settings {
+			field := fmt.Sprintf("%s.settings.%s", fieldPrefix, key)
+			
+			segments, err := ParseSettingsKey(key)
+			if err != nil {
 				result.Add(ValidationError{
-					Type:       "error",
-					Title:      "invalid file mode",
-					Field:      fieldPrefix + ".mode",
-					Value:      file.Mode,
-					Message:    "file mode must be valid octal (e.g., '644', '755')",
-					Help:       "use '644' for regular files, '755' for executables",
-					Suggestion: "mode: \"644\"",
+					Type:    "error",
+					Title:   "invalid settings key",
+					Field:   field,
+					Value:   key,
+					Message: err.Error(),
+					Help:    "use a dotted path (editor.fontSize) or a JSON pointer (/editor.fontSize)",
 				})
-			} else if isOverlyPermissive(file.Mode) {
+				continue
+			}
+			paths[key] = segments
+			
+			if format == SettingsFormatINI && len(segments) > 2 {
 				result.Add(ValidationError{
-					Type:    "warning",
-					Title:   "overly permissive mode",
-					Field:   fieldPrefix + ".mode",
-					Value:   file.Mode,
-					Message: "file mode allows write access for others",
-					Help:    "consider using '644' for better security",
-					Note:    "mode '777' or '666' can be security risks",
+					Type:    "error",
+					Title:   "invalid settings key",
+					Field:   field,
+					Value:   key,
+					Message: "INI keys are a key or section.key",
+					Help:    "use a JSON pointer for sections or keys containing dots (/section.name/key)",
 				})
 			}
+			
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				if format == SettingsFormatINI {
+					result.Add(ValidationError{
+						Type:    "error",
+						Title:   "invalid settings value",
+						Field:   field,
+						Message: "INI values must be strings, numbers or booleans",
+						Help:    "set each key of the section separately",
+					})
+				}
+			case nil:
+				if format == SettingsFormatINI || format == SettingsFormatTOML {
+					result.Add(ValidationError{
+						Type:    "error",
+						Title:   "invalid settings value",
+						Field:   field,
+						Message: fmt.Sprintf("%s files have no null values", strings.ToUpper(format)),
+						Help:    "set a value for the key",
+					})
+				}
+			}
 		}
 		
-		// Validate destination path
-		if strings.Contains(file.Destination, "..") {
-			result.Add(ValidationError{
-				Type:    "error",
-				Title:   "unsafe destination path",
-				Field:   fieldPrefix + ".destination",
-				Value:   file.Destination,
-				Message: "destination path contains '..' which is not allowed",
-				Help:    "use absolute paths or paths relative to home (~)",
-				Note:    "this prevents accidental file overwrites outside intended directories",
-			})
+		// A key inside another managed key would be overwritten depending on the order keys are applied
+		for key, segments := range paths {
+			for other, otherSegments := range paths {
+				if key != other && len(otherSegments) > len(segments) && slices.Equal(otherSegments[:len(segments)], segments) {
+					result.Add(ValidationError{
+						Type:    "error",
+						Title:   "conflicting settings keys",
+						Field:   fmt.Sprintf("%s.settings.%s", fieldPrefix, other),
+						Value:   other,
+						Message: fmt.Sprintf("key '%s' is inside key '%s'", other, key),
+						Help:    "manage either the whole value or the keys inside it",
+					})
+				}
+			}
 		}
 	}
 }
@@ -848,6 +1510,23 @@ func validateDConf(config *Config, result *ValidationResult, configPos *ConfigWi
 	}
 }
 
+// validateDebconfSelections checks the top-level debconf_selections lines
+func validateDebconfSelections(config *Config, result *ValidationResult, configPos *ConfigWithPosition, configPath string) {
+	for i, line := range config.DebconfSelections {
+		if _, err := ParseDebconfSelection(line); err != nil {
+			result.Add(ValidationError{
+				Type:       "error",
+				Title:      "invalid debconf selection",
+				Field:      fmt.Sprintf("debconf_selections[%d]", i),
+				Value:      line,
+				Message:    err.Error(),
+				Help:       "use the debconf-set-selections format: '<package> <question> <type> <value>'",
+				Suggestion: "wireshark-common wireshark-common/install-setuid boolean true",
+			})
+		}
+	}
+}
+
 // Helper functions
 func isValidPackageName(name string) bool {
 	matched, _ := regexp.MatchString(`^[a-z0-9][a-z0-9\-\.\+]*$`, name)
@@ -860,75 +1539,35 @@ func sanitizePackageName(name string) string {
 
 // isValidPackageNameForManager validates package names based on the specific package manager
 func isValidPackageNameForManager(name, manager string) bool {
-	switch manager {
-	case "apt":
-		// Check if it's a remote .deb file (HTTPS URL)
-		if strings.HasPrefix(name, "https://") || strings.HasPrefix(name, "http://") {
-			return isValidRemoteDebURL(name)
-		}
-		// Check if it's a local .deb file
-		if strings.HasSuffix(name, ".deb") {
-			// If it ends with .deb, it must be a valid file path
-			return isValidDebFilePath(name)
-		}
-		// APT package names: lowercase, numbers, hyphens, dots, plus signs
-		matched, _ := regexp.MatchString(`^[a-z0-9][a-z0-9\-\.\+]*$`, name)
-		return matched
-	case "flatpak":
-		// Flatpak app IDs: reverse domain notation with dots, letters, numbers
-		matched, _ := regexp.MatchString(`^[a-zA-Z0-9][a-zA-Z0-9\-\._]*[a-zA-Z0-9]$`, name)
-		return matched
-	case "snap":
-		// Snap package names: lowercase, numbers, hyphens
-		matched, _ := regexp.MatchString(`^[a-z0-9][a-z0-9\-]*$`, name)
-		return matched
-	default:
-		// Fallback to original validation
-		return isValidPackageName(name)
+	if spec, registered := LookupPackageManager(manager); registered && spec.ValidateName != nil {
+		return spec.ValidateName(name)
 	}
+	// Fallback to original validation
+	return isValidPackageName(name)
 }
 
 // getPackageNameValidationMessage returns validation message for specific package manager
 func getPackageNameValidationMessage(manager string) string {
-	switch manager {
-	case "apt":
-		return "APT package name or .deb file path contains invalid characters"
-	case "flatpak":
-		return "Flatpak app ID contains invalid characters"
-	case "snap":
-		return "Snap package name contains invalid characters"
-	default:
-		return "package name contains invalid characters"
+	if spec, registered := LookupPackageManager(manager); registered && spec.NameMessage != "" {
+		return spec.NameMessage
 	}
+	return "package name contains invalid characters"
 }
 
 // getPackageNameValidationHelp returns validation help for specific package manager
 func getPackageNameValidationHelp(manager string) string {
-	switch manager {
-	case "apt":
-		return "use only lowercase letters, numbers, hyphens, dots, and plus signs"
-	case "flatpak":
-		return "use reverse domain notation like org.app.Name or com.company.App"
-	case "snap":
-		return "use only lowercase letters, numbers, and hyphens"
-	default:
-		return "use only lowercase letters, numbers, hyphens, and dots"
+	if spec, registered := LookupPackageManager(manager); registered && spec.NameHelp != "" {
+		return spec.NameHelp
 	}
+	return "use only lowercase letters, numbers, hyphens, and dots"
 }
 
 // sanitizePackageNameForManager sanitizes package names based on the specific package manager
 func sanitizePackageNameForManager(name, manager string) string {
-	switch manager {
-	case "apt":
-		return strings.ToLower(regexp.MustCompile(`[^a-z0-9\-\.\+]`).ReplaceAllString(name, "-"))
-	case "flatpak":
-		// For flatpak, preserve case and dots, replace invalid chars with dots
-		return regexp.MustCompile(`[^a-zA-Z0-9\-\._]`).ReplaceAllString(name, ".")
-	case "snap":
-		return strings.ToLower(regexp.MustCompile(`[^a-z0-9\-]`).ReplaceAllString(name, "-"))
-	default:
-		return sanitizePackageName(name)
+	if spec, registered := LookupPackageManager(manager); registered && spec.SanitizeName != nil {
+		return spec.SanitizeName(name)
 	}
+	return sanitizePackageName(name)
 }
 
 // validatePackageEntries validates a list of PackageEntry instances
@@ -975,7 +1614,268 @@ func validatePackageEntries(packages []PackageEntry, manager string, allPackages
 		
 		// Validate package flags
 		validatePackageFlags(pkg, manager, result)
+		
+		// Validate download checksum
+		validatePackageChecksum(pkg, manager, result)
+		
+		// Validate Flatpak-specific options
+		validateFlatpakPackageOptions(pkg, manager, result)
+		
+		// Validate debconf answers
+		validatePackageDebconf(pkg, manager, result)
+		
+		// Validate manager-specific options
+		validatePackageOptions(pkg, manager, result)
+	}
+}
+
+// validatePackageOptions checks manager-specific options against the registered package manager
+func validatePackageOptions(pkg PackageEntry, manager string, result *ValidationResult) {
+	spec, registered := LookupPackageManager(manager)
+	if !registered {
+		return
+	}
+	
+	field := fmt.Sprintf("packages.%s", manager)
+	
+	for key := range pkg.Options {
+		known := false
+		for _, option := range spec.Options {
+			if option == key {
+				known = true
+				break
+			}
+		}
+		if !known {
+			help := "remove the option or check for typos"
+			if len(spec.Options) > 0 {
+				help = fmt.Sprintf("%s packages support: %s", spec.DisplayName, strings.Join(spec.Options, ", "))
+			}
+			result.Add(ValidationError{
+				Type:    "warning",
+				Title:   "unknown package option",
+				Field:   fmt.Sprintf("%s[\"%s\"].%s", field, pkg.Name, key),
+				Value:   key,
+				Message: fmt.Sprintf("option '%s' is not used by %s packages", key, spec.DisplayName),
+				Help:    help,
+			})
+		}
+	}
+	
+	if spec.ValidateEntry != nil {
+		for _, err := range spec.ValidateEntry(pkg, field) {
+			result.Add(err)
+		}
+	}
+}
+
+// validatePackageDebconf validates the debconf answers on a package entry, which only apply to APT
+func validatePackageDebconf(pkg PackageEntry, manager string, result *ValidationResult) {
+	if len(pkg.Debconf) == 0 {
+		return
+	}
+	
+	field := fmt.Sprintf("packages.%s", manager)
+	
+	if manager != "apt" {
+		result.Add(ValidationError{
+			Type:    "warning",
+			Title:   "unused debconf answers",
+			Field:   field,
+			Value:   pkg.Name,
+			Message: "debconf answers are only used for APT packages",
+			Help:    "move the debconf answers to the APT entry or to debconf_selections",
+		})
+		return
+	}
+	
+	for question, answer := range pkg.Debconf {
+		if _, err := ParseDebconfAnswer(pkg.Name, question, answer); err != nil {
+			result.Add(ValidationError{
+				Type:       "error",
+				Title:      "invalid debconf answer",
+				Field:      fmt.Sprintf("%s[\"%s\"].debconf[\"%s\"]", field, pkg.Name, question),
+				Value:      answer,
+				Message:    err.Error(),
+				Help:       "answers use the form '<type> <value>' keyed by the full question name",
+				Suggestion: fmt.Sprintf("\"%s/question\": \"boolean true\"", pkg.Name),
+			})
+		}
+	}
+}
+
+// validatePackageChecksum validates the sha256 option, which only applies to remote .deb URLs
+func validatePackageChecksum(pkg PackageEntry, manager string, result *ValidationResult) {
+	if pkg.SHA256 == "" {
+		return
+	}
+	
+	field := fmt.Sprintf("packages.%s", manager)
+	
+	if manager != "apt" || !strings.HasPrefix(pkg.Name, "https://") {
+		result.Add(ValidationError{
+			Type:    "warning",
+			Title:   "unused checksum",
+			Field:   field,
+			Value:   pkg.Name,
+			Message: "sha256 is only used for remote .deb URLs",
+			Help:    "remove the sha256 option or use an https:// URL to a .deb file",
+		})
+		return
+	}
+	
+	if !regexp.MustCompile(`^[a-fA-F0-9]{64}$`).MatchString(pkg.SHA256) {
+		result.Add(ValidationError{
+			Type:    "error",
+			Title:   "invalid sha256 checksum",
+			Field:   field,
+			Value:   pkg.SHA256,
+			Message: fmt.Sprintf("sha256 for '%s' must be 64 hexadecimal characters", pkg.Name),
+			Help:    "generate the checksum with 'sha256sum package.deb'",
+		})
+	}
+}
+
+// validateFlatpakPackageOptions validates remote, scope, branch and overrides on a package entry
+func validateFlatpakPackageOptions(pkg PackageEntry, manager string, result *ValidationResult) {
+	if !pkg.HasFlatpakOptions() {
+		return
+	}
+	
+	field := fmt.Sprintf("packages.%s", manager)
+	
+	if manager != "flatpak" {
+		result.Add(ValidationError{
+			Type:    "warning",
+			Title:   "unsupported package options",
+			Field:   field,
+			Value:   pkg.Name,
+			Message: "remote, scope, branch and overrides only apply to Flatpak packages",
+			Help:    "remove these options or move the package to packages.flatpak",
+		})
+		return
+	}
+	
+	if pkg.Scope != "" && pkg.Scope != "user" && pkg.Scope != "system" {
+		result.Add(ValidationError{
+			Type:       "error",
+			Title:      "invalid Flatpak scope",
+			Field:      field,
+			Value:      pkg.Scope,
+			Message:    fmt.Sprintf("scope for '%s' must be 'user' or 'system'", pkg.Name),
+			Help:       "use 'scope: user' for per-user installs or 'scope: system' for system-wide installs",
+			Suggestion: "scope: system",
+		})
+	}
+	
+	if pkg.Scope != "" {
+		for _, flag := range pkg.Flags {
+			if (flag == "--user" || flag == "--system") && flag != "--"+pkg.Scope {
+				result.Add(ValidationError{
+					Type:    "warning",
+					Title:   "scope overrides flag",
+					Field:   field,
+					Value:   pkg.Name,
+					Message: fmt.Sprintf("flag '%s' conflicts with 'scope: %s'", flag, pkg.Scope),
+					Help:    "remove the flag; the scope setting takes precedence",
+				})
+			}
+		}
+	}
+	
+	if pkg.Remote != "" && !isValidFlatpakRemoteName(pkg.Remote) {
+		result.Add(ValidationError{
+			Type:       "error",
+			Title:      "invalid Flatpak remote",
+			Field:      field,
+			Value:      pkg.Remote,
+			Message:    fmt.Sprintf("remote name for '%s' contains invalid characters", pkg.Name),
+			Help:       "use a remote name like 'flathub' as defined in repositories.flatpak",
+			Suggestion: suggestFlatpakRemoteName(pkg.Remote),
+		})
+	}
+	
+	if pkg.Branch != "" && !regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9\-\._]*$`).MatchString(pkg.Branch) {
+		result.Add(ValidationError{
+			Type:    "error",
+			Title:   "invalid Flatpak branch",
+			Field:   field,
+			Value:   pkg.Branch,
+			Message: fmt.Sprintf("branch for '%s' contains invalid characters", pkg.Name),
+			Help:    "use a branch name like 'stable' or 'beta'",
+		})
+	}
+	
+	if pkg.Overrides != nil {
+		validateFlatpakOverrides(pkg.Name, *pkg.Overrides, field, result)
+	}
+}
+
+// validateFlatpakOverrides validates the entries of a Flatpak overrides block
+func validateFlatpakOverrides(packageName string, overrides FlatpakOverrides, field string, result *ValidationResult) {
+	for _, fs := range overrides.Filesystems {
+		if strings.TrimSpace(fs) == "" || strings.ContainsAny(fs, " ;") {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "invalid filesystem override",
+				Field:   field,
+				Value:   fs,
+				Message: fmt.Sprintf("filesystem override for '%s' must be a single path or keyword", packageName),
+				Help:    "use entries like 'home', 'xdg-download:ro' or '~/Projects'",
+			})
+		}
+	}
+	
+	for _, socket := range overrides.Sockets {
+		if !isValidFlatpakSocket(socket) {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "invalid socket override",
+				Field:   field,
+				Value:   socket,
+				Message: fmt.Sprintf("unknown socket '%s' for '%s'", socket, packageName),
+				Help:    "valid sockets: x11, wayland, fallback-x11, pulseaudio, system-bus, session-bus, ssh-auth, pcsc, cups, gpg-agent, inherit-wayland-socket",
+			})
+		}
+	}
+	
+	for key := range overrides.Env {
+		if !regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`).MatchString(key) {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "invalid environment override",
+				Field:   field,
+				Value:   key,
+				Message: fmt.Sprintf("environment variable name '%s' for '%s' is invalid", key, packageName),
+				Help:    "use letters, numbers and underscores, not starting with a number",
+			})
+		}
+	}
+	
+	for _, name := range overrides.TalkNames {
+		if !regexp.MustCompile(`^[A-Za-z0-9_\-]+(\.[A-Za-z0-9_\-]+)+(\.\*)?$`).MatchString(name) {
+			result.Add(ValidationError{
+				Type:    "error",
+				Title:   "invalid D-Bus name",
+				Field:   field,
+				Value:   name,
+				Message: fmt.Sprintf("talk_name '%s' for '%s' is not a valid D-Bus name", name, packageName),
+				Help:    "use a well-known bus name like 'org.freedesktop.Notifications' or 'org.kde.*'",
+			})
+		}
+	}
+}
+
+// isValidFlatpakSocket checks a socket name against the sockets flatpak understands
+func isValidFlatpakSocket(socket string) bool {
+	validSockets := []string{"x11", "wayland", "fallback-x11", "pulseaudio", "system-bus", "session-bus",
+		"ssh-auth", "pcsc", "cups", "gpg-agent", "inherit-wayland-socket"}
+	for _, valid := range validSockets {
+		if socket == valid {
+			return true
+		}
 	}
+	return false
 }
 
 // validatePackageFlags validates the flags for a specific package entry
@@ -1123,6 +2023,49 @@ func validatePackageDefaults(defaults map[string][]string, result *ValidationRes
 	}
 }
 
+// validatePackageSettings validates the package_settings section against the settings each manager accepts
+func validatePackageSettings(settings map[string]map[string]string, result *ValidationResult) {
+	for manager, values := range settings {
+		spec, registered := LookupPackageManager(manager)
+		if !registered {
+			result.Add(ValidationError{
+				Type:       "error",
+				Title:      "unsupported package manager",
+				Field:      fmt.Sprintf("package_settings.%s", manager),
+				Value:      manager,
+				Message:    fmt.Sprintf("'%s' is not a supported package manager", manager),
+				Help:       fmt.Sprintf("use one of: %v", RegisteredPackageManagers()),
+				Suggestion: "remove this entry or check for typos",
+			})
+			continue
+		}
+		
+		for key := range values {
+			known := false
+			for _, setting := range spec.Settings {
+				if key == setting {
+					known = true
+					break
+				}
+			}
+			if !known {
+				help := fmt.Sprintf("%s has no package settings", spec.DisplayName)
+				if len(spec.Settings) > 0 {
+					help = fmt.Sprintf("%s supports: %s", spec.DisplayName, strings.Join(spec.Settings, ", "))
+				}
+				result.Add(ValidationError{
+					Type:    "warning",
+					Title:   "unknown package setting",
+					Field:   fmt.Sprintf("package_settings.%s.%s", manager, key),
+					Value:   key,
+					Message: fmt.Sprintf("'%s' is not a %s setting and will be ignored", key, spec.DisplayName),
+					Help:    help,
+				})
+			}
+		}
+	}
+}
+
 func isValidFileMode(mode string) bool {
 	if len(mode) != 3 && len(mode) != 4 {
 		return false
@@ -1184,6 +2127,30 @@ func isValidDebFilePath(debPath string) bool {
 	return true
 }
 
+// isValidFlatpakBundlePath validates a .flatpakref or .flatpak bundle reference
+// Accepts HTTPS URLs and local paths (which must contain a path separator, like .deb files)
+func isValidFlatpakBundlePath(bundlePath string) bool {
+	if !strings.HasSuffix(bundlePath, ".flatpakref") && !strings.HasSuffix(bundlePath, ".flatpak") {
+		return false
+	}
+	
+	if strings.Contains(bundlePath, "..") || strings.Contains(bundlePath, " ") {
+		return false
+	}
+	
+	if strings.HasPrefix(bundlePath, "http://") {
+		return false
+	}
+	
+	if !strings.HasPrefix(bundlePath, "https://") && !strings.Contains(bundlePath, "/") {
+		return false
+	}
+	
+	// Filename must be more than just the extension
+	filename := bundlePath[strings.LastIndex(bundlePath, "/")+1:]
+	return filename != ".flatpakref" && filename != ".flatpak"
+}
+
 // Repository validation helper functions
 
 // isValidPPAFormat validates PPA format (user/repo)
@@ -1432,4 +2399,10 @@ func isValidRemoteDebURL(url string) bool {
 	}
 	
 	return true
-}
\ No newline at end of file
+}
+
+// isValidDnfRepositoryID validates a DNF repository id (also used as the .repo file name)
+func isValidDnfRepositoryID(id string) bool {
+	matched, _ := regexp.MatchString(`^[a-zA-Z0-9][a-zA-Z0-9\-_.:]*$`, id)
+	return matched
+}
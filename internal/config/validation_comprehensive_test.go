@@ -37,17 +37,19 @@ func TestValidation_ComprehensiveScenarios(t *testing.T) {
 				},
 			},
 			Packages: PackageManagement{
-				Apt: []PackageEntry{
-					{Name: "curl"},
-					{Name: "vim", Flags: []string{"-y", "--install-suggests"}},
-				},
-				Flatpak: []PackageEntry{
-					{Name: "org.mozilla.Firefox"},
-					{Name: "org.gimp.GIMP", Flags: []string{"--user"}},
-				},
-				Snap: []PackageEntry{
-					{Name: "code"},
-					{Name: "discord", Flags: []string{"--classic"}},
+				Managers: map[string][]PackageEntry{
+					"apt": {
+						{Name: "curl"},
+						{Name: "vim", Flags: []string{"-y", "--install-suggests"}},
+					},
+					"flatpak": {
+						{Name: "org.mozilla.Firefox"},
+						{Name: "org.gimp.GIMP", Flags: []string{"--user"}},
+					},
+					"snap": {
+						{Name: "code"},
+						{Name: "discord", Flags: []string{"--classic"}},
+					},
 				},
 			},
 			Files: map[string]File{
@@ -175,8 +177,10 @@ func TestValidation_ComprehensiveScenarios(t *testing.T) {
 				config: &Config{
 					Version: "1.0",
 					Packages: PackageManagement{
-						Apt: []PackageEntry{
-							{Name: ""},
+						Managers: map[string][]PackageEntry{
+							"apt": {
+								{Name: ""},
+							},
 						},
 					},
 				},
@@ -187,8 +191,10 @@ func TestValidation_ComprehensiveScenarios(t *testing.T) {
 				config: &Config{
 					Version: "1.0",
 					Packages: PackageManagement{
-						Flatpak: []PackageEntry{
-							{Name: "invalid-app-id"},
+						Managers: map[string][]PackageEntry{
+							"flatpak": {
+								{Name: "invalid-app-id"},
+							},
 						},
 					},
 				},
@@ -199,8 +205,10 @@ func TestValidation_ComprehensiveScenarios(t *testing.T) {
 				config: &Config{
 					Version: "1.0",
 					Packages: PackageManagement{
-						Snap: []PackageEntry{
-							{Name: "Invalid_Package_Name"},
+						Managers: map[string][]PackageEntry{
+							"snap": {
+								{Name: "Invalid_Package_Name"},
+							},
 						},
 					},
 				},
@@ -211,8 +219,10 @@ func TestValidation_ComprehensiveScenarios(t *testing.T) {
 				config: &Config{
 					Version: "1.0",
 					Packages: PackageManagement{
-						Snap: []PackageEntry{
-							{Name: "this-is-a-very-long-package-name-that-exceeds-forty-characters"},
+						Managers: map[string][]PackageEntry{
+							"snap": {
+								{Name: "this-is-a-very-long-package-name-that-exceeds-forty-characters"},
+							},
 						},
 					},
 				},
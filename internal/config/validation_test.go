@@ -167,6 +167,71 @@ func TestValidate_FileValidation(t *testing.T) {
 			shouldError: true,
 			errorTitle:  "unsafe destination path",
 		},
+		{
+			name: "valid template",
+			file: File{
+				Source:      "test.txt",
+				Destination: "~/test.txt",
+				Template:    true,
+			},
+			shouldError: false,
+		},
+		{
+			name: "invalid template",
+			file: File{
+				Source:      "broken.tmpl",
+				Destination: "~/test.txt",
+				Template:    true,
+			},
+			shouldError: true,
+			errorTitle:  "invalid template",
+		},
+		{
+			name: "inline content",
+			file: File{
+				Content:     "KEY=value\n",
+				Destination: "~/.config/environment.d/key.conf",
+			},
+			shouldError: false,
+		},
+		{
+			name: "source and content",
+			file: File{
+				Source:      "test.txt",
+				Content:     "KEY=value\n",
+				Destination: "~/test.txt",
+			},
+			shouldError: true,
+			errorTitle:  "conflicting file source",
+		},
+		{
+			name: "remote source",
+			file: File{
+				Source:      "https://example.com/99-device.rules",
+				SHA256:      strings.Repeat("a", 64),
+				Destination: "/etc/udev/rules.d/99-device.rules",
+			},
+			shouldError: false,
+		},
+		{
+			name: "insecure remote source",
+			file: File{
+				Source:      "http://example.com/99-device.rules",
+				Destination: "/etc/udev/rules.d/99-device.rules",
+			},
+			shouldError: true,
+			errorTitle:  "insecure source URL",
+		},
+		{
+			name: "invalid remote checksum",
+			file: File{
+				Source:      "https://example.com/99-device.rules",
+				SHA256:      "abc123",
+				Destination: "/etc/udev/rules.d/99-device.rules",
+			},
+			shouldError: true,
+			errorTitle:  "invalid sha256 checksum",
+		},
 	}
 	
 	// Create a test source file for valid tests
@@ -175,6 +240,9 @@ func TestValidate_FileValidation(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create test file: %v", err)
 	}
+	if err := os.WriteFile(filepath.Join(tempDir, "broken.tmpl"), []byte("email = {{ .Vars.email"), 0644); err != nil {
+		t.Fatalf("failed to create test template: %v", err)
+	}
 	
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -290,15 +358,17 @@ func TestValidate_PackageValidation(t *testing.T) {
 		{
 			name: "valid package names",
 			packages: PackageManagement{
-				Apt: []PackageEntry{
-					{Name: "git"},
-					{Name: "curl"},
-				},
-				Flatpak: []PackageEntry{
-					{Name: "org.mozilla.firefox"},
-				},
-				Snap: []PackageEntry{
-					{Name: "discord"},
+				Managers: map[string][]PackageEntry{
+					"apt": {
+						{Name: "git"},
+						{Name: "curl"},
+					},
+					"flatpak": {
+						{Name: "org.mozilla.firefox"},
+					},
+					"snap": {
+						{Name: "discord"},
+					},
 				},
 			},
 			shouldError: false,
@@ -306,8 +376,10 @@ func TestValidate_PackageValidation(t *testing.T) {
 		{
 			name: "empty package name",
 			packages: PackageManagement{
-				Apt: []PackageEntry{
-					{Name: ""}, // Empty name
+				Managers: map[string][]PackageEntry{
+					"apt": {
+						{Name: ""}, // Empty name
+					},
 				},
 			},
 			shouldError: true,
@@ -510,7 +582,9 @@ func TestValidate_AptPackageValidation(t *testing.T) {
 			config := &Config{
 				Version: "1.0",
 				Packages: PackageManagement{
-					Apt: tt.packages,
+					Managers: map[string][]PackageEntry{
+						"apt": tt.packages,
+					},
 				},
 			}
 
@@ -1229,9 +1303,11 @@ func TestValidate_ThreeTierFlagSystem(t *testing.T) {
 					"apt": {"-y"},
 				},
 				Packages: PackageManagement{
-					Apt: []PackageEntry{
-						{Name: "git", Flags: []string{"--no-install-recommends"}},
-						{Name: "curl"}, // Uses defaults
+					Managers: map[string][]PackageEntry{
+						"apt": {
+							{Name: "git", Flags: []string{"--no-install-recommends"}},
+							{Name: "curl"}, // Uses defaults
+						},
 					},
 				},
 			},
@@ -1242,8 +1318,10 @@ func TestValidate_ThreeTierFlagSystem(t *testing.T) {
 			config: &Config{
 				Version: "1.0",
 				Packages: PackageManagement{
-					Apt: []PackageEntry{
-						{Name: "git", Flags: []string{"--invalid-flag"}},
+					Managers: map[string][]PackageEntry{
+						"apt": {
+							{Name: "git", Flags: []string{"--invalid-flag"}},
+						},
 					},
 				},
 			},
@@ -1257,8 +1335,10 @@ func TestValidate_ThreeTierFlagSystem(t *testing.T) {
 					"apt": {"--no-install-recommends"},
 				},
 				Packages: PackageManagement{
-					Apt: []PackageEntry{
-						{Name: "git", Flags: []string{"--install-recommends"}},
+					Managers: map[string][]PackageEntry{
+						"apt": {
+							{Name: "git", Flags: []string{"--install-recommends"}},
+						},
 					},
 				},
 			},
@@ -1315,15 +1395,17 @@ func TestValidate_RemovalSystemValidation(t *testing.T) {
 			},
 		},
 		Packages: PackageManagement{
-			Apt: []PackageEntry{
-				{Name: "git"},
-				{Name: "curl"},
-			},
-			Flatpak: []PackageEntry{
-				{Name: "org.mozilla.firefox"},
-			},
-			Snap: []PackageEntry{
-				{Name: "code"},
+			Managers: map[string][]PackageEntry{
+				"apt": {
+					{Name: "git"},
+					{Name: "curl"},
+				},
+				"flatpak": {
+					{Name: "org.mozilla.firefox"},
+				},
+				"snap": {
+					{Name: "code"},
+				},
 			},
 		},
 	}
@@ -1545,3 +1627,532 @@ func TestValidateBinaries(t *testing.T) {
 		})
 	}
 }
+
+func TestIsValidFlatpakBundlePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+		name     string
+	}{
+		{"./apps/vendor.flatpakref", true, "relative .flatpakref"},
+		{"/opt/bundles/tool.flatpak", true, "absolute .flatpak bundle"},
+		{"https://dl.example.com/app.flatpakref", true, "HTTPS .flatpakref"},
+		{"http://dl.example.com/app.flatpakref", false, "plain HTTP URL"},
+		{"app.flatpakref", false, "no path separator"},
+		{"../outside/app.flatpak", false, "path traversal attempt"},
+		{"/opt/bundles/.flatpak", false, "just extension"},
+		{"/opt/bundles/tool.tar", false, "not a bundle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := isValidFlatpakBundlePath(tt.path)
+			if result != tt.expected {
+				t.Errorf("isValidFlatpakBundlePath(%s) = %v, expected %v", tt.path, result, tt.expected)
+			}
+		})
+	}
+
+	// Bundle paths are accepted as Flatpak package names, app IDs still validate as before
+	if !isValidPackageNameForManager("./apps/vendor.flatpakref", "flatpak") {
+		t.Error("expected .flatpakref path to be a valid flatpak package entry")
+	}
+	if !isValidPackageNameForManager("org.mozilla.Firefox", "flatpak") {
+		t.Error("expected app ID to remain a valid flatpak package entry")
+	}
+}
+
+func TestValidate_RemoteDebChecksum(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name          string
+		pkg           PackageEntry
+		manager       string
+		expectError   bool
+		expectWarning bool
+	}{
+		{
+			name:    "valid checksum on remote .deb",
+			pkg:     PackageEntry{Name: "https://example.com/tool_1.0_amd64.deb", SHA256: strings.Repeat("a", 64)},
+			manager: "apt",
+		},
+		{
+			name:        "malformed checksum",
+			pkg:         PackageEntry{Name: "https://example.com/tool_1.0_amd64.deb", SHA256: "abc"},
+			manager:     "apt",
+			expectError: true,
+		},
+		{
+			name:          "checksum on repository package",
+			pkg:           PackageEntry{Name: "curl", SHA256: strings.Repeat("a", 64)},
+			manager:       "apt",
+			expectWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Version: "1.0"}
+			config.Packages.Set("apt", []PackageEntry{tt.pkg})
+
+			result := Validate(config, filepath.Join(tempDir, "config.yaml"))
+
+			hasChecksumError := false
+			for _, err := range result.Errors {
+				if err.Title == "invalid sha256 checksum" {
+					hasChecksumError = true
+				}
+			}
+			hasChecksumWarning := false
+			for _, warning := range result.Warnings {
+				if warning.Title == "unused checksum" {
+					hasChecksumWarning = true
+				}
+			}
+
+			if hasChecksumError != tt.expectError {
+				t.Errorf("expected checksum error %v, got %v (errors: %v)", tt.expectError, hasChecksumError, result.Errors)
+			}
+			if hasChecksumWarning != tt.expectWarning {
+				t.Errorf("expected checksum warning %v, got %v", tt.expectWarning, hasChecksumWarning)
+			}
+		})
+	}
+}
+
+func TestValidate_DebconfSelections(t *testing.T) {
+	tempDir := t.TempDir()
+
+	config := &Config{Version: "1.0"}
+	config.DebconfSelections = []string{
+		"postfix postfix/main_mailer_type select Internet Site",
+		"postfix postfix/relayhost",
+	}
+	config.Packages.Set("apt", []PackageEntry{
+		{Name: "wireshark-common", Debconf: map[string]string{"wireshark-common/install-setuid": "boolean maybe"}},
+	})
+	config.Packages.Set("snap", []PackageEntry{
+		{Name: "code", Debconf: map[string]string{"code/question": "boolean true"}},
+	})
+
+	result := Validate(config, filepath.Join(tempDir, "config.yaml"))
+
+	titles := make(map[string]int)
+	for _, err := range result.Errors {
+		titles[err.Title]++
+	}
+	for _, warning := range result.Warnings {
+		titles[warning.Title]++
+	}
+
+	if titles["invalid debconf selection"] != 1 {
+		t.Errorf("expected 1 invalid debconf selection error, got %d", titles["invalid debconf selection"])
+	}
+	if titles["invalid debconf answer"] != 1 {
+		t.Errorf("expected 1 invalid debconf answer error, got %d", titles["invalid debconf answer"])
+	}
+	if titles["unused debconf answers"] != 1 {
+		t.Errorf("expected 1 unused debconf answers warning, got %d", titles["unused debconf answers"])
+	}
+}
+
+func TestValidate_LogicalPackages(t *testing.T) {
+	tempDir := t.TempDir()
+
+	config := &Config{Version: "1.0"}
+	config.Packages.Any = map[string][]string{
+		"vscode":   {"apt:code", "snap:code --classic", "flatpak:com.visualstudio.code"},
+		"obsidian": {"flatpak:md.obsidian.Obsidian", "pacman:obsidian"},
+		"empty":    {},
+		"bad-name": {"snap:Obsidian_App"},
+	}
+
+	result := Validate(config, filepath.Join(tempDir, "config.yaml"))
+
+	titles := make(map[string]int)
+	for _, err := range result.Errors {
+		titles[err.Title]++
+	}
+
+	if titles["invalid package candidate"] != 1 {
+		t.Errorf("expected 1 invalid package candidate error, got %d", titles["invalid package candidate"])
+	}
+	if titles["logical package without candidates"] != 1 {
+		t.Errorf("expected 1 logical package without candidates error, got %d", titles["logical package without candidates"])
+	}
+	if titles["invalid package name"] != 1 {
+		t.Errorf("expected 1 invalid package name error, got %d", titles["invalid package name"])
+	}
+}
+
+func TestValidate_DirectoryValidation(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, "nvim"), 0755); err != nil {
+		t.Fatalf("failed to create source directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "vimrc"), []byte("set nu"), 0644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	
+	tests := []struct {
+		name       string
+		directory  Directory
+		errorTitle string
+	}{
+		{
+			name:      "valid directory",
+			directory: Directory{Source: "nvim", Destination: "~/.config/nvim", Exclude: []string{"*.swp"}},
+		},
+		{
+			name:       "missing source directory",
+			directory:  Directory{Source: "kitty", Destination: "~/.config/kitty"},
+			errorTitle: "source directory not found",
+		},
+		{
+			name:       "source is a file",
+			directory:  Directory{Source: "vimrc", Destination: "~/.vim"},
+			errorTitle: "source is not a directory",
+		},
+		{
+			name:       "invalid method",
+			directory:  Directory{Source: "nvim", Destination: "~/.config/nvim", Method: "hardlink"},
+			errorTitle: "invalid directory method",
+		},
+		{
+			name:       "invalid glob",
+			directory:  Directory{Source: "nvim", Destination: "~/.config/nvim", Include: []string{"lua/["}},
+			errorTitle: "invalid glob pattern",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Version:     "1.0",
+				Directories: map[string]Directory{"test": tt.directory},
+			}
+			
+			result := Validate(config, filepath.Join(tempDir, "config.yaml"))
+			
+			if tt.errorTitle == "" {
+				if result.HasErrors() {
+					t.Errorf("validation should pass, got errors: %v", result.Errors)
+				}
+				return
+			}
+			found := false
+			for _, err := range result.Errors {
+				if err.Title == tt.errorTitle {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected error %q, got %v", tt.errorTitle, result.Errors)
+			}
+		})
+	}
+}
+
+func TestValidate_LineAndBlockValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		lines      map[string]LineInFile
+		blocks     map[string]Block
+		errorTitle string
+	}{
+		{
+			name:   "valid line and block",
+			lines:  map[string]LineInFile{"nas": {Path: "/etc/hosts", Line: "10.0.0.5 nas", Regexp: `\snas$`}},
+			blocks: map[string]Block{"aliases": {Path: "~/.bashrc", Content: "alias g=git"}},
+		},
+		{
+			name:       "missing line path",
+			lines:      map[string]LineInFile{"nas": {Line: "10.0.0.5 nas"}},
+			errorTitle: "missing target path",
+		},
+		{
+			name:       "multiline line",
+			lines:      map[string]LineInFile{"nas": {Path: "/etc/hosts", Line: "10.0.0.5 nas\n10.0.0.6 printer"}},
+			errorTitle: "invalid line",
+		},
+		{
+			name:       "invalid regexp",
+			lines:      map[string]LineInFile{"nas": {Path: "/etc/hosts", Line: "10.0.0.5 nas", Regexp: "(nas"}},
+			errorTitle: "invalid regular expression",
+		},
+		{
+			name:       "marker without placeholder",
+			blocks:     map[string]Block{"aliases": {Path: "~/.bashrc", Content: "alias g=git", Marker: "# configr"}},
+			errorTitle: "invalid block marker",
+		},
+		{
+			name:       "marker in content",
+			blocks:     map[string]Block{"aliases": {Path: "~/.bashrc", Content: "# END CONFIGR MANAGED BLOCK: aliases"}},
+			errorTitle: "marker in block content",
+		},
+		{
+			name: "duplicate marker",
+			blocks: map[string]Block{
+				"a": {Path: "~/.bashrc", Content: "alias g=git", Marker: "# {mark} aliases"},
+				"b": {Path: "~/.bashrc", Content: "alias k=kubectl", Marker: "# {mark} aliases"},
+			},
+			errorTitle: "duplicate block marker",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Version: "1.0",
+				Lines:   tt.lines,
+				Blocks:  tt.blocks,
+			}
+
+			result := Validate(config, "")
+
+			if tt.errorTitle == "" {
+				if result.HasErrors() {
+					t.Errorf("validation should pass, got errors: %v", result.Errors)
+				}
+				return
+			}
+			found := false
+			for _, err := range result.Errors {
+				if err.Title == tt.errorTitle {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected error %q, got %v", tt.errorTitle, result.Errors)
+			}
+		})
+	}
+}
+
+func TestValidate_SettingsFileValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		settingsFile SettingsFile
+		errorTitle   string
+	}{
+		{
+			name:         "valid json settings",
+			settingsFile: SettingsFile{Path: "~/.config/Code/User/settings.json", Settings: map[string]interface{}{"/editor.fontSize": 14, "workbench.colorTheme": "Default Dark+"}},
+		},
+		{
+			name:         "valid ini settings",
+			settingsFile: SettingsFile{Path: "~/.config/app/app.conf", Format: "ini", Settings: map[string]interface{}{"General.theme": "dark"}},
+		},
+		{
+			name:         "missing path",
+			settingsFile: SettingsFile{Settings: map[string]interface{}{"a": 1}},
+			errorTitle:   "missing target path",
+		},
+		{
+			name:         "unknown format",
+			settingsFile: SettingsFile{Path: "~/.config/app/app.conf", Settings: map[string]interface{}{"a": 1}},
+			errorTitle:   "unsupported settings format",
+		},
+		{
+			name:         "empty key segment",
+			settingsFile: SettingsFile{Path: "settings.json", Settings: map[string]interface{}{"editor..fontSize": 14}},
+			errorTitle:   "invalid settings key",
+		},
+		{
+			name:         "nested ini key",
+			settingsFile: SettingsFile{Path: "app.ini", Settings: map[string]interface{}{"General.window.width": 800}},
+			errorTitle:   "invalid settings key",
+		},
+		{
+			name:         "ini section value",
+			settingsFile: SettingsFile{Path: "app.ini", Settings: map[string]interface{}{"General": map[string]interface{}{"theme": "dark"}}},
+			errorTitle:   "invalid settings value",
+		},
+		{
+			name:         "conflicting keys",
+			settingsFile: SettingsFile{Path: "config.yaml", Settings: map[string]interface{}{"font": map[string]interface{}{"size": 12}, "font.size": 13}},
+			errorTitle:   "conflicting settings keys",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Version:       "1.0",
+				SettingsFiles: map[string]SettingsFile{"test": tt.settingsFile},
+			}
+
+			result := Validate(config, "")
+
+			if tt.errorTitle == "" {
+				if result.HasErrors() {
+					t.Errorf("validation should pass, got errors: %v", result.Errors)
+				}
+				return
+			}
+			found := false
+			for _, err := range result.Errors {
+				if err.Title == tt.errorTitle {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected error %q, got %v", tt.errorTitle, result.Errors)
+			}
+		})
+	}
+}
+
+func TestValidate_EncryptedFileMode(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "npmrc.age"), []byte("age-encryption.org/v1"), 0644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		mode       string
+		expectWarn bool
+	}{
+		{name: "no mode", mode: ""},
+		{name: "private mode", mode: "0600"},
+		{name: "public mode", mode: "644", expectWarn: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Version: "1.0",
+				Files: map[string]File{
+					"npmrc": {Source: "npmrc.age", Destination: "~/.npmrc", Mode: tt.mode},
+				},
+			}
+
+			result := Validate(config, filepath.Join(tempDir, "configr.yaml"))
+			if result.HasErrors() {
+				t.Fatalf("validation should pass, got errors: %v", result.Errors)
+			}
+
+			found := false
+			for _, warning := range result.Warnings {
+				if warning.Title == "secret file mode ignored" {
+					found = true
+				}
+			}
+			if found != tt.expectWarn {
+				t.Errorf("expected warning=%v, got %v", tt.expectWarn, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestParseSettingsKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected []string
+	}{
+		{"editor.fontSize", []string{"editor", "fontSize"}},
+		{"/editor.fontSize", []string{"editor.fontSize"}},
+		{"/a~1b/c~0d", []string{"a/b", "c~d"}},
+	}
+
+	for _, tt := range tests {
+		segments, err := ParseSettingsKey(tt.key)
+		if err != nil || strings.Join(segments, "|") != strings.Join(tt.expected, "|") {
+			t.Errorf("ParseSettingsKey(%q) = %v, %v; expected %v", tt.key, segments, err, tt.expected)
+		}
+	}
+
+	if _, err := ParseSettingsKey("/"); err == nil {
+		t.Error("expected an error for an empty JSON pointer segment")
+	}
+}
+
+func TestValidate_FileAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       File
+		errorTitle string
+	}{
+		{
+			name: "valid attributes",
+			file: File{Content: "x", Destination: "/etc/app.conf", ACL: []string{"g:devs:rw", "user:1000:r-x", "m::7"}, Xattrs: map[string]string{"user.origin": "configr"}, Immutable: true},
+		},
+		{
+			name:       "invalid acl tag",
+			file:       File{Content: "x", Destination: "/etc/app.conf", ACL: []string{"team:devs:rw"}},
+			errorTitle: "invalid ACL entry",
+		},
+		{
+			name:       "default acl entry",
+			file:       File{Content: "x", Destination: "/etc/app.conf", ACL: []string{"default:group:devs:rw"}},
+			errorTitle: "invalid ACL entry",
+		},
+		{
+			name:       "xattr without namespace",
+			file:       File{Content: "x", Destination: "/etc/app.conf", Xattrs: map[string]string{"origin": "configr"}},
+			errorTitle: "invalid extended attribute name",
+		},
+		{
+			name:       "symlinked file",
+			file:       File{Source: "app.conf", Destination: "~/.app.conf", AppendOnly: true},
+			errorTitle: "extended attributes need a copy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Version: "1.0",
+				Files:   map[string]File{"test": tt.file},
+			}
+
+			result := Validate(config, "")
+
+			found := false
+			for _, err := range result.Errors {
+				if err.Title == tt.errorTitle {
+					found = true
+				}
+			}
+			if tt.errorTitle == "" {
+				for _, err := range result.Errors {
+					if strings.Contains(err.Title, "ACL") || strings.Contains(err.Title, "attribute") {
+						t.Errorf("unexpected error: %v", err)
+					}
+				}
+				return
+			}
+			if !found {
+				t.Errorf("expected error %q, got %v", tt.errorTitle, result.Errors)
+			}
+		})
+	}
+}
+
+func TestNormalizeACLEntry(t *testing.T) {
+	tests := []struct {
+		entry    string
+		expected string
+	}{
+		{"g:devs:rw", "group:devs:rw-"},
+		{"user:1000:r-x", "user:1000:r-x"},
+		{"u::7", "user::rwx"},
+		{"mask::r", "mask::r--"},
+		{"o::0", "other::---"},
+	}
+
+	for _, tt := range tests {
+		normalized, err := NormalizeACLEntry(tt.entry)
+		if err != nil || normalized != tt.expected {
+			t.Errorf("NormalizeACLEntry(%q) = %q, %v; expected %q", tt.entry, normalized, err, tt.expected)
+		}
+	}
+
+	for _, entry := range []string{"group:devs", "other:nobody:r", "user:bad name:r", "group:devs:rwz", "user:alice:"} {
+		if _, err := NormalizeACLEntry(entry); err == nil {
+			t.Errorf("expected an error for %q", entry)
+		}
+	}
+}
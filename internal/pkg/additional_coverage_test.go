@@ -183,9 +183,11 @@ func TestPackageState_Structure(t *testing.T) {
 		Version:     "1.0",
 		LastUpdated: time.Now(),
 		Packages: ManagedPackages{
-			Apt:     []string{"vim"},
-			Flatpak: []string{"firefox"},
-			Snap:    []string{"code"},
+			Managers: map[string][]string{
+				"apt":     {"vim"},
+				"flatpak": {"firefox"},
+				"snap":    {"code"},
+			},
 		},
 	}
 	
@@ -193,15 +195,15 @@ func TestPackageState_Structure(t *testing.T) {
 		t.Error("PackageState Version not set correctly")
 	}
 	
-	if len(ps.Packages.Apt) != 1 {
+	if len(ps.Packages.Get("apt")) != 1 {
 		t.Error("PackageState Apt packages not set correctly")
 	}
 	
-	if len(ps.Packages.Flatpak) != 1 {
+	if len(ps.Packages.Get("flatpak")) != 1 {
 		t.Error("PackageState Flatpak packages not set correctly")
 	}
 	
-	if len(ps.Packages.Snap) != 1 {
+	if len(ps.Packages.Get("snap")) != 1 {
 		t.Error("PackageState Snap packages not set correctly")
 	}
 }
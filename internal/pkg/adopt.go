@@ -0,0 +1,99 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// AdoptFile brings an existing file under management by moving it to sourcePath in the config repository
+// The original is replaced with a symlink to the moved file, or left in place in copy mode
+func (fm *FileManager) AdoptFile(name, destPath, sourcePath string, copy bool) (ManagedFile, error) {
+	info, err := os.Lstat(destPath)
+	if err != nil {
+		return ManagedFile{}, fmt.Errorf("failed to stat %s: %w", destPath, err)
+	}
+	if !info.Mode().IsRegular() {
+		return ManagedFile{}, fmt.Errorf("%s is not a regular file", destPath)
+	}
+	if _, err := os.Lstat(sourcePath); err == nil {
+		return ManagedFile{}, fmt.Errorf("%s already exists in the config repository", sourcePath)
+	} else if !os.IsNotExist(err) {
+		return ManagedFile{}, fmt.Errorf("failed to stat %s: %w", sourcePath, err)
+	}
+
+	managedFile := ManagedFile{
+		Name:        name,
+		Destination: destPath,
+		IsSymlink:   !copy,
+	}
+
+	if fm.dryRun {
+		if copy {
+			fm.logger.Info("DRY RUN: Would copy file into the config repository", "name", name, "from", destPath, "to", sourcePath)
+		} else {
+			fm.logger.Info("DRY RUN: Would move file into the config repository and link it back", "name", name, "from", destPath, "to", sourcePath)
+		}
+		return managedFile, nil
+	}
+
+	if err := fm.ensureDirectory(filepath.Dir(sourcePath)); err != nil {
+		return ManagedFile{}, fmt.Errorf("failed to create source directory: %w", err)
+	}
+
+	if copy {
+		if err := fm.copyFile(destPath, sourcePath); err != nil {
+			return ManagedFile{}, fmt.Errorf("failed to copy file: %w", err)
+		}
+		if err := os.Chmod(sourcePath, info.Mode().Perm()); err != nil {
+			return ManagedFile{}, fmt.Errorf("failed to set permissions: %w", err)
+		}
+
+		// The deployed copy is the adopted file itself, so later local edits can be detected
+		if managedFile.ContentHash, err = fm.calculateFileHash(destPath); err != nil {
+			return ManagedFile{}, fmt.Errorf("failed to hash adopted file: %w", err)
+		}
+
+		fm.logger.Info("✓ File adopted", "name", name, "source", sourcePath, "destination", destPath)
+		return managedFile, nil
+	}
+
+	if err := fm.moveFile(destPath, sourcePath, info.Mode().Perm()); err != nil {
+		return ManagedFile{}, err
+	}
+	if err := fm.createSymlink(sourcePath, destPath); err != nil {
+		// Put the file back, so a failed adoption never loses it
+		if restoreErr := fm.moveFile(sourcePath, destPath, info.Mode().Perm()); restoreErr != nil {
+			return ManagedFile{}, fmt.Errorf("failed to create symlink: %w (the file is now at %s)", err, sourcePath)
+		}
+		return ManagedFile{}, fmt.Errorf("failed to create symlink: %w", err)
+	}
+
+	fm.logger.Info("✓ File adopted", "name", name, "source", sourcePath, "destination", destPath)
+	return managedFile, nil
+}
+
+// moveFile renames a file, falling back to copy and remove across filesystems
+func (fm *FileManager) moveFile(from, to string, mode os.FileMode) error {
+	err := os.Rename(from, to)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return fmt.Errorf("failed to move %s to %s: %w", from, to, err)
+	}
+
+	fm.logger.Debug("Moving file across filesystems", "from", from, "to", to)
+	if err := fm.copyFile(from, to); err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", from, to, err)
+	}
+	if err := os.Chmod(to, mode); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := os.Remove(from); err != nil {
+		return fmt.Errorf("failed to remove %s after copying it: %w", from, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestFileManager_AdoptFile(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	destPath := filepath.Join(tempDir, ".gitconfig")
+	sourcePath := filepath.Join(tempDir, "repo", "dotfiles", "gitconfig")
+	if err := os.WriteFile(destPath, []byte("[user]\n"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	// Dry runs leave the file alone
+	if _, err := NewFileManager(logger, true, tempDir).AdoptFile("gitconfig", destPath, sourcePath, false); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if info, err := os.Lstat(destPath); err != nil || !info.Mode().IsRegular() {
+		t.Fatalf("dry run changed the file: %v", err)
+	}
+
+	fm := NewFileManager(logger, false, tempDir)
+	managed, err := fm.AdoptFile("gitconfig", destPath, sourcePath, false)
+	if err != nil {
+		t.Fatalf("AdoptFile failed: %v", err)
+	}
+	if !managed.IsSymlink || managed.Destination != destPath || managed.Name != "gitconfig" {
+		t.Errorf("unexpected managed file: %+v", managed)
+	}
+
+	if target, err := os.Readlink(destPath); err != nil || target != sourcePath {
+		t.Errorf("expected a symlink to %s, got %q (%v)", sourcePath, target, err)
+	}
+	if content, _ := os.ReadFile(sourcePath); string(content) != "[user]\n" {
+		t.Errorf("unexpected adopted content: %q", content)
+	}
+	if info, _ := os.Stat(sourcePath); info.Mode().Perm() != 0600 {
+		t.Errorf("expected permissions to be preserved, got %v", info.Mode().Perm())
+	}
+
+	// A symlink, or a source that already exists, can't be adopted
+	if _, err := fm.AdoptFile("gitconfig", destPath, filepath.Join(tempDir, "other"), false); err == nil {
+		t.Error("expected an error for adopting a symlink")
+	}
+	otherPath := filepath.Join(tempDir, ".npmrc")
+	if err := os.WriteFile(otherPath, []byte("a"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := fm.AdoptFile("npmrc", otherPath, sourcePath, false); err == nil {
+		t.Error("expected an error for an existing source")
+	}
+}
+
+func TestFileManager_AdoptFile_Copy(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	destPath := filepath.Join(tempDir, ".npmrc")
+	sourcePath := filepath.Join(tempDir, "repo", "npmrc")
+	if err := os.WriteFile(destPath, []byte("registry=local\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	fm := NewFileManager(logger, false, tempDir)
+	managed, err := fm.AdoptFile("npmrc", destPath, sourcePath, true)
+	if err != nil {
+		t.Fatalf("AdoptFile failed: %v", err)
+	}
+
+	if info, _ := os.Lstat(destPath); !info.Mode().IsRegular() {
+		t.Error("expected the original to stay in place")
+	}
+	if content, _ := os.ReadFile(sourcePath); string(content) != "registry=local\n" {
+		t.Errorf("unexpected adopted content: %q", content)
+	}
+	if managed.IsSymlink || managed.ContentHash == "" {
+		t.Errorf("expected a copy with a content hash, got %+v", managed)
+	}
+
+	// The adopted copy counts as unmodified until it's edited
+	if modified, err := fm.isFileModifiedByUser(destPath, managed); err != nil || modified {
+		t.Errorf("expected an unmodified file, got modified=%v err=%v", modified, err)
+	}
+}
@@ -96,6 +96,12 @@ func (am *AptManager) resolvePackageFlags(pkg config.PackageEntry, packageDefaul
 
 // installPackageGroup installs a group of packages with the same flags
 func (am *AptManager) installPackageGroup(packages []config.PackageEntry, flags []string) error {
+	// Downloaded .deb files are installed from their resolved local file
+	downloadedDebs, packages := splitDownloadedDebs(packages)
+	if err := am.installDownloadedDebFiles(downloadedDebs, flags); err != nil {
+		return err
+	}
+
 	packageNames := make([]string, len(packages))
 	localDebFiles := make([]string, 0)
 	
@@ -132,6 +138,136 @@ func (am *AptManager) isLocalDebFile(packageName string) bool {
 	return strings.HasSuffix(packageName, ".deb") && (strings.HasPrefix(packageName, "/") || strings.Contains(packageName, "/"))
 }
 
+// isRemoteDebURL checks if a package name refers to a remote .deb file
+func (am *AptManager) isRemoteDebURL(packageName string) bool {
+	return strings.HasPrefix(packageName, "https://") && strings.HasSuffix(packageName, ".deb")
+}
+
+// ResolveEntries resolves remote .deb URLs so the entries carry real package names
+func (am *AptManager) ResolveEntries(packages []config.PackageEntry, configDir string) ([]config.PackageEntry, error) {
+	return am.ResolveRemoteDebEntries(packages)
+}
+
+// ResolveRemoteDebEntries downloads remote .deb URLs and replaces them with the real package name
+// Nothing is downloaded during a dry run; the entries keep their URL.
+// The package name and version are read from the .deb control data with dpkg-deb, and the
+// downloaded file is kept in Source, so state tracking and removal use the package name.
+func (am *AptManager) ResolveRemoteDebEntries(packages []config.PackageEntry) ([]config.PackageEntry, error) {
+	resolved := make([]config.PackageEntry, 0, len(packages))
+
+	for _, pkg := range packages {
+		if pkg.Source != "" || !am.isRemoteDebURL(pkg.Name) {
+			resolved = append(resolved, pkg)
+			continue
+		}
+
+		// Remote .deb files aren't downloaded during a dry run, so they keep their URL
+		if am.dryRun {
+			am.logger.Info("DRY RUN: Would download .deb file", "url", pkg.Name)
+			resolved = append(resolved, pkg)
+			continue
+		}
+
+		am.logger.Debug("Downloading remote .deb file", "url", pkg.Name)
+		localPath, err := downloadToCache(pkg.Name, "debs", pkg.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", pkg.Name, err)
+		}
+
+		packageName, version, err := am.readDebControl(localPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect %s: %w", pkg.Name, err)
+		}
+
+		am.logger.Debug("Resolved remote .deb file", "url", pkg.Name, "package", packageName, "version", version)
+		pkg.Name = packageName
+		pkg.Source = localPath
+		resolved = append(resolved, pkg)
+	}
+
+	return resolved, nil
+}
+
+// readDebControl reads the package name and version from a .deb file using dpkg-deb
+func (am *AptManager) readDebControl(debFile string) (string, string, error) {
+	cmd := exec.Command("dpkg-deb", "-f", debFile, "Package", "Version")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", "", fmt.Errorf("dpkg-deb failed: %w (%s)", err, strings.TrimSpace(string(output)))
+	}
+
+	fields := parseDebControlFields(string(output))
+	if fields["Package"] == "" {
+		return "", "", fmt.Errorf("no Package field in control data")
+	}
+
+	return fields["Package"], fields["Version"], nil
+}
+
+// parseDebControlFields parses "Key: value" lines from dpkg-deb -f output
+func parseDebControlFields(output string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		if key, value, found := strings.Cut(line, ":"); found {
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return fields
+}
+
+// splitDownloadedDebs separates resolved (downloaded) .deb entries from the other packages
+func splitDownloadedDebs(packages []config.PackageEntry) ([]config.PackageEntry, []config.PackageEntry) {
+	var downloaded, rest []config.PackageEntry
+	for _, pkg := range packages {
+		if pkg.Source != "" {
+			downloaded = append(downloaded, pkg)
+		} else {
+			rest = append(rest, pkg)
+		}
+	}
+	return downloaded, rest
+}
+
+// installDownloadedDebFiles installs downloaded .deb files, skipping those whose version is already installed
+func (am *AptManager) installDownloadedDebFiles(packages []config.PackageEntry, flags []string) error {
+	for _, pkg := range packages {
+		_, version, err := am.readDebControl(pkg.Source)
+		if err != nil {
+			return fmt.Errorf("failed to inspect %s: %w", pkg.Source, err)
+		}
+
+		installedVersion, err := am.installedVersion(pkg.Name)
+		if err != nil {
+			am.logger.Warn("Failed to check installed version, installing anyway", "package", pkg.Name, "error", err)
+		} else if installedVersion == version {
+			am.logger.Debug("Package version already installed", "package", pkg.Name, "version", version)
+			continue
+		}
+
+		am.logger.Debug("Installing downloaded .deb file", "package", pkg.Name, "version", version, "installed", installedVersion)
+		if err := am.installSingleDebFile(pkg.Source, flags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installedVersion returns the installed version of a package, or "" if it is not installed
+func (am *AptManager) installedVersion(packageName string) (string, error) {
+	installed, err := am.isPackageInstalled(packageName)
+	if err != nil || !installed {
+		return "", err
+	}
+
+	cmd := exec.Command("dpkg-query", "-W", "-f=${Version}", packageName)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("dpkg-query failed for %s: %w", packageName, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
 // filterOutLocalFiles removes local .deb files from the package list
 func (am *AptManager) filterOutLocalFiles(packageNames []string) []string {
 	filtered := make([]string, 0, len(packageNames))
@@ -181,6 +317,7 @@ func (am *AptManager) installSingleDebFile(debFile string, flags []string) error
 	}
 
 	cmd := exec.Command("apt", args...)
+	cmd.Env = noninteractiveEnv()
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -228,6 +365,7 @@ func (am *AptManager) installRepositoryPackages(packageNames []string, flags []s
 	}
 
 	cmd := exec.Command("apt", args...)
+	cmd.Env = noninteractiveEnv()
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -272,6 +410,23 @@ func (am *AptManager) isPackageInstalled(packageName string) (bool, error) {
 	return strings.Contains(outputStr, "Status: install ok installed"), nil
 }
 
+// isPackageAvailable checks if a package has an installation candidate in the configured repositories
+func (am *AptManager) isPackageAvailable(packageName string) (bool, error) {
+	cmd := exec.Command("apt-cache", "policy", packageName)
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to query apt-cache policy for %s: %w", packageName, err)
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if candidate, found := strings.CutPrefix(strings.TrimSpace(line), "Candidate:"); found {
+			return strings.TrimSpace(candidate) != "(none)", nil
+		}
+	}
+
+	return false, nil
+}
+
 // RemovePackages removes packages that are no longer in the configuration
 func (am *AptManager) RemovePackages(packagesToRemove []string) error {
 	if len(packagesToRemove) == 0 {
@@ -304,6 +459,7 @@ func (am *AptManager) RemovePackages(packagesToRemove []string) error {
 	}
 
 	cmd := exec.Command("apt", args...)
+	cmd.Env = noninteractiveEnv()
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -342,28 +498,25 @@ func (am *AptManager) SearchPackages(searchTerm string) ([]string, error) {
 		return nil, fmt.Errorf("apt search failed: %w", err)
 	}
 
+	packages := parseAptSearch(string(output))
+
+	am.logger.Debug("Found APT packages", "search", searchTerm, "count", len(packages))
+	return packages, nil
+}
+
+// parseAptSearch parses `apt search` output
+// Format: "packagename/repository version arch [installed]" followed by an indented description line
+func parseAptSearch(output string) []string {
 	var packages []string
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "WARNING:") || strings.HasPrefix(line, "NOTE:") {
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "WARNING:") || strings.HasPrefix(line, "NOTE:") {
 			continue
 		}
-		
-		// APT search output format: "packagename/repository - description"
-		if strings.Contains(line, "/") && strings.Contains(line, " - ") {
-			parts := strings.Split(line, "/")
-			if len(parts) > 0 {
-				packageName := strings.TrimSpace(parts[0])
-				if packageName != "" {
-					packages = append(packages, packageName)
-				}
-			}
+		if name, _, found := strings.Cut(line, "/"); found && name != "" && !strings.Contains(name, " ") {
+			packages = append(packages, name)
 		}
 	}
-
-	am.logger.Debug("Found APT packages", "search", searchTerm, "count", len(packages))
-	return packages, nil
+	return packages
 }
 
 // GetPackageInfo returns detailed information about an APT package
@@ -382,9 +535,10 @@ func (am *AptManager) GetPackageInfo(packageName string) (string, error) {
 		return "", fmt.Errorf("apt not available: %w", err)
 	}
 
+	// stdout only: apt warns on stderr that its CLI is not stable
 	args := []string{"show", packageName}
 	cmd := exec.Command("apt", args...)
-	output, err := cmd.CombinedOutput()
+	output, err := cmd.Output()
 
 	if err != nil {
 		return "", fmt.Errorf("apt show failed for package %s: %w", packageName, err)
@@ -414,27 +568,94 @@ func (am *AptManager) ListUpgradablePackages() ([]string, error) {
 	}
 
 	var packages []string
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
+	for _, candidate := range parseAptUpgradable(string(output)) {
+		packages = append(packages, candidate.Name)
+	}
+
+	am.logger.Debug("Found upgradable APT packages", "count", len(packages))
+	return packages, nil
+}
+
+// parseAptUpgradable parses `apt list --upgradable` output
+// Format: "packagename/repository version arch [upgradable from: oldversion]"
+func parseAptUpgradable(output string) []UpgradeCandidate {
+	var candidates []UpgradeCandidate
+	for _, line := range strings.Split(output, "\n") {
 		line = strings.TrimSpace(line)
 		if line == "" || strings.HasPrefix(line, "WARNING:") || strings.HasPrefix(line, "Listing...") {
 			continue
 		}
-		
-		// APT list output format: "packagename/repository version [upgradable from: oldversion]"
-		if strings.Contains(line, "/") && strings.Contains(line, "[upgradable from:") {
-			parts := strings.Split(line, "/")
-			if len(parts) > 0 {
-				packageName := strings.TrimSpace(parts[0])
-				if packageName != "" {
-					packages = append(packages, packageName)
-				}
-			}
+		if !strings.Contains(line, "/") || !strings.Contains(line, "[upgradable from:") {
+			continue
+		}
+
+		name, rest, _ := strings.Cut(line, "/")
+		candidate := UpgradeCandidate{Name: strings.TrimSpace(name)}
+		if fields := strings.Fields(rest); len(fields) > 1 {
+			candidate.NewVersion = fields[1]
+		}
+		if _, from, found := strings.Cut(rest, "[upgradable from:"); found {
+			candidate.CurrentVersion = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(from), "]"))
+		}
+		if candidate.Name != "" {
+			candidates = append(candidates, candidate)
 		}
 	}
+	return candidates
+}
 
-	am.logger.Debug("Found upgradable APT packages", "count", len(packages))
-	return packages, nil
+// RefreshIndex updates the APT package index so upgrades are listed against current repositories
+func (am *AptManager) RefreshIndex() error {
+	if am.dryRun {
+		am.logger.Info("  [DRY RUN] Would run:", "command", "apt update")
+		return nil
+	}
+
+	cmd := exec.Command("apt", "update")
+	cmd.Env = noninteractiveEnv()
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		am.logger.Error("Command failed", "command", "apt update", "error", err, "output", string(output))
+		return fmt.Errorf("apt update failed: %w", err)
+	}
+	return nil
+}
+
+// ListUpgrades returns upgradable APT packages; packages held with apt-mark are marked as held
+// Pins from APT preferences are already applied to the candidate versions apt lists
+func (am *AptManager) ListUpgrades() ([]UpgradeCandidate, error) {
+	output, err := exec.Command("apt", "list", "--upgradable").Output()
+	if err != nil {
+		return nil, fmt.Errorf("apt list --upgradable failed: %w", err)
+	}
+	candidates := parseAptUpgradable(string(output))
+
+	held := make(map[string]bool)
+	if output, err := exec.Command("apt-mark", "showhold").Output(); err == nil {
+		for _, name := range strings.Fields(string(output)) {
+			held[name] = true
+		}
+	} else {
+		am.logger.Warn("Could not list held APT packages", "error", err)
+	}
+
+	for i := range candidates {
+		candidates[i].Held = held[candidates[i].Name]
+	}
+	return candidates, nil
+}
+
+// Upgrade upgrades the given APT packages, or all packages with apt upgrade (which skips held packages)
+func (am *AptManager) Upgrade(packageNames []string) error {
+	if am.dryRun {
+		args := []string{"apt", "upgrade", "-y"}
+		if len(packageNames) > 0 {
+			args = append([]string{"apt", "install", "--only-upgrade", "-y"}, packageNames...)
+		}
+		am.logger.Info("  [DRY RUN] Would run:", "command", strings.Join(args, " "))
+		return nil
+	}
+	return am.UpgradePackages(packageNames, []string{"-y"})
 }
 
 // UpgradePackages upgrades all upgradable packages or specific packages
@@ -462,6 +683,7 @@ func (am *AptManager) UpgradePackages(packageNames []string, flags []string) err
 	}
 
 	cmd := exec.Command("apt", args...)
+	cmd.Env = noninteractiveEnv()
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -3,6 +3,7 @@ package pkg
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/bashfulrobot/configr/internal/config"
@@ -300,4 +301,191 @@ func contains(s, substr string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+// writeStubCommand writes an executable shell script named name into dir
+func writeStubCommand(t *testing.T, dir, name, script string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("failed to write stub %s: %v", name, err)
+	}
+}
+
+func TestAptManager_isRemoteDebURL(t *testing.T) {
+	logger := log.New(os.Stderr)
+	aptManager := NewAptManager(logger, true)
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"https://example.com/downloads/app_1.0_amd64.deb", true},
+		{"http://example.com/downloads/app.deb", false},
+		{"./local/app.deb", false},
+		{"curl", false},
+	}
+
+	for _, tt := range tests {
+		if result := aptManager.isRemoteDebURL(tt.name); result != tt.expected {
+			t.Errorf("isRemoteDebURL(%s) = %v, expected %v", tt.name, result, tt.expected)
+		}
+	}
+}
+
+func TestAptManager_ResolveRemoteDebEntries_DryRunSkipsDownload(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var output strings.Builder
+	logger := log.New(&output)
+
+	// Nothing listens on port 1, so any download attempt fails
+	url := "https://127.0.0.1:1/app_1.0_amd64.deb"
+	resolved, err := NewAptManager(logger, true).ResolveRemoteDebEntries([]config.PackageEntry{{Name: url}, {Name: "curl"}})
+	if err != nil {
+		t.Fatalf("expected no download during a dry run, got: %v", err)
+	}
+	if len(resolved) != 2 || resolved[0].Name != url || resolved[0].Source != "" {
+		t.Errorf("expected the remote .deb entry to be unchanged, got %+v", resolved)
+	}
+	if !strings.Contains(output.String(), "Would download .deb file") {
+		t.Errorf("expected the download to be reported, got:\n%s", output.String())
+	}
+}
+
+func TestParseDebControlFields(t *testing.T) {
+	fields := parseDebControlFields("Package: code\nVersion: 1.95.0-1731512139\n")
+
+	if fields["Package"] != "code" {
+		t.Errorf("expected Package 'code', got %q", fields["Package"])
+	}
+	if fields["Version"] != "1.95.0-1731512139" {
+		t.Errorf("expected Version '1.95.0-1731512139', got %q", fields["Version"])
+	}
+}
+
+func TestSplitDownloadedDebs(t *testing.T) {
+	packages := []config.PackageEntry{
+		{Name: "git"},
+		{Name: "code", Source: "/cache/code.deb"},
+		{Name: "./local/app.deb"},
+	}
+
+	downloaded, rest := splitDownloadedDebs(packages)
+
+	if len(downloaded) != 1 || downloaded[0].Name != "code" {
+		t.Errorf("expected only code to be a downloaded .deb, got %v", downloaded)
+	}
+	if len(rest) != 2 {
+		t.Errorf("expected 2 remaining packages, got %v", rest)
+	}
+}
+
+func TestAptManager_installDownloadedDebFiles_VersionCheck(t *testing.T) {
+	binDir := t.TempDir()
+	markerPath := filepath.Join(t.TempDir(), "apt-called")
+
+	writeStubCommand(t, binDir, "dpkg-deb", "printf 'Package: code\\nVersion: 1.2.3\\n'\n")
+	writeStubCommand(t, binDir, "dpkg", "echo 'Status: install ok installed'\n")
+	writeStubCommand(t, binDir, "apt", "echo \"$@\" > "+markerPath+"\n")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+	aptManager := NewAptManager(logger, false)
+
+	debFile := filepath.Join(t.TempDir(), "code.deb")
+	if err := os.WriteFile(debFile, []byte("fake deb"), 0644); err != nil {
+		t.Fatalf("failed to write fake deb: %v", err)
+	}
+	packages := []config.PackageEntry{{Name: "code", Source: debFile}}
+
+	t.Run("same version installed is skipped", func(t *testing.T) {
+		writeStubCommand(t, binDir, "dpkg-query", "printf '1.2.3'\n")
+		os.Remove(markerPath)
+
+		if err := aptManager.installDownloadedDebFiles(packages, []string{"-y"}); err != nil {
+			t.Fatalf("installDownloadedDebFiles failed: %v", err)
+		}
+		if _, err := os.Stat(markerPath); !os.IsNotExist(err) {
+			t.Error("apt should not run when the same version is installed")
+		}
+	})
+
+	t.Run("different version is installed", func(t *testing.T) {
+		writeStubCommand(t, binDir, "dpkg-query", "printf '1.0.0'\n")
+		os.Remove(markerPath)
+
+		if err := aptManager.installDownloadedDebFiles(packages, []string{"-y"}); err != nil {
+			t.Fatalf("installDownloadedDebFiles failed: %v", err)
+		}
+		data, err := os.ReadFile(markerPath)
+		if err != nil {
+			t.Fatal("apt should run when a different version is installed")
+		}
+		if !strings.Contains(string(data), debFile) {
+			t.Errorf("expected apt to install %s, got args: %s", debFile, data)
+		}
+	})
+}
+
+func TestParseAptUpgradable(t *testing.T) {
+	output := `Listing...
+firefox/noble-updates 130.0+build2-0ubuntu1 amd64 [upgradable from: 129.0+build1-0ubuntu1]
+libc6/noble-security 2.39-0ubuntu8.3 amd64 [upgradable from: 2.39-0ubuntu8.2]
+
+WARNING: apt does not have a stable CLI interface. Use with caution in scripts.
+`
+	expected := []UpgradeCandidate{
+		{Name: "firefox", CurrentVersion: "129.0+build1-0ubuntu1", NewVersion: "130.0+build2-0ubuntu1"},
+		{Name: "libc6", CurrentVersion: "2.39-0ubuntu8.2", NewVersion: "2.39-0ubuntu8.3"},
+	}
+
+	candidates := parseAptUpgradable(output)
+	if len(candidates) != len(expected) {
+		t.Fatalf("expected %d candidates, got %+v", len(expected), candidates)
+	}
+	for i := range expected {
+		if candidates[i] != expected[i] {
+			t.Errorf("candidate %d: expected %+v, got %+v", i, expected[i], candidates[i])
+		}
+	}
+}
+
+func TestAptManager_ListUpgrades_MarksHeldPackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	binDir := t.TempDir()
+	writeStubCommand(t, binDir, "apt", `echo "Listing..."
+echo "firefox/noble-updates 130.0 amd64 [upgradable from: 129.0]"
+echo "linux-generic/noble-updates 6.8.0.45 amd64 [upgradable from: 6.8.0.41]"
+`)
+	writeStubCommand(t, binDir, "apt-mark", `echo "linux-generic"
+`)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	candidates, err := NewAptManager(logger, false).ListUpgrades()
+	if err != nil {
+		t.Fatalf("ListUpgrades failed: %v", err)
+	}
+	if len(candidates) != 2 || candidates[0].Held || !candidates[1].Held {
+		t.Errorf("expected linux-generic to be held, got %+v", candidates)
+	}
+}
+
+func TestParseAptSearch(t *testing.T) {
+	output := `WARNING: apt does not have a stable CLI interface. Use with caution in scripts.
+
+Sorting...
+Full Text Search...
+ripgrep/oldstable 13.0.0-4+b2 amd64
+  Recursively searches directories for a regex pattern
+
+ripgrep-all/stable,now 0.9.6-1 amd64 [installed]
+  rga: ripgrep, but also search in PDFs, E-Books, Office documents, zip, tar.gz, etc.
+`
+	packages := parseAptSearch(output)
+	if strings.Join(packages, " ") != "ripgrep ripgrep-all" {
+		t.Errorf("unexpected packages: %v", packages)
+	}
+}
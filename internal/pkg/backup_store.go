@@ -0,0 +1,532 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/klauspost/compress/zstd"
+)
+
+// System backups are kept with root's data, so backups of /etc files don't sit in /etc
+const systemBackupDir = "/var/lib/configr/backups"
+
+// backupRun identifies the apply run backups are made in
+var backupRun = fmt.Sprintf("%s-%d", time.Now().Format("20060102-150405"), os.Getpid())
+
+// BackupEntry describes a backup in the backup store index
+type BackupEntry struct {
+	ID         string      `json:"id"`
+	Path       string      `json:"path"`                  // Original path of the backed up file
+	Hash       string      `json:"hash,omitempty"`        // SHA256 of the content, empty for symlinks
+	Mode       os.FileMode `json:"mode"`                  // Original mode
+	Owner      string      `json:"owner,omitempty"`       // Original owner
+	Group      string      `json:"group,omitempty"`       // Original group
+	Size       int64       `json:"size"`                  // Size of the content
+	Time       time.Time   `json:"time"`                  // When the backup was made
+	Run        string      `json:"run"`                   // Apply run the backup was made in
+	LinkTarget string      `json:"link_target,omitempty"` // Target of a backed up symlink
+	Compressed bool        `json:"compressed,omitempty"`  // Whether the stored content is zstd-compressed
+
+	dir string // Store directory the entry is indexed in
+}
+
+// backupIndex is the metadata index of a backup store directory
+type backupIndex struct {
+	Backups []BackupEntry `json:"backups"`
+}
+
+// BackupStore keeps backups of replaced files as deduplicated, content-addressed blobs with a metadata index
+// Backups of system paths are kept in /var/lib/configr/backups, others in the user's data directory
+// When the system directory isn't writable (configr run without root), system paths are backed up to the user's store
+type BackupStore struct {
+	logger    *log.Logger
+	userDir   string
+	systemDir string
+	compress  bool
+
+	systemChecked  bool // Whether systemWritable has been determined
+	systemWritable bool // Whether backups can be written to systemDir
+}
+
+// NewBackupStore creates a backup store in $XDG_DATA_HOME/configr/backups (~/.local/share/configr/backups)
+func NewBackupStore(logger *log.Logger) *BackupStore {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			logger.Warn("Could not determine home directory, using /tmp for backups", "error", err)
+			homeDir = "/tmp"
+		}
+		dataDir = filepath.Join(homeDir, ".local", "share")
+	}
+	return &BackupStore{
+		logger:    logger,
+		userDir:   filepath.Join(dataDir, "configr", "backups"),
+		systemDir: systemBackupDir,
+	}
+}
+
+// NewBackupStoreWithPath creates a backup store that keeps all backups in a custom directory
+func NewBackupStoreWithPath(logger *log.Logger, dir string) *BackupStore {
+	return &BackupStore{
+		logger:    logger,
+		userDir:   dir,
+		systemDir: dir,
+	}
+}
+
+// SetCompression sets whether new backups are stored zstd-compressed
+func (bs *BackupStore) SetCompression(compress bool) {
+	bs.compress = compress
+}
+
+// dirFor returns the store directory backups of path are kept in
+func (bs *BackupStore) dirFor(path string) string {
+	if isSystemPath(path) && bs.systemDirWritable() {
+		return bs.systemDir
+	}
+	return bs.userDir
+}
+
+// systemDirWritable reports whether backups can be written to the system store directory
+// A missing directory is writable when its closest existing parent is
+func (bs *BackupStore) systemDirWritable() bool {
+	if bs.systemDir == bs.userDir {
+		return true
+	}
+	if !bs.systemChecked {
+		bs.systemChecked = true
+		bs.systemWritable = dirWritable(bs.systemDir)
+		if !bs.systemWritable {
+			bs.logger.Debug("System backup directory isn't writable, using the user's backup store", "dir", bs.systemDir, "fallback", bs.userDir)
+		}
+	}
+	return bs.systemWritable
+}
+
+// dirWritable tests write access to dir, or to its closest existing parent, by creating a temporary file
+func dirWritable(dir string) bool {
+	for {
+		if info, err := os.Stat(dir); err == nil {
+			if !info.IsDir() {
+				return false
+			}
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+
+	f, err := os.CreateTemp(dir, ".configr-write-test-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// Save backs up the file at path and returns its index entry; the file itself is left in place
+// Content already in the store isn't stored again
+func (bs *BackupStore) Save(path string) (BackupEntry, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return BackupEntry{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	now := time.Now()
+	entry := BackupEntry{
+		ID:   fmt.Sprintf("%s-%x", now.Format("20060102-150405"), sha256.Sum256([]byte(path+strconv.FormatInt(now.UnixNano(), 10))))[:24],
+		Path: path,
+		Mode: info.Mode(),
+		Time: now,
+		Run:  backupRun,
+		dir:  bs.dirFor(path),
+	}
+	entry.Owner, entry.Group = fileOwnership(info)
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		if entry.LinkTarget, err = os.Readlink(path); err != nil {
+			return BackupEntry{}, fmt.Errorf("failed to read symlink %s: %w", path, err)
+		}
+	} else {
+		if !info.Mode().IsRegular() {
+			return BackupEntry{}, fmt.Errorf("cannot back up %s: not a regular file or symlink", path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return BackupEntry{}, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		entry.Size = int64(len(content))
+		if entry.Hash, entry.Compressed, err = bs.storeObject(entry.dir, content); err != nil {
+			return BackupEntry{}, err
+		}
+	}
+
+	index, err := bs.loadIndex(entry.dir)
+	if err != nil {
+		return BackupEntry{}, err
+	}
+	index.Backups = append(index.Backups, entry)
+	if err := bs.saveIndex(entry.dir, index); err != nil {
+		return BackupEntry{}, err
+	}
+
+	bs.logger.Debug("Stored backup", "id", entry.ID, "path", path, "hash", entry.Hash)
+	return entry, nil
+}
+
+// storeObject stores content by its hash and reports whether it's kept compressed
+func (bs *BackupStore) storeObject(dir string, content []byte) (string, bool, error) {
+	hash := fmt.Sprintf("%x", sha256.Sum256(content))
+	objectPath := filepath.Join(dir, "objects", hash)
+	if _, err := os.Stat(objectPath); err == nil {
+		return hash, false, nil
+	}
+	if _, err := os.Stat(objectPath + ".zst"); err == nil {
+		return hash, true, nil
+	}
+
+	data := content
+	if bs.compress {
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to create zstd encoder: %w", err)
+		}
+		data = encoder.EncodeAll(content, nil)
+		encoder.Close()
+		objectPath += ".zst"
+	}
+
+	// Backed up files may be private, so the store is readable by the owner only
+	if err := os.MkdirAll(filepath.Dir(objectPath), 0700); err != nil {
+		return "", false, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := writeFileAtomic(objectPath, data, 0600); err != nil {
+		return "", false, fmt.Errorf("failed to store backup: %w", err)
+	}
+	return hash, bs.compress, nil
+}
+
+// Content returns the backed up content of an entry
+func (bs *BackupStore) Content(entry BackupEntry) ([]byte, error) {
+	if entry.Hash == "" {
+		return nil, fmt.Errorf("backup %s is a symlink and has no content", entry.ID)
+	}
+
+	objectPath := filepath.Join(entry.dir, "objects", filepath.Base(entry.Hash))
+	if entry.Compressed {
+		objectPath += ".zst"
+	}
+	data, err := os.ReadFile(objectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup %s: %w", entry.ID, err)
+	}
+	if entry.Compressed {
+		decoder, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+		}
+		defer decoder.Close()
+		if data, err = decoder.DecodeAll(data, nil); err != nil {
+			return nil, fmt.Errorf("failed to decompress backup %s: %w", entry.ID, err)
+		}
+	}
+	if fmt.Sprintf("%x", sha256.Sum256(data)) != entry.Hash {
+		return nil, fmt.Errorf("backup %s is corrupted", entry.ID)
+	}
+	return data, nil
+}
+
+// Get returns the index entry of a backup
+func (bs *BackupStore) Get(id string) (BackupEntry, error) {
+	entries, err := bs.List()
+	if err != nil {
+		return BackupEntry{}, err
+	}
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return BackupEntry{}, fmt.Errorf("backup %s not found: %w", id, os.ErrNotExist)
+}
+
+// List returns the backups in the store, oldest first
+func (bs *BackupStore) List() ([]BackupEntry, error) {
+	var entries []BackupEntry
+	for _, dir := range bs.dirs() {
+		index, err := bs.loadIndex(dir)
+		if err != nil {
+			// Users can't read the system index, which only holds backups made as root
+			if os.IsPermission(err) && dir == bs.systemDir {
+				bs.logger.Debug("Skipping unreadable backup index", "dir", dir)
+				continue
+			}
+			return nil, err
+		}
+		entries = append(entries, index.Backups...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return entries, nil
+}
+
+// Restore writes a backup back to dest with its original mode, replacing whatever is there
+// Ownership is restored when running as root; the backup stays in the store
+func (bs *BackupStore) Restore(id, dest string) error {
+	entry, err := bs.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	if entry.LinkTarget != "" {
+		if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove existing file: %w", err)
+		}
+		if err := os.Symlink(entry.LinkTarget, dest); err != nil {
+			return fmt.Errorf("failed to restore symlink: %w", err)
+		}
+		return nil
+	}
+
+	content, err := bs.Content(entry)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(dest, content, entry.Mode.Perm()); err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+	if os.Geteuid() == 0 && entry.Owner != "" {
+		if err := chownByName(dest, entry.Owner, entry.Group); err != nil {
+			bs.logger.Warn("Could not restore ownership", "path", dest, "error", err)
+		}
+	}
+	return nil
+}
+
+// Remove removes backups from the index, and the content no remaining backup refers to
+func (bs *BackupStore) Remove(ids []string) error {
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+
+	for _, dir := range bs.dirs() {
+		index, err := bs.loadIndex(dir)
+		if err != nil {
+			if os.IsPermission(err) && dir == bs.systemDir {
+				continue
+			}
+			return err
+		}
+
+		kept := index.Backups[:0]
+		for _, entry := range index.Backups {
+			if !remove[entry.ID] {
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) == len(index.Backups) {
+			continue
+		}
+		index.Backups = kept
+		if err := bs.saveIndex(dir, index); err != nil {
+			return err
+		}
+		if _, err := bs.pruneObjects(dir, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune removes stored content no backup in the index refers to, such as content stored by a run
+// that failed before updating the index, and returns how many objects were removed
+func (bs *BackupStore) Prune() (int, error) {
+	removed := 0
+	for _, dir := range bs.dirs() {
+		index, err := bs.loadIndex(dir)
+		if err != nil {
+			if os.IsPermission(err) && dir == bs.systemDir {
+				continue
+			}
+			return removed, err
+		}
+		n, err := bs.pruneObjects(dir, index)
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// pruneObjects removes stored content no backup in the index refers to and returns how many were removed
+func (bs *BackupStore) pruneObjects(dir string, index backupIndex) (int, error) {
+	files, err := os.ReadDir(filepath.Join(dir, "objects"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read backup objects: %w", err)
+	}
+
+	referenced := referencedObjects(index)
+	removed := 0
+	for _, file := range files {
+		if referenced[file.Name()] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, "objects", file.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove backup object %s: %w", file.Name(), err)
+		}
+		removed++
+		bs.logger.Debug("Removed unreferenced backup object", "object", file.Name())
+	}
+	return removed, nil
+}
+
+// referencedObjects returns the object file names the backups in an index refer to
+func referencedObjects(index backupIndex) map[string]bool {
+	referenced := make(map[string]bool, len(index.Backups))
+	for _, entry := range index.Backups {
+		if entry.Hash == "" {
+			continue
+		}
+		if entry.Compressed {
+			referenced[entry.Hash+".zst"] = true
+		} else {
+			referenced[entry.Hash] = true
+		}
+	}
+	return referenced
+}
+
+// dirs returns the store directories
+func (bs *BackupStore) dirs() []string {
+	if bs.systemDir == bs.userDir {
+		return []string{bs.userDir}
+	}
+	return []string{bs.userDir, bs.systemDir}
+}
+
+// loadIndex reads the index of a store directory; a missing index is empty
+func (bs *BackupStore) loadIndex(dir string) (backupIndex, error) {
+	var index backupIndex
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return index, fmt.Errorf("failed to read backup index: %w", err)
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return index, fmt.Errorf("failed to parse backup index %s: %w", filepath.Join(dir, "index.json"), err)
+	}
+	for i := range index.Backups {
+		index.Backups[i].dir = dir
+	}
+	return index, nil
+}
+
+// saveIndex writes the index of a store directory
+func (bs *BackupStore) saveIndex(dir string, index backupIndex) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal backup index: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, "index.json"), data, 0600); err != nil {
+		return fmt.Errorf("failed to write backup index: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes a file through a temporary file in the same directory, so it's never partially written
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	partialPath := path + ".partial"
+	if err := os.WriteFile(partialPath, data, mode); err != nil {
+		return err
+	}
+	// WriteFile doesn't change the mode of an existing file, and the umask may have narrowed it
+	if err := os.Chmod(partialPath, mode); err != nil {
+		os.Remove(partialPath)
+		return err
+	}
+	if err := os.Rename(partialPath, path); err != nil {
+		os.Remove(partialPath)
+		return err
+	}
+	return nil
+}
+
+// fileOwnership returns the owner and group names of a file, or their IDs if they have no name
+func fileOwnership(info os.FileInfo) (string, string) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", ""
+	}
+	owner := strconv.FormatUint(uint64(stat.Uid), 10)
+	group := strconv.FormatUint(uint64(stat.Gid), 10)
+	if u, err := user.LookupId(owner); err == nil {
+		owner = u.Username
+	}
+	if g, err := user.LookupGroupId(group); err == nil {
+		group = g.Name
+	}
+	return owner, group
+}
+
+// chownByName changes the ownership of a file to a user and group given by name or ID
+func chownByName(path, owner, group string) error {
+	uid, err := strconv.Atoi(owner)
+	if err != nil {
+		u, err := user.Lookup(owner)
+		if err != nil {
+			return fmt.Errorf("unknown user %s: %w", owner, err)
+		}
+		uid, _ = strconv.Atoi(u.Uid)
+	}
+	gid := -1
+	if group != "" {
+		if gid, err = strconv.Atoi(group); err != nil {
+			g, err := user.LookupGroup(group)
+			if err != nil {
+				return fmt.Errorf("unknown group %s: %w", group, err)
+			}
+			gid, _ = strconv.Atoi(g.Gid)
+		}
+	}
+	return os.Lchown(path, uid, gid)
+}
+
+// isSystemPath reports whether a path lies in a system directory rather than a user's
+func isSystemPath(path string) bool {
+	for _, prefix := range []string{"/etc/", "/usr/", "/opt/", "/var/", "/bin/", "/sbin/", "/lib/", "/lib64/", "/boot/", "/srv/"} {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+func TestBackupStore_SaveRestore(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	store := NewBackupStoreWithPath(logger, filepath.Join(tempDir, "backups"))
+
+	original := filepath.Join(tempDir, "gitconfig")
+	if err := os.WriteFile(original, []byte("[user]\n\tname = configr\n"), 0640); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	link := filepath.Join(tempDir, "link")
+	if err := os.Symlink(original, link); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	first, err := store.Save(original)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	second, err := store.Save(original)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	linked, err := store.Save(link)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Identical content is stored once
+	if first.ID == second.ID || first.Hash != second.Hash {
+		t.Errorf("expected two backups of the same content, got %+v and %+v", first, second)
+	}
+	objects, _ := os.ReadDir(filepath.Join(tempDir, "backups", "objects"))
+	if len(objects) != 1 {
+		t.Errorf("expected one stored object, got %d", len(objects))
+	}
+	if first.Run != backupRun || first.Owner == "" || first.Size != 23 {
+		t.Errorf("unexpected metadata: %+v", first)
+	}
+
+	entries, err := store.List()
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("expected three backups, got %+v (%v)", entries, err)
+	}
+
+	restored := filepath.Join(tempDir, "restored", "gitconfig")
+	if err := store.Restore(first.ID, restored); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if content, _ := os.ReadFile(restored); string(content) != "[user]\n\tname = configr\n" {
+		t.Errorf("unexpected restored content: %q", content)
+	}
+	if info, _ := os.Stat(restored); info.Mode().Perm() != 0640 {
+		t.Errorf("expected mode 0640, got %v", info.Mode().Perm())
+	}
+
+	restoredLink := filepath.Join(tempDir, "restored", "link")
+	if err := store.Restore(linked.ID, restoredLink); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if target, _ := os.Readlink(restoredLink); target != original {
+		t.Errorf("expected restored symlink to %s, got %q", original, target)
+	}
+
+	if err := store.Restore("missing", restored); err == nil {
+		t.Error("expected an error restoring an unknown backup")
+	}
+}
+
+func TestBackupStore_CompressionAndRemove(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	store := NewBackupStoreWithPath(logger, filepath.Join(tempDir, "backups"))
+	store.SetCompression(true)
+
+	content := strings.Repeat("export PATH=$HOME/bin:$PATH\n", 100)
+	original := filepath.Join(tempDir, "profile")
+	if err := os.WriteFile(original, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	entry, err := store.Save(original)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if !entry.Compressed {
+		t.Fatal("expected a compressed backup")
+	}
+	objectPath := filepath.Join(tempDir, "backups", "objects", entry.Hash+".zst")
+	info, err := os.Stat(objectPath)
+	if err != nil {
+		t.Fatalf("expected a compressed object: %v", err)
+	}
+	if info.Size() >= int64(len(content)) {
+		t.Errorf("expected the object to be smaller than the content, got %d bytes", info.Size())
+	}
+	if stored, err := store.Content(entry); err != nil || string(stored) != content {
+		t.Errorf("unexpected content (%v)", err)
+	}
+
+	// Removing the last backup of the content removes the content too
+	if err := store.Remove([]string{entry.ID}); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if entries, _ := store.List(); len(entries) != 0 {
+		t.Errorf("expected no backups, got %+v", entries)
+	}
+	if _, err := os.Stat(objectPath); !os.IsNotExist(err) {
+		t.Error("expected the object to be removed")
+	}
+}
+
+func TestBackupStore_SystemFallback(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	// A system directory below a regular file can't be created, like /var/lib for a user
+	blocker := filepath.Join(tempDir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	store := &BackupStore{
+		logger:    logger,
+		userDir:   filepath.Join(tempDir, "user"),
+		systemDir: filepath.Join(blocker, "backups"),
+	}
+
+	entry, err := store.Save("/etc/hostname")
+	if err != nil {
+		t.Fatalf("expected the backup to fall back to the user store, got %v", err)
+	}
+	if entry.dir != store.userDir {
+		t.Errorf("expected the backup in %s, got %s", store.userDir, entry.dir)
+	}
+	if index, err := store.loadIndex(store.userDir); err != nil || len(index.Backups) != 1 || index.Backups[0].ID != entry.ID {
+		t.Errorf("expected the backup in the user index, got %+v (%v)", index, err)
+	}
+
+	// A writable system directory is used for system paths
+	store = &BackupStore{
+		logger:    logger,
+		userDir:   filepath.Join(tempDir, "user"),
+		systemDir: filepath.Join(tempDir, "system"),
+	}
+	if entry, err = store.Save("/etc/hostname"); err != nil || entry.dir != store.systemDir {
+		t.Errorf("expected the backup in %s, got %q (%v)", store.systemDir, entry.dir, err)
+	}
+}
+
+func TestFileManager_ApplyBackupPolicy(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	store := NewBackupStoreWithPath(logger, filepath.Join(tempDir, "backups"))
+	fm := NewFileManager(logger, false, tempDir)
+	fm.SetBackupStore(store)
+
+	bashrc := filepath.Join(tempDir, ".bashrc")
+	vimrc := filepath.Join(tempDir, ".vimrc")
+	var bashrcBackups []BackupEntry
+	for i := 0; i < 3; i++ {
+		if err := os.WriteFile(bashrc, []byte(strings.Repeat("#\n", i+1)), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		entry, err := store.Save(bashrc)
+		if err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		bashrcBackups = append(bashrcBackups, entry)
+	}
+	if err := os.WriteFile(vimrc, []byte("set nu\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	vimrcBackup, err := store.Save(vimrc)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Make the backups look like they come from earlier runs, one per day
+	index, err := store.loadIndex(store.userDir)
+	if err != nil {
+		t.Fatalf("failed to load index: %v", err)
+	}
+	for i := range index.Backups {
+		index.Backups[i].Run = "earlier"
+		index.Backups[i].Time = time.Now().Add(-time.Duration(len(index.Backups)-i) * 24 * time.Hour)
+	}
+	if err := store.saveIndex(store.userDir, index); err != nil {
+		t.Fatalf("failed to save index: %v", err)
+	}
+
+	// The oldest .bashrc backup is referenced by a managed file, the .vimrc backup by nothing
+	state := &PackageState{
+		Files: []ManagedFile{{Name: "bashrc", Destination: bashrc, BackupID: bashrcBackups[0].ID}},
+	}
+	orphaned, err := fm.FindOrphanedBackups(state)
+	if err != nil {
+		t.Fatalf("FindOrphanedBackups failed: %v", err)
+	}
+	if len(orphaned) != 3 {
+		t.Fatalf("expected three orphaned backups, got %+v", orphaned)
+	}
+
+	policy := config.BackupPolicy{AutoCleanup: true, MaxCount: 1, MaxAge: "84h"}
+	if err := fm.ApplyBackupPolicy(state, policy); err != nil {
+		t.Fatalf("ApplyBackupPolicy failed: %v", err)
+	}
+
+	// max_age would remove the 4 day old backup and max_count all but the newest .bashrc backup,
+	// but the referenced backup is kept
+	backups := fm.ListBackups(state.Files)
+	if len(backups) != 3 || backups[0].ID != bashrcBackups[0].ID || backups[1].ID != bashrcBackups[2].ID || backups[2].ID != vimrcBackup.ID {
+		t.Fatalf("unexpected backups after policy: %+v", backups)
+	}
+
+	policy = config.BackupPolicy{AutoCleanup: true, CleanupOrphaned: true}
+	if err := fm.ApplyBackupPolicy(state, policy); err != nil {
+		t.Fatalf("ApplyBackupPolicy failed: %v", err)
+	}
+	if backups := fm.ListBackups(state.Files); len(backups) != 1 || backups[0].ID != bashrcBackups[0].ID {
+		t.Errorf("expected only the referenced backup to be kept, got %+v", backups)
+	}
+	if objects, _ := os.ReadDir(filepath.Join(tempDir, "backups", "objects")); len(objects) != 1 {
+		t.Errorf("expected one stored object, got %d", len(objects))
+	}
+}
+
+func TestFileManager_ApplyBackupPolicy_KeepsEditBackups(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	store := NewBackupStoreWithPath(logger, filepath.Join(tempDir, "backups"))
+	fm := NewFileManager(logger, false, tempDir)
+	fm.SetBackupStore(store)
+
+	hosts := filepath.Join(tempDir, "hosts")
+	if err := os.WriteFile(hosts, []byte("127.0.0.1 localhost\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	cfg := &config.Config{
+		Lines: map[string]config.LineInFile{
+			"nas": {Path: hosts, Line: "192.168.1.10 nas", Backup: true},
+		},
+	}
+	if err := fm.EnsureLines(cfg.Lines); err != nil {
+		t.Fatalf("EnsureLines failed: %v", err)
+	}
+
+	// Make the backup look like it comes from an earlier run
+	index, err := store.loadIndex(store.userDir)
+	if err != nil || len(index.Backups) != 1 {
+		t.Fatalf("expected one backup, got %+v (%v)", index, err)
+	}
+	index.Backups[0].Run = "earlier"
+	if err := store.saveIndex(store.userDir, index); err != nil {
+		t.Fatalf("failed to save index: %v", err)
+	}
+
+	policy := config.BackupPolicy{AutoCleanup: true, CleanupOrphaned: true}
+	state := &PackageState{EditBackups: extractEditBackups(cfg, nil, fm.EditBackups())}
+	if err := fm.ApplyBackupPolicy(state, policy); err != nil {
+		t.Fatalf("ApplyBackupPolicy failed: %v", err)
+	}
+	if entries, _ := store.List(); len(entries) != 1 || entries[0].ID != index.Backups[0].ID {
+		t.Fatalf("expected the edit backup to be kept, got %+v", entries)
+	}
+
+	// The backup is kept by later runs that don't change the file, and orphaned once no edit targets it
+	state.EditBackups = extractEditBackups(cfg, state.EditBackups, nil)
+	if err := fm.ApplyBackupPolicy(state, policy); err != nil {
+		t.Fatalf("ApplyBackupPolicy failed: %v", err)
+	}
+	if entries, _ := store.List(); len(entries) != 1 {
+		t.Fatalf("expected the edit backup to be kept, got %+v", entries)
+	}
+
+	state.EditBackups = extractEditBackups(&config.Config{}, state.EditBackups, nil)
+	if err := fm.ApplyBackupPolicy(state, policy); err != nil {
+		t.Fatalf("ApplyBackupPolicy failed: %v", err)
+	}
+	if entries, _ := store.List(); len(entries) != 0 {
+		t.Errorf("expected the orphaned edit backup to be removed, got %+v", entries)
+	}
+}
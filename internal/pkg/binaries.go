@@ -21,6 +21,7 @@ type BinaryManager struct {
 	dryRun      bool
 	configDir   string
 	interactive *InteractiveManager
+	backups     *BackupStore
 }
 
 // ManagedBinary represents a binary managed by configr
@@ -28,7 +29,8 @@ type ManagedBinary struct {
 	Name        string `json:"name"`        // Binary identifier from YAML
 	Source      string `json:"source"`      // URL where binary was downloaded from
 	Destination string `json:"destination"` // Where the binary was deployed
-	BackupPath  string `json:"backup_path,omitempty"` // Path to backup file if created
+	BackupPath  string `json:"backup_path,omitempty"` // Path to a sidecar backup made before the backup store
+	BackupID    string `json:"backup_id,omitempty"`   // Backup store entry of the binary it replaced
 }
 
 // NewBinaryManager creates a new BinaryManager instance
@@ -38,9 +40,15 @@ func NewBinaryManager(logger *log.Logger, dryRun bool, configDir string) *Binary
 		dryRun:      dryRun,
 		configDir:   configDir,
 		interactive: NewInteractiveManager(logger),
+		backups:     NewBackupStore(logger),
 	}
 }
 
+// SetBackupStore sets where replaced binaries are backed up
+func (bm *BinaryManager) SetBackupStore(store *BackupStore) {
+	bm.backups = store
+}
+
 // DeployBinaries processes all binaries in the configuration and returns deployed binary info
 func (bm *BinaryManager) DeployBinaries(binaries map[string]config.Binary) ([]ManagedBinary, error) {
 	if len(binaries) == 0 {
@@ -85,7 +93,7 @@ func (bm *BinaryManager) deployBinary(name string, binary config.Binary) (Manage
 	}
 
 	// Handle existing binary (backup if needed, with interactive support)
-	backupPath, err := bm.handleExistingBinary(name, destPath, binary)
+	backupID, err := bm.handleExistingBinary(name, destPath, binary)
 	if err != nil {
 		return ManagedBinary{}, fmt.Errorf("failed to handle existing binary: %w", err)
 	}
@@ -107,7 +115,7 @@ func (bm *BinaryManager) deployBinary(name string, binary config.Binary) (Manage
 		Name:        name,
 		Source:      binary.Source,
 		Destination: destPath,
-		BackupPath:  backupPath,
+		BackupID:    backupID,
 	}, nil
 }
 
@@ -173,7 +181,7 @@ func (bm *BinaryManager) ensureDirectory(dir string) error {
 }
 
 // handleExistingBinary handles existing binaries at the destination, with interactive support
-// Returns the backup path if a backup was created, empty string otherwise
+// Returns the backup ID if a backup was created, empty string otherwise
 func (bm *BinaryManager) handleExistingBinary(name, destPath string, binary config.Binary) (string, error) {
 	if _, err := os.Lstat(destPath); os.IsNotExist(err) {
 		// Binary doesn't exist, nothing to handle
@@ -189,7 +197,7 @@ func (bm *BinaryManager) handleExistingBinary(name, destPath string, binary conf
 	if bm.dryRun {
 		if binary.Backup {
 			bm.logger.Debug("DRY RUN: Would backup existing binary", "path", destPath)
-			return fmt.Sprintf("%s.backup.%s", destPath, time.Now().Format("20060102-150405")), nil
+			return "", nil
 		} else {
 			bm.logger.Debug("DRY RUN: Would remove existing binary", "path", destPath)
 			return "", nil
@@ -236,13 +244,16 @@ func (bm *BinaryManager) handleExistingBinary(name, destPath string, binary conf
 	shouldBackup := binary.Backup
 	
 	if shouldBackup {
-		backupPath := fmt.Sprintf("%s.backup.%s", destPath, time.Now().Format("20060102-150405"))
-		bm.logger.Info("⚠ Backing up existing binary", "from", destPath, "to", backupPath)
-		
-		if err := os.Rename(destPath, backupPath); err != nil {
+		entry, err := bm.backups.Save(destPath)
+		if err != nil {
 			return "", fmt.Errorf("failed to backup binary: %w", err)
 		}
-		return backupPath, nil
+		bm.logger.Info("⚠ Backing up existing binary", "from", destPath, "backup", entry.ID)
+		
+		if err := os.Remove(destPath); err != nil {
+			return "", fmt.Errorf("failed to remove backed up binary: %w", err)
+		}
+		return entry.ID, nil
 	} else {
 		bm.logger.Info("⚠ Removing existing binary", "path", destPath)
 		if err := os.Remove(destPath); err != nil {
@@ -562,9 +573,9 @@ func (bm *BinaryManager) removeBinary(binary ManagedBinary) error {
 	bm.logger.Info("✓ Binary removed", "name", binary.Name, "destination", binary.Destination)
 
 	// If there was a backup, optionally restore it
-	if binary.BackupPath != "" {
+	if binary.BackupID != "" || binary.BackupPath != "" {
 		if err := bm.offerBackupRestore(binary); err != nil {
-			bm.logger.Warn("Could not restore backup", "backup", binary.BackupPath, "error", err)
+			bm.logger.Warn("Could not restore backup", "name", binary.Name, "error", err)
 			// Don't fail the removal operation for backup restoration issues
 		}
 	}
@@ -574,13 +585,22 @@ func (bm *BinaryManager) removeBinary(binary ManagedBinary) error {
 
 // offerBackupRestore handles backup restoration when removing binaries
 func (bm *BinaryManager) offerBackupRestore(binary ManagedBinary) error {
-	// Check if backup still exists
-	if _, err := os.Stat(binary.BackupPath); os.IsNotExist(err) {
-		bm.logger.Debug("Backup binary no longer exists", "backup", binary.BackupPath)
-		return nil
+	backup := binary.BackupID
+	if backup != "" {
+		if _, err := bm.backups.Get(backup); err != nil {
+			bm.logger.Debug("Backup binary no longer exists", "backup", backup, "error", err)
+			return nil
+		}
+	} else {
+		// State written before the backup store refers to a sidecar backup file
+		backup = binary.BackupPath
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			bm.logger.Debug("Backup binary no longer exists", "backup", backup)
+			return nil
+		}
 	}
 
-	bm.logger.Info("📁 Backup available for removed binary", "backup", binary.BackupPath, "original", binary.Destination)
+	bm.logger.Info("📁 Backup available for removed binary", "backup", backup, "original", binary.Destination)
 	
 	// Interactive backup restoration offer
 	if bm.interactive.IsInteractiveMode() {
@@ -594,6 +614,9 @@ func (bm *BinaryManager) offerBackupRestore(binary ManagedBinary) error {
 		}
 		
 		if shouldRestore {
+			if binary.BackupID != "" {
+				return bm.RestoreBackup(binary.BackupID, binary.Destination)
+			}
 			return bm.RestoreFromBackup(binary.BackupPath, binary.Destination)
 		}
 	}
@@ -601,7 +624,24 @@ func (bm *BinaryManager) offerBackupRestore(binary ManagedBinary) error {
 	return nil
 }
 
-// RestoreFromBackup restores a binary from its backup
+// RestoreBackup restores a binary from the backup store, with its original mode and ownership
+func (bm *BinaryManager) RestoreBackup(id, originalDestination string) error {
+	bm.logger.Info("🔄 Restoring binary from backup", "backup", id, "destination", originalDestination)
+
+	if bm.dryRun {
+		bm.logger.Info("DRY RUN: Would restore binary from backup", "backup", id, "destination", originalDestination)
+		return nil
+	}
+
+	if err := bm.backups.Restore(id, originalDestination); err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	bm.logger.Info("✓ Binary restored from backup successfully", "destination", originalDestination)
+	return nil
+}
+
+// RestoreFromBackup restores a binary from a sidecar backup made before the backup store
 func (bm *BinaryManager) RestoreFromBackup(backupPath, originalDestination string) error {
 	bm.logger.Info("🔄 Restoring binary from backup", "backup", backupPath, "destination", originalDestination)
 	
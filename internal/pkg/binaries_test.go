@@ -165,6 +165,7 @@ func TestBinaryManager_handleExistingBinary(t *testing.T) {
 	logger.SetLevel(log.FatalLevel)
 
 	bm := NewBinaryManager(logger, false, "")
+	bm.SetBackupStore(NewBackupStoreWithPath(logger, filepath.Join(tempDir, "backups")))
 
 	destPath := filepath.Join(tempDir, "existing-binary")
 
@@ -180,18 +181,18 @@ func TestBinaryManager_handleExistingBinary(t *testing.T) {
 		Backup:      true,
 	}
 
-	backupPath, err := bm.handleExistingBinary("test", destPath, binary)
+	backupID, err := bm.handleExistingBinary("test", destPath, binary)
 	if err != nil {
 		t.Fatalf("handle existing binary failed: %v", err)
 	}
 
 	// Verify backup was created
-	if backupPath == "" {
-		t.Error("expected backup path to be returned")
+	if backupID == "" {
+		t.Error("expected backup ID to be returned")
 	}
 
-	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
-		t.Error("backup file should exist")
+	if _, err := bm.backups.Get(backupID); err != nil {
+		t.Errorf("backup should exist: %v", err)
 	}
 
 	// Verify original file was removed
@@ -913,6 +914,7 @@ func TestBinaryManager_BackupAndRestore(t *testing.T) {
 	logger.SetLevel(log.FatalLevel)
 
 	bm := NewBinaryManager(logger, false, "")
+	bm.SetBackupStore(NewBackupStoreWithPath(logger, filepath.Join(tempDir, "backups")))
 
 	t.Run("backup without restore", func(t *testing.T) {
 		destPath := filepath.Join(tempDir, "backup-test")
@@ -928,17 +930,21 @@ func TestBinaryManager_BackupAndRestore(t *testing.T) {
 			Backup: true,
 		}
 
-		backupPath, err := bm.handleExistingBinary("test", destPath, binary)
+		backupID, err := bm.handleExistingBinary("test", destPath, binary)
 		if err != nil {
 			t.Fatalf("backup failed: %v", err)
 		}
 
 		// Verify backup was created
-		if backupPath == "" {
-			t.Error("backup path should not be empty")
+		if backupID == "" {
+			t.Fatal("backup ID should not be empty")
 		}
 
-		backupContent, err := os.ReadFile(backupPath)
+		entry, err := bm.backups.Get(backupID)
+		if err != nil {
+			t.Fatalf("failed to find backup: %v", err)
+		}
+		backupContent, err := bm.backups.Content(entry)
 		if err != nil {
 			t.Fatalf("failed to read backup: %v", err)
 		}
@@ -951,6 +957,18 @@ func TestBinaryManager_BackupAndRestore(t *testing.T) {
 		if _, err := os.Stat(destPath); !os.IsNotExist(err) {
 			t.Error("original file should be removed after backup")
 		}
+
+		// Restoring brings back the content and mode
+		if err := bm.RestoreBackup(backupID, destPath); err != nil {
+			t.Fatalf("restore failed: %v", err)
+		}
+		info, err := os.Stat(destPath)
+		if err != nil {
+			t.Fatalf("restored binary missing: %v", err)
+		}
+		if info.Mode().Perm() != 0755 {
+			t.Errorf("expected mode 0755, got %v", info.Mode().Perm())
+		}
 	})
 
 	t.Run("backup with no backup flag", func(t *testing.T) {
@@ -966,13 +984,13 @@ func TestBinaryManager_BackupAndRestore(t *testing.T) {
 			Backup: false,
 		}
 
-		backupPath, err := bm.handleExistingBinary("test", destPath, binary)
+		backupID, err := bm.handleExistingBinary("test", destPath, binary)
 		if err != nil {
 			t.Fatalf("handling existing binary failed: %v", err)
 		}
 
 		// Should not create backup
-		if backupPath != "" {
+		if backupID != "" {
 			t.Error("should not create backup when backup=false")
 		}
 
@@ -0,0 +1,411 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// BrewManager handles Homebrew formulae on Linux (Linuxbrew)
+// Homebrew refuses to run as root, so commands run as the invoking user when configr runs under sudo
+type BrewManager struct {
+	logger *log.Logger
+	dryRun bool
+	prefix string // Homebrew prefix (package_settings.brew.prefix); empty searches PATH and the standard prefixes
+}
+
+// NewBrewManager creates a new Homebrew package manager
+func NewBrewManager(logger *log.Logger, dryRun bool, prefix string) *BrewManager {
+	return &BrewManager{
+		logger: logger,
+		dryRun: dryRun,
+		prefix: prefix,
+	}
+}
+
+// brewFormulaPattern matches a formula name, optionally qualified with its tap: "ripgrep", "python@3.12", "hashicorp/tap/terraform"
+var brewFormulaPattern = regexp.MustCompile(`^(([A-Za-z0-9][A-Za-z0-9_-]*)/([A-Za-z0-9][A-Za-z0-9_-]*)/)?[a-z0-9][a-z0-9._+@-]*$`)
+
+// brewDefaultPrefixes are the standard Homebrew on Linux installation prefixes ("~" is the invoking user's home)
+var brewDefaultPrefixes = []string{"/home/linuxbrew/.linuxbrew", "~/.linuxbrew"}
+
+func init() {
+	RegisterPackageManager(PackageManagerRegistration{
+		Name:    "brew",
+		Command: "brew",
+		Spec: &config.PackageManagerSpec{
+			Name:         "brew",
+			DisplayName:  "Homebrew",
+			DefaultFlags: []string{},
+			ValidateName: func(name string) bool {
+				return brewFormulaPattern.MatchString(name)
+			},
+			NameMessage: "Homebrew formula name contains invalid characters",
+			NameHelp:    "use a formula name like 'ripgrep' or 'python@3.12', or a tap-qualified name like 'hashicorp/tap/terraform'",
+			Settings:    []string{"prefix"},
+		},
+		New: func(opts ManagerOptions) PackageManager {
+			return NewBrewManager(opts.Logger, opts.DryRun, opts.Settings["prefix"])
+		},
+	})
+}
+
+// formulaTap returns the tap of a qualified formula name ("hashicorp/tap/terraform" -> "hashicorp/tap"), or ""
+func formulaTap(name string) string {
+	matches := brewFormulaPattern.FindStringSubmatch(name)
+	if matches == nil || matches[1] == "" {
+		return ""
+	}
+	return matches[2] + "/" + matches[3]
+}
+
+// InstallPackages taps missing taps and installs missing formulae, batching formulae with the same flags
+func (bm *BrewManager) InstallPackages(packages []config.PackageEntry, packageDefaults map[string][]string) error {
+	if len(packages) == 0 {
+		bm.logger.Debug("No Homebrew packages to install")
+		return nil
+	}
+
+	brew, err := bm.brewPath()
+	if err != nil {
+		return err
+	}
+
+	bm.logger.Info("Managing Homebrew packages...", "count", len(packages))
+
+	installed, err := bm.listInstalled(brew)
+	if err != nil {
+		bm.logger.Warn("Failed to list installed formulae, proceeding anyway", "error", err)
+		installed = map[string]string{}
+	}
+
+	var pending []config.PackageEntry
+	for _, pkg := range packages {
+		if version, isInstalled := installed[pkg.Name]; isInstalled {
+			bm.logger.Debug("Formula already installed", "package", pkg.Name, "version", version)
+			continue
+		}
+		pending = append(pending, pkg)
+	}
+
+	if len(pending) == 0 {
+		bm.logger.Info("✓ Homebrew packages processed successfully")
+		return nil
+	}
+
+	if err := bm.tapMissing(brew, pending); err != nil {
+		return err
+	}
+
+	flagGroups := bm.groupPackagesByFlags(pending, packageDefaults)
+
+	flagKeys := make([]string, 0, len(flagGroups))
+	for flagsKey := range flagGroups {
+		flagKeys = append(flagKeys, flagsKey)
+	}
+	sort.Strings(flagKeys)
+
+	for _, flagsKey := range flagKeys {
+		args := []string{brew, "install"}
+		if flagsKey != "" {
+			args = append(args, strings.Split(flagsKey, "|")...)
+		}
+		for _, pkg := range flagGroups[flagsKey] {
+			args = append(args, pkg.Name)
+		}
+
+		if err := bm.run(args); err != nil {
+			return fmt.Errorf("failed to install Homebrew packages: %w", err)
+		}
+		for _, pkg := range flagGroups[flagsKey] {
+			config.Success("Installed formula: %s", pkg.Name)
+		}
+	}
+
+	bm.logger.Info("✓ Homebrew packages processed successfully")
+	return nil
+}
+
+// tapMissing adds the taps of tap-qualified formulae that are not tapped yet
+func (bm *BrewManager) tapMissing(brew string, packages []config.PackageEntry) error {
+	var needed []string
+	seen := make(map[string]bool)
+	for _, pkg := range packages {
+		if tap := formulaTap(pkg.Name); tap != "" && !seen[tap] {
+			seen[tap] = true
+			needed = append(needed, tap)
+		}
+	}
+	if len(needed) == 0 {
+		return nil
+	}
+
+	output, err := bm.command(brew, "tap").Output()
+	if err != nil {
+		return fmt.Errorf("brew tap failed: %w", err)
+	}
+	tapped := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			tapped[strings.ToLower(line)] = true
+		}
+	}
+
+	for _, tap := range needed {
+		if tapped[strings.ToLower(tap)] {
+			continue
+		}
+		if err := bm.run([]string{brew, "tap", tap}); err != nil {
+			return fmt.Errorf("failed to tap %s: %w", tap, err)
+		}
+		config.Success("Tapped: %s", tap)
+	}
+	return nil
+}
+
+// groupPackagesByFlags groups packages with the same resolved flags together
+func (bm *BrewManager) groupPackagesByFlags(packages []config.PackageEntry, packageDefaults map[string][]string) map[string][]config.PackageEntry {
+	flagGroups := make(map[string][]config.PackageEntry)
+
+	for _, pkg := range packages {
+		flags := bm.resolvePackageFlags(pkg, packageDefaults)
+		flagsKey := strings.Join(flags, "|") // Use "|" as separator since it's not valid in flags
+		flagGroups[flagsKey] = append(flagGroups[flagsKey], pkg)
+	}
+
+	return flagGroups
+}
+
+// resolvePackageFlags implements the three-tier flag resolution system
+func (bm *BrewManager) resolvePackageFlags(pkg config.PackageEntry, packageDefaults map[string][]string) []string {
+	// Tier 3: Per-package flags (highest priority)
+	if pkg.Flags != nil {
+		return pkg.Flags
+	}
+
+	// Tier 2: User package defaults
+	if userDefaults, exists := packageDefaults["brew"]; exists {
+		return userDefaults
+	}
+
+	// Tier 1: Internal defaults
+	return config.GetDefaultFlags("brew")
+}
+
+// brewPath locates the brew executable: the configured prefix, then PATH, then the standard prefixes
+func (bm *BrewManager) brewPath() (string, error) {
+	if bm.prefix != "" {
+		brew := filepath.Join(bm.expandHome(bm.prefix), "bin", "brew")
+		if _, err := os.Stat(brew); err != nil {
+			return "", fmt.Errorf("brew not found in configured prefix %s", bm.prefix)
+		}
+		return brew, nil
+	}
+
+	if brew, err := exec.LookPath("brew"); err == nil {
+		return brew, nil
+	}
+
+	for _, prefix := range brewDefaultPrefixes {
+		brew := filepath.Join(bm.expandHome(prefix), "bin", "brew")
+		if _, err := os.Stat(brew); err == nil {
+			return brew, nil
+		}
+	}
+
+	return "", fmt.Errorf("brew command not found - install Homebrew or set package_settings.brew.prefix")
+}
+
+// expandHome expands a leading ~/ to the home directory of the user brew runs as
+func (bm *BrewManager) expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	if invokingUser := brewUser(); invokingUser != nil {
+		return filepath.Join(invokingUser.HomeDir, path[2:])
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(homeDir, path[2:])
+	}
+	return path
+}
+
+// brewUser returns the user that invoked configr through sudo, or nil when not running as root via sudo
+func brewUser() *user.User {
+	if os.Geteuid() != 0 {
+		return nil
+	}
+	sudoUser := os.Getenv("SUDO_USER")
+	if sudoUser == "" || sudoUser == "root" {
+		return nil
+	}
+	invokingUser, err := user.Lookup(sudoUser)
+	if err != nil {
+		return nil
+	}
+	return invokingUser
+}
+
+// command builds a brew command that runs as the invoking user when configr runs as root via sudo
+func (bm *BrewManager) command(name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+
+	invokingUser := brewUser()
+	if invokingUser == nil {
+		return cmd
+	}
+
+	uid, uidErr := strconv.ParseUint(invokingUser.Uid, 10, 32)
+	gid, gidErr := strconv.ParseUint(invokingUser.Gid, 10, 32)
+	if uidErr != nil || gidErr != nil {
+		return cmd
+	}
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)},
+	}
+	cmd.Env = append(os.Environ(),
+		"HOME="+invokingUser.HomeDir,
+		"USER="+invokingUser.Username,
+		"LOGNAME="+invokingUser.Username,
+	)
+	cmd.Dir = invokingUser.HomeDir
+	return cmd
+}
+
+// run executes a brew command (or logs it in dry-run mode)
+func (bm *BrewManager) run(args []string) error {
+	if bm.dryRun {
+		bm.logger.Info("  [DRY RUN] Would run:", "command", strings.Join(args, " "))
+		return nil
+	}
+
+	output, err := bm.command(args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		bm.logger.Error("Command failed", "command", strings.Join(args, " "), "error", err, "output", string(output))
+		return fmt.Errorf("brew %s failed: %w", args[1], err)
+	}
+
+	bm.logger.Debug("Command completed", "command", strings.Join(args, " "), "output", string(output))
+	return nil
+}
+
+// listInstalled returns installed formulae keyed by both short and tap-qualified names
+func (bm *BrewManager) listInstalled(brew string) (map[string]string, error) {
+	output, err := bm.command(brew, "info", "--json=v2", "--installed").Output()
+	if err != nil {
+		return nil, fmt.Errorf("brew info failed: %w", err)
+	}
+	return parseBrewInfoJSON(output)
+}
+
+// parseBrewInfoJSON parses `brew info --json=v2 --installed` output
+func parseBrewInfoJSON(data []byte) (map[string]string, error) {
+	var info struct {
+		Formulae []struct {
+			Name      string `json:"name"`
+			FullName  string `json:"full_name"`
+			Installed []struct {
+				Version string `json:"version"`
+			} `json:"installed"`
+		} `json:"formulae"`
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse brew info output: %w", err)
+	}
+
+	installed := make(map[string]string)
+	for _, formula := range info.Formulae {
+		if len(formula.Installed) == 0 {
+			continue
+		}
+		version := formula.Installed[len(formula.Installed)-1].Version
+		installed[formula.Name] = version
+		if formula.FullName != "" {
+			installed[formula.FullName] = version
+		}
+	}
+	return installed, nil
+}
+
+// isPackageInstalled checks if a formula is installed
+func (bm *BrewManager) isPackageInstalled(packageName string) (bool, error) {
+	brew, err := bm.brewPath()
+	if err != nil {
+		return false, err
+	}
+	installed, err := bm.listInstalled(brew)
+	if err != nil {
+		return false, err
+	}
+	_, isInstalled := installed[packageName]
+	return isInstalled, nil
+}
+
+// isPackageAvailable checks if a formula exists (`brew info` fails for unknown formulae)
+func (bm *BrewManager) isPackageAvailable(packageName string) (bool, error) {
+	brew, err := bm.brewPath()
+	if err != nil {
+		return false, nil
+	}
+	if err := bm.command(brew, "info", "--json=v2", packageName).Run(); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// RemovePackages uninstalls formulae that are no longer in the configuration
+func (bm *BrewManager) RemovePackages(packagesToRemove []string) error {
+	if len(packagesToRemove) == 0 {
+		return nil
+	}
+
+	brew, err := bm.brewPath()
+	if err != nil {
+		return err
+	}
+
+	bm.logger.Info("Removing Homebrew packages no longer in configuration", "packages", packagesToRemove)
+
+	installed, err := bm.listInstalled(brew)
+	if err != nil {
+		bm.logger.Warn("Could not list installed formulae, attempting removal anyway", "error", err)
+		installed = nil
+	}
+
+	args := []string{brew, "uninstall"}
+	var removed []string
+	for _, pkg := range packagesToRemove {
+		if installed != nil {
+			if _, isInstalled := installed[pkg]; !isInstalled {
+				bm.logger.Debug("Formula not installed, skipping removal", "package", pkg)
+				continue
+			}
+		}
+		removed = append(removed, pkg)
+	}
+
+	if len(removed) == 0 {
+		return nil
+	}
+
+	if err := bm.run(append(args, removed...)); err != nil {
+		return fmt.Errorf("failed to remove Homebrew packages: %w", err)
+	}
+	for _, pkg := range removed {
+		config.Success("Removed formula: %s", pkg)
+	}
+
+	return nil
+}
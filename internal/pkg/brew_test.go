@@ -0,0 +1,130 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+const stubBrewInfoJSON = `{
+  "formulae": [
+    {"name": "ripgrep", "full_name": "ripgrep", "installed": [{"version": "14.1.0"}]},
+    {"name": "terraform", "full_name": "hashicorp/tap/terraform", "installed": [{"version": "1.8.0"}]}
+  ],
+  "casks": []
+}`
+
+// setupStubBrew creates a Homebrew prefix with a stub brew executable that records every install, tap and uninstall
+// Returns the prefix and the command log path
+func setupStubBrew(t *testing.T) (string, string) {
+	t.Helper()
+	prefix := t.TempDir()
+	binDir := filepath.Join(prefix, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("failed to create brew prefix: %v", err)
+	}
+	logPath := filepath.Join(t.TempDir(), "commands.log")
+
+	infoPath := filepath.Join(prefix, "info.json")
+	if err := os.WriteFile(infoPath, []byte(stubBrewInfoJSON), 0644); err != nil {
+		t.Fatalf("failed to write brew info output: %v", err)
+	}
+
+	writeStubCommand(t, binDir, "brew", `case "$1" in
+  info) cat `+infoPath+` ;;
+  tap) if [ -z "$2" ]; then echo "hashicorp/tap"; else echo "brew $@" >> `+logPath+`; fi ;;
+  *) echo "brew $@" >> `+logPath+` ;;
+esac
+`)
+	// Commands must not be redirected to another user in tests
+	t.Setenv("SUDO_USER", "")
+
+	return prefix, logPath
+}
+
+func TestFormulaTap(t *testing.T) {
+	tests := map[string]string{
+		"ripgrep":                 "",
+		"python@3.12":             "",
+		"hashicorp/tap/terraform": "hashicorp/tap",
+		"Homebrew/cask/foo":       "Homebrew/cask",
+	}
+
+	for name, expected := range tests {
+		if got := formulaTap(name); got != expected {
+			t.Errorf("formulaTap(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestParseBrewInfoJSON(t *testing.T) {
+	installed, err := parseBrewInfoJSON([]byte(stubBrewInfoJSON))
+	if err != nil {
+		t.Fatalf("parseBrewInfoJSON failed: %v", err)
+	}
+
+	for _, name := range []string{"ripgrep", "terraform", "hashicorp/tap/terraform"} {
+		if _, ok := installed[name]; !ok {
+			t.Errorf("expected %s to be installed, got %v", name, installed)
+		}
+	}
+}
+
+func TestBrewManager_InstallPackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	prefix, logPath := setupStubBrew(t)
+
+	packages := []config.PackageEntry{
+		{Name: "ripgrep"},                              // installed
+		{Name: "hashicorp/tap/terraform"},              // installed from a tap
+		{Name: "fd"},                                   // missing
+		{Name: "jandedobbeleer/oh-my-posh/oh-my-posh"}, // missing, untapped
+		{Name: "neovim", Flags: []string{"--HEAD"}},    // missing, own flags
+	}
+
+	if err := NewBrewManager(logger, false, prefix).InstallPackages(packages, nil); err != nil {
+		t.Fatalf("InstallPackages failed: %v", err)
+	}
+
+	expected := []string{
+		"brew tap jandedobbeleer/oh-my-posh",
+		"brew install fd jandedobbeleer/oh-my-posh/oh-my-posh",
+		"brew install --HEAD neovim",
+	}
+	commands := readCommandLog(t, logPath)
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected commands %v, got %v", expected, commands)
+	}
+}
+
+func TestBrewManager_RemovePackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	prefix, logPath := setupStubBrew(t)
+
+	if err := NewBrewManager(logger, false, prefix).RemovePackages([]string{"hashicorp/tap/terraform", "not-installed"}); err != nil {
+		t.Fatalf("RemovePackages failed: %v", err)
+	}
+
+	expected := []string{"brew uninstall hashicorp/tap/terraform"}
+	commands := readCommandLog(t, logPath)
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected commands %v, got %v", expected, commands)
+	}
+}
+
+func TestBrewManager_MissingPrefix(t *testing.T) {
+	logger := log.New(os.Stderr)
+
+	err := NewBrewManager(logger, false, filepath.Join(t.TempDir(), "missing")).InstallPackages([]config.PackageEntry{{Name: "fd"}}, nil)
+	if err == nil || !strings.Contains(err.Error(), "configured prefix") {
+		t.Errorf("expected missing prefix error, got %v", err)
+	}
+}
@@ -230,6 +230,66 @@ func (cm *CacheManager) SaveSystemStateCache(cache *SystemStateCache) error {
 	return nil
 }
 
+// packageAvailabilityTTL is how long online package availability results are reused
+const packageAvailabilityTTL = 24 * time.Hour
+
+// PackageAvailabilityCache caches online package availability checks (validate --online)
+type PackageAvailabilityCache struct {
+	Packages map[string]PackageAvailability `json:"packages"` // Keyed by manager, sources and package name
+	Version  string                         `json:"version"`
+}
+
+// LoadPackageAvailabilityCache loads cached availability results, dropping entries older than a day
+// A missing or unreadable cache yields an empty cache
+func (cm *CacheManager) LoadPackageAvailabilityCache() *PackageAvailabilityCache {
+	cache := &PackageAvailabilityCache{Packages: make(map[string]PackageAvailability)}
+	
+	data, err := os.ReadFile(filepath.Join(cm.cacheDir, "package_availability.json"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			cm.logger.Debug("Could not read package availability cache", "error", err)
+		}
+		return cache
+	}
+	
+	var cached PackageAvailabilityCache
+	if err := json.Unmarshal(data, &cached); err != nil {
+		cm.logger.Debug("Could not parse package availability cache", "error", err)
+		return cache
+	}
+	
+	for key, entry := range cached.Packages {
+		if time.Since(entry.CheckedAt) <= packageAvailabilityTTL {
+			cache.Packages[key] = entry
+		}
+	}
+	
+	cm.logger.Debug("Loaded package availability cache", "entries", len(cache.Packages))
+	return cache
+}
+
+// SavePackageAvailabilityCache saves availability results to cache
+func (cm *CacheManager) SavePackageAvailabilityCache(cache *PackageAvailabilityCache) error {
+	if err := os.MkdirAll(cm.cacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	
+	cache.Version = "1.0"
+	
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal package availability cache: %w", err)
+	}
+	
+	cachePath := filepath.Join(cm.cacheDir, "package_availability.json")
+	if err := os.WriteFile(cachePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write package availability cache: %w", err)
+	}
+	
+	cm.logger.Debug("Package availability cache saved successfully")
+	return nil
+}
+
 // ClearCache removes all cached data
 func (cm *CacheManager) ClearCache() error {
 	cm.logger.Info("Clearing all cache data", "cache_dir", cm.cacheDir)
@@ -128,7 +128,7 @@ func BenchmarkCacheOverhead(b *testing.B) {
 			}
 			
 			// Simulate using the configuration
-			_ = len(cached.Config.Packages.Apt)
+			_ = len(cached.Config.Packages.Get("apt"))
 		}
 	})
 
@@ -157,30 +157,32 @@ func createLargeTestConfig() *config.Config {
 	cfg := &config.Config{
 		Version: "1.0",
 		Packages: config.PackageManagement{
-			Apt: make([]config.PackageEntry, 100),
-			Flatpak: make([]config.PackageEntry, 50),
-			Snap: make([]config.PackageEntry, 25),
+			Managers: map[string][]config.PackageEntry{
+				"apt":     make([]config.PackageEntry, 100),
+				"flatpak": make([]config.PackageEntry, 50),
+				"snap":    make([]config.PackageEntry, 25),
+			},
 		},
 		Files: make(map[string]config.File),
 	}
 
 	// Add APT packages
 	for i := 0; i < 100; i++ {
-		cfg.Packages.Apt[i] = config.PackageEntry{
+		cfg.Packages.Get("apt")[i] = config.PackageEntry{
 			Name: generatePackageName("apt-pkg", i),
 		}
 	}
 
 	// Add Flatpak packages
 	for i := 0; i < 50; i++ {
-		cfg.Packages.Flatpak[i] = config.PackageEntry{
+		cfg.Packages.Get("flatpak")[i] = config.PackageEntry{
 			Name: generateFlatpakName("com.example.App", i),
 		}
 	}
 
 	// Add Snap packages
 	for i := 0; i < 25; i++ {
-		cfg.Packages.Snap[i] = config.PackageEntry{
+		cfg.Packages.Get("snap")[i] = config.PackageEntry{
 			Name: generatePackageName("snap-pkg", i),
 		}
 	}
@@ -448,9 +450,11 @@ func createComplexTestConfig() *config.Config {
 			},
 		},
 		Packages: config.PackageManagement{
-			Apt: make([]config.PackageEntry, 200),
-			Flatpak: make([]config.PackageEntry, 100),
-			Snap: make([]config.PackageEntry, 50),
+			Managers: map[string][]config.PackageEntry{
+				"apt":     make([]config.PackageEntry, 200),
+				"flatpak": make([]config.PackageEntry, 100),
+				"snap":    make([]config.PackageEntry, 50),
+			},
 		},
 		Files: make(map[string]config.File),
 		DConf: config.DConfConfig{
@@ -460,21 +464,21 @@ func createComplexTestConfig() *config.Config {
 	
 	// Add many packages with various flag configurations
 	for i := 0; i < 200; i++ {
-		cfg.Packages.Apt[i] = config.PackageEntry{
+		cfg.Packages.Get("apt")[i] = config.PackageEntry{
 			Name: generatePackageName("apt-pkg", i),
 			Flags: []string{"-y"},
 		}
 	}
 	
 	for i := 0; i < 100; i++ {
-		cfg.Packages.Flatpak[i] = config.PackageEntry{
+		cfg.Packages.Get("flatpak")[i] = config.PackageEntry{
 			Name: generateFlatpakName("com.example.App", i),
 			Flags: []string{"--user"},
 		}
 	}
 	
 	for i := 0; i < 50; i++ {
-		cfg.Packages.Snap[i] = config.PackageEntry{
+		cfg.Packages.Get("snap")[i] = config.PackageEntry{
 			Name: generatePackageName("snap-pkg", i),
 			Flags: []string{"--classic"},
 		}
@@ -23,9 +23,11 @@ func TestCacheManager_ConfigCache(t *testing.T) {
 	cfg := &config.Config{
 		Version: "1.0",
 		Packages: config.PackageManagement{
-			Apt: []config.PackageEntry{
-				{Name: "vim"},
-				{Name: "git"},
+			Managers: map[string][]config.PackageEntry{
+				"apt": {
+					{Name: "vim"},
+					{Name: "git"},
+				},
 			},
 		},
 	}
@@ -58,8 +60,8 @@ func TestCacheManager_ConfigCache(t *testing.T) {
 		t.Errorf("Version mismatch: expected %s, got %s", cfg.Version, cached.Config.Version)
 	}
 
-	if len(cached.Config.Packages.Apt) != len(cfg.Packages.Apt) {
-		t.Errorf("APT packages mismatch: expected %d, got %d", len(cfg.Packages.Apt), len(cached.Config.Packages.Apt))
+	if len(cached.Config.Packages.Get("apt")) != len(cfg.Packages.Get("apt")) {
+		t.Errorf("APT packages mismatch: expected %d, got %d", len(cfg.Packages.Get("apt")), len(cached.Config.Packages.Get("apt")))
 	}
 
 	// Verify cache metadata
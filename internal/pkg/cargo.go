@@ -0,0 +1,276 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// CargoManager handles Rust binaries installed with cargo install
+type CargoManager struct {
+	logger *log.Logger
+	dryRun bool
+}
+
+// NewCargoManager creates a new cargo package manager
+func NewCargoManager(logger *log.Logger, dryRun bool) *CargoManager {
+	return &CargoManager{
+		logger: logger,
+		dryRun: dryRun,
+	}
+}
+
+// cargoCratePattern matches a crate name with an optional version requirement: "ripgrep", "ripgrep@14.1.0", "bat@^0.24"
+var cargoCratePattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_-]*)(@([=^~<>]*[0-9][0-9A-Za-z.+*-]*))?$`)
+
+// cargoExactVersionPattern matches a version that cargo install treats as an exact pin
+var cargoExactVersionPattern = regexp.MustCompile(`^=?([0-9]+\.[0-9]+\.[0-9]+([-+][0-9A-Za-z.+-]+)?)$`)
+
+func init() {
+	RegisterPackageManager(PackageManagerRegistration{
+		Name:    "cargo",
+		Command: "cargo",
+		Spec: &config.PackageManagerSpec{
+			Name:         "cargo",
+			DisplayName:  "Cargo",
+			DefaultFlags: []string{"--locked"},
+			ValidateName: func(name string) bool {
+				return cargoCratePattern.MatchString(name)
+			},
+			NameMessage: "cargo package must be a crate name with an optional @version",
+			NameHelp:    "use a crates.io name like 'ripgrep' or 'ripgrep@14.1.0'",
+		},
+		New: func(opts ManagerOptions) PackageManager {
+			return NewCargoManager(opts.Logger, opts.DryRun)
+		},
+	})
+}
+
+// crateName returns the crate name of a "crate@version" spec
+func crateName(spec string) string {
+	if matches := cargoCratePattern.FindStringSubmatch(spec); matches != nil {
+		return matches[1]
+	}
+	return spec
+}
+
+// cratePinnedVersion returns the exact version of a "crate@version" spec, or "" if the version is not pinned
+func cratePinnedVersion(spec string) string {
+	matches := cargoCratePattern.FindStringSubmatch(spec)
+	if matches == nil {
+		return ""
+	}
+	if version := cargoExactVersionPattern.FindStringSubmatch(matches[3]); version != nil {
+		return version[1]
+	}
+	return ""
+}
+
+// ResolveEntries splits version specs so state tracks plain crate names
+// "ripgrep@14.1.0" becomes Name "ripgrep" with Source "ripgrep@14.1.0"
+func (cm *CargoManager) ResolveEntries(packages []config.PackageEntry, configDir string) ([]config.PackageEntry, error) {
+	resolved := make([]config.PackageEntry, len(packages))
+	for i, pkg := range packages {
+		if name := crateName(pkg.Name); pkg.Source == "" && name != pkg.Name {
+			pkg.Source = pkg.Name
+			pkg.Name = name
+		}
+		resolved[i] = pkg
+	}
+	return resolved, nil
+}
+
+// InstallPackages installs crates with cargo install
+// Packages are skipped with a warning when cargo is not installed
+func (cm *CargoManager) InstallPackages(packages []config.PackageEntry, packageDefaults map[string][]string) error {
+	if len(packages) == 0 {
+		cm.logger.Debug("No cargo packages to install")
+		return nil
+	}
+
+	if _, err := exec.LookPath("cargo"); err != nil {
+		cm.logger.Warn("cargo command not found - skipping cargo packages", "count", len(packages))
+		return nil
+	}
+
+	cm.logger.Info("Managing cargo packages...", "count", len(packages))
+
+	installed, err := cm.installedCrates()
+	if err != nil {
+		cm.logger.Warn("Failed to read installed crates, proceeding anyway", "error", err)
+		installed = map[string]string{}
+	}
+
+	for _, pkg := range packages {
+		spec := pkg.Name
+		if pkg.Source != "" {
+			spec = pkg.Source
+		}
+		name := crateName(spec)
+		pinned := cratePinnedVersion(spec)
+
+		if version, isInstalled := installed[name]; isInstalled {
+			if pinned == "" || pinned == version {
+				cm.logger.Debug("Crate already installed", "package", name, "version", version)
+				continue
+			}
+			cm.logger.Info("Reinstalling crate with pinned version", "package", name, "installed", version, "pinned", pinned)
+		}
+
+		args := append([]string{"cargo", "install"}, cm.resolvePackageFlags(pkg, packageDefaults)...)
+		args = append(args, spec)
+		if err := cm.run(args); err != nil {
+			return fmt.Errorf("failed to install cargo package '%s': %w", name, err)
+		}
+		config.Success("Installed crate: %s", name)
+	}
+
+	cm.logger.Info("✓ cargo packages processed successfully")
+	return nil
+}
+
+// resolvePackageFlags implements the three-tier flag resolution system
+func (cm *CargoManager) resolvePackageFlags(pkg config.PackageEntry, packageDefaults map[string][]string) []string {
+	// Tier 3: Per-package flags (highest priority)
+	if pkg.Flags != nil {
+		return pkg.Flags
+	}
+
+	// Tier 2: User package defaults
+	if userDefaults, exists := packageDefaults["cargo"]; exists {
+		return userDefaults
+	}
+
+	// Tier 1: Internal defaults
+	return config.GetDefaultFlags("cargo")
+}
+
+// run executes a cargo command (or logs it in dry-run mode)
+func (cm *CargoManager) run(args []string) error {
+	if cm.dryRun {
+		cm.logger.Info("  [DRY RUN] Would run:", "command", strings.Join(args, " "))
+		return nil
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		cm.logger.Error("Command failed", "command", strings.Join(args, " "), "error", err, "output", string(output))
+		return fmt.Errorf("%s failed: %w", strings.Join(args[:2], " "), err)
+	}
+
+	cm.logger.Debug("Command completed", "command", strings.Join(args, " "), "output", string(output))
+	return nil
+}
+
+// cratesFilePath returns the path of cargo's install tracking file ($CARGO_HOME/.crates2.json)
+func cratesFilePath() (string, error) {
+	if cargoHome := os.Getenv("CARGO_HOME"); cargoHome != "" {
+		return filepath.Join(cargoHome, ".crates2.json"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".cargo", ".crates2.json"), nil
+}
+
+// installedCrates returns the installed crates and their versions from .crates2.json
+func (cm *CargoManager) installedCrates() (map[string]string, error) {
+	path, err := cratesFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return parseCrates2JSON(data)
+}
+
+// parseCrates2JSON parses cargo's .crates2.json, whose install keys look like
+// "ripgrep 14.1.0 (registry+https://github.com/rust-lang/crates.io-index)"
+func parseCrates2JSON(data []byte) (map[string]string, error) {
+	var crates struct {
+		Installs map[string]json.RawMessage `json:"installs"`
+	}
+	if err := json.Unmarshal(data, &crates); err != nil {
+		return nil, fmt.Errorf("failed to parse .crates2.json: %w", err)
+	}
+
+	installed := make(map[string]string, len(crates.Installs))
+	for key := range crates.Installs {
+		fields := strings.Fields(key)
+		if len(fields) < 2 {
+			continue
+		}
+		installed[fields[0]] = fields[1]
+	}
+	return installed, nil
+}
+
+// isPackageInstalled checks if a crate is installed
+func (cm *CargoManager) isPackageInstalled(packageName string) (bool, error) {
+	installed, err := cm.installedCrates()
+	if err != nil {
+		return false, err
+	}
+	_, isInstalled := installed[crateName(packageName)]
+	return isInstalled, nil
+}
+
+// isPackageAvailable reports whether a crate can be installed
+// crates.io availability is only known at install time, so any valid crate spec is considered available
+func (cm *CargoManager) isPackageAvailable(packageName string) (bool, error) {
+	return cargoCratePattern.MatchString(packageName), nil
+}
+
+// RemovePackages uninstalls crates (and their binaries) that are no longer in the configuration
+func (cm *CargoManager) RemovePackages(packagesToRemove []string) error {
+	if len(packagesToRemove) == 0 {
+		return nil
+	}
+
+	if _, err := exec.LookPath("cargo"); err != nil {
+		cm.logger.Warn("cargo command not found - cannot remove cargo packages", "packages", packagesToRemove)
+		return nil
+	}
+
+	cm.logger.Info("Removing cargo packages no longer in configuration", "packages", packagesToRemove)
+
+	installed, err := cm.installedCrates()
+	if err != nil {
+		cm.logger.Warn("Could not read installed crates, attempting removal anyway", "error", err)
+		installed = nil
+	}
+
+	for _, pkg := range packagesToRemove {
+		name := crateName(pkg)
+		if installed != nil {
+			if _, isInstalled := installed[name]; !isInstalled {
+				cm.logger.Debug("Crate not installed, skipping removal", "package", name)
+				continue
+			}
+		}
+
+		if err := cm.run([]string{"cargo", "uninstall", name}); err != nil {
+			return fmt.Errorf("failed to remove cargo package %s: %w", name, err)
+		}
+		config.Success("Removed crate: %s", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+const stubCrates2JSON = `{
+  "installs": {
+    "ripgrep 14.1.0 (registry+https://github.com/rust-lang/crates.io-index)": {"bins": ["rg"]},
+    "bat 0.24.0 (registry+https://github.com/rust-lang/crates.io-index)": {"bins": ["bat"]}
+  }
+}`
+
+// setupStubCargo installs a stub cargo executable and a CARGO_HOME with installed crates
+func setupStubCargo(t *testing.T) string {
+	t.Helper()
+	binDir := t.TempDir()
+	cargoHome := t.TempDir()
+	logPath := filepath.Join(t.TempDir(), "commands.log")
+
+	if err := os.WriteFile(filepath.Join(cargoHome, ".crates2.json"), []byte(stubCrates2JSON), 0644); err != nil {
+		t.Fatalf("failed to write .crates2.json: %v", err)
+	}
+
+	writeStubCommand(t, binDir, "cargo", "echo \"cargo $@\" >> "+logPath+"\n")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("CARGO_HOME", cargoHome)
+
+	return logPath
+}
+
+func TestCrateSpecParsing(t *testing.T) {
+	tests := []struct {
+		spec   string
+		name   string
+		pinned string
+	}{
+		{"ripgrep", "ripgrep", ""},
+		{"ripgrep@14.1.0", "ripgrep", "14.1.0"},
+		{"bat@=0.24.0", "bat", "0.24.0"},
+		{"bat@^0.24", "bat", ""},
+		{"cargo-edit@0.12.2-rc.1", "cargo-edit", "0.12.2-rc.1"},
+	}
+
+	for _, tt := range tests {
+		if got := crateName(tt.spec); got != tt.name {
+			t.Errorf("crateName(%q) = %q, expected %q", tt.spec, got, tt.name)
+		}
+		if got := cratePinnedVersion(tt.spec); got != tt.pinned {
+			t.Errorf("cratePinnedVersion(%q) = %q, expected %q", tt.spec, got, tt.pinned)
+		}
+	}
+}
+
+func TestCargoManager_InstallPackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	tests := []struct {
+		name     string
+		entry    config.PackageEntry
+		expected []string
+	}{
+		{"installed crate is skipped", config.PackageEntry{Name: "ripgrep"}, nil},
+		{"matching pin is skipped", config.PackageEntry{Name: "ripgrep", Source: "ripgrep@14.1.0"}, nil},
+		{"pin mismatch is reinstalled", config.PackageEntry{Name: "bat", Source: "bat@0.23.0"}, []string{"cargo install --locked bat@0.23.0"}},
+		{"new crate is installed with flags", config.PackageEntry{Name: "zoxide", Flags: []string{"--features", "nix"}}, []string{"cargo install --features nix zoxide"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logPath := setupStubCargo(t)
+
+			if err := NewCargoManager(logger, false).InstallPackages([]config.PackageEntry{tt.entry}, nil); err != nil {
+				t.Fatalf("InstallPackages failed: %v", err)
+			}
+
+			commands := readCommandLog(t, logPath)
+			if strings.Join(commands, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected commands %v, got %v", tt.expected, commands)
+			}
+		})
+	}
+}
+
+func TestCargoManager_MissingToolchain(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	t.Setenv("PATH", t.TempDir())
+
+	cm := NewCargoManager(logger, false)
+	if err := cm.InstallPackages([]config.PackageEntry{{Name: "ripgrep"}}, nil); err != nil {
+		t.Errorf("expected missing cargo to be skipped, got %v", err)
+	}
+	if err := cm.RemovePackages([]string{"ripgrep"}); err != nil {
+		t.Errorf("expected missing cargo to be skipped on removal, got %v", err)
+	}
+}
+
+func TestCargoManager_RemovePackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	logPath := setupStubCargo(t)
+
+	if err := NewCargoManager(logger, false).RemovePackages([]string{"ripgrep", "not-installed"}); err != nil {
+		t.Fatalf("RemovePackages failed: %v", err)
+	}
+
+	expected := []string{"cargo uninstall ripgrep"}
+	commands := readCommandLog(t, logPath)
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected commands %v, got %v", expected, commands)
+	}
+}
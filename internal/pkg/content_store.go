@@ -0,0 +1,110 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+)
+
+// ContentStore keeps the content of deployed copies, addressed by their SHA256
+// It's the common ancestor for merging local edits of a copied file with a new version of its source
+type ContentStore struct {
+	logger *log.Logger
+	dir    string
+}
+
+// NewContentStore creates a content store in ~/.config/configr/content
+func NewContentStore(logger *log.Logger) *ContentStore {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		logger.Warn("Could not determine home directory, using /tmp for deployed content", "error", err)
+		homeDir = "/tmp"
+	}
+	return NewContentStoreWithPath(logger, filepath.Join(homeDir, ".config", "configr", "content"))
+}
+
+// NewContentStoreWithPath creates a content store in a custom directory
+func NewContentStoreWithPath(logger *log.Logger, dir string) *ContentStore {
+	return &ContentStore{
+		logger: logger,
+		dir:    dir,
+	}
+}
+
+// Save stores the content of a file and returns its hash
+func (cs *ContentStore) Save(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return cs.Store(content)
+}
+
+// Store stores content and returns its hash; content that is already stored isn't written again
+func (cs *ContentStore) Store(content []byte) (string, error) {
+	hash := fmt.Sprintf("%x", sha256.Sum256(content))
+	path := filepath.Join(cs.dir, hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+
+	// Deployed files may be private, so the store is readable by the owner only
+	if err := os.MkdirAll(cs.dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create content directory: %w", err)
+	}
+	partialPath := path + ".partial"
+	if err := os.WriteFile(partialPath, content, 0600); err != nil {
+		return "", fmt.Errorf("failed to store content: %w", err)
+	}
+	if err := os.Rename(partialPath, path); err != nil {
+		os.Remove(partialPath)
+		return "", fmt.Errorf("failed to store content: %w", err)
+	}
+
+	cs.logger.Debug("Stored deployed content", "hash", hash)
+	return hash, nil
+}
+
+// Load returns the content stored for a hash
+func (cs *ContentStore) Load(hash string) ([]byte, error) {
+	content, err := os.ReadFile(filepath.Join(cs.dir, filepath.Base(hash)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deployed content %s: %w", hash, err)
+	}
+	if fmt.Sprintf("%x", sha256.Sum256(content)) != hash {
+		return nil, fmt.Errorf("deployed content %s is corrupted", hash)
+	}
+	return content, nil
+}
+
+// Prune removes stored content no managed file refers to anymore
+func (cs *ContentStore) Prune(managedFiles []ManagedFile) error {
+	entries, err := os.ReadDir(cs.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read content directory: %w", err)
+	}
+
+	keep := make(map[string]bool, len(managedFiles))
+	for _, file := range managedFiles {
+		if file.ContentHash != "" {
+			keep[file.ContentHash] = true
+		}
+	}
+
+	for _, entry := range entries {
+		if keep[entry.Name()] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(cs.dir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove deployed content %s: %w", entry.Name(), err)
+		}
+		cs.logger.Debug("Removed unreferenced deployed content", "hash", entry.Name())
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestContentStore(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	store := NewContentStoreWithPath(logger, filepath.Join(tempDir, "content"))
+
+	hash, err := store.Store([]byte("editor = vim\n"))
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if content, err := store.Load(hash); err != nil || string(content) != "editor = vim\n" {
+		t.Fatalf("unexpected content: %q, %v", content, err)
+	}
+	if info, _ := os.Stat(filepath.Join(tempDir, "content", hash)); info.Mode().Perm() != 0600 {
+		t.Errorf("expected stored content to be private, got %v", info.Mode().Perm())
+	}
+
+	sourcePath := filepath.Join(tempDir, "gitconfig")
+	if err := os.WriteFile(sourcePath, []byte("[user]\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	saved, err := store.Save(sourcePath)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Corrupted content is rejected
+	if err := os.WriteFile(filepath.Join(tempDir, "content", saved), []byte("changed"), 0600); err != nil {
+		t.Fatalf("failed to corrupt content: %v", err)
+	}
+	if _, err := store.Load(saved); err == nil {
+		t.Error("expected an error for corrupted content")
+	}
+
+	// Pruning keeps only referenced content
+	if err := store.Prune([]ManagedFile{{Name: "vimrc", ContentHash: hash}, {Name: "link", IsSymlink: true}}); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if _, err := store.Load(hash); err != nil {
+		t.Errorf("expected referenced content to be kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "content", saved)); !os.IsNotExist(err) {
+		t.Errorf("expected unreferenced content to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,366 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// CustomPackageManager runs the command templates of a manager defined under custom_managers
+type CustomPackageManager struct {
+	logger     *log.Logger
+	dryRun     bool
+	name       string
+	definition config.CustomManager
+}
+
+// NewCustomPackageManager creates a package manager from a custom_managers definition
+func NewCustomPackageManager(logger *log.Logger, dryRun bool, name string, definition config.CustomManager) *CustomPackageManager {
+	return &CustomPackageManager{
+		logger:     logger,
+		dryRun:     dryRun,
+		name:       name,
+		definition: definition,
+	}
+}
+
+// customCommandData is the data command templates are rendered with
+// Every value is shell-quoted, so templates use the fields unquoted
+type customCommandData struct {
+	Name    string // Package name (without "@version")
+	Version string // Version from a "name@version" entry
+	Flags   string // Resolved flags joined with spaces
+	Names   string // Package specs of a batch ("name" or "name@version") joined with spaces
+}
+
+// shellSafeWord matches words that need no quoting in sh
+var shellSafeWord = regexp.MustCompile(`^[A-Za-z0-9@%+=:,./_-]+$`)
+
+// shellQuote quotes a word for sh, leaving empty words and words made of safe characters as they are
+func shellQuote(word string) string {
+	if word == "" || shellSafeWord.MatchString(word) {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// shellWords quotes each word for sh and joins them with spaces
+func shellWords(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = shellQuote(word)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// customManagerCommand returns the command a custom manager needs on PATH: the first word of its install template
+func customManagerCommand(definition config.CustomManager) string {
+	fields := strings.Fields(definition.Install)
+	if len(fields) == 0 || strings.Contains(fields[0], "{{") {
+		return "sh"
+	}
+	return fields[0]
+}
+
+// displayName returns the human-readable name of the manager
+func (cm *CustomPackageManager) displayName() string {
+	return config.PackageManagerDisplayName(cm.name)
+}
+
+// ResolveEntries splits version specs so state tracks plain package names
+// "foo@1.2.0" becomes Name "foo" with Source "foo@1.2.0"
+func (cm *CustomPackageManager) ResolveEntries(packages []config.PackageEntry, configDir string) ([]config.PackageEntry, error) {
+	resolved := make([]config.PackageEntry, len(packages))
+	for i, pkg := range packages {
+		if name, _ := config.SplitCustomPackage(pkg.Name); pkg.Source == "" && name != pkg.Name {
+			pkg.Source = pkg.Name
+			pkg.Name = name
+		}
+		resolved[i] = pkg
+	}
+	return resolved, nil
+}
+
+// ValidatePackageNames validates package names with the manager's name rules before any command is rendered
+func (cm *CustomPackageManager) ValidatePackageNames(packages []config.PackageEntry) error {
+	spec, registered := config.LookupPackageManager(cm.name)
+	if !registered {
+		return nil
+	}
+	for _, pkg := range packages {
+		if !spec.ValidateName(customPackageSpec(pkg)) {
+			return fmt.Errorf("invalid %s package name '%s'", cm.displayName(), customPackageSpec(pkg))
+		}
+	}
+	return nil
+}
+
+// customPackageSpec returns the entry as written ("name" or "name@version")
+func customPackageSpec(pkg config.PackageEntry) string {
+	if pkg.Source != "" {
+		return pkg.Source
+	}
+	return pkg.Name
+}
+
+// InstallPackages installs missing packages; install templates using {{.Names}} run once per flag group
+func (cm *CustomPackageManager) InstallPackages(packages []config.PackageEntry, packageDefaults map[string][]string) error {
+	if len(packages) == 0 {
+		cm.logger.Debug(fmt.Sprintf("No %s packages to install", cm.displayName()))
+		return nil
+	}
+
+	cm.logger.Info(fmt.Sprintf("Managing %s packages...", cm.displayName()), "count", len(packages))
+
+	installed, listed := cm.listInstalled()
+
+	var pending []config.PackageEntry
+	for _, pkg := range packages {
+		name, pinned := config.SplitCustomPackage(customPackageSpec(pkg))
+
+		var isInstalled bool
+		var version string
+		if listed {
+			version, isInstalled = installed[name]
+		} else {
+			isInstalled = cm.checkInstalled(name)
+		}
+
+		if isInstalled {
+			// Versions can only be compared when the list command reports them
+			if pinned == "" || version == "" || version == pinned {
+				cm.logger.Debug("Package already installed", "manager", cm.name, "package", name)
+				continue
+			}
+			cm.logger.Info("Reinstalling package with pinned version", "manager", cm.name, "package", name, "installed", version, "pinned", pinned)
+		}
+		pending = append(pending, pkg)
+	}
+
+	flagGroups := cm.groupPackagesByFlags(pending, packageDefaults)
+
+	flagKeys := make([]string, 0, len(flagGroups))
+	for flagsKey := range flagGroups {
+		flagKeys = append(flagKeys, flagsKey)
+	}
+	sort.Strings(flagKeys)
+
+	for _, flagsKey := range flagKeys {
+		var flags string
+		if flagsKey != "" {
+			flags = shellWords(strings.Split(flagsKey, "|"))
+		}
+		group := flagGroups[flagsKey]
+
+		if strings.Contains(cm.definition.Install, ".Names") {
+			specs := make([]string, len(group))
+			for i, pkg := range group {
+				specs[i] = customPackageSpec(pkg)
+			}
+			if err := cm.run("install", cm.definition.Install, customCommandData{Flags: flags, Names: shellWords(specs)}); err != nil {
+				return fmt.Errorf("failed to install %s packages: %w", cm.displayName(), err)
+			}
+			for _, pkg := range group {
+				config.Success("Installed %s package: %s", cm.displayName(), pkg.Name)
+			}
+			continue
+		}
+
+		for _, pkg := range group {
+			spec := customPackageSpec(pkg)
+			name, version := config.SplitCustomPackage(spec)
+			data := customCommandData{Name: shellQuote(name), Version: shellQuote(version), Flags: flags, Names: shellQuote(spec)}
+			if err := cm.run("install", cm.definition.Install, data); err != nil {
+				return fmt.Errorf("failed to install %s package '%s': %w", cm.displayName(), name, err)
+			}
+			config.Success("Installed %s package: %s", cm.displayName(), name)
+		}
+	}
+
+	cm.logger.Info(fmt.Sprintf("✓ %s packages processed successfully", cm.displayName()))
+	return nil
+}
+
+// groupPackagesByFlags groups packages with the same resolved flags together
+func (cm *CustomPackageManager) groupPackagesByFlags(packages []config.PackageEntry, packageDefaults map[string][]string) map[string][]config.PackageEntry {
+	flagGroups := make(map[string][]config.PackageEntry)
+
+	for _, pkg := range packages {
+		flags := cm.resolvePackageFlags(pkg, packageDefaults)
+		flagsKey := strings.Join(flags, "|") // Use "|" as separator since it's not valid in flags
+		flagGroups[flagsKey] = append(flagGroups[flagsKey], pkg)
+	}
+
+	return flagGroups
+}
+
+// resolvePackageFlags implements the three-tier flag resolution system
+func (cm *CustomPackageManager) resolvePackageFlags(pkg config.PackageEntry, packageDefaults map[string][]string) []string {
+	// Tier 3: Per-package flags (highest priority)
+	if pkg.Flags != nil {
+		return pkg.Flags
+	}
+
+	// Tier 2: User package defaults
+	if userDefaults, exists := packageDefaults[cm.name]; exists {
+		return userDefaults
+	}
+
+	// Tier 1: Internal defaults (default_flags of the custom manager)
+	return config.GetDefaultFlags(cm.name)
+}
+
+// render renders a command template
+func (cm *CustomPackageManager) render(key, text string, data customCommandData) (string, error) {
+	tmpl, err := template.New(cm.name + "." + key).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", key, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", key, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// run renders and executes a modifying command with sh -c (or logs it in dry-run mode)
+func (cm *CustomPackageManager) run(key, text string, data customCommandData) error {
+	command, err := cm.render(key, text, data)
+	if err != nil {
+		return err
+	}
+
+	if cm.dryRun {
+		cm.logger.Info("  [DRY RUN] Would run:", "command", command)
+		return nil
+	}
+
+	output, err := exec.Command("sh", "-c", command).CombinedOutput()
+	if err != nil {
+		cm.logger.Error("Command failed", "command", command, "error", err, "output", string(output))
+		return fmt.Errorf("%s command failed: %w", key, err)
+	}
+
+	cm.logger.Debug("Command completed", "command", command, "output", string(output))
+	return nil
+}
+
+// checkInstalled runs the is_installed command for a package; exit status 0 means installed
+func (cm *CustomPackageManager) checkInstalled(name string) bool {
+	command, err := cm.render("is_installed", cm.definition.IsInstalled, customCommandData{Name: shellQuote(name), Names: shellQuote(name)})
+	if err != nil {
+		cm.logger.Warn("Could not check if package is installed", "manager", cm.name, "package", name, "error", err)
+		return false
+	}
+	return exec.Command("sh", "-c", command).Run() == nil
+}
+
+// listInstalled runs the optional list command; the second result is false when there is no usable list
+func (cm *CustomPackageManager) listInstalled() (map[string]string, bool) {
+	if strings.TrimSpace(cm.definition.List) == "" {
+		return nil, false
+	}
+
+	command, err := cm.render("list", cm.definition.List, customCommandData{})
+	if err != nil {
+		cm.logger.Warn("Could not list installed packages, checking packages one by one", "manager", cm.name, "error", err)
+		return nil, false
+	}
+
+	output, err := exec.Command("sh", "-c", command).Output()
+	if err != nil {
+		cm.logger.Warn("Could not list installed packages, checking packages one by one", "manager", cm.name, "error", err)
+		return nil, false
+	}
+
+	return parseCustomList(string(output)), true
+}
+
+// parseCustomList parses list output with one "name [version]" per line
+func parseCustomList(output string) map[string]string {
+	installed := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		version := ""
+		if len(fields) > 1 {
+			version = fields[1]
+		}
+		installed[fields[0]] = version
+	}
+	return installed
+}
+
+// isPackageInstalled checks if a package is installed using the list or is_installed command
+func (cm *CustomPackageManager) isPackageInstalled(packageName string) (bool, error) {
+	name, _ := config.SplitCustomPackage(packageName)
+	if installed, listed := cm.listInstalled(); listed {
+		_, isInstalled := installed[name]
+		return isInstalled, nil
+	}
+	return cm.checkInstalled(name), nil
+}
+
+// isPackageAvailable reports whether a package can be installed
+// Custom managers have no availability command, so any valid package name is considered available
+func (cm *CustomPackageManager) isPackageAvailable(packageName string) (bool, error) {
+	return true, nil
+}
+
+// RemovePackages removes installed packages that are no longer in the configuration
+func (cm *CustomPackageManager) RemovePackages(packagesToRemove []string) error {
+	if len(packagesToRemove) == 0 {
+		return nil
+	}
+
+	cm.logger.Info(fmt.Sprintf("Removing %s packages no longer in configuration", cm.displayName()), "packages", packagesToRemove)
+
+	installed, listed := cm.listInstalled()
+
+	var names []string
+	for _, pkg := range packagesToRemove {
+		name, _ := config.SplitCustomPackage(pkg)
+		isInstalled := false
+		if listed {
+			_, isInstalled = installed[name]
+		} else {
+			isInstalled = cm.checkInstalled(name)
+		}
+		if !isInstalled {
+			cm.logger.Debug("Package not installed, skipping removal", "manager", cm.name, "package", name)
+			continue
+		}
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	if strings.Contains(cm.definition.Remove, ".Names") {
+		if err := cm.run("remove", cm.definition.Remove, customCommandData{Names: shellWords(names)}); err != nil {
+			return fmt.Errorf("failed to remove %s packages: %w", cm.displayName(), err)
+		}
+	} else {
+		for _, name := range names {
+			if err := cm.run("remove", cm.definition.Remove, customCommandData{Name: shellQuote(name), Names: shellQuote(name)}); err != nil {
+				return fmt.Errorf("failed to remove %s package %s: %w", cm.displayName(), name, err)
+			}
+		}
+	}
+
+	for _, name := range names {
+		config.Success("Removed %s package: %s", cm.displayName(), name)
+	}
+	return nil
+}
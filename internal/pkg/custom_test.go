@@ -0,0 +1,177 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// newTestCustomManager returns a custom manager definition whose commands record themselves in a log
+// Installed packages are listed in a file, one "name version" per line
+func newTestCustomManager(t *testing.T, batch bool, withList bool) (config.CustomManager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "commands.log")
+	installedPath := filepath.Join(dir, "installed")
+
+	if err := os.WriteFile(installedPath, []byte("node 20.11.0\npython 3.12.1\n"), 0644); err != nil {
+		t.Fatalf("failed to write installed list: %v", err)
+	}
+
+	definition := config.CustomManager{
+		Install:     "echo install {{.Flags}} {{.Name}} {{.Version}} >> " + logPath,
+		Remove:      "echo remove {{.Name}} >> " + logPath,
+		IsInstalled: "cut -d' ' -f1 " + installedPath + " | grep -qxF -- {{.Name}}",
+	}
+	if batch {
+		definition.Install = "echo install {{.Flags}} {{.Names}} >> " + logPath
+		definition.Remove = "echo remove {{.Names}} >> " + logPath
+	}
+	if withList {
+		definition.List = "cat " + installedPath
+	}
+	return definition, logPath
+}
+
+func TestCustomPackageManager_InstallPackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	packages := []config.PackageEntry{
+		{Name: "node", Source: "node@20.11.0"},    // installed at the pinned version
+		{Name: "python", Source: "python@3.13.0"}, // installed at another version
+		{Name: "go"}, // missing
+		{Name: "rust", Flags: []string{"--quiet"}}, // missing, own flags
+	}
+
+	tests := []struct {
+		name     string
+		batch    bool
+		withList bool
+		expected []string
+	}{
+		{
+			name:     "list detects versions",
+			withList: true,
+			expected: []string{"install python 3.13.0", "install go", "install --quiet rust"},
+		},
+		{
+			name:     "is_installed without list",
+			expected: []string{"install go", "install --quiet rust"},
+		},
+		{
+			name:     "batched install",
+			batch:    true,
+			withList: true,
+			expected: []string{"install python@3.13.0 go", "install --quiet rust"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definition, logPath := newTestCustomManager(t, tt.batch, tt.withList)
+
+			if err := NewCustomPackageManager(logger, false, "mise", definition).InstallPackages(packages, nil); err != nil {
+				t.Fatalf("InstallPackages failed: %v", err)
+			}
+
+			commands := readCommandLog(t, logPath)
+			if strings.Join(commands, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected commands %v, got %v", tt.expected, commands)
+			}
+		})
+	}
+}
+
+func TestCustomPackageManager_QuotesValues(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	for _, batch := range []bool{false, true} {
+		definition, logPath := newTestCustomManager(t, batch, true)
+		marker := filepath.Join(filepath.Dir(logPath), "injected")
+
+		packages := []config.PackageEntry{{Name: "go", Flags: []string{"--note=it's; touch " + marker}}}
+		if err := NewCustomPackageManager(logger, false, "mise", definition).InstallPackages(packages, nil); err != nil {
+			t.Fatalf("batch=%v: InstallPackages failed: %v", batch, err)
+		}
+
+		expected := "install --note=it's; touch " + marker + " go"
+		if commands := readCommandLog(t, logPath); strings.Join(commands, "\n") != expected {
+			t.Errorf("batch=%v: expected command %q, got %v", batch, expected, commands)
+		}
+		if _, err := os.Stat(marker); err == nil {
+			t.Errorf("batch=%v: flags were interpreted by the shell", batch)
+		}
+	}
+}
+
+func TestCustomPackageManager_DryRun(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	definition, logPath := newTestCustomManager(t, false, true)
+
+	if err := NewCustomPackageManager(logger, true, "mise", definition).InstallPackages([]config.PackageEntry{{Name: "go"}}, nil); err != nil {
+		t.Fatalf("InstallPackages failed: %v", err)
+	}
+	if commands := readCommandLog(t, logPath); len(commands) != 0 {
+		t.Errorf("expected no commands in dry-run mode, got %v", commands)
+	}
+}
+
+func TestCustomPackageManager_RemovePackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	for _, batch := range []bool{false, true} {
+		definition, logPath := newTestCustomManager(t, batch, false)
+
+		if err := NewCustomPackageManager(logger, false, "mise", definition).RemovePackages([]string{"node", "go", "python"}); err != nil {
+			t.Fatalf("RemovePackages failed: %v", err)
+		}
+
+		expected := []string{"remove node", "remove python"}
+		if batch {
+			expected = []string{"remove node python"}
+		}
+		commands := readCommandLog(t, logPath)
+		if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("batch=%v: expected commands %v, got %v", batch, expected, commands)
+		}
+	}
+}
+
+func TestNewPackageManager_CustomManager(t *testing.T) {
+	logger := log.New(os.Stderr)
+
+	definition, _ := newTestCustomManager(t, false, false)
+	definition.Install = "krew install {{.Name}}"
+	config.RegisterCustomManagers(&config.Config{CustomManagers: map[string]config.CustomManager{"testkrew": definition}})
+
+	manager, err := NewPackageManager("testkrew", ManagerOptions{Logger: logger})
+	if err != nil {
+		t.Fatalf("NewPackageManager failed: %v", err)
+	}
+	if _, ok := manager.(*CustomPackageManager); !ok {
+		t.Errorf("expected *CustomPackageManager, got %T", manager)
+	}
+
+	found := false
+	for _, name := range RegisteredPackageManagers() {
+		if name == "testkrew" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected custom manager to be listed as registered")
+	}
+
+	if command := PackageManagerCommand("testkrew"); command != "krew" {
+		t.Errorf("expected command krew, got %s", command)
+	}
+}
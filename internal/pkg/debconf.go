@@ -0,0 +1,140 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// DebconfManager handles debconf preseeding so APT installs never stop on prompts
+type DebconfManager struct {
+	logger *log.Logger
+	dryRun bool
+}
+
+// NewDebconfManager creates a new debconf manager
+func NewDebconfManager(logger *log.Logger, dryRun bool) *DebconfManager {
+	return &DebconfManager{
+		logger: logger,
+		dryRun: dryRun,
+	}
+}
+
+// noninteractiveEnv returns the current environment with debconf prompts disabled
+func noninteractiveEnv() []string {
+	return append(os.Environ(), "DEBIAN_FRONTEND=noninteractive")
+}
+
+// ApplySelections feeds the selections that differ from the current debconf database to debconf-set-selections
+func (dm *DebconfManager) ApplySelections(selections []config.DebconfSelection) error {
+	if len(selections) == 0 {
+		dm.logger.Debug("No debconf selections to apply")
+		return nil
+	}
+
+	if _, err := exec.LookPath("debconf-set-selections"); err != nil {
+		return fmt.Errorf("debconf-set-selections command not found - is this a Debian/Ubuntu system?")
+	}
+
+	changed := dm.changedSelections(selections)
+	if len(changed) == 0 {
+		dm.logger.Debug("All debconf selections already set", "count", len(selections))
+		return nil
+	}
+
+	input := make([]string, 0, len(changed))
+	for _, selection := range changed {
+		input = append(input, selection.String())
+	}
+
+	if dm.dryRun {
+		dm.logger.Info("  [DRY RUN] Would run:", "command", "debconf-set-selections", "selections", len(changed))
+		return nil
+	}
+
+	cmd := exec.Command("debconf-set-selections")
+	cmd.Env = noninteractiveEnv()
+	cmd.Stdin = strings.NewReader(strings.Join(input, "\n") + "\n")
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("debconf-set-selections failed: %w (output: %s)", err, strings.TrimSpace(string(output)))
+	}
+
+	for _, selection := range changed {
+		config.Success("Preseeded debconf answer: %s", selection.Question)
+	}
+
+	return nil
+}
+
+// changedSelections returns the selections whose value differs from the current debconf database,
+// reporting each difference
+func (dm *DebconfManager) changedSelections(selections []config.DebconfSelection) []config.DebconfSelection {
+	current := make(map[string]map[string]string)
+	var changed []config.DebconfSelection
+
+	for _, selection := range selections {
+		if _, loaded := current[selection.Owner]; !loaded {
+			values, err := dm.currentSelections(selection.Owner)
+			if err != nil {
+				dm.logger.Debug("Could not read current debconf selections", "package", selection.Owner, "error", err)
+				values = map[string]string{}
+			}
+			current[selection.Owner] = values
+		}
+
+		currentValue, exists := current[selection.Owner][selection.Question]
+
+		// Passwords are never shown by debconf-show, so they are always applied and never logged
+		if selection.Type == "password" {
+			changed = append(changed, selection)
+			continue
+		}
+
+		if exists && currentValue == selection.Value {
+			continue
+		}
+
+		if exists {
+			dm.logger.Info("Debconf selection differs", "question", selection.Question, "current", currentValue, "desired", selection.Value)
+		} else {
+			dm.logger.Info("Debconf selection not set", "question", selection.Question, "desired", selection.Value)
+		}
+		changed = append(changed, selection)
+	}
+
+	return changed
+}
+
+// currentSelections reads the current debconf answers for a package with debconf-show
+func (dm *DebconfManager) currentSelections(owner string) (map[string]string, error) {
+	cmd := exec.Command("debconf-show", owner)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDebconfShow(string(output)), nil
+}
+
+// parseDebconfShow parses debconf-show output, e.g.:
+//   - postfix/main_mailer_type: Internet Site
+//     wireshark-common/install-setuid: false
+func parseDebconfShow(output string) map[string]string {
+	values := make(map[string]string)
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+		question, value, found := strings.Cut(line, ":")
+		if !found || question == "" {
+			continue
+		}
+		values[strings.TrimSpace(question)] = strings.TrimSpace(value)
+	}
+
+	return values
+}
@@ -0,0 +1,93 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+func TestParseDebconfShow(t *testing.T) {
+	output := "* postfix/main_mailer_type: Internet Site\n" +
+		"  postfix/mailname: host.example.com\n" +
+		"  postfix/relayhost:\n" +
+		"\n"
+
+	values := parseDebconfShow(output)
+
+	expected := map[string]string{
+		"postfix/main_mailer_type": "Internet Site",
+		"postfix/mailname":         "host.example.com",
+		"postfix/relayhost":        "",
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("expected %d values, got %d: %v", len(expected), len(values), values)
+	}
+	for question, value := range expected {
+		if values[question] != value {
+			t.Errorf("expected %s=%q, got %q", question, value, values[question])
+		}
+	}
+}
+
+func TestDebconfManager_ApplySelections(t *testing.T) {
+	binDir := t.TempDir()
+	inputPath := filepath.Join(t.TempDir(), "selections")
+	envPath := filepath.Join(t.TempDir(), "env")
+
+	writeStubCommand(t, binDir, "debconf-show", "printf '* postfix/main_mailer_type: Internet Site\\n  postfix/mailname: old.example.com\\n'\n")
+	writeStubCommand(t, binDir, "debconf-set-selections", "cat > "+inputPath+"\necho \"$DEBIAN_FRONTEND\" > "+envPath+"\n")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	selections := []config.DebconfSelection{
+		{Owner: "postfix", Question: "postfix/main_mailer_type", Type: "select", Value: "Internet Site"},
+		{Owner: "postfix", Question: "postfix/mailname", Type: "string", Value: "new.example.com"},
+	}
+
+	t.Run("only changed selections are applied", func(t *testing.T) {
+		if err := NewDebconfManager(logger, false).ApplySelections(selections); err != nil {
+			t.Fatalf("ApplySelections failed: %v", err)
+		}
+
+		data, err := os.ReadFile(inputPath)
+		if err != nil {
+			t.Fatal("debconf-set-selections should run when a selection differs")
+		}
+		if got := strings.TrimSpace(string(data)); got != "postfix postfix/mailname string new.example.com" {
+			t.Errorf("unexpected selections fed to debconf-set-selections: %q", got)
+		}
+
+		env, _ := os.ReadFile(envPath)
+		if strings.TrimSpace(string(env)) != "noninteractive" {
+			t.Errorf("expected DEBIAN_FRONTEND=noninteractive, got %q", strings.TrimSpace(string(env)))
+		}
+	})
+
+	t.Run("unchanged selections are skipped", func(t *testing.T) {
+		os.Remove(inputPath)
+
+		if err := NewDebconfManager(logger, false).ApplySelections(selections[:1]); err != nil {
+			t.Fatalf("ApplySelections failed: %v", err)
+		}
+		if _, err := os.Stat(inputPath); !os.IsNotExist(err) {
+			t.Error("debconf-set-selections should not run when selections already match")
+		}
+	})
+
+	t.Run("dry run does not apply", func(t *testing.T) {
+		os.Remove(inputPath)
+
+		if err := NewDebconfManager(logger, true).ApplySelections(selections); err != nil {
+			t.Fatalf("ApplySelections failed: %v", err)
+		}
+		if _, err := os.Stat(inputPath); !os.IsNotExist(err) {
+			t.Error("debconf-set-selections should not run in dry-run mode")
+		}
+	})
+}
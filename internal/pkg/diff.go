@@ -0,0 +1,153 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
+// diffOp is one line of an edit script: ' ' (unchanged), '-' (removed) or '+' (added)
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns a unified diff between two versions of a file, or "" if they are equal
+func unifiedDiff(path string, before, after []string) string {
+	ops := diffLines(before, after)
+
+	var hunks strings.Builder
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk until more than 2*context unchanged lines separate it from the next change
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			unchanged := end
+			for unchanged < len(ops) && ops[unchanged].kind == ' ' {
+				unchanged++
+			}
+			if unchanged == len(ops) || unchanged-end > 2*diffContextLines {
+				break
+			}
+			end = unchanged
+		}
+
+		from := max(start-diffContextLines, 0)
+		to := min(end+diffContextLines, len(ops))
+		writeHunk(&hunks, ops, from, to)
+		start = to
+	}
+
+	if hunks.Len() == 0 {
+		return ""
+	}
+	return fmt.Sprintf("--- %s\n+++ %s\n%s", path, path, hunks.String())
+}
+
+// writeHunk writes the ops in [from, to) as a hunk with its @@ header
+func writeHunk(out *strings.Builder, ops []diffOp, from, to int) {
+	// Line numbers (1-based) of the hunk start in the old and new file
+	oldStart, newStart := 1, 1
+	for _, op := range ops[:from] {
+		if op.kind != '+' {
+			oldStart++
+		}
+		if op.kind != '-' {
+			newStart++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	var body strings.Builder
+	for _, op := range ops[from:to] {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+		body.WriteByte(op.kind)
+		body.WriteString(op.line)
+		body.WriteByte('\n')
+	}
+
+	// An empty range starts at the line before it
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n%s", oldStart, oldCount, newStart, newCount, body.String())
+}
+
+// diffLines computes an edit script between two line slices
+// Common leading and trailing lines are skipped, so the quadratic LCS only covers the changed region
+func diffLines(before, after []string) []diffOp {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range before[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	oldMiddle := before[prefix : len(before)-suffix]
+	newMiddle := after[prefix : len(after)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of oldMiddle[i:] and newMiddle[j:]
+	lcs := make([][]int, len(oldMiddle)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newMiddle)+1)
+	}
+	for i := len(oldMiddle) - 1; i >= 0; i-- {
+		for j := len(newMiddle) - 1; j >= 0; j-- {
+			if oldMiddle[i] == newMiddle[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(oldMiddle) || j < len(newMiddle) {
+		switch {
+		case i < len(oldMiddle) && j < len(newMiddle) && oldMiddle[i] == newMiddle[j]:
+			ops = append(ops, diffOp{' ', oldMiddle[i]})
+			i++
+			j++
+		case j == len(newMiddle) || (i < len(oldMiddle) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', oldMiddle[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', newMiddle[j]})
+			j++
+		}
+	}
+
+	for _, line := range before[len(before)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
@@ -0,0 +1,191 @@
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/config"
+)
+
+// ignoreFileName lists patterns of files to skip, one per line, at the root of a source directory
+const ignoreFileName = ".configrignore"
+
+// ExpandDirectories expands directory trees into the individual files to deploy
+// Files are named "<directory>/<relative path>", so each one is deployed, tracked in state and
+// pruned like any other file when it disappears from the source
+func (fm *FileManager) ExpandDirectories(directories map[string]config.Directory) (map[string]config.File, error) {
+	files := make(map[string]config.File)
+	for name, directory := range directories {
+		expanded, err := fm.expandDirectory(name, directory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand directory '%s': %w", name, err)
+		}
+		for fileName, file := range expanded {
+			files[fileName] = file
+		}
+	}
+	return files, nil
+}
+
+// expandDirectory returns the files of a single directory tree
+func (fm *FileManager) expandDirectory(name string, directory config.Directory) (map[string]config.File, error) {
+	sourceDir := directory.Source
+	if !filepath.IsAbs(sourceDir) {
+		configDir := directory.ConfigDir
+		if configDir == "" {
+			configDir = fm.configDir
+		}
+		sourceDir = filepath.Join(configDir, sourceDir)
+	}
+
+	info, err := os.Stat(sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("source directory does not exist: %s", sourceDir)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("source is not a directory: %s", sourceDir)
+	}
+
+	// The whole directory is a single symlink
+	if directory.DeployMethod() == config.DirectoryMethodLinkDir {
+		return map[string]config.File{
+			name: {
+				Source:      sourceDir,
+				Destination: directory.Destination,
+				Backup:      directory.Backup,
+				Interactive: directory.Interactive,
+			},
+		}, nil
+	}
+
+	root, err := fm.resolveDestinationPath(directory.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve destination path: %w", err)
+	}
+
+	ignored, err := readIgnoreFile(filepath.Join(sourceDir, ignoreFileName))
+	if err != nil {
+		return nil, err
+	}
+	exclude := append(append([]string{ignoreFileName}, directory.Exclude...), ignored...)
+
+	files := make(map[string]config.File)
+	err = filepath.WalkDir(sourceDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath == sourceDir {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(sourceDir, filePath)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if matchesGlob(relPath, exclude) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if len(directory.Include) > 0 && !matchesPathOrParent(relPath, directory.Include) {
+			return nil
+		}
+
+		files[name+"/"+relPath] = config.File{
+			Source:        filePath,
+			Destination:   filepath.Join(root, filepath.FromSlash(relPath)),
+			Backup:        directory.Backup,
+			Copy:          directory.DeployMethod() == config.DirectoryMethodCopy,
+			Interactive:   directory.Interactive,
+			DirectoryRoot: root,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source directory %s: %w", sourceDir, err)
+	}
+
+	fm.logger.Debug("Expanded directory", "name", name, "source", sourceDir, "files", len(files))
+	return files, nil
+}
+
+// readIgnoreFile reads the patterns of a .configrignore file; a missing file has no patterns
+// Blank lines and lines starting with # are skipped
+func readIgnoreFile(ignorePath string) ([]string, error) {
+	file, err := os.Open(ignorePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ignorePath, err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ignorePath, err)
+	}
+	return patterns, nil
+}
+
+// matchesGlob reports whether a slash-separated relative path matches any pattern
+// Patterns without a slash match a file or directory name at any depth ("*.swp", ".git");
+// patterns with a slash match the path from the source root ("lua/plugins/*")
+func matchesGlob(relPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.Trim(pattern, "/")
+		target := relPath
+		if !strings.Contains(pattern, "/") {
+			target = path.Base(relPath)
+		}
+		if matched, _ := path.Match(pattern, target); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPathOrParent reports whether a path or any of its parent directories matches a pattern,
+// so including a directory includes everything below it
+func matchesPathOrParent(relPath string, patterns []string) bool {
+	for current := relPath; current != "." && current != "/"; current = path.Dir(current) {
+		if matchesGlob(current, patterns) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeEmptyParents removes directories left empty by pruning a file, up to (not including) root
+func (fm *FileManager) removeEmptyParents(dir, root string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil || len(entries) > 0 {
+			return
+		}
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		fm.logger.Debug("Removed empty directory", "path", dir)
+	}
+}
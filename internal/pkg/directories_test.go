@@ -0,0 +1,174 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// writeTree creates files (relative path → content) below dir
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for relPath, content := range files {
+		filePath := filepath.Join(dir, relPath)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", relPath, err)
+		}
+	}
+}
+
+func TestMatchesGlob(t *testing.T) {
+	tests := []struct {
+		path     string
+		patterns []string
+		expected bool
+	}{
+		{"init.lua", []string{"*.lua"}, true},
+		{"lua/plugins/telescope.lua", []string{"*.lua"}, true},
+		{"lua/plugins/telescope.lua", []string{"lua/*"}, false},
+		{"lua/plugins/telescope.lua", []string{"lua/plugins/*"}, true},
+		{"lazy-lock.json", []string{"/lazy-lock.json"}, true},
+		{"spell", []string{"spell/"}, true},
+		{"kitty.conf", []string{"*.swp", "*.bak"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesGlob(tt.path, tt.patterns); got != tt.expected {
+			t.Errorf("matchesGlob(%q, %v) = %v, expected %v", tt.path, tt.patterns, got, tt.expected)
+		}
+	}
+}
+
+func TestFileManager_ExpandDirectories(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	writeTree(t, filepath.Join(tempDir, "nvim"), map[string]string{
+		"init.lua":                  "require('config')",
+		"lua/config.lua":            "-- config",
+		"lua/plugins/telescope.lua": "-- telescope",
+		"lazy-lock.json":            "{}",
+		"spell/en.utf-8.add":        "configr",
+		"session.vim.swp":           "",
+		ignoreFileName:              "# generated files\nlazy-lock.json\nspell/\n",
+	})
+
+	fm := NewFileManager(logger, false, tempDir)
+	destDir := filepath.Join(tempDir, "home", ".config", "nvim")
+
+	t.Run("per-file symlinks", func(t *testing.T) {
+		files, err := fm.ExpandDirectories(map[string]config.Directory{
+			"nvim": {Source: "nvim", Destination: destDir, Exclude: []string{"*.swp"}},
+		})
+		if err != nil {
+			t.Fatalf("ExpandDirectories failed: %v", err)
+		}
+
+		var names []string
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		expected := "nvim/init.lua nvim/lua/config.lua nvim/lua/plugins/telescope.lua"
+		if strings.Join(names, " ") != expected {
+			t.Errorf("unexpected files: %v", names)
+		}
+
+		file := files["nvim/lua/plugins/telescope.lua"]
+		if file.Destination != filepath.Join(destDir, "lua", "plugins", "telescope.lua") || file.Copy || file.DirectoryRoot != destDir {
+			t.Errorf("unexpected file: %+v", file)
+		}
+	})
+
+	t.Run("include", func(t *testing.T) {
+		files, err := fm.ExpandDirectories(map[string]config.Directory{
+			"nvim": {Source: "nvim", Destination: destDir, Method: config.DirectoryMethodCopy, Include: []string{"lua/plugins"}},
+		})
+		if err != nil {
+			t.Fatalf("ExpandDirectories failed: %v", err)
+		}
+		if len(files) != 1 || !files["nvim/lua/plugins/telescope.lua"].Copy {
+			t.Errorf("expected only the copied plugins directory, got %+v", files)
+		}
+	})
+
+	t.Run("directory symlink", func(t *testing.T) {
+		files, err := fm.ExpandDirectories(map[string]config.Directory{
+			"nvim": {Source: "nvim", Destination: destDir, Method: config.DirectoryMethodLinkDir},
+		})
+		if err != nil {
+			t.Fatalf("ExpandDirectories failed: %v", err)
+		}
+		if len(files) != 1 || files["nvim"].Source != filepath.Join(tempDir, "nvim") || files["nvim"].Destination != destDir {
+			t.Errorf("expected a single directory symlink, got %+v", files)
+		}
+	})
+}
+
+func TestFileManager_DirectoryPruning(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	sourceDir := filepath.Join(tempDir, "kitty")
+	writeTree(t, sourceDir, map[string]string{
+		"kitty.conf":       "include themes/dark.conf",
+		"themes/dark.conf": "background #000000",
+	})
+
+	fm := NewFileManager(logger, false, tempDir)
+	destDir := filepath.Join(tempDir, "home", ".config", "kitty")
+	directories := map[string]config.Directory{
+		"kitty": {Source: "kitty", Destination: destDir},
+	}
+
+	files, err := fm.ExpandDirectories(directories)
+	if err != nil {
+		t.Fatalf("ExpandDirectories failed: %v", err)
+	}
+	deployed, err := fm.DeployFiles(files)
+	if err != nil {
+		t.Fatalf("DeployFiles failed: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(destDir, "themes", "dark.conf")); err != nil || target != filepath.Join(sourceDir, "themes", "dark.conf") {
+		t.Fatalf("expected a per-file symlink, got %q (%v)", target, err)
+	}
+
+	// Delete a file from the source; it drops out of the expanded files and is pruned from state
+	if err := os.RemoveAll(filepath.Join(sourceDir, "themes")); err != nil {
+		t.Fatalf("failed to remove source file: %v", err)
+	}
+	files, err = fm.ExpandDirectories(directories)
+	if err != nil {
+		t.Fatalf("ExpandDirectories failed: %v", err)
+	}
+
+	var toRemove []ManagedFile
+	for _, file := range deployed {
+		if _, exists := files[file.Name]; !exists {
+			toRemove = append(toRemove, file)
+		}
+	}
+	if len(toRemove) != 1 || toRemove[0].Name != "kitty/themes/dark.conf" {
+		t.Fatalf("unexpected files to prune: %+v", toRemove)
+	}
+	if err := fm.RemoveFiles(toRemove); err != nil {
+		t.Fatalf("RemoveFiles failed: %v", err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(destDir, "themes")); !os.IsNotExist(err) {
+		t.Error("expected the emptied themes directory to be removed")
+	}
+	if _, err := os.Lstat(filepath.Join(destDir, "kitty.conf")); err != nil {
+		t.Errorf("expected kitty.conf to remain: %v", err)
+	}
+}
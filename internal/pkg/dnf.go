@@ -0,0 +1,327 @@
+package pkg
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// DnfManager handles DNF package operations on Fedora and RHEL-based distributions
+// Packages are RPM names; a leading "@" selects a package group or environment (e.g., "@development-tools")
+type DnfManager struct {
+	logger *log.Logger
+	dryRun bool
+}
+
+// NewDnfManager creates a new DNF package manager
+func NewDnfManager(logger *log.Logger, dryRun bool) *DnfManager {
+	return &DnfManager{
+		logger: logger,
+		dryRun: dryRun,
+	}
+}
+
+// dnfPackagePattern matches RPM package names and "@group" names
+var dnfPackagePattern = regexp.MustCompile(`^@?[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+func init() {
+	RegisterPackageManager(PackageManagerRegistration{
+		Name:    "dnf",
+		Command: "dnf",
+		Spec: &config.PackageManagerSpec{
+			Name:        "dnf",
+			DisplayName: "DNF",
+			// DNF defaults: assume yes for prompts
+			DefaultFlags: []string{"-y"},
+			ValidateName: func(name string) bool {
+				return dnfPackagePattern.MatchString(name)
+			},
+			SanitizeName: func(name string) string {
+				return regexp.MustCompile(`[^A-Za-z0-9._+@-]`).ReplaceAllString(name, "-")
+			},
+			NameMessage: "DNF package name contains invalid characters",
+			NameHelp:    "use an RPM package name like 'git' or 'python3-devel', or '@group' for package groups",
+			Distros:     []string{"fedora", "rhel", "centos"},
+		},
+		New: func(opts ManagerOptions) PackageManager {
+			return NewDnfManager(opts.Logger, opts.DryRun)
+		},
+	})
+}
+
+// isDnfGroup reports whether a package entry names a group or environment
+func isDnfGroup(name string) bool {
+	return strings.HasPrefix(name, "@")
+}
+
+// InstallPackages installs missing packages, batching packages with the same flags into one dnf transaction
+// Groups are always passed to dnf, which skips groups that are already installed
+func (dm *DnfManager) InstallPackages(packages []config.PackageEntry, packageDefaults map[string][]string) error {
+	if len(packages) == 0 {
+		dm.logger.Debug("No DNF packages to install")
+		return nil
+	}
+
+	dm.logger.Info("Managing DNF packages...", "count", len(packages))
+
+	var pending []config.PackageEntry
+	for _, pkg := range packages {
+		if !isDnfGroup(pkg.Name) && dm.rpmInstalled(pkg.Name) {
+			dm.logger.Debug("Package already installed", "package", pkg.Name)
+			continue
+		}
+		pending = append(pending, pkg)
+	}
+
+	flagGroups := dm.groupPackagesByFlags(pending, packageDefaults)
+
+	flagKeys := make([]string, 0, len(flagGroups))
+	for flagsKey := range flagGroups {
+		flagKeys = append(flagKeys, flagsKey)
+	}
+	sort.Strings(flagKeys)
+
+	for _, flagsKey := range flagKeys {
+		args := []string{"dnf", "install"}
+		if flagsKey != "" {
+			args = append(args, strings.Split(flagsKey, "|")...)
+		}
+		for _, pkg := range flagGroups[flagsKey] {
+			args = append(args, pkg.Name)
+		}
+
+		if err := dm.run(args); err != nil {
+			return fmt.Errorf("failed to install DNF packages: %w", err)
+		}
+		for _, pkg := range flagGroups[flagsKey] {
+			if isDnfGroup(pkg.Name) {
+				dm.logger.Debug("Package group ensured", "group", pkg.Name)
+				continue
+			}
+			config.Success("Installed DNF package: %s", pkg.Name)
+		}
+	}
+
+	dm.logger.Info("✓ DNF packages processed successfully")
+	return nil
+}
+
+// groupPackagesByFlags groups packages with the same resolved flags together
+func (dm *DnfManager) groupPackagesByFlags(packages []config.PackageEntry, packageDefaults map[string][]string) map[string][]config.PackageEntry {
+	flagGroups := make(map[string][]config.PackageEntry)
+
+	for _, pkg := range packages {
+		flags := dm.resolvePackageFlags(pkg, packageDefaults)
+		flagsKey := strings.Join(flags, "|") // Use "|" as separator since it's not valid in flags
+		flagGroups[flagsKey] = append(flagGroups[flagsKey], pkg)
+	}
+
+	return flagGroups
+}
+
+// resolvePackageFlags implements the three-tier flag resolution system
+func (dm *DnfManager) resolvePackageFlags(pkg config.PackageEntry, packageDefaults map[string][]string) []string {
+	// Tier 3: Per-package flags (highest priority)
+	if pkg.Flags != nil {
+		return pkg.Flags
+	}
+
+	// Tier 2: User package defaults
+	if userDefaults, exists := packageDefaults["dnf"]; exists {
+		return userDefaults
+	}
+
+	// Tier 1: Internal defaults
+	return config.GetDefaultFlags("dnf")
+}
+
+// run executes a dnf command (or logs it in dry-run mode)
+func (dm *DnfManager) run(args []string) error {
+	if dm.dryRun {
+		dm.logger.Info("  [DRY RUN] Would run:", "command", strings.Join(args, " "))
+		return nil
+	}
+
+	output, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		dm.logger.Error("Command failed", "command", strings.Join(args, " "), "error", err, "output", string(output))
+		return fmt.Errorf("dnf %s failed: %w", args[1], err)
+	}
+
+	dm.logger.Debug("Command completed", "command", strings.Join(args, " "), "output", string(output))
+	return nil
+}
+
+// rpmInstalled checks the RPM database for an installed package
+func (dm *DnfManager) rpmInstalled(name string) bool {
+	return exec.Command("rpm", "-q", "--quiet", name).Run() == nil
+}
+
+// isPackageInstalled checks if a package is installed (groups are reported as not installed)
+func (dm *DnfManager) isPackageInstalled(packageName string) (bool, error) {
+	if isDnfGroup(packageName) {
+		return false, nil
+	}
+	return dm.rpmInstalled(packageName), nil
+}
+
+// isPackageAvailable checks if a package exists in the enabled repositories
+func (dm *DnfManager) isPackageAvailable(packageName string) (bool, error) {
+	args := []string{"-q", "info", packageName}
+	if isDnfGroup(packageName) {
+		args = []string{"-q", "group", "info", strings.TrimPrefix(packageName, "@")}
+	}
+	if err := exec.Command("dnf", args...).Run(); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// RemovePackages removes installed packages that are no longer in the configuration in one transaction
+func (dm *DnfManager) RemovePackages(packagesToRemove []string) error {
+	if len(packagesToRemove) == 0 {
+		return nil
+	}
+
+	dm.logger.Info("Removing DNF packages no longer in configuration", "packages", packagesToRemove)
+
+	var removed []string
+	for _, pkg := range packagesToRemove {
+		if !isDnfGroup(pkg) && !dm.rpmInstalled(pkg) {
+			dm.logger.Debug("Package not installed, skipping removal", "package", pkg)
+			continue
+		}
+		removed = append(removed, pkg)
+	}
+
+	if len(removed) == 0 {
+		return nil
+	}
+
+	args := append([]string{"dnf", "remove", "-y"}, removed...)
+	if err := dm.run(args); err != nil {
+		return fmt.Errorf("failed to remove DNF packages: %w", err)
+	}
+	for _, pkg := range removed {
+		config.Success("Removed DNF package: %s", pkg)
+	}
+
+	return nil
+}
+
+// ListUpgrades returns packages with updates available from `dnf check-update`
+// Packages locked with dnf versionlock or excluded in dnf.conf are not listed by dnf
+func (dm *DnfManager) ListUpgrades() ([]UpgradeCandidate, error) {
+	output, err := exec.Command("dnf", "-q", "check-update").Output()
+	if err != nil {
+		// Exit status 100 means updates are available
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 100 {
+			return nil, fmt.Errorf("dnf check-update failed: %w", err)
+		}
+	}
+	return parseDnfCheckUpdate(string(output)), nil
+}
+
+// parseDnfCheckUpdate parses `dnf check-update` output ("name.arch  version  repository")
+// Parsing stops at the "Obsoleting Packages" section
+func parseDnfCheckUpdate(output string) []UpgradeCandidate {
+	var candidates []UpgradeCandidate
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "Obsoleting") {
+			break
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		name := fields[0]
+		if dot := strings.LastIndex(name, "."); dot > 0 {
+			name = name[:dot] // Strip the architecture
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		candidates = append(candidates, UpgradeCandidate{Name: name, NewVersion: fields[1]})
+	}
+	return candidates
+}
+
+// Upgrade upgrades the given packages, or all packages when no names are given
+func (dm *DnfManager) Upgrade(packageNames []string) error {
+	args := append([]string{"dnf", "upgrade", "-y"}, packageNames...)
+	if err := dm.run(args); err != nil {
+		return fmt.Errorf("failed to upgrade DNF packages: %w", err)
+	}
+	return nil
+}
+
+// SearchPackages searches the enabled repositories for packages matching a term
+func (dm *DnfManager) SearchPackages(searchTerm string) ([]string, error) {
+	if searchTerm == "" {
+		return nil, fmt.Errorf("search term cannot be empty")
+	}
+
+	output, err := exec.Command("dnf", "-q", "search", searchTerm).Output()
+	if err != nil {
+		return nil, fmt.Errorf("dnf search failed: %w", err)
+	}
+
+	packages := parseDnfSearch(string(output))
+	dm.logger.Debug("Found DNF packages", "search", searchTerm, "count", len(packages))
+	return packages, nil
+}
+
+// parseDnfSearch parses `dnf search` output ("name.arch : summary" for dnf4, " name.arch: summary" for dnf5)
+// Section headers ("=== Name Matched: ... ===", "Matched fields: ...") are skipped
+func parseDnfSearch(output string) []string {
+	var packages []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "=") || strings.HasPrefix(line, "Matched") {
+			continue
+		}
+		nameArch, _, found := strings.Cut(line, ":")
+		nameArch = strings.TrimSpace(nameArch)
+		if !found || strings.Contains(nameArch, " ") {
+			continue
+		}
+		name := nameArch
+		if dot := strings.LastIndex(name, "."); dot > 0 {
+			name = name[:dot] // Strip the architecture
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		packages = append(packages, name)
+	}
+	return packages
+}
+
+// GetPackageInfo returns detailed information about a package or "@group"
+func (dm *DnfManager) GetPackageInfo(packageName string) (string, error) {
+	if packageName == "" {
+		return "", fmt.Errorf("package name cannot be empty")
+	}
+
+	args := []string{"-q", "info", packageName}
+	if isDnfGroup(packageName) {
+		args = []string{"-q", "group", "info", strings.TrimPrefix(packageName, "@")}
+	}
+	output, err := exec.Command("dnf", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("dnf info failed for package %s: %w", packageName, err)
+	}
+	if strings.TrimSpace(string(output)) == "" {
+		return "", fmt.Errorf("no matching DNF package: %s", packageName)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,241 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// setupStubDnf puts stub dnf and rpm executables on PATH; rpm reports git and vim-enhanced as installed
+// Returns the command log path
+func setupStubDnf(t *testing.T) string {
+	t.Helper()
+	binDir := t.TempDir()
+	logPath := filepath.Join(t.TempDir(), "commands.log")
+
+	writeStubCommand(t, binDir, "dnf", `echo "dnf $@" >> `+logPath+`
+`)
+	writeStubCommand(t, binDir, "rpm", `case "$1" in
+  -q) for name in "$@"; do :; done
+      case "$name" in git|vim-enhanced) exit 0 ;; *) exit 1 ;; esac ;;
+  *) echo "rpm $@" >> `+logPath+` ;;
+esac
+`)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return logPath
+}
+
+func TestDnfPackageNameValidation(t *testing.T) {
+	spec, registered := config.LookupPackageManager("dnf")
+	if !registered {
+		t.Fatal("expected dnf to be registered")
+	}
+
+	valid := []string{"git", "python3-devel", "gcc-c++", "@development-tools", "NetworkManager"}
+	for _, name := range valid {
+		if !spec.ValidateName(name) {
+			t.Errorf("expected %q to be valid", name)
+		}
+	}
+
+	invalid := []string{"", "-y", "git;rm", "@", "foo bar"}
+	for _, name := range invalid {
+		if spec.ValidateName(name) {
+			t.Errorf("expected %q to be invalid", name)
+		}
+	}
+}
+
+func TestDnfManager_InstallPackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	logPath := setupStubDnf(t)
+
+	packages := []config.PackageEntry{
+		{Name: "git"},                // installed
+		{Name: "ripgrep"},            // missing
+		{Name: "@development-tools"}, // group, always passed to dnf
+		{Name: "podman", Flags: []string{"-y", "--setopt=install_weak_deps=False"}}, // missing, own flags
+	}
+
+	if err := NewDnfManager(logger, false).InstallPackages(packages, nil); err != nil {
+		t.Fatalf("InstallPackages failed: %v", err)
+	}
+
+	expected := []string{
+		"dnf install -y ripgrep @development-tools",
+		"dnf install -y --setopt=install_weak_deps=False podman",
+	}
+	commands := readCommandLog(t, logPath)
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected commands %v, got %v", expected, commands)
+	}
+}
+
+func TestDnfManager_InstallPackages_DryRun(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	logPath := setupStubDnf(t)
+
+	if err := NewDnfManager(logger, true).InstallPackages([]config.PackageEntry{{Name: "ripgrep"}}, nil); err != nil {
+		t.Fatalf("InstallPackages failed: %v", err)
+	}
+
+	if commands := readCommandLog(t, logPath); len(commands) != 0 {
+		t.Errorf("expected no commands in dry-run mode, got %v", commands)
+	}
+}
+
+func TestDnfManager_RemovePackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	logPath := setupStubDnf(t)
+
+	if err := NewDnfManager(logger, false).RemovePackages([]string{"vim-enhanced", "not-installed", "git"}); err != nil {
+		t.Fatalf("RemovePackages failed: %v", err)
+	}
+
+	expected := []string{"dnf remove -y vim-enhanced git"}
+	commands := readCommandLog(t, logPath)
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected commands %v, got %v", expected, commands)
+	}
+}
+
+func TestRepositoryManager_AddDnfRepositories(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	logPath := setupStubDnf(t)
+
+	reposDir := t.TempDir()
+	original := yumReposDir
+	yumReposDir = reposDir
+	t.Cleanup(func() { yumReposDir = original })
+
+	disabled := false
+	repositories := config.RepositoryManagement{
+		Dnf: []config.DnfRepository{
+			{
+				Name:        "vscode",
+				Description: "Visual Studio Code",
+				BaseURL:     "https://packages.microsoft.com/yumrepos/vscode",
+				GPGKey:      "https://packages.microsoft.com/keys/microsoft.asc",
+			},
+			{
+				Name:     "local",
+				BaseURL:  "file:///srv/repo",
+				GPGCheck: &disabled,
+				Enabled:  &disabled,
+			},
+		},
+	}
+
+	rm := NewRepositoryManager(logger, false)
+	rm.osRelease = config.OSRelease{ID: "fedora"}
+	if err := rm.AddRepositories(repositories); err != nil {
+		t.Fatalf("AddRepositories failed: %v", err)
+	}
+
+	expectedFiles := map[string]string{
+		"vscode.repo": "[vscode]\nname=Visual Studio Code\nbaseurl=https://packages.microsoft.com/yumrepos/vscode\nenabled=1\ngpgcheck=1\ngpgkey=https://packages.microsoft.com/keys/microsoft.asc\n",
+		"local.repo":  "[local]\nname=local\nbaseurl=file:///srv/repo\nenabled=0\ngpgcheck=0\n",
+	}
+	for file, expected := range expectedFiles {
+		content, err := os.ReadFile(filepath.Join(reposDir, file))
+		if err != nil {
+			t.Fatalf("expected %s to be written: %v", file, err)
+		}
+		if string(content) != expected {
+			t.Errorf("%s content mismatch:\nexpected:\n%s\ngot:\n%s", file, expected, content)
+		}
+	}
+
+	expected := []string{"rpm --import https://packages.microsoft.com/keys/microsoft.asc"}
+	commands := readCommandLog(t, logPath)
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected commands %v, got %v", expected, commands)
+	}
+}
+
+func TestRepositoryManager_SkipsUnsupportedRepositories(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	reposDir := t.TempDir()
+	original := yumReposDir
+	yumReposDir = reposDir
+	t.Cleanup(func() { yumReposDir = original })
+
+	repositories := config.RepositoryManagement{
+		Apt: []config.AptRepository{{Name: "python", PPA: "deadsnakes/ppa"}},
+		Dnf: []config.DnfRepository{{Name: "vscode", BaseURL: "https://packages.microsoft.com/yumrepos/vscode"}},
+	}
+
+	// On Ubuntu the DNF repository is skipped; APT repositories run in dry-run mode
+	rm := NewRepositoryManager(logger, true)
+	rm.osRelease = config.OSRelease{ID: "ubuntu", IDLike: []string{"debian"}}
+	if err := rm.AddRepositories(repositories); err != nil {
+		t.Fatalf("AddRepositories failed: %v", err)
+	}
+
+	// On Fedora the APT repository is skipped instead of failing the Ubuntu version check
+	rm = NewRepositoryManager(logger, false)
+	rm.osRelease = config.OSRelease{ID: "fedora"}
+	if err := rm.AddRepositories(config.RepositoryManagement{Apt: repositories.Apt}); err != nil {
+		t.Fatalf("expected APT repositories to be skipped on Fedora, got: %v", err)
+	}
+
+	if entries, _ := os.ReadDir(reposDir); len(entries) != 0 {
+		t.Errorf("expected no repository files, got %d", len(entries))
+	}
+}
+
+func TestParseDnfCheckUpdate(t *testing.T) {
+	output := `
+firefox.x86_64                      130.0-1.fc40           updates
+python3.12.x86_64                   3.12.5-1.fc40          updates
+kernel-core.x86_64                  6.10.8-200.fc40        updates
+Obsoleting Packages
+grub2-tools.x86_64                  1:2.06-123.fc40        updates
+`
+	candidates := parseDnfCheckUpdate(output)
+	names := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		names[i] = candidate.Name
+	}
+	if strings.Join(names, " ") != "firefox python3.12 kernel-core" {
+		t.Errorf("unexpected candidates: %+v", candidates)
+	}
+	if candidates[0].NewVersion != "130.0-1.fc40" {
+		t.Errorf("expected version 130.0-1.fc40, got %q", candidates[0].NewVersion)
+	}
+}
+
+func TestParseDnfSearch(t *testing.T) {
+	dnf4 := `========================= Name Exactly Matched: git =========================
+git.x86_64 : Fast Version Control System
+======================== Name & Summary Matched: git ========================
+git-lfs.x86_64 : Git extension for versioning large files
+git.i686 : Fast Version Control System
+`
+	dnf5 := `Matched fields: name (exact)
+ git.x86_64: Fast Version Control System
+Matched fields: name, summary
+ git-lfs.x86_64: Git extension for versioning large files
+`
+	for _, output := range []string{dnf4, dnf5} {
+		packages := parseDnfSearch(output)
+		if strings.Join(packages, " ") != "git git-lfs" {
+			t.Errorf("unexpected packages: %v", packages)
+		}
+	}
+}
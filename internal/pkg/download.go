@@ -0,0 +1,122 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// downloadCacheDir returns the directory used for downloaded package artifacts
+// Default location: ~/.cache/configr/<kind> (e.g., ~/.cache/configr/debs)
+func downloadCacheDir(kind string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "/tmp"
+	}
+	return filepath.Join(homeDir, ".cache", "configr", kind)
+}
+
+// downloadToCache downloads a URL into the download cache and returns the local path
+// If expectedSHA256 is set, the download is verified and rejected on mismatch
+func downloadToCache(sourceURL, kind, expectedSHA256 string) (string, error) {
+	if !strings.HasPrefix(sourceURL, "https://") {
+		return "", fmt.Errorf("only HTTPS URLs are allowed: %s", sourceURL)
+	}
+
+	cacheDir := downloadCacheDir(kind)
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	destPath := filepath.Join(cacheDir, downloadCacheName(sourceURL))
+
+	if err := downloadFile(sourceURL, destPath, expectedSHA256); err != nil {
+		return "", err
+	}
+
+	return destPath, nil
+}
+
+// downloadCacheName returns the cache file name for a URL
+// The name is prefixed with a hash of the full URL so artifacts with the same basename don't collide,
+// and keeps the basename so tools that look at the extension (apt, flatpak) still recognize the file
+func downloadCacheName(sourceURL string) string {
+	sum := sha256.Sum256([]byte(sourceURL))
+	prefix := hex.EncodeToString(sum[:8])
+
+	parsed, err := url.Parse(sourceURL)
+	if err != nil {
+		return prefix
+	}
+	baseName := path.Base(parsed.Path)
+	if baseName == "." || baseName == "/" {
+		return prefix
+	}
+	return prefix + "-" + baseName
+}
+
+// downloadFile downloads a URL to destPath, verifying the SHA-256 checksum if one is given
+// The file is written to a temporary path first so a failed download never leaves a partial file
+func downloadFile(sourceURL, destPath, expectedSHA256 string) error {
+	client := &http.Client{
+		Timeout: 5 * time.Minute,
+	}
+
+	resp, err := client.Get(sourceURL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", sourceURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download: HTTP %d from %s", resp.StatusCode, sourceURL)
+	}
+
+	tmpPath := destPath + ".part"
+	dst, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create download file: %w", err)
+	}
+
+	hasher := sha256.New()
+	_, copyErr := io.Copy(io.MultiWriter(dst, hasher), resp.Body)
+	closeErr := dst.Close()
+	if copyErr != nil || closeErr != nil {
+		os.Remove(tmpPath)
+		if copyErr != nil {
+			return fmt.Errorf("failed to save download: %w", copyErr)
+		}
+		return fmt.Errorf("failed to save download: %w", closeErr)
+	}
+
+	if err := verifySHA256(hex.EncodeToString(hasher.Sum(nil)), expectedSHA256); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("download from %s rejected: %w", sourceURL, err)
+	}
+
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move download into place: %w", err)
+	}
+
+	return nil
+}
+
+// verifySHA256 compares a computed checksum with the expected one (if any)
+func verifySHA256(actual, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("sha256 mismatch: expected %s, got %s", strings.ToLower(expected), actual)
+	}
+	return nil
+}
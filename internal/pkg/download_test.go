@@ -0,0 +1,101 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadFile(t *testing.T) {
+	content := []byte("package payload")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+
+	t.Run("without checksum", func(t *testing.T) {
+		dest := filepath.Join(tempDir, "plain")
+		if err := downloadFile(server.URL+"/file", dest, ""); err != nil {
+			t.Fatalf("downloadFile failed: %v", err)
+		}
+		data, _ := os.ReadFile(dest)
+		if string(data) != string(content) {
+			t.Errorf("unexpected content: %s", data)
+		}
+	})
+
+	t.Run("matching checksum", func(t *testing.T) {
+		dest := filepath.Join(tempDir, "verified")
+		if err := downloadFile(server.URL+"/file", dest, checksum); err != nil {
+			t.Fatalf("downloadFile failed: %v", err)
+		}
+	})
+
+	t.Run("mismatching checksum", func(t *testing.T) {
+		dest := filepath.Join(tempDir, "rejected")
+		if err := downloadFile(server.URL+"/file", dest, "deadbeef"); err == nil {
+			t.Fatal("expected checksum mismatch error")
+		}
+		if _, err := os.Stat(dest); !os.IsNotExist(err) {
+			t.Error("rejected download should not be left on disk")
+		}
+		if _, err := os.Stat(dest + ".part"); !os.IsNotExist(err) {
+			t.Error("partial download should be cleaned up")
+		}
+	})
+
+	t.Run("HTTP error", func(t *testing.T) {
+		if err := downloadFile(server.URL+"/missing", filepath.Join(tempDir, "missing"), ""); err == nil {
+			t.Fatal("expected error for HTTP 404")
+		}
+	})
+}
+
+func TestDownloadToCache_RejectsInsecureURL(t *testing.T) {
+	if _, err := downloadToCache("http://example.com/app.flatpakref", "flatpak", ""); err == nil {
+		t.Error("expected plain HTTP URL to be rejected")
+	}
+}
+
+func TestDownloadCacheName(t *testing.T) {
+	first := downloadCacheName("https://example.com/v1/app.deb")
+	second := downloadCacheName("https://example.com/v2/app.deb")
+	if first == second {
+		t.Errorf("expected URLs with the same basename to get different cache names, got %s", first)
+	}
+	if !strings.HasSuffix(first, "-app.deb") {
+		t.Errorf("expected the basename to be kept, got %s", first)
+	}
+	if name := downloadCacheName("https://example.com/app.deb?token=abc"); !strings.HasSuffix(name, "-app.deb") {
+		t.Errorf("expected the query to be dropped from the basename, got %s", name)
+	}
+	if name := downloadCacheName("https://example.com/"); strings.Contains(name, "/") || name == "" {
+		t.Errorf("expected a plain file name for a URL without a path, got %s", name)
+	}
+}
+
+func TestVerifySHA256(t *testing.T) {
+	if err := verifySHA256("abc123", ""); err != nil {
+		t.Errorf("empty expected checksum should always pass, got %v", err)
+	}
+	if err := verifySHA256("abc123", "ABC123"); err != nil {
+		t.Errorf("checksum comparison should be case-insensitive, got %v", err)
+	}
+	if err := verifySHA256("abc123", "def456"); err == nil {
+		t.Error("expected mismatch error")
+	}
+}
@@ -0,0 +1,323 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"os/user"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/config"
+)
+
+// AttributeDrift describes an extended attribute of a file that differs from the configuration
+type AttributeDrift struct {
+	Path      string
+	Attribute string // "acl", "xattr <name>", "immutable" or "append_only"
+	Expected  string
+	Actual    string
+}
+
+// unlockDestination reports attribute drift of an existing file and clears its immutable and append-only flags,
+// so it can be replaced; flags that left the configuration aren't set again
+// Without lsattr (or on filesystems without flags) this only fails if flags are configured or were set before
+func (fm *FileManager) unlockDestination(name, destPath string, file config.File) error {
+	if info, err := os.Lstat(destPath); err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+
+	flags, err := fm.getFileFlags(destPath)
+	if err != nil {
+		if file.Immutable || file.AppendOnly || fm.previousFlags[destPath] {
+			return err
+		}
+		fm.logger.Debug("Could not read attribute flags", "path", destPath, "error", err)
+	}
+	locked := strings.ContainsAny(flags, "ia")
+
+	if file.HasExtendedAttributes() || locked {
+		fm.checkAttributeDrift(name, destPath, file)
+	}
+	if !locked {
+		return nil
+	}
+	return fm.unlockFile(destPath)
+}
+
+// checkAttributeDrift reports ACL entries, extended attributes and flags of an existing file that differ from the configuration
+func (fm *FileManager) checkAttributeDrift(name, destPath string, file config.File) {
+	if info, err := os.Lstat(destPath); err != nil || !info.Mode().IsRegular() {
+		return
+	}
+
+	drifts, err := fm.attributeDrift(destPath, file)
+	if err != nil {
+		fm.logger.Warn("Could not check file attributes", "name", name, "path", destPath, "error", err)
+		return
+	}
+	for _, drift := range drifts {
+		fm.logger.Warn("⚠ Attribute drift", "name", name, "path", destPath, "attribute", drift.Attribute, "expected", drift.Expected, "actual", drift.Actual)
+	}
+}
+
+// attributeDrift compares the ACL entries, extended attributes and flags of a file with the configuration
+// ACL entries and extended attributes that aren't configured are left alone, so they aren't drift, but flags that aren't configured are
+func (fm *FileManager) attributeDrift(path string, file config.File) ([]AttributeDrift, error) {
+	var drifts []AttributeDrift
+
+	if len(file.ACL) > 0 {
+		current, err := fm.getACL(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range file.ACL {
+			expected, err := normalizeACLEntry(entry)
+			if err != nil {
+				return nil, err
+			}
+			if !slices.Contains(current, expected) {
+				drifts = append(drifts, AttributeDrift{Path: path, Attribute: "acl", Expected: expected, Actual: aclEntryFor(current, expected)})
+			}
+		}
+	}
+
+	for _, xattr := range slices.Sorted(maps.Keys(file.Xattrs)) {
+		value, exists, err := fm.getXattr(path, xattr)
+		if err != nil {
+			return nil, err
+		}
+		if !exists || value != file.Xattrs[xattr] {
+			actual := value
+			if !exists {
+				actual = "(unset)"
+			}
+			drifts = append(drifts, AttributeDrift{Path: path, Attribute: "xattr " + xattr, Expected: file.Xattrs[xattr], Actual: actual})
+		}
+	}
+
+	// Flags are checked either way, as a flag that isn't configured is drift too
+	flags, err := fm.getFileFlags(path)
+	if err != nil && (file.Immutable || file.AppendOnly) {
+		return nil, err
+	}
+	if err == nil {
+		if immutable := strings.ContainsRune(flags, 'i'); immutable != file.Immutable {
+			drifts = append(drifts, AttributeDrift{Path: path, Attribute: "immutable", Expected: strconv.FormatBool(file.Immutable), Actual: strconv.FormatBool(immutable)})
+		}
+		if appendOnly := strings.ContainsRune(flags, 'a'); appendOnly != file.AppendOnly {
+			drifts = append(drifts, AttributeDrift{Path: path, Attribute: "append_only", Expected: strconv.FormatBool(file.AppendOnly), Actual: strconv.FormatBool(appendOnly)})
+		}
+	}
+
+	return drifts, nil
+}
+
+// setExtendedAttributes sets the configured ACL entries, extended attributes and flags of a deployed copy
+// Flags are set last, as an immutable file can't be changed any further
+func (fm *FileManager) setExtendedAttributes(path string, file config.File) error {
+	if !file.HasExtendedAttributes() {
+		return nil
+	}
+
+	if fm.dryRun {
+		fm.logger.Debug("DRY RUN: Would set extended attributes", "path", path, "acl", file.ACL, "xattrs", len(file.Xattrs), "immutable", file.Immutable, "append_only", file.AppendOnly)
+		return nil
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return fmt.Errorf("failed to get file info: %w", err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		fm.logger.Warn("Extended attributes can't be set on a symlink, deploy the file as a copy", "path", path)
+		return nil
+	}
+
+	if len(file.ACL) > 0 {
+		if err := fm.setACL(path, file.ACL); err != nil {
+			return fmt.Errorf("failed to set ACL: %w", err)
+		}
+	}
+	if len(file.Xattrs) > 0 {
+		if err := fm.setXattrs(path, file.Xattrs); err != nil {
+			return fmt.Errorf("failed to set extended attributes: %w", err)
+		}
+	}
+	if file.Immutable || file.AppendOnly {
+		if err := fm.setFileFlags(path, file.Immutable, file.AppendOnly); err != nil {
+			return fmt.Errorf("failed to set attribute flags: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// setACL adds ACL entries to a file with setfacl; entries that aren't configured are kept
+func (fm *FileManager) setACL(path string, entries []string) error {
+	normalized := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		expected, err := normalizeACLEntry(entry)
+		if err != nil {
+			return err
+		}
+		normalized = append(normalized, expected)
+	}
+
+	fm.logger.Debug("Setting ACL", "path", path, "entries", normalized)
+	return runAttributeCommand("setfacl", "acl", "-m", strings.Join(normalized, ","), path)
+}
+
+// getACL returns the ACL entries of a file as getfacl prints them, without effective permission comments
+func (fm *FileManager) getACL(path string) ([]string, error) {
+	output, err := attributeCommandOutput("getfacl", "acl", "--omit-header", "--absolute-names", path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []string
+	for _, line := range strings.Split(output, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			entries = append(entries, line)
+		}
+	}
+	return entries, nil
+}
+
+// setXattrs sets extended attributes with setfattr; values are passed hex-encoded, so any text is stored as is
+func (fm *FileManager) setXattrs(path string, xattrs map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(xattrs)) {
+		fm.logger.Debug("Setting extended attribute", "path", path, "name", name)
+		if err := runAttributeCommand("setfattr", "attr", "-n", name, "-v", "0x"+hex.EncodeToString([]byte(xattrs[name])), path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getXattr returns the value of an extended attribute, and whether it's set
+func (fm *FileManager) getXattr(path, name string) (string, bool, error) {
+	output, err := attributeCommandOutput("getfattr", "attr", "--absolute-names", "--only-values", "-n", name, path)
+	if err != nil {
+		if strings.Contains(err.Error(), "No such attribute") {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return output, true, nil
+}
+
+// setFileFlags sets the immutable and append-only flags with chattr
+func (fm *FileManager) setFileFlags(path string, immutable, appendOnly bool) error {
+	var flags []string
+	if appendOnly {
+		flags = append(flags, "+a")
+	}
+	if immutable {
+		flags = append(flags, "+i")
+	}
+
+	fm.logger.Debug("Setting attribute flags", "path", path, "flags", flags)
+	return runAttributeCommand("chattr", "e2fsprogs", append(flags, path)...)
+}
+
+// getFileFlags returns the attribute flags of a file as lsattr prints them (e.g. "----i---------e-------")
+func (fm *FileManager) getFileFlags(path string) (string, error) {
+	output, err := attributeCommandOutput("lsattr", "e2fsprogs", "-d", path)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("unexpected lsattr output for %s", path)
+	}
+	return fields[0], nil
+}
+
+// unlockFile clears the immutable and append-only flags of an existing file, so it can be replaced
+// The configured flags are set again once the file is deployed
+func (fm *FileManager) unlockFile(path string) error {
+	if fm.dryRun {
+		return nil
+	}
+	if info, err := os.Lstat(path); err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+
+	flags, err := fm.getFileFlags(path)
+	if err != nil {
+		return err
+	}
+	if !strings.ContainsAny(flags, "ia") {
+		return nil
+	}
+
+	fm.logger.Debug("Clearing attribute flags before replacing file", "path", path, "flags", flags)
+	if err := runAttributeCommand("chattr", "e2fsprogs", "-i", "-a", path); err != nil {
+		return fmt.Errorf("failed to clear attribute flags of %s: %w", path, err)
+	}
+	return nil
+}
+
+// normalizeACLEntry returns an ACL entry the way getfacl prints it, with user and group IDs resolved to names
+func normalizeACLEntry(entry string) (string, error) {
+	normalized, err := config.NormalizeACLEntry(entry)
+	if err != nil {
+		return "", fmt.Errorf("invalid ACL entry %q: %w", entry, err)
+	}
+
+	// getfacl prints names for IDs that have one
+	parts := strings.SplitN(normalized, ":", 3)
+	if parts[1] != "" && strings.Trim(parts[1], "0123456789") == "" {
+		switch parts[0] {
+		case "user":
+			if u, err := user.LookupId(parts[1]); err == nil {
+				parts[1] = u.Username
+			}
+		case "group":
+			if g, err := user.LookupGroupId(parts[1]); err == nil {
+				parts[1] = g.Name
+			}
+		}
+	}
+	return strings.Join(parts, ":"), nil
+}
+
+// aclEntryFor returns the current entry for the same tag and qualifier as expected, or "(unset)"
+func aclEntryFor(current []string, expected string) string {
+	prefix := expected[:strings.LastIndex(expected, ":")+1]
+	for _, entry := range current {
+		if strings.HasPrefix(entry, prefix) {
+			return entry
+		}
+	}
+	return "(unset)"
+}
+
+// runAttributeCommand runs an attribute tool, naming the package that provides it when it's missing
+func runAttributeCommand(name, packageName string, args ...string) error {
+	_, err := attributeCommandOutput(name, packageName, args...)
+	return err
+}
+
+// attributeCommandOutput runs an attribute tool and returns its output
+func attributeCommandOutput(name, packageName string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s not found, install the %s package", name, packageName)
+	}
+
+	cmd := exec.Command(name, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %s: %w", name, strings.TrimSpace(stderr.String()), err)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,145 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+func TestFileManager_DeployFiles_ExtendedAttributes(t *testing.T) {
+	tempDir := t.TempDir()
+	binDir := t.TempDir()
+	callLog := filepath.Join(t.TempDir(), "calls")
+
+	// The attribute tools are stubbed: getters report the current state, setters record their arguments
+	writeStubCommand(t, binDir, "getfacl", "printf 'user::rw-\\ngroup::r--\\ngroup:devs:r--\\t#effective:r--\\nmask::r--\\nother::r--\\n'\n")
+	writeStubCommand(t, binDir, "getfattr", "echo \"$6: $4: No such attribute\" >&2\nexit 1\n")
+	writeStubCommand(t, binDir, "lsattr", "echo \"----i---------e------- $2\"\n")
+	for _, name := range []string{"setfacl", "setfattr", "chattr"} {
+		writeStubCommand(t, binDir, name, "echo \""+name+" $*\" >> "+callLog+"\n")
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var output strings.Builder
+	logger := log.New(&output)
+	logger.SetLevel(log.WarnLevel)
+
+	destFile := filepath.Join(tempDir, "sudoers.d", "team")
+	if err := os.MkdirAll(filepath.Dir(destFile), 0755); err != nil {
+		t.Fatalf("failed to create destination directory: %v", err)
+	}
+	if err := os.WriteFile(destFile, []byte("old\n"), 0644); err != nil {
+		t.Fatalf("failed to create existing file: %v", err)
+	}
+
+	fm := NewFileManager(logger, false, tempDir)
+	_, err := fm.DeployFiles(map[string]config.File{
+		"team": {
+			Content:     "%devs ALL=(ALL) ALL\n",
+			Destination: destFile,
+			ACL:         []string{"g:devs:rw"},
+			Xattrs:      map[string]string{"user.origin": "configr"},
+			Immutable:   true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error during deployment: %v", err)
+	}
+
+	// The ACL entry and extended attribute differ; the file is already immutable
+	logs := output.String()
+	for _, expected := range []string{"attribute=acl expected=group:devs:rw- actual=group:devs:r--", "expected=configr actual=(unset)"} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("expected drift %q to be reported, got:\n%s", expected, logs)
+		}
+	}
+	if strings.Contains(logs, "attribute=immutable") {
+		t.Errorf("expected no immutable drift, got:\n%s", logs)
+	}
+
+	calls, err := os.ReadFile(callLog)
+	if err != nil {
+		t.Fatalf("failed to read call log: %v", err)
+	}
+	expected := strings.Join([]string{
+		"chattr -i -a " + destFile,
+		"setfacl -m group:devs:rw- " + destFile,
+		"setfattr -n user.origin -v 0x636f6e66696772 " + destFile,
+		"chattr +i " + destFile,
+	}, "\n") + "\n"
+	if string(calls) != expected {
+		t.Errorf("unexpected attribute commands:\n%s\nexpected:\n%s", calls, expected)
+	}
+}
+
+func TestFileManager_setExtendedAttributes_MissingTool(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	err := NewFileManager(logger, false, "").setExtendedAttributes(path, config.File{ACL: []string{"user:1000:r"}})
+	if err == nil || !strings.Contains(err.Error(), "install the acl package") {
+		t.Errorf("expected a missing setfacl error, got %v", err)
+	}
+}
+
+func TestFileManager_DeployFiles_FlagsRemovedFromConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	binDir := t.TempDir()
+	callLog := filepath.Join(t.TempDir(), "calls")
+
+	writeStubCommand(t, binDir, "lsattr", "echo \"----i---------e------- $2\"\n")
+	writeStubCommand(t, binDir, "chattr", "echo \"chattr $*\" >> "+callLog+"\n")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var output strings.Builder
+	logger := log.New(&output)
+	logger.SetLevel(log.WarnLevel)
+
+	destFile := filepath.Join(tempDir, "motd")
+	if err := os.WriteFile(destFile, []byte("Welcome\n"), 0644); err != nil {
+		t.Fatalf("failed to create existing file: %v", err)
+	}
+
+	// The previous apply made the file immutable; the configuration no longer does
+	fm := NewFileManager(logger, false, tempDir)
+	fm.SetPreviousFiles([]ManagedFile{{Name: "motd", Destination: destFile, Immutable: true}})
+	deployed, err := fm.DeployFiles(map[string]config.File{
+		"motd": {Content: "Welcome\n", Destination: destFile},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error during deployment: %v", err)
+	}
+	if deployed[0].Immutable {
+		t.Error("expected the file not to be recorded as immutable")
+	}
+
+	if logs := output.String(); !strings.Contains(logs, "attribute=immutable expected=false actual=true") {
+		t.Errorf("expected immutable drift to be reported, got:\n%s", logs)
+	}
+	calls, err := os.ReadFile(callLog)
+	if err != nil {
+		t.Fatalf("failed to read call log: %v", err)
+	}
+	if string(calls) != "chattr -i -a "+destFile+"\n" {
+		t.Errorf("expected only the flags to be cleared, got:\n%s", calls)
+	}
+
+	// Without lsattr, copies without configured or recorded flags are deployed as usual
+	t.Setenv("PATH", t.TempDir())
+	if _, err := NewFileManager(logger, false, tempDir).DeployFiles(map[string]config.File{
+		"motd": {Content: "Hello\n", Destination: destFile},
+	}); err != nil {
+		t.Errorf("expected deployment without lsattr to succeed, got %v", err)
+	}
+}
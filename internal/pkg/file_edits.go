@@ -0,0 +1,273 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/bashfulrobot/configr/internal/config"
+)
+
+// EnsureLines makes sure every configured line exists in its target file
+func (fm *FileManager) EnsureLines(lines map[string]config.LineInFile) error {
+	names := make([]string, 0, len(lines))
+	for name := range lines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		line := lines[name]
+		var pattern *regexp.Regexp
+		if line.Regexp != "" {
+			var err error
+			if pattern, err = regexp.Compile(line.Regexp); err != nil {
+				return fmt.Errorf("invalid regexp for line '%s': %w", name, err)
+			}
+		}
+
+		err := fm.editFile(name, line.Path, line.Create, line.Backup, func(current []string) ([]string, error) {
+			return ensureLine(current, line.Line, pattern), nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to ensure line '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// EnsureBlocks makes sure every configured block exists with its current content in its target file
+func (fm *FileManager) EnsureBlocks(blocks map[string]config.Block) error {
+	names := make([]string, 0, len(blocks))
+	for name := range blocks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		block := blocks[name]
+		begin, end := block.Markers(name)
+		content := splitLines(block.Content)
+
+		err := fm.editFile(name, block.Path, block.Create, block.Backup, func(current []string) ([]string, error) {
+			return ensureBlock(current, begin, end, content)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to ensure block '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// RemoveBlocks removes blocks that are no longer in the configuration from their target files
+func (fm *FileManager) RemoveBlocks(blocks []ManagedBlock) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	fm.logger.Info("Removing blocks no longer in configuration", "count", len(blocks))
+	for _, block := range blocks {
+		begin, end := config.Block{Marker: block.Marker}.Markers(block.Name)
+		err := fm.editFile(block.Name, block.Path, false, false, func(current []string) ([]string, error) {
+			return removeBlock(current, begin, end)
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			fm.logger.Debug("Block target no longer exists", "name", block.Name, "path", block.Path)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to remove block '%s': %w", block.Name, err)
+		}
+	}
+	return nil
+}
+
+// ensureLine returns the lines with line present
+// With a pattern, the last matching line is replaced; without a match the line is appended unless it already exists
+func ensureLine(current []string, line string, pattern *regexp.Regexp) []string {
+	if pattern != nil {
+		for i := len(current) - 1; i >= 0; i-- {
+			if pattern.MatchString(current[i]) {
+				updated := append([]string{}, current...)
+				updated[i] = line
+				return updated
+			}
+		}
+	}
+
+	for _, existing := range current {
+		if existing == line {
+			return current
+		}
+	}
+	return append(append([]string{}, current...), line)
+}
+
+// ensureBlock returns the lines with the block between begin and end replaced by content,
+// or appended if the markers aren't present
+func ensureBlock(current []string, begin, end string, content []string) ([]string, error) {
+	block := append(append([]string{begin}, content...), end)
+
+	start, stop, err := findBlock(current, begin, end)
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 {
+		return append(append([]string{}, current...), block...), nil
+	}
+
+	updated := append([]string{}, current[:start]...)
+	updated = append(updated, block...)
+	return append(updated, current[stop+1:]...), nil
+}
+
+// removeBlock returns the lines without the block between begin and end (markers included)
+func removeBlock(current []string, begin, end string) ([]string, error) {
+	start, stop, err := findBlock(current, begin, end)
+	if err != nil || start < 0 {
+		return current, err
+	}
+	return append(append([]string{}, current[:start]...), current[stop+1:]...), nil
+}
+
+// findBlock returns the line indexes of the begin and end markers, or -1, -1 if the block is missing
+// A begin marker without an end marker is an error: the block can't be told apart from the lines after it
+func findBlock(lines []string, begin, end string) (int, int, error) {
+	for i, line := range lines {
+		if line != begin {
+			continue
+		}
+		for j := i + 1; j < len(lines); j++ {
+			if lines[j] == end {
+				return i, j, nil
+			}
+		}
+		return -1, -1, fmt.Errorf("begin marker on line %d has no matching end marker %q; restore or remove the marker", i+1, end)
+	}
+	return -1, -1, nil
+}
+
+// editFile applies an edit to the lines of a file configr doesn't own
+// Unchanged files aren't touched; dry runs print a unified diff instead of writing
+func (fm *FileManager) editFile(name, path string, create, backup bool, edit func([]string) ([]string, error)) error {
+	targetPath, err := fm.resolveDestinationPath(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	mode := os.FileMode(0644)
+	data, err := os.ReadFile(targetPath)
+	switch {
+	case os.IsNotExist(err) && create:
+		data = nil
+	case os.IsNotExist(err):
+		return fmt.Errorf("target file does not exist: %s (set 'create: true' to create it): %w", targetPath, err)
+	case err != nil:
+		return fmt.Errorf("failed to read %s: %w", targetPath, err)
+	default:
+		if info, statErr := os.Stat(targetPath); statErr == nil {
+			mode = info.Mode().Perm()
+		}
+	}
+
+	current := splitLines(string(data))
+	updated, err := edit(current)
+	if err != nil {
+		return err
+	}
+	if slices.Equal(current, updated) {
+		fm.logger.Debug("File already up to date", "name", name, "path", targetPath)
+		return nil
+	}
+
+	if fm.dryRun {
+		fm.logger.Info("DRY RUN: Would update file", "name", name, "path", targetPath)
+		fmt.Print(unifiedDiff(targetPath, current, updated))
+		return nil
+	}
+
+	if backup && data != nil {
+		if err := fm.backupEditedFile(path, targetPath); err != nil {
+			return err
+		}
+	}
+
+	if err := fm.ensureDirectory(filepath.Dir(targetPath)); err != nil {
+		return err
+	}
+
+	content := ""
+	if len(updated) > 0 {
+		content = strings.Join(updated, "\n") + "\n"
+	}
+	if err := writeEditedFile(targetPath, []byte(content), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", targetPath, err)
+	}
+
+	fm.logger.Info("✓ File updated", "name", name, "path", targetPath)
+	return nil
+}
+
+// writeEditedFile replaces a file through a temporary file, so an interrupted write never leaves it truncated
+// A symlinked file is written at its target, and the owner of an existing file is kept when possible
+func writeEditedFile(path string, data []byte, mode os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	info, statErr := os.Stat(path)
+
+	if err := writeFileAtomic(path, data, mode); err != nil {
+		return err
+	}
+
+	if statErr != nil {
+		return nil
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && (int(stat.Uid) != os.Getuid() || int(stat.Gid) != os.Getgid()) {
+		// Best effort: only root can give the file back to another owner
+		_ = os.Lchown(path, int(stat.Uid), int(stat.Gid))
+	}
+	return nil
+}
+
+// backupEditedFile backs up a file before its first edit in this run
+// The backup is recorded by configured path, so state can keep it from being pruned as orphaned
+func (fm *FileManager) backupEditedFile(path, targetPath string) error {
+	if fm.backedUp == nil {
+		fm.backedUp = make(map[string]string)
+		fm.editBackups = make(map[string]string)
+	}
+
+	id, ok := fm.backedUp[targetPath]
+	if !ok {
+		entry, err := fm.backups.Save(targetPath)
+		if err != nil {
+			return fmt.Errorf("failed to backup file: %w", err)
+		}
+		fm.logger.Info("⚠ Backing up existing file", "from", targetPath, "backup", entry.ID)
+		id = entry.ID
+		fm.backedUp[targetPath] = id
+	}
+	fm.editBackups[path] = id
+	return nil
+}
+
+// EditBackups returns the backups of files edited in place in this run, by configured path
+func (fm *FileManager) EditBackups() map[string]string {
+	return fm.editBackups
+}
+
+// splitLines splits file content into lines, ignoring the final newline
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
@@ -0,0 +1,241 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+func TestEnsureLine(t *testing.T) {
+	hosts := []string{"127.0.0.1 localhost", "127.0.1.1 old-name", "10.0.0.5 nas"}
+
+	tests := []struct {
+		name     string
+		line     string
+		pattern  string
+		expected []string
+	}{
+		{"already present", "10.0.0.5 nas", "", hosts},
+		{"appended", "10.0.0.6 printer", "", append(append([]string{}, hosts...), "10.0.0.6 printer")},
+		{"replaced", "127.0.1.1 laptop", `^127\.0\.1\.1\s`, []string{"127.0.0.1 localhost", "127.0.1.1 laptop", "10.0.0.5 nas"}},
+		{"no match appends", "192.168.1.1 router", `^192\.168\.1\.1\s`, append(append([]string{}, hosts...), "192.168.1.1 router")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pattern *regexp.Regexp
+			if tt.pattern != "" {
+				pattern = regexp.MustCompile(tt.pattern)
+			}
+			got := ensureLine(hosts, tt.line, pattern)
+			if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("unexpected lines:\n%s", strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestEnsureAndRemoveBlock(t *testing.T) {
+	begin, end := config.Block{}.Markers("aliases")
+	original := []string{"export PATH=$PATH:~/bin", "alias ls='ls --color'"}
+
+	added, err := ensureBlock(original, begin, end, []string{"alias g=git"})
+	if err != nil {
+		t.Fatalf("ensureBlock failed: %v", err)
+	}
+	expected := append(append([]string{}, original...), begin, "alias g=git", end)
+	if strings.Join(added, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected lines after adding:\n%s", strings.Join(added, "\n"))
+	}
+
+	updated, err := ensureBlock(append(added, "# user line"), begin, end, []string{"alias g=git", "alias k=kubectl"})
+	if err != nil {
+		t.Fatalf("ensureBlock failed: %v", err)
+	}
+	expected = append(append([]string{}, original...), begin, "alias g=git", "alias k=kubectl", end, "# user line")
+	if strings.Join(updated, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected lines after updating:\n%s", strings.Join(updated, "\n"))
+	}
+
+	removed, err := removeBlock(updated, begin, end)
+	if err != nil {
+		t.Fatalf("removeBlock failed: %v", err)
+	}
+	expected = append(append([]string{}, original...), "# user line")
+	if strings.Join(removed, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected lines after removing:\n%s", strings.Join(removed, "\n"))
+	}
+
+	// A begin marker whose end marker was deleted is reported instead of adding a second block
+	orphaned := append(append([]string{}, original...), begin, "alias g=git")
+	if _, err := ensureBlock(orphaned, begin, end, []string{"alias g=git"}); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected an error for the begin marker on line 3, got %v", err)
+	}
+	if _, err := removeBlock(orphaned, begin, end); err == nil {
+		t.Error("expected an error removing a block without an end marker")
+	}
+}
+
+func TestFileManager_EnsureBlocks(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	bashrc := filepath.Join(tempDir, ".bashrc")
+	if err := os.WriteFile(bashrc, []byte("export EDITOR=vim\n"), 0600); err != nil {
+		t.Fatalf("failed to write target file: %v", err)
+	}
+
+	blocks := map[string]config.Block{
+		"aliases": {Path: bashrc, Content: "alias g=git\n", Backup: true},
+	}
+	expected := "export EDITOR=vim\n# BEGIN CONFIGR MANAGED BLOCK: aliases\nalias g=git\n# END CONFIGR MANAGED BLOCK: aliases\n"
+
+	// Dry runs leave the file alone
+	if err := NewFileManager(logger, true, tempDir).EnsureBlocks(blocks); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if content, _ := os.ReadFile(bashrc); string(content) != "export EDITOR=vim\n" {
+		t.Fatalf("dry run changed the file:\n%s", content)
+	}
+
+	fm := NewFileManager(logger, false, tempDir)
+	store := NewBackupStoreWithPath(logger, filepath.Join(tempDir, "backups"))
+	fm.SetBackupStore(store)
+	if err := fm.EnsureBlocks(blocks); err != nil {
+		t.Fatalf("EnsureBlocks failed: %v", err)
+	}
+	content, _ := os.ReadFile(bashrc)
+	if string(content) != expected {
+		t.Fatalf("unexpected content:\n%s", content)
+	}
+	if info, _ := os.Stat(bashrc); info.Mode().Perm() != 0600 {
+		t.Errorf("expected permissions to be preserved, got %v", info.Mode().Perm())
+	}
+	backups, _ := store.List()
+	if len(backups) != 1 || backups[0].Path != bashrc || backups[0].Mode.Perm() != 0600 {
+		t.Fatalf("expected one backup, got %+v", backups)
+	}
+	if backup, _ := store.Content(backups[0]); string(backup) != "export EDITOR=vim\n" {
+		t.Errorf("unexpected backup content: %q", backup)
+	}
+
+	// A second run is a no-op
+	info, _ := os.Stat(bashrc)
+	if err := NewFileManager(logger, false, tempDir).EnsureBlocks(blocks); err != nil {
+		t.Fatalf("EnsureBlocks failed: %v", err)
+	}
+	if again, _ := os.Stat(bashrc); !again.ModTime().Equal(info.ModTime()) {
+		t.Error("expected an unchanged file not to be rewritten")
+	}
+
+	if err := fm.RemoveBlocks([]ManagedBlock{{Name: "aliases", Path: bashrc}}); err != nil {
+		t.Fatalf("RemoveBlocks failed: %v", err)
+	}
+	if content, _ := os.ReadFile(bashrc); string(content) != "export EDITOR=vim\n" {
+		t.Errorf("expected the block to be removed, got:\n%s", content)
+	}
+}
+
+func TestFileManager_EnsureLines_MissingFile(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	fm := NewFileManager(logger, false, tempDir)
+	target := filepath.Join(tempDir, "environment.d", "editor.conf")
+
+	if err := fm.EnsureLines(map[string]config.LineInFile{"editor": {Path: target, Line: "EDITOR=nvim"}}); err == nil {
+		t.Error("expected an error for a missing target file")
+	}
+
+	if err := fm.EnsureLines(map[string]config.LineInFile{"editor": {Path: target, Line: "EDITOR=nvim", Create: true}}); err != nil {
+		t.Fatalf("EnsureLines failed: %v", err)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "EDITOR=nvim\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestFileManager_EnsureLines_Symlink(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	// Dotfiles kept in a repository are often symlinked into place
+	target := filepath.Join(tempDir, "dotfiles", "gitconfig")
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(target, []byte("[user]\n"), 0640); err != nil {
+		t.Fatalf("failed to write target file: %v", err)
+	}
+	link := filepath.Join(tempDir, ".gitconfig")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	if err := NewFileManager(logger, false, tempDir).EnsureLines(map[string]config.LineInFile{"name": {Path: link, Line: "\tname = Dev"}}); err != nil {
+		t.Fatalf("EnsureLines failed: %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected the symlink to be kept, got %v (%v)", info, err)
+	}
+	if content, _ := os.ReadFile(target); string(content) != "[user]\n\tname = Dev\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0640 {
+		t.Errorf("expected permissions to be preserved, got %v", info.Mode().Perm())
+	}
+	if _, err := os.Stat(target + ".partial"); !os.IsNotExist(err) {
+		t.Errorf("expected no temporary file to be left behind, got %v", err)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	after := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}
+
+	expected := `--- /etc/hosts
++++ /etc/hosts
+@@ -8,3 +8,4 @@
+ h
+ i
+ j
++k
+`
+	if diff := unifiedDiff("/etc/hosts", before, after); diff != expected {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+
+	after = []string{"a", "B", "c", "d", "e", "f", "g", "h", "i", "J"}
+	expected = `--- f
++++ f
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -7,4 +7,4 @@
+ g
+ h
+ i
+-j
++J
+`
+	if diff := unifiedDiff("f", before, after); diff != expected {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+
+	if diff := unifiedDiff("f", before, before); diff != "" {
+		t.Errorf("expected no diff for equal files, got:\n%s", diff)
+	}
+}
@@ -0,0 +1,139 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bashfulrobot/configr/internal/config"
+)
+
+// SetContentStore sets where the content of deployed copies is kept, as the base for merging local edits
+func (fm *FileManager) SetContentStore(store *ContentStore) {
+	fm.content = store
+}
+
+// SetPreviousFiles sets the files deployed by the previous apply, whose content hashes locate the merge base,
+// whose backups stay restorable and whose flags are cleared if they're no longer configured
+func (fm *FileManager) SetPreviousFiles(files []ManagedFile) {
+	fm.deployedHashes = make(map[string]string, len(files))
+	fm.previousBackups = make(map[string]string, len(files))
+	fm.previousFlags = make(map[string]bool)
+	for _, file := range files {
+		if file.Immutable || file.AppendOnly {
+			fm.previousFlags[file.Destination] = true
+		}
+		if file.ContentHash != "" {
+			fm.deployedHashes[file.Destination] = file.ContentHash
+		}
+		if file.BackupID != "" {
+			fm.previousBackups[file.Destination] = file.BackupID
+		}
+	}
+}
+
+// storeDeployedContent keeps the content of a deployed copy, so later local edits can be merged
+func (fm *FileManager) storeDeployedContent(sourcePath string) {
+	if fm.content == nil || fm.dryRun {
+		return
+	}
+	if _, err := fm.content.Save(sourcePath); err != nil {
+		fm.logger.Warn("Could not store deployed content for merging", "error", err)
+	}
+}
+
+// mergeBase returns the content configr last deployed to a destination, if it's known
+func (fm *FileManager) mergeBase(destPath string) ([]byte, bool) {
+	hash, ok := fm.deployedHashes[destPath]
+	if !ok || fm.content == nil {
+		return nil, false
+	}
+	base, err := fm.content.Load(hash)
+	if err != nil {
+		fm.logger.Debug("Last deployed content unavailable", "path", destPath, "error", err)
+		return nil, false
+	}
+	return base, true
+}
+
+// canPullBack reports whether the local version of a file can be copied back to its source:
+// the source must be a plain file in the config repository, not rendered, inline or downloaded
+func canPullBack(file config.File) bool {
+	return !file.Template && !file.IsInline() && !file.IsRemote() && !file.IsEncrypted()
+}
+
+// mergeConflict merges the local edits of a deployed copy with the new version of its source, using the
+// last deployed content as the common ancestor. The result replaces sourcePath, so it's what gets deployed
+// (for plain sources that's the file in the config repository, which keeps the merge)
+// Conflicts, or edit, open the result for the user to resolve. Returns false if conflicts remain unresolved
+func (fm *FileManager) mergeConflict(name, destPath, sourcePath string, file config.File, base []byte, edit bool) (bool, error) {
+	local, err := os.ReadFile(destPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", destPath, err)
+	}
+	remote, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", sourcePath, err)
+	}
+
+	merged, conflicts := mergeLines(splitLines(string(base)), splitLines(string(local)), splitLines(string(remote)), destPath, file.DisplaySource())
+	fm.logger.Info("🔀 Merged local changes", "name", name, "conflicts", conflicts)
+
+	if conflicts > 0 || edit {
+		tmpDir, err := os.MkdirTemp("", "configr-merge-*")
+		if err != nil {
+			return false, fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		// Keep the file name, so editors pick the right syntax
+		fileName := filepath.Base(destPath)
+		paths := map[string][]byte{
+			"base." + fileName:   base,
+			"local." + fileName:  local,
+			"remote." + fileName: remote,
+			fileName:             joinLines(merged),
+		}
+		for path, content := range paths {
+			if err := os.WriteFile(filepath.Join(tmpDir, path), content, 0600); err != nil {
+				return false, fmt.Errorf("failed to write merge file: %w", err)
+			}
+		}
+
+		mergedPath := filepath.Join(tmpDir, fileName)
+		if err := fm.interactive.ResolveMerge(filepath.Join(tmpDir, "base."+fileName), filepath.Join(tmpDir, "local."+fileName), filepath.Join(tmpDir, "remote."+fileName), mergedPath); err != nil {
+			return false, err
+		}
+
+		resolved, err := os.ReadFile(mergedPath)
+		if err != nil {
+			return false, fmt.Errorf("failed to read merge result: %w", err)
+		}
+		merged = splitLines(string(resolved))
+		if hasConflictMarkers(merged) {
+			fm.logger.Warn("⚠ Unresolved conflict markers remain", "name", name)
+			return false, nil
+		}
+	}
+
+	if err := os.WriteFile(sourcePath, joinLines(merged), 0644); err != nil {
+		return false, fmt.Errorf("failed to write merge result: %w", err)
+	}
+	if canPullBack(file) {
+		fm.logger.Info("✓ Merge result saved to source", "name", name, "source", sourcePath)
+	}
+	return true, nil
+}
+
+// pullBack copies the local version of a file over its source in the config repository
+func (fm *FileManager) pullBack(name, destPath, sourcePath string) error {
+	local, err := os.ReadFile(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", destPath, err)
+	}
+	if err := os.WriteFile(sourcePath, local, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", sourcePath, err)
+	}
+	fm.logger.Info("✓ Local version saved to source", "name", name, "source", sourcePath)
+	return nil
+}
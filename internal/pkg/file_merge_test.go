@@ -0,0 +1,165 @@
+package pkg
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+func TestFileManager_DeployFiles_StoresMergeBase(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	if err := os.WriteFile(filepath.Join(tempDir, "gitconfig"), []byte("[user]\nname = Jane\n"), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+	destFile := filepath.Join(tempDir, ".gitconfig")
+
+	fm := NewFileManager(logger, false, tempDir)
+	fm.SetContentStore(NewContentStoreWithPath(logger, filepath.Join(tempDir, "content")))
+	deployed, err := fm.DeployFiles(map[string]config.File{
+		"gitconfig": {Source: "gitconfig", Destination: destFile, Copy: true},
+	})
+	if err != nil {
+		t.Fatalf("DeployFiles failed: %v", err)
+	}
+
+	// The next run finds the deployed content by the hash recorded in state
+	fm.SetPreviousFiles(deployed)
+	base, ok := fm.mergeBase(destFile)
+	if !ok || string(base) != "[user]\nname = Jane\n" {
+		t.Errorf("expected the deployed content as merge base, got %q (%v)", base, ok)
+	}
+	if _, ok := fm.mergeBase(filepath.Join(tempDir, ".unknown")); ok {
+		t.Error("expected no merge base for a file that wasn't deployed")
+	}
+}
+
+func TestFileManager_MergeConflict(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+	t.Setenv("MERGETOOL", "")
+
+	base := []byte("[user]\nname = Jane\n[core]\neditor = vim\n")
+	destPath := filepath.Join(tempDir, ".gitconfig")
+	sourcePath := filepath.Join(tempDir, "gitconfig")
+	file := config.File{Source: "gitconfig", Destination: destPath, Copy: true}
+
+	// Changes to different lines merge cleanly, without opening an editor
+	t.Setenv("EDITOR", "false")
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+	writeFile(destPath, "[user]\nname = Jane Doe\n[core]\neditor = vim\n")
+	writeFile(sourcePath, "[user]\nname = Jane\n[core]\neditor = nvim\n")
+
+	fm := NewFileManager(logger, false, tempDir)
+	merged, err := fm.mergeConflict("gitconfig", destPath, sourcePath, file, base, false)
+	if err != nil || !merged {
+		t.Fatalf("expected a clean merge, got merged=%v err=%v", merged, err)
+	}
+	if content, _ := os.ReadFile(sourcePath); string(content) != "[user]\nname = Jane Doe\n[core]\neditor = nvim\n" {
+		t.Errorf("unexpected merge result:\n%s", content)
+	}
+
+	// Conflicts left in place by the editor aren't accepted
+	writeStubCommand(t, tempDir, "noop-editor", "exit 0\n")
+	t.Setenv("EDITOR", filepath.Join(tempDir, "noop-editor"))
+	writeFile(destPath, "[user]\nname = Jane\n[core]\neditor = emacs\n")
+	writeFile(sourcePath, "[user]\nname = Jane\n[core]\neditor = nvim\n")
+	merged, err = fm.mergeConflict("gitconfig", destPath, sourcePath, file, base, false)
+	if err != nil || merged {
+		t.Fatalf("expected unresolved conflicts, got merged=%v err=%v", merged, err)
+	}
+	if content, _ := os.ReadFile(sourcePath); string(content) != "[user]\nname = Jane\n[core]\neditor = nvim\n" {
+		t.Errorf("expected the source to be unchanged, got:\n%s", content)
+	}
+
+	// A merge tool resolves the conflict, here by taking the local version
+	t.Setenv("MERGETOOL", `cp "$LOCAL" "$MERGED"`)
+	merged, err = fm.mergeConflict("gitconfig", destPath, sourcePath, file, base, false)
+	if err != nil || !merged {
+		t.Fatalf("expected the merge tool to resolve the conflict, got merged=%v err=%v", merged, err)
+	}
+	if content, _ := os.ReadFile(sourcePath); string(content) != "[user]\nname = Jane\n[core]\neditor = emacs\n" {
+		t.Errorf("unexpected merge result:\n%s", content)
+	}
+}
+
+func TestFileManager_PullBack(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	destPath := filepath.Join(tempDir, ".vimrc")
+	sourcePath := filepath.Join(tempDir, "vimrc")
+	if err := os.WriteFile(destPath, []byte("set number\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.WriteFile(sourcePath, []byte("set nonumber\n"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := NewFileManager(logger, false, tempDir).pullBack("vimrc", destPath, sourcePath); err != nil {
+		t.Fatalf("pullBack failed: %v", err)
+	}
+	if content, _ := os.ReadFile(sourcePath); string(content) != "set number\n" {
+		t.Errorf("unexpected source content: %q", content)
+	}
+	if info, _ := os.Stat(sourcePath); info.Mode().Perm() != 0600 {
+		t.Errorf("expected the source permissions to be kept, got %v", info.Mode().Perm())
+	}
+
+	if canPullBack(config.File{Source: "vimrc.tmpl", Template: true}) || canPullBack(config.File{Content: "x"}) || !canPullBack(config.File{Source: "vimrc"}) {
+		t.Error("unexpected canPullBack result")
+	}
+}
+
+func TestInteractiveManager_PromptForConflictResolution_Merge(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	destPath := filepath.Join(tempDir, ".gitconfig")
+	if err := os.WriteFile(destPath, []byte("[user]\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	info, _ := os.Stat(destPath)
+
+	tests := []struct {
+		input    string
+		conflict FileConflictInfo
+		expected ConflictResolution
+	}{
+		{"m\n", FileConflictInfo{CanMerge: true}, ResolutionMerge},
+		{"e\n", FileConflictInfo{CanMerge: true}, ResolutionEdit},
+		{"p\n", FileConflictInfo{CanPullBack: true}, ResolutionPullBack},
+		{"m\np\ns\n", FileConflictInfo{}, ResolutionSkip}, // Unavailable options ask again
+	}
+
+	for _, tt := range tests {
+		im := NewInteractiveManager(logger)
+		im.reader = bufio.NewReader(strings.NewReader(tt.input))
+
+		tt.conflict.Name = "gitconfig"
+		tt.conflict.DestinationPath = destPath
+		tt.conflict.ExistingInfo = info
+		resolution, err := im.PromptForConflictResolution(tt.conflict)
+		if err != nil {
+			t.Fatalf("PromptForConflictResolution failed: %v", err)
+		}
+		if resolution != tt.expected {
+			t.Errorf("input %q: expected resolution %d, got %d", tt.input, tt.expected, resolution)
+		}
+	}
+}
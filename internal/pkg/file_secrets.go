@@ -0,0 +1,106 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bashfulrobot/configr/internal/config"
+)
+
+// secretFileMode is the mode of every file holding decrypted content, whatever the configured mode
+const secretFileMode = "600"
+
+// decryptSource decrypts an encrypted file source, rendering it afterwards if it's templated
+func (fm *FileManager) decryptSource(sourcePath string, file config.File) ([]byte, error) {
+	ciphertext, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encrypted source: %w", err)
+	}
+
+	plaintext, err := fm.secrets.Decrypt(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", file.Source, err)
+	}
+
+	if file.Template {
+		return fm.renderTemplateContent(sourcePath, plaintext)
+	}
+	return plaintext, nil
+}
+
+// deploySecretFile writes decrypted content straight to the destination with mode 0600
+// The plaintext never touches a temporary file, isn't shown in diffs, and state keeps only a keyed fingerprint of it
+func (fm *FileManager) deploySecretFile(name string, file config.File, destPath string, plaintext []byte) (ManagedFile, error) {
+	managedFile := ManagedFile{
+		Name:        name,
+		Destination: destPath,
+		Template:    file.Template,
+		Secret:      true,
+		Root:        file.DirectoryRoot,
+		Immutable:   file.Immutable,
+		AppendOnly:  file.AppendOnly,
+	}
+
+	if fm.dryRun {
+		fm.logger.Info("DRY RUN: Would deploy secret file", "name", name, "destination", destPath, "mode", secretFileMode)
+		return managedFile, nil
+	}
+
+	if err := fm.ensureDirectory(filepath.Dir(destPath)); err != nil {
+		return ManagedFile{}, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	// An up-to-date file is left in place; anything else is removed first
+	upToDate := false
+	if info, err := os.Lstat(destPath); err == nil {
+		if existing, err := os.ReadFile(destPath); err == nil && info.Mode().IsRegular() && bytes.Equal(existing, plaintext) {
+			fm.logger.Debug("Secret file already up to date", "path", destPath)
+			upToDate = true
+		} else {
+			// Never backed up: the backup store would keep the old plaintext outside the destination
+			if file.Backup {
+				fm.logger.Debug("Not backing up secret file", "path", destPath)
+			}
+			if _, err := fm.replaceExistingFile(destPath, false); err != nil {
+				return ManagedFile{}, fmt.Errorf("failed to handle existing file: %w", err)
+			}
+		}
+	}
+	if managedFile.BackupID == "" {
+		// The file was backed up by an earlier apply, keep it restorable
+		managedFile.BackupID = fm.previousBackups[destPath]
+	}
+
+	// Created with 0600, so the plaintext is never readable by others, even briefly
+	if !upToDate {
+		dst, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return ManagedFile{}, fmt.Errorf("failed to create destination file: %w", err)
+		}
+		if _, err := dst.Write(plaintext); err != nil {
+			dst.Close()
+			return ManagedFile{}, fmt.Errorf("failed to write secret file: %w", err)
+		}
+		if err := dst.Close(); err != nil {
+			return ManagedFile{}, fmt.Errorf("failed to write secret file: %w", err)
+		}
+	}
+
+	file.Mode = secretFileMode
+	file.PromptPermissions = false
+	if err := fm.setFileAttributes(destPath, file); err != nil {
+		return ManagedFile{}, fmt.Errorf("failed to set file attributes: %w", err)
+	}
+
+	// Without a fingerprint, removing the file later can't tell whether it was edited and leaves it in place
+	fingerprint, err := fm.secrets.Fingerprint(plaintext)
+	if err != nil {
+		fm.logger.Warn("Could not fingerprint secret file", "path", destPath, "error", err)
+	}
+	managedFile.Fingerprint = fingerprint
+
+	fm.logger.Info("✓ Secret file deployed", "name", name, "destination", destPath)
+	return managedFile, nil
+}
@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/config"
+)
+
+// prepareSource returns the local path of the content to deploy for a file
+// Inline content and remote sources are written to temporary files, which cleanup removes
+func (fm *FileManager) prepareSource(name string, file config.File) (string, func(), error) {
+	noCleanup := func() {}
+
+	switch {
+	case file.IsInline():
+		tmpPath, err := fm.writeTempSource(name, []byte(file.Content))
+		if err != nil {
+			return "", noCleanup, err
+		}
+		return tmpPath, func() { os.Remove(tmpPath) }, nil
+
+	case file.IsRemote():
+		tmpPath, err := fm.downloadSource(name, file)
+		if err != nil {
+			return "", noCleanup, err
+		}
+		return tmpPath, func() { os.Remove(tmpPath) }, nil
+	}
+
+	sourcePath, err := fm.resolveSourcePath(file.Source, file)
+	if err != nil {
+		return "", noCleanup, fmt.Errorf("failed to resolve source path: %w", err)
+	}
+
+	// Check if source file exists
+	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
+		return "", noCleanup, fmt.Errorf("source file does not exist: %s", sourcePath)
+	}
+	return sourcePath, noCleanup, nil
+}
+
+// downloadSource downloads a remote file source to a temporary file, verifying its sha256 if set
+func (fm *FileManager) downloadSource(name string, file config.File) (string, error) {
+	if err := fm.validateSourceURL(file.Source); err != nil {
+		return "", err
+	}
+
+	tmpPath, err := fm.writeTempSource(name, nil)
+	if err != nil {
+		return "", err
+	}
+
+	fm.logger.Debug("Downloading file", "name", name, "url", file.Source)
+	if err := downloadFile(file.Source, tmpPath, file.SHA256); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to download source: %w", err)
+	}
+	return tmpPath, nil
+}
+
+// validateSourceURL checks that a remote file source uses HTTPS
+func (fm *FileManager) validateSourceURL(url string) error {
+	if !strings.HasPrefix(url, "https://") {
+		return fmt.Errorf("source URL must use HTTPS for security: %s", url)
+	}
+	return nil
+}
+
+// writeTempSource writes content for a file to a new temporary file and returns its path
+func (fm *FileManager) writeTempSource(name string, content []byte) (string, error) {
+	tmpFile, err := os.CreateTemp("", "configr-file-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file for %s: %w", name, err)
+	}
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.Write(content); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("failed to write temporary file for %s: %w", name, err)
+	}
+	return tmpFile.Name(), nil
+}
@@ -0,0 +1,127 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+func TestFileManager_DeployFiles_InlineContent(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	// An existing file goes through the usual backup flow
+	destFile := filepath.Join(tempDir, "environment.d", "editor.conf")
+	if err := os.MkdirAll(filepath.Dir(destFile), 0755); err != nil {
+		t.Fatalf("failed to create destination directory: %v", err)
+	}
+	if err := os.WriteFile(destFile, []byte("EDITOR=nano\n"), 0644); err != nil {
+		t.Fatalf("failed to create existing file: %v", err)
+	}
+
+	fm := NewFileManager(logger, false, tempDir)
+	store := NewBackupStoreWithPath(logger, filepath.Join(tempDir, "backups"))
+	fm.SetBackupStore(store)
+	deployed, err := fm.DeployFiles(map[string]config.File{
+		"editor": {Content: "EDITOR=nvim\n", Destination: destFile, Backup: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error during deployment: %v", err)
+	}
+
+	if content, _ := os.ReadFile(destFile); string(content) != "EDITOR=nvim\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+	if info, _ := os.Lstat(destFile); info.Mode()&os.ModeSymlink != 0 {
+		t.Error("inline content should be deployed as a copy")
+	}
+	if len(deployed) != 1 || deployed[0].IsSymlink || deployed[0].ContentHash == "" || deployed[0].BackupID == "" {
+		t.Fatalf("unexpected managed file: %+v", deployed)
+	}
+	entry, err := store.Get(deployed[0].BackupID)
+	if err != nil {
+		t.Fatalf("failed to find backup: %v", err)
+	}
+	if backup, _ := store.Content(entry); string(backup) != "EDITOR=nano\n" {
+		t.Errorf("expected the existing file to be backed up, got %q", backup)
+	}
+}
+
+func TestFileManager_DeployFiles_RemoteSource(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	content := []byte(`SUBSYSTEM=="usb", MODE="0666"` + "\n")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	// Trust the test server's certificate
+	originalTransport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	t.Cleanup(func() { http.DefaultTransport = originalTransport })
+
+	fm := NewFileManager(logger, false, tempDir)
+
+	t.Run("matching checksum", func(t *testing.T) {
+		destFile := filepath.Join(tempDir, "99-usb.rules")
+		_, err := fm.DeployFiles(map[string]config.File{
+			"udev": {Source: server.URL + "/99-usb.rules", SHA256: checksum, Destination: destFile},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error during deployment: %v", err)
+		}
+		if data, _ := os.ReadFile(destFile); string(data) != string(content) {
+			t.Errorf("unexpected content: %q", data)
+		}
+	})
+
+	t.Run("mismatching checksum", func(t *testing.T) {
+		destFile := filepath.Join(tempDir, "rejected.rules")
+		_, err := fm.DeployFiles(map[string]config.File{
+			"udev": {Source: server.URL + "/99-usb.rules", SHA256: strings.Repeat("0", 64), Destination: destFile},
+		})
+		if err == nil || !strings.Contains(err.Error(), "sha256 mismatch") {
+			t.Fatalf("expected a checksum error, got %v", err)
+		}
+		if _, err := os.Stat(destFile); !os.IsNotExist(err) {
+			t.Error("rejected download should not be deployed")
+		}
+	})
+
+	t.Run("insecure URL", func(t *testing.T) {
+		_, err := fm.DeployFiles(map[string]config.File{
+			"udev": {Source: "http://example.com/99-usb.rules", Destination: filepath.Join(tempDir, "insecure.rules")},
+		})
+		if err == nil || !strings.Contains(err.Error(), "HTTPS") {
+			t.Errorf("expected an HTTPS error, got %v", err)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		destFile := filepath.Join(tempDir, "dry-run.rules")
+		_, err := NewFileManager(logger, true, tempDir).DeployFiles(map[string]config.File{
+			"udev": {Source: "https://example.invalid/99-usb.rules", Destination: destFile},
+		})
+		if err != nil {
+			t.Fatalf("dry run should not download: %v", err)
+		}
+		if _, err := os.Stat(destFile); !os.IsNotExist(err) {
+			t.Error("dry run should not deploy the file")
+		}
+	})
+}
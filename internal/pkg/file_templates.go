@@ -0,0 +1,120 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"runtime"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/config"
+)
+
+// TemplateData is the data available to templated files (template: true)
+type TemplateData struct {
+	Vars map[string]interface{} // The vars: section of the configuration
+	Host HostFacts              // Facts about the current host
+	Env  map[string]string      // Environment variables
+}
+
+// HostFacts describes the host a templated file is rendered on
+type HostFacts struct {
+	Hostname      string   // Host name (e.g., "laptop")
+	OS            string   // Operating system (e.g., "linux")
+	Arch          string   // Architecture (e.g., "amd64", "arm64")
+	Distro        string   // os-release ID (e.g., "ubuntu", "fedora")
+	DistroLike    []string // os-release ID_LIKE (e.g., ["debian"])
+	DistroVersion string   // os-release VERSION_ID (e.g., "24.04")
+	DistroName    string   // os-release PRETTY_NAME
+	User          string   // Current user name
+	Home          string   // Home directory of the current user
+}
+
+// detectHostFacts gathers the facts available to templates as .Host
+// Facts that can't be determined are left empty
+func detectHostFacts() HostFacts {
+	facts := HostFacts{
+		OS:   runtime.GOOS,
+		Arch: runtime.GOARCH,
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		facts.Hostname = hostname
+	}
+	if osRelease, err := config.DetectOSRelease(); err == nil {
+		facts.Distro = osRelease.ID
+		facts.DistroLike = osRelease.IDLike
+		facts.DistroVersion = osRelease.VersionID
+		facts.DistroName = osRelease.PrettyName
+	}
+	if currentUser, err := user.Current(); err == nil {
+		facts.User = currentUser.Username
+		facts.Home = currentUser.HomeDir
+	}
+	return facts
+}
+
+// environmentMap returns the environment variables as a map
+func environmentMap() map[string]string {
+	env := make(map[string]string)
+	for _, entry := range os.Environ() {
+		if key, value, found := strings.Cut(entry, "="); found {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// SetTemplateVars sets the variables available to templated files as .Vars
+func (fm *FileManager) SetTemplateVars(vars map[string]interface{}) {
+	fm.templateVars = vars
+}
+
+// SetSecretManager sets the secret manager used to decrypt sources, e.g. the one that decrypted the vars
+func (fm *FileManager) SetSecretManager(secrets *SecretManager) {
+	fm.secrets = secrets
+}
+
+// templateData returns the data templates are rendered with; host facts are detected once
+func (fm *FileManager) templateData() TemplateData {
+	if fm.hostFacts == nil {
+		facts := detectHostFacts()
+		fm.hostFacts = &facts
+	}
+
+	vars := fm.templateVars
+	if vars == nil {
+		vars = make(map[string]interface{})
+	}
+	return TemplateData{
+		Vars: vars,
+		Host: *fm.hostFacts,
+		Env:  environmentMap(),
+	}
+}
+
+// renderTemplate renders a templated source file
+func (fm *FileManager) renderTemplate(sourcePath string) ([]byte, error) {
+	content, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	return fm.renderTemplateContent(sourcePath, content)
+}
+
+// renderTemplateContent renders template content read from (or decrypted from) sourcePath
+// Rendering is strict: undefined variables are errors instead of "<no value>"
+func (fm *FileManager) renderTemplateContent(sourcePath string, content []byte) ([]byte, error) {
+	tmpl, err := config.ParseFileTemplate(sourcePath, string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, fm.templateData()); err != nil {
+		// Execution errors may quote values, which can be decrypted vars
+		return nil, fmt.Errorf("failed to render template: %s", fm.secrets.Redact(err.Error()))
+	}
+	return rendered.Bytes(), nil
+}
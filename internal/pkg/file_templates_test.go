@@ -0,0 +1,89 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+func TestFileManager_DeployFiles_Template(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	t.Setenv("CONFIGR_TEST_PROXY", "proxy.example.com")
+	template := `email = {{ .Vars.git.email }}
+arch = {{ .Host.Arch }}
+proxy = {{ .Env.CONFIGR_TEST_PROXY }}
+scale = {{ index .Vars "scale" | default "1" }}
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "gitconfig.tmpl"), []byte(template), 0644); err != nil {
+		t.Fatalf("failed to create template: %v", err)
+	}
+
+	fm := NewFileManager(logger, false, tempDir)
+	fm.SetTemplateVars(map[string]interface{}{
+		"git": map[string]interface{}{"email": "me@example.com"},
+	})
+
+	destFile := filepath.Join(tempDir, "gitconfig")
+	deployed, err := fm.DeployFiles(map[string]config.File{
+		"gitconfig": {Source: "gitconfig.tmpl", Destination: destFile, Template: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error during deployment: %v", err)
+	}
+
+	content, err := os.ReadFile(destFile)
+	if err != nil {
+		t.Fatalf("failed to read rendered file: %v", err)
+	}
+	expected := "email = me@example.com\narch = " + runtime.GOARCH + "\nproxy = proxy.example.com\nscale = 1\n"
+	if string(content) != expected {
+		t.Errorf("unexpected rendered content:\n%s", content)
+	}
+
+	// Rendered files are copies tracked by content hash
+	if info, _ := os.Lstat(destFile); info.Mode()&os.ModeSymlink != 0 {
+		t.Error("templated files should be deployed as copies")
+	}
+	if len(deployed) != 1 || deployed[0].IsSymlink || !deployed[0].Template || deployed[0].ContentHash == "" {
+		t.Fatalf("unexpected managed file: %+v", deployed)
+	}
+
+	modified, err := fm.isFileModifiedByUser(destFile, deployed[0])
+	if err != nil || modified {
+		t.Errorf("expected an unmodified file, got modified=%v err=%v", modified, err)
+	}
+	if err := os.WriteFile(destFile, []byte("email = local@example.com\n"), 0644); err != nil {
+		t.Fatalf("failed to edit rendered file: %v", err)
+	}
+	modified, err = fm.isFileModifiedByUser(destFile, deployed[0])
+	if err != nil || !modified {
+		t.Errorf("expected a local edit to be detected, got modified=%v err=%v", modified, err)
+	}
+}
+
+func TestFileManager_DeployFiles_TemplateUndefinedVariable(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	if err := os.WriteFile(filepath.Join(tempDir, "proxy.tmpl"), []byte("host = {{ .Vars.proxy_host }}\n"), 0644); err != nil {
+		t.Fatalf("failed to create template: %v", err)
+	}
+
+	// Dry runs render too, so undefined variables are reported before anything changes
+	fm := NewFileManager(logger, true, tempDir)
+	_, err := fm.DeployFiles(map[string]config.File{
+		"proxy": {Source: "proxy.tmpl", Destination: filepath.Join(tempDir, "proxy.conf"), Template: true},
+	})
+	if err == nil || !strings.Contains(err.Error(), `"proxy_host"`) {
+		t.Errorf("expected an undefined variable error, got %v", err)
+	}
+}
@@ -21,15 +21,29 @@ type FileManager struct {
 	dryRun      bool
 	configDir   string
 	interactive *InteractiveManager
+
+	templateVars map[string]interface{} // vars: section available to templated files
+	secrets      *SecretManager         // Decrypts encrypted sources and vars
+	hostFacts    *HostFacts             // Host facts for templates, detected on first use
+	backedUp     map[string]string      // Backup of each file edited in place in this run, by path
+	editBackups  map[string]string      // Backup of each file edited in place in this run, by configured path
+	backups      *BackupStore           // Where replaced and edited files are backed up
+
+	content         *ContentStore     // Content of deployed copies, the base for merging local edits
+	deployedHashes  map[string]string // Content hash of each copy deployed by the previous apply, by destination
+	previousBackups map[string]string // Backup of each file deployed by the previous apply, by destination
+	previousFlags   map[string]bool   // Files the previous apply made immutable or append-only, by destination
+	keptFiles       []ManagedFile     // Edited secret files RemoveFiles left in place
 }
 
 // BackupInfo contains information about available backups
 type BackupInfo struct {
-	FileName       string    `json:"file_name"`
-	BackupPath     string    `json:"backup_path"`
+	FileName       string    `json:"file_name,omitempty"` // Managed file the backup belongs to, if any
+	ID             string    `json:"id"`
 	OriginalPath   string    `json:"original_path"`
 	BackupTime     time.Time `json:"backup_time"`
 	BackupSize     int64     `json:"backup_size"`
+	Run            string    `json:"run"`
 	OriginalExists bool      `json:"original_exists"`
 }
 
@@ -40,9 +54,16 @@ func NewFileManager(logger *log.Logger, dryRun bool, configDir string) *FileMana
 		dryRun:      dryRun,
 		configDir:   configDir,
 		interactive: NewInteractiveManager(logger),
+		secrets:     NewSecretManager(logger),
+		backups:     NewBackupStore(logger),
 	}
 }
 
+// SetBackupStore sets where replaced and edited files are backed up
+func (fm *FileManager) SetBackupStore(store *BackupStore) {
+	fm.backups = store
+}
+
 // DeployFiles processes all files in the configuration and returns deployed file info
 func (fm *FileManager) DeployFiles(files map[string]config.File) ([]ManagedFile, error) {
 	if len(files) == 0 {
@@ -67,13 +88,7 @@ func (fm *FileManager) DeployFiles(files map[string]config.File) ([]ManagedFile,
 
 // deployFile handles the deployment of a single file and returns file info
 func (fm *FileManager) deployFile(name string, file config.File) (ManagedFile, error) {
-	fm.logger.Debug("Deploying file", "name", name, "source", file.Source, "destination", file.Destination)
-
-	// Resolve source path
-	sourcePath, err := fm.resolveSourcePath(file.Source, file)
-	if err != nil {
-		return ManagedFile{}, fmt.Errorf("failed to resolve source path: %w", err)
-	}
+	fm.logger.Debug("Deploying file", "name", name, "source", file.DisplaySource(), "destination", file.Destination)
 
 	// Resolve destination path
 	destPath, err := fm.resolveDestinationPath(file.Destination)
@@ -81,11 +96,54 @@ func (fm *FileManager) deployFile(name string, file config.File) (ManagedFile, e
 		return ManagedFile{}, fmt.Errorf("failed to resolve destination path: %w", err)
 	}
 
-	// Check if source file exists
-	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-		return ManagedFile{}, fmt.Errorf("source file does not exist: %s", sourcePath)
+	// Remote sources aren't downloaded during a dry run
+	if file.IsRemote() && fm.dryRun {
+		fm.logger.Info("DRY RUN: Would download file", "name", name, "url", file.Source, "destination", destPath)
+		return ManagedFile{Name: name, Destination: destPath, Template: file.Template}, nil
+	}
+
+	// Report attributes changed outside configr; immutable and append-only files are unlocked to be replaced
+	if err := fm.unlockDestination(name, destPath, file); err != nil {
+		return ManagedFile{}, err
+	}
+
+	// Resolve source path; inline content and downloads are written to temporary files
+	sourcePath, cleanup, err := fm.prepareSource(name, file)
+	if err != nil {
+		return ManagedFile{}, err
+	}
+	defer cleanup()
+
+	// Encrypted sources are decrypted in memory and written only to the destination
+	if file.IsEncrypted() {
+		plaintext, err := fm.decryptSource(sourcePath, file)
+		if err != nil {
+			return ManagedFile{}, err
+		}
+		return fm.deploySecretFile(name, file, destPath, plaintext)
+	}
+
+	// Render templated files; output containing decrypted vars is deployed like an encrypted file
+	if file.Template {
+		rendered, err := fm.renderTemplate(sourcePath)
+		if err != nil {
+			return ManagedFile{}, err
+		}
+		if fm.secrets.ContainsSecret(rendered) {
+			return fm.deploySecretFile(name, file, destPath, rendered)
+		}
+
+		renderedPath, err := fm.writeTempSource(name, rendered)
+		if err != nil {
+			return ManagedFile{}, err
+		}
+		defer os.Remove(renderedPath)
+		sourcePath = renderedPath
 	}
 
+	// Inline, remote and templated files have nothing to link to and are deployed as copies
+	file.Copy = file.DeploysAsCopy()
+
 	// Create destination directory if it doesn't exist
 	destDir := filepath.Dir(destPath)
 	if err := fm.ensureDirectory(destDir); err != nil {
@@ -93,17 +151,28 @@ func (fm *FileManager) deployFile(name string, file config.File) (ManagedFile, e
 	}
 
 	// Handle existing file (backup if needed, with interactive support)
-	backupPath, err := fm.handleExistingFile(name, destPath, sourcePath, file)
+	backupID, err := fm.handleExistingFile(name, destPath, sourcePath, file)
 	if err != nil {
 		return ManagedFile{}, fmt.Errorf("failed to handle existing file: %w", err)
 	}
+	if backupID == "" {
+		// The file was backed up by an earlier apply, keep it restorable
+		backupID = fm.previousBackups[destPath]
+	}
 
 	// Deploy file (either copy or symlink)
 	isSymlink := !file.Copy
+	var contentHash string
 	if file.Copy {
 		if err := fm.copyFile(sourcePath, destPath); err != nil {
 			return ManagedFile{}, fmt.Errorf("failed to copy file: %w", err)
 		}
+
+		// Remember what was deployed so later local edits can be detected and merged
+		if contentHash, err = fm.calculateFileHash(sourcePath); err != nil {
+			return ManagedFile{}, fmt.Errorf("failed to hash deployed content: %w", err)
+		}
+		fm.storeDeployedContent(sourcePath)
 	} else {
 		if err := fm.createSymlink(sourcePath, destPath); err != nil {
 			return ManagedFile{}, fmt.Errorf("failed to create symlink: %w", err)
@@ -122,7 +191,12 @@ func (fm *FileManager) deployFile(name string, file config.File) (ManagedFile, e
 		Name:        name,
 		Destination: destPath,
 		IsSymlink:   isSymlink,
-		BackupPath:  backupPath,
+		BackupID:    backupID,
+		Template:    file.Template,
+		ContentHash: contentHash,
+		Root:        file.DirectoryRoot,
+		Immutable:   file.Immutable,
+		AppendOnly:  file.AppendOnly,
 	}, nil
 }
 
@@ -198,7 +272,7 @@ func (fm *FileManager) ensureDirectory(dir string) error {
 }
 
 // handleExistingFile handles existing files at the destination, with interactive support
-// Returns the backup path if a backup was created, empty string otherwise
+// Returns the backup ID if a backup was created, empty string otherwise
 func (fm *FileManager) handleExistingFile(name, destPath, sourcePath string, file config.File) (string, error) {
 	if _, err := os.Lstat(destPath); os.IsNotExist(err) {
 		// File doesn't exist, nothing to handle
@@ -216,7 +290,7 @@ func (fm *FileManager) handleExistingFile(name, destPath, sourcePath string, fil
 	if fm.dryRun {
 		if file.Backup {
 			fm.logger.Debug("DRY RUN: Would backup existing file", "path", destPath)
-			return fmt.Sprintf("%s.backup.%s", destPath, time.Now().Format("20060102-150405")), nil
+			return "", nil
 		} else {
 			fm.logger.Debug("DRY RUN: Would remove existing file", "path", destPath)
 			return "", nil
@@ -251,6 +325,13 @@ func (fm *FileManager) handleExistingFile(name, destPath, sourcePath string, fil
 			ExistingInfo:    fileInfo,
 			IsSymlink:       isSymlink,
 			BackupEnabled:   file.Backup,
+			CanPullBack:     !isSymlink && fileInfo.Mode().IsRegular() && canPullBack(file),
+		}
+
+		// Local edits of a copy can be merged when configr knows what it deployed last
+		var base []byte
+		if !isSymlink && file.Copy {
+			base, conflict.CanMerge = fm.mergeBase(destPath)
 		}
 
 		for {
@@ -270,6 +351,25 @@ func (fm *FileManager) handleExistingFile(name, destPath, sourcePath string, fil
 					fm.logger.Warn("Failed to show diff", "error", err)
 				}
 				continue // Ask again
+			case ResolutionMerge, ResolutionEdit:
+				merged, err := fm.mergeConflict(name, destPath, sourcePath, file, base, resolution == ResolutionEdit)
+				if err != nil {
+					fm.logger.Warn("Failed to merge", "error", err)
+					continue // Ask again
+				}
+				if !merged {
+					continue // Ask again
+				}
+				// The destination is overwritten in place with the merge result, no backup needed
+				return "", nil
+			case ResolutionPullBack:
+				if err := fm.pullBack(name, destPath, sourcePath); err != nil {
+					fm.logger.Warn("Failed to save local version", "error", err)
+					continue // Ask again
+				}
+				if file.Copy {
+					return "", nil // The destination already holds the new source content
+				}
 			case ResolutionOverwrite:
 				break // Continue with overwrite
 			case ResolutionBackup:
@@ -281,16 +381,23 @@ func (fm *FileManager) handleExistingFile(name, destPath, sourcePath string, fil
 	}
 
 	// Determine if we should backup based on config or user choice
-	shouldBackup := file.Backup
-	
-	if shouldBackup {
-		backupPath := fmt.Sprintf("%s.backup.%s", destPath, time.Now().Format("20060102-150405"))
-		fm.logger.Info("⚠ Backing up existing file", "from", destPath, "to", backupPath)
-		
-		if err := os.Rename(destPath, backupPath); err != nil {
+	return fm.replaceExistingFile(destPath, file.Backup)
+}
+
+// replaceExistingFile moves an existing destination out of the way, backing it up if requested
+// Returns the backup ID if a backup was created, empty string otherwise
+func (fm *FileManager) replaceExistingFile(destPath string, backup bool) (string, error) {
+	if backup {
+		entry, err := fm.backups.Save(destPath)
+		if err != nil {
 			return "", fmt.Errorf("failed to backup file: %w", err)
 		}
-		return backupPath, nil
+		fm.logger.Info("⚠ Backing up existing file", "from", destPath, "backup", entry.ID)
+
+		if err := os.Remove(destPath); err != nil {
+			return "", fmt.Errorf("failed to remove backed up file at %s: %w", destPath, err)
+		}
+		return entry.ID, nil
 	} else {
 		fm.logger.Info("⚠ Removing existing file", "path", destPath)
 		if err := os.Remove(destPath); err != nil {
@@ -358,7 +465,7 @@ func (fm *FileManager) copyFile(sourcePath, destPath string) error {
 	return nil
 }
 
-// setFileAttributes sets ownership and permissions on the file if specified
+// setFileAttributes sets ownership, permissions and extended attributes on the file if specified
 func (fm *FileManager) setFileAttributes(destPath string, file config.File) error {
 	if fm.dryRun {
 		fm.logger.Debug("DRY RUN: Would set file attributes", "path", destPath, "mode", file.Mode, "owner", file.Owner, "group", file.Group)
@@ -411,7 +518,8 @@ func (fm *FileManager) setFileAttributes(destPath string, file config.File) erro
 		}
 	}
 
-	return nil
+	// Set ACL entries, extended attributes and flags last, an immutable file can't be changed anymore
+	return fm.setExtendedAttributes(destPath, file)
 }
 
 // getCurrentOwnership gets the current owner and group names for a file
@@ -628,6 +736,11 @@ func (fm *FileManager) removeFile(file ManagedFile) error {
 		if modified, err := fm.isFileModifiedByUser(file.Destination, file); err != nil {
 			fm.logger.Warn("Could not check if file was modified", "destination", file.Destination, "error", err)
 			// Continue with removal but log the warning
+		} else if modified && file.Secret {
+			// Secrets are often edited in place (e.g. refreshed tokens); they stay tracked instead of failing the apply
+			fm.logger.Warn("⚠ Secret file appears modified, leaving it in place", "name", file.Name, "destination", file.Destination)
+			fm.keptFiles = append(fm.keptFiles, file)
+			return nil
 		} else if modified {
 			fm.logger.Warn("File appears to be modified by user, skipping removal for safety", "destination", file.Destination)
 			return fmt.Errorf("file appears modified by user, skipping removal for safety: %s", file.Destination)
@@ -639,6 +752,13 @@ func (fm *FileManager) removeFile(file ManagedFile) error {
 		return nil
 	}
 
+	// Copies may have been made immutable or append-only, which prevents removing them
+	if !file.IsSymlink {
+		if err := fm.unlockFile(file.Destination); err != nil {
+			fm.logger.Debug("Could not check attribute flags", "destination", file.Destination, "error", err)
+		}
+	}
+
 	// Perform the removal
 	if err := os.Remove(file.Destination); err != nil {
 		return fmt.Errorf("failed to remove file: %w", err)
@@ -646,10 +766,15 @@ func (fm *FileManager) removeFile(file ManagedFile) error {
 
 	fm.logger.Info("✓ File removed", "name", file.Name, "destination", file.Destination)
 
+	// Files pruned from a directory tree don't leave empty directories behind
+	if file.Root != "" {
+		fm.removeEmptyParents(filepath.Dir(file.Destination), file.Root)
+	}
+
 	// If there was a backup, optionally restore it
-	if file.BackupPath != "" {
+	if file.BackupID != "" || file.BackupPath != "" {
 		if err := fm.offerBackupRestore(file); err != nil {
-			fm.logger.Warn("Could not restore backup", "backup", file.BackupPath, "error", err)
+			fm.logger.Warn("Could not restore backup", "name", file.Name, "error", err)
 			// Don't fail the removal operation for backup restoration issues
 		}
 	}
@@ -672,16 +797,33 @@ func (fm *FileManager) verifySymlinkSafety(symlinkPath string) error {
 	return nil
 }
 
-// isFileModifiedByUser attempts to detect if a copied file was modified by the user
+// KeptFiles returns the edited secret files RemoveFiles left in place, which stay tracked in state
+func (fm *FileManager) KeptFiles() []ManagedFile {
+	return fm.keptFiles
+}
+
+// isFileModifiedByUser detects if a copied file was modified by the user
+// Files deployed with a content hash or fingerprint are compared against it; older state falls back to a heuristic
 func (fm *FileManager) isFileModifiedByUser(filePath string, file ManagedFile) (bool, error) {
-	// This is a basic heuristic - in a full implementation, you might:
-	// 1. Store checksums of deployed files
-	// 2. Compare modification times
-	// 3. Use more sophisticated change detection
-	
-	// For now, we'll be conservative and assume files might be modified
-	// A more sophisticated implementation would store file hashes in the state
-	
+	if file.Secret && file.Fingerprint != "" {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return false, err
+		}
+		fingerprint, err := fm.secrets.Fingerprint(content)
+		if err != nil {
+			return false, err
+		}
+		return fingerprint != file.Fingerprint, nil
+	}
+	if file.ContentHash != "" {
+		currentHash, err := fm.calculateFileHash(filePath)
+		if err != nil {
+			return false, err
+		}
+		return currentHash != file.ContentHash, nil
+	}
+
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
 		return false, err
@@ -699,13 +841,22 @@ func (fm *FileManager) isFileModifiedByUser(filePath string, file ManagedFile) (
 
 // offerBackupRestore handles backup restoration when removing files
 func (fm *FileManager) offerBackupRestore(file ManagedFile) error {
-	// Check if backup still exists
-	if _, err := os.Stat(file.BackupPath); os.IsNotExist(err) {
-		fm.logger.Debug("Backup file no longer exists", "backup", file.BackupPath)
-		return nil
+	backup := file.BackupID
+	if backup != "" {
+		if _, err := fm.backups.Get(backup); err != nil {
+			fm.logger.Debug("Backup no longer exists", "backup", backup, "error", err)
+			return nil
+		}
+	} else {
+		// State written before the backup store refers to a sidecar backup file
+		backup = file.BackupPath
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			fm.logger.Debug("Backup file no longer exists", "backup", backup)
+			return nil
+		}
 	}
 
-	fm.logger.Info("📁 Backup available for removed file", "backup", file.BackupPath, "original", file.Destination)
+	fm.logger.Info("📁 Backup available for removed file", "backup", backup, "original", file.Destination)
 	
 	// Interactive backup restoration offer
 	if fm.interactive.IsInteractiveMode() {
@@ -719,6 +870,9 @@ func (fm *FileManager) offerBackupRestore(file ManagedFile) error {
 		}
 		
 		if shouldRestore {
+			if file.BackupID != "" {
+				return fm.RestoreBackup(file.BackupID, file.Destination)
+			}
 			return fm.RestoreFromBackup(file.BackupPath, file.Destination)
 		}
 	}
@@ -726,7 +880,24 @@ func (fm *FileManager) offerBackupRestore(file ManagedFile) error {
 	return nil
 }
 
-// RestoreFromBackup restores a file from its backup
+// RestoreBackup restores a file from the backup store, with its original mode and ownership
+func (fm *FileManager) RestoreBackup(id, originalDestination string) error {
+	fm.logger.Info("🔄 Restoring file from backup", "backup", id, "destination", originalDestination)
+
+	if fm.dryRun {
+		fm.logger.Info("DRY RUN: Would restore file from backup", "backup", id, "destination", originalDestination)
+		return nil
+	}
+
+	if err := fm.backups.Restore(id, originalDestination); err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	fm.logger.Info("✓ File restored from backup successfully", "destination", originalDestination)
+	return nil
+}
+
+// RestoreFromBackup restores a file from a sidecar backup made before the backup store
 func (fm *FileManager) RestoreFromBackup(backupPath, originalDestination string) error {
 	fm.logger.Info("🔄 Restoring file from backup", "backup", backupPath, "destination", originalDestination)
 	
@@ -768,24 +939,32 @@ func (fm *FileManager) RestoreAllBackups(managedFiles []ManagedFile) error {
 	var restored, failed int
 	
 	for _, file := range managedFiles {
-		if file.BackupPath == "" {
+		if file.BackupID == "" && file.BackupPath == "" {
 			continue // No backup for this file
 		}
 		
-		// Check if backup still exists
-		if _, err := os.Stat(file.BackupPath); os.IsNotExist(err) {
-			fm.logger.Debug("Backup no longer exists", "backup", file.BackupPath)
-			continue
-		}
-		
 		// Check if original file exists (don't restore if file is still there)
 		if _, err := os.Lstat(file.Destination); err == nil {
 			fm.logger.Debug("Original file still exists, skipping restore", "destination", file.Destination)
 			continue
 		}
 		
-		if err := fm.RestoreFromBackup(file.BackupPath, file.Destination); err != nil {
-			fm.logger.Error("Failed to restore backup", "file", file.Name, "backup", file.BackupPath, "error", err)
+		var err error
+		if file.BackupID != "" {
+			if _, getErr := fm.backups.Get(file.BackupID); getErr != nil {
+				fm.logger.Debug("Backup no longer exists", "backup", file.BackupID)
+				continue
+			}
+			err = fm.RestoreBackup(file.BackupID, file.Destination)
+		} else {
+			if _, statErr := os.Stat(file.BackupPath); os.IsNotExist(statErr) {
+				fm.logger.Debug("Backup no longer exists", "backup", file.BackupPath)
+				continue
+			}
+			err = fm.RestoreFromBackup(file.BackupPath, file.Destination)
+		}
+		if err != nil {
+			fm.logger.Error("Failed to restore backup", "file", file.Name, "error", err)
 			failed++
 		} else {
 			restored++
@@ -803,189 +982,110 @@ func (fm *FileManager) RestoreAllBackups(managedFiles []ManagedFile) error {
 	return nil
 }
 
-// ListBackups returns information about available backups
+// ListBackups returns information about the backups in the backup store, oldest first
 func (fm *FileManager) ListBackups(managedFiles []ManagedFile) []BackupInfo {
-	var backups []BackupInfo
-	
+	entries, err := fm.backups.List()
+	if err != nil {
+		fm.logger.Warn("Could not read backup index", "error", err)
+		return nil
+	}
+
+	// Backups a managed file refers to are listed under its name
+	names := make(map[string]string, len(managedFiles))
 	for _, file := range managedFiles {
-		if file.BackupPath == "" {
-			continue
-		}
-		
-		backupStat, err := os.Stat(file.BackupPath)
-		if os.IsNotExist(err) {
-			continue // Backup doesn't exist
+		if file.BackupID != "" {
+			names[file.BackupID] = file.Name
 		}
-		
-		// Check if original file exists
-		originalExists := true
-		if _, err := os.Lstat(file.Destination); os.IsNotExist(err) {
-			originalExists = false
-		}
-		
-		backup := BackupInfo{
-			FileName:       file.Name,
-			BackupPath:     file.BackupPath,
-			OriginalPath:   file.Destination,
-			BackupTime:     backupStat.ModTime(),
-			BackupSize:     backupStat.Size(),
-			OriginalExists: originalExists,
-		}
-		
-		backups = append(backups, backup)
+	}
+
+	backups := make([]BackupInfo, 0, len(entries))
+	for _, entry := range entries {
+		_, err := os.Lstat(entry.Path)
+		backups = append(backups, BackupInfo{
+			FileName:       names[entry.ID],
+			ID:             entry.ID,
+			OriginalPath:   entry.Path,
+			BackupTime:     entry.Time,
+			BackupSize:     entry.Size,
+			Run:            entry.Run,
+			OriginalExists: err == nil,
+		})
 	}
 	
 	return backups
 }
 
 // CleanupExpiredBackups removes backups older than the specified duration
-func (fm *FileManager) CleanupExpiredBackups(managedFiles []ManagedFile, maxAge time.Duration) error {
-	var cleaned, failed int
+// Backups state still refers to are kept regardless of their age
+func (fm *FileManager) CleanupExpiredBackups(state *PackageState, maxAge time.Duration) error {
 	cutoffTime := time.Now().Add(-maxAge)
 	
 	fm.logger.Info("Cleaning up expired backups", "max_age", maxAge, "cutoff", cutoffTime.Format("2006-01-02 15:04:05"))
 	
-	for _, file := range managedFiles {
-		if file.BackupPath == "" {
-			continue
-		}
-		
-		backupStat, err := os.Stat(file.BackupPath)
-		if os.IsNotExist(err) {
-			continue // Backup doesn't exist
-		}
-		if err != nil {
-			fm.logger.Warn("Could not stat backup file", "backup", file.BackupPath, "error", err)
-			continue
-		}
-		
-		// Check if backup is older than cutoff
-		if backupStat.ModTime().Before(cutoffTime) {
-			if fm.dryRun {
-				fm.logger.Info("DRY RUN: Would remove expired backup", "backup", file.BackupPath, "age", time.Since(backupStat.ModTime()))
-				cleaned++
-				continue
-			}
-			
-			fm.logger.Debug("Removing expired backup", "backup", file.BackupPath, "age", time.Since(backupStat.ModTime()))
-			if err := os.Remove(file.BackupPath); err != nil {
-				fm.logger.Error("Failed to remove expired backup", "backup", file.BackupPath, "error", err)
-				failed++
-			} else {
-				cleaned++
-			}
-		}
-	}
-	
-	if cleaned > 0 || failed > 0 {
-		fm.logger.Info("✓ Backup cleanup completed", "cleaned", cleaned, "failed", failed)
-	}
-	
-	if failed > 0 {
-		return fmt.Errorf("failed to clean %d backup(s)", failed)
+	entries, err := fm.backups.List()
+	if err != nil {
+		return err
 	}
-	
-	return nil
-}
 
-// FindOrphanedBackups finds backup files that are no longer tracked in state
-func (fm *FileManager) FindOrphanedBackups(managedFiles []ManagedFile) ([]string, error) {
-	var orphanedBackups []string
-	
-	// Get all backup paths from managed files
-	knownBackups := make(map[string]bool)
-	for _, file := range managedFiles {
-		if file.BackupPath != "" {
-			knownBackups[file.BackupPath] = true
+	referenced := referencedBackups(state)
+	var expired []BackupEntry
+	for _, entry := range entries {
+		if entry.Time.Before(cutoffTime) && !referenced[entry.ID] {
+			expired = append(expired, entry)
 		}
 	}
 	
-	// Search common backup directories for orphaned backups
-	searchDirs := []string{
-		"~/.config",
-		"~/.local",
-		"~/",
+	cleaned, err := fm.removeBackups(expired, "expired")
+	if cleaned > 0 {
+		fm.logger.Info("✓ Backup cleanup completed", "cleaned", cleaned)
 	}
-	
-	for _, dir := range searchDirs {
-		// Expand home directory
-		if dir[0] == '~' {
-			homeDir, err := os.UserHomeDir()
-			if err != nil {
-				continue
-			}
-			dir = filepath.Join(homeDir, dir[1:])
-		}
-		
-		orphaned, err := fm.searchForOrphanedBackups(dir, knownBackups)
-		if err != nil {
-			fm.logger.Debug("Could not search for orphaned backups", "dir", dir, "error", err)
-			continue
-		}
-		
-		orphanedBackups = append(orphanedBackups, orphaned...)
-	}
-	
-	return orphanedBackups, nil
+	return err
 }
 
-// searchForOrphanedBackups recursively searches for backup files in a directory
-func (fm *FileManager) searchForOrphanedBackups(dir string, knownBackups map[string]bool) ([]string, error) {
-	var orphaned []string
-	
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip directories we can't access
-		}
-		
-		// Skip directories
-		if info.IsDir() {
-			return nil
-		}
-		
-		// Check if this looks like a configr backup file
-		if fm.isLikelyConfigrBackup(path, info) && !knownBackups[path] {
-			orphaned = append(orphaned, path)
+// FindOrphanedBackups finds backups no managed file, binary or edited file refers to anymore
+// Backups made in the current run aren't orphaned, as state isn't saved yet
+func (fm *FileManager) FindOrphanedBackups(state *PackageState) ([]BackupEntry, error) {
+	referenced := referencedBackups(state)
+
+	entries, err := fm.backups.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var orphaned []BackupEntry
+	for _, entry := range entries {
+		if !referenced[entry.ID] && entry.Run != backupRun {
+			orphaned = append(orphaned, entry)
 		}
-		
-		return nil
-	})
-	
-	return orphaned, err
+	}
+	return orphaned, nil
 }
 
-// isLikelyConfigrBackup checks if a file appears to be a configr backup
-func (fm *FileManager) isLikelyConfigrBackup(path string, info os.FileInfo) bool {
-	// Check for backup naming pattern: *.backup.YYYYMMDD-HHMMSS
-	filename := info.Name()
-	
-	// Must contain ".backup." and end with timestamp pattern
-	if !strings.Contains(filename, ".backup.") {
-		return false
+// referencedBackups returns the IDs of the backups managed files, binaries and edited files in state refer to
+// Policy cleanup never removes these, as they're restored when the entry is removed from the configuration
+func referencedBackups(state *PackageState) map[string]bool {
+	referenced := make(map[string]bool)
+	if state == nil {
+		return referenced
 	}
-	
-	// Extract timestamp part
-	parts := strings.Split(filename, ".backup.")
-	if len(parts) != 2 {
-		return false
+	for _, id := range state.EditBackups {
+		referenced[id] = true
 	}
-	
-	timestamp := parts[1]
-	// Check if it matches our timestamp format: YYYYMMDD-HHMMSS
-	if len(timestamp) != 15 {
-		return false
+	for _, file := range state.Files {
+		if file.BackupID != "" {
+			referenced[file.BackupID] = true
+		}
 	}
-	
-	// Basic validation of timestamp format
-	if timestamp[8] != '-' {
-		return false
+	for _, binary := range state.Binaries {
+		if binary.BackupID != "" {
+			referenced[binary.BackupID] = true
+		}
 	}
-	
-	return true
+	return referenced
 }
 
 // GetBackupStatistics returns comprehensive backup statistics
-func (fm *FileManager) GetBackupStatistics(managedFiles []ManagedFile) (*BackupStatistics, error) {
+func (fm *FileManager) GetBackupStatistics() (*BackupStatistics, error) {
 	stats := &BackupStatistics{
 		TotalBackups:    0,
 		TotalSize:       0,
@@ -995,39 +1095,32 @@ func (fm *FileManager) GetBackupStatistics(managedFiles []ManagedFile) (*BackupS
 		RestorableCount: 0,
 	}
 	
+	entries, err := fm.backups.List()
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	
-	for _, file := range managedFiles {
-		if file.BackupPath == "" {
-			continue
-		}
-		
-		backupInfo, err := os.Stat(file.BackupPath)
-		if os.IsNotExist(err) {
-			continue // Backup doesn't exist
-		}
-		if err != nil {
-			continue // Skip files we can't stat
-		}
-		
+	for _, entry := range entries {
 		stats.TotalBackups++
-		stats.TotalSize += backupInfo.Size()
+		stats.TotalSize += entry.Size
 		
 		// Track oldest and newest
-		if backupInfo.ModTime().Before(stats.OldestBackup) {
-			stats.OldestBackup = backupInfo.ModTime()
+		if entry.Time.Before(stats.OldestBackup) {
+			stats.OldestBackup = entry.Time
 		}
-		if backupInfo.ModTime().After(stats.NewestBackup) {
-			stats.NewestBackup = backupInfo.ModTime()
+		if entry.Time.After(stats.NewestBackup) {
+			stats.NewestBackup = entry.Time
 		}
 		
 		// Check if restorable (original doesn't exist)
-		if _, err := os.Lstat(file.Destination); os.IsNotExist(err) {
+		if _, err := os.Lstat(entry.Path); os.IsNotExist(err) {
 			stats.RestorableCount++
 		}
 		
 		// Categorize by age
-		age := now.Sub(backupInfo.ModTime())
+		age := now.Sub(entry.Time)
 		switch {
 		case age < 24*time.Hour:
 			stats.BackupsByAge["< 1 day"]++
@@ -1049,13 +1142,22 @@ func (fm *FileManager) GetBackupStatistics(managedFiles []ManagedFile) (*BackupS
 	return stats, nil
 }
 
-// CleanupOrphanedBackups removes backup files that are no longer tracked
-func (fm *FileManager) CleanupOrphanedBackups(managedFiles []ManagedFile) error {
-	orphaned, err := fm.FindOrphanedBackups(managedFiles)
+// CleanupOrphanedBackups removes backups that are no longer tracked
+func (fm *FileManager) CleanupOrphanedBackups(state *PackageState) error {
+	orphaned, err := fm.FindOrphanedBackups(state)
 	if err != nil {
 		return fmt.Errorf("failed to find orphaned backups: %w", err)
 	}
 	
+	// Content left behind by an interrupted backup isn't in the index at all
+	if !fm.dryRun {
+		if pruned, err := fm.backups.Prune(); err != nil {
+			return fmt.Errorf("failed to prune backup store: %w", err)
+		} else if pruned > 0 {
+			fm.logger.Debug("Pruned unreferenced backup content", "count", pruned)
+		}
+	}
+
 	if len(orphaned) == 0 {
 		fm.logger.Info("No orphaned backups found")
 		return nil
@@ -1063,30 +1165,34 @@ func (fm *FileManager) CleanupOrphanedBackups(managedFiles []ManagedFile) error
 	
 	fm.logger.Info("Found orphaned backups", "count", len(orphaned))
 	
-	var removed, failed int
-	for _, backupPath := range orphaned {
+	removed, err := fm.removeBackups(orphaned, "orphaned")
+	fm.logger.Info("✓ Orphaned backup cleanup completed", "removed", removed)
+	return err
+}
+
+// removeBackups removes backups from the backup store and returns how many were (or would be) removed
+func (fm *FileManager) removeBackups(entries []BackupEntry, reason string) (int, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
 		if fm.dryRun {
-			fm.logger.Info("DRY RUN: Would remove orphaned backup", "path", backupPath)
-			removed++
-			continue
-		}
-		
-		fm.logger.Debug("Removing orphaned backup", "path", backupPath)
-		if err := os.Remove(backupPath); err != nil {
-			fm.logger.Error("Failed to remove orphaned backup", "path", backupPath, "error", err)
-			failed++
+			fm.logger.Info("DRY RUN: Would remove "+reason+" backup", "backup", entry.ID, "path", entry.Path, "age", time.Since(entry.Time))
 		} else {
-			removed++
+			fm.logger.Debug("Removing "+reason+" backup", "backup", entry.ID, "path", entry.Path, "age", time.Since(entry.Time))
 		}
+		ids = append(ids, entry.ID)
 	}
-	
-	fm.logger.Info("✓ Orphaned backup cleanup completed", "removed", removed, "failed", failed)
-	
-	if failed > 0 {
-		return fmt.Errorf("failed to remove %d orphaned backup(s)", failed)
+	if fm.dryRun {
+		return len(ids), nil
 	}
-	
-	return nil
+
+	if err := fm.backups.Remove(ids); err != nil {
+		return 0, fmt.Errorf("failed to remove %d %s backup(s): %w", len(ids), reason, err)
+	}
+	return len(ids), nil
 }
 
 // BackupStatistics contains comprehensive backup information
@@ -1099,8 +1205,8 @@ type BackupStatistics struct {
 	RestorableCount int               `json:"restorable_count"`
 }
 
-// ApplyBackupPolicy enforces the configured backup policy
-func (fm *FileManager) ApplyBackupPolicy(managedFiles []ManagedFile, policy config.BackupPolicy) error {
+// ApplyBackupPolicy enforces the configured backup policy on the backup store
+func (fm *FileManager) ApplyBackupPolicy(state *PackageState, policy config.BackupPolicy) error {
 	if !policy.AutoCleanup {
 		fm.logger.Debug("Backup policy auto-cleanup disabled")
 		return nil
@@ -1112,7 +1218,7 @@ func (fm *FileManager) ApplyBackupPolicy(managedFiles []ManagedFile, policy conf
 
 	// Clean up orphaned backups if enabled
 	if policy.CleanupOrphaned {
-		if err := fm.CleanupOrphanedBackups(managedFiles); err != nil {
+		if err := fm.CleanupOrphanedBackups(state); err != nil {
 			errors = append(errors, fmt.Errorf("orphaned backup cleanup failed: %w", err))
 		}
 	}
@@ -1123,7 +1229,7 @@ func (fm *FileManager) ApplyBackupPolicy(managedFiles []ManagedFile, policy conf
 		if err != nil {
 			errors = append(errors, fmt.Errorf("invalid max_age in backup policy: %w", err))
 		} else {
-			if err := fm.CleanupExpiredBackups(managedFiles, maxAge); err != nil {
+			if err := fm.CleanupExpiredBackups(state, maxAge); err != nil {
 				errors = append(errors, fmt.Errorf("age-based cleanup failed: %w", err))
 			}
 		}
@@ -1131,7 +1237,7 @@ func (fm *FileManager) ApplyBackupPolicy(managedFiles []ManagedFile, policy conf
 
 	// Apply count-based cleanup
 	if policy.MaxCount > 0 {
-		if err := fm.cleanupByCount(managedFiles, policy.MaxCount, policy.PreserveRecent); err != nil {
+		if err := fm.cleanupByCount(state, policy.MaxCount, policy.PreserveRecent); err != nil {
 			errors = append(errors, fmt.Errorf("count-based cleanup failed: %w", err))
 		}
 	}
@@ -1143,94 +1249,41 @@ func (fm *FileManager) ApplyBackupPolicy(managedFiles []ManagedFile, policy conf
 	return nil
 }
 
-// cleanupByCount removes excess backups keeping only the most recent ones
-func (fm *FileManager) cleanupByCount(managedFiles []ManagedFile, maxCount, preserveRecent int) error {
-	// Group backups by original file
-	fileBackups := make(map[string][]BackupInfo)
-	
-	for _, file := range managedFiles {
-		if file.BackupPath == "" {
-			continue
-		}
-		
-		backupStat, err := os.Stat(file.BackupPath)
-		if os.IsNotExist(err) {
-			continue
-		}
-		if err != nil {
-			continue
-		}
-		
-		backup := BackupInfo{
-			FileName:     file.Name,
-			BackupPath:   file.BackupPath,
-			OriginalPath: file.Destination,
-			BackupTime:   backupStat.ModTime(),
-			BackupSize:   backupStat.Size(),
-		}
-		
-		fileBackups[file.Destination] = append(fileBackups[file.Destination], backup)
+// cleanupByCount removes excess backups keeping only the most recent ones of each original path
+// Backups state still refers to are kept even when they're in excess
+func (fm *FileManager) cleanupByCount(state *PackageState, maxCount, preserveRecent int) error {
+	entries, err := fm.backups.List()
+	if err != nil {
+		return err
+	}
+
+	// Group backups by original file, newest first
+	fileBackups := make(map[string][]BackupEntry)
+	for i := len(entries) - 1; i >= 0; i-- {
+		fileBackups[entries[i].Path] = append(fileBackups[entries[i].Path], entries[i])
 	}
 	
-	var cleaned, failed int
-	
-	// Process each file's backups
+	// Determine how many to preserve
+	preserveCount := max(maxCount, preserveRecent)
+
+	referenced := referencedBackups(state)
+	var excess []BackupEntry
 	for _, backups := range fileBackups {
-		if len(backups) <= maxCount {
-			continue // No cleanup needed
-		}
-		
-		// Sort by backup time (newest first)
-		for i := 0; i < len(backups)-1; i++ {
-			for j := i + 1; j < len(backups); j++ {
-				if backups[i].BackupTime.Before(backups[j].BackupTime) {
-					backups[i], backups[j] = backups[j], backups[i]
-				}
-			}
-		}
-		
-		// Determine how many to preserve
-		preserveCount := maxCount
-		if preserveRecent > preserveCount {
-			preserveCount = preserveRecent
+		if len(backups) <= preserveCount {
+			continue
 		}
-		
-		// Remove excess backups
-		for i := preserveCount; i < len(backups); i++ {
-			backup := backups[i]
-			
-			if fm.dryRun {
-				fm.logger.Info("DRY RUN: Would remove excess backup", 
-					"file", backup.FileName, 
-					"backup", backup.BackupPath,
-					"age", time.Since(backup.BackupTime))
-				cleaned++
-				continue
-			}
-			
-			fm.logger.Debug("Removing excess backup", 
-				"file", backup.FileName, 
-				"backup", backup.BackupPath, 
-				"age", time.Since(backup.BackupTime))
-				
-			if err := os.Remove(backup.BackupPath); err != nil {
-				fm.logger.Error("Failed to remove excess backup", "backup", backup.BackupPath, "error", err)
-				failed++
-			} else {
-				cleaned++
+		for _, entry := range backups[preserveCount:] {
+			if !referenced[entry.ID] {
+				excess = append(excess, entry)
 			}
 		}
 	}
 	
-	if cleaned > 0 || failed > 0 {
-		fm.logger.Info("✓ Count-based backup cleanup completed", "cleaned", cleaned, "failed", failed)
+	cleaned, err := fm.removeBackups(excess, "excess")
+	if cleaned > 0 {
+		fm.logger.Info("✓ Count-based backup cleanup completed", "cleaned", cleaned)
 	}
-	
-	if failed > 0 {
-		return fmt.Errorf("failed to clean %d backup(s)", failed)
-	}
-	
-	return nil
+	return err
 }
 
 // parseBackupAge parses duration strings for backup policies
@@ -218,6 +218,7 @@ func TestFileManager_BackupExistingFile(t *testing.T) {
 	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
 
 	fm := NewFileManager(logger, false, tempDir)
+	fm.SetBackupStore(NewBackupStoreWithPath(logger, filepath.Join(tempDir, "backups")))
 
 	// Create existing file at destination
 	destFile := filepath.Join(tempDir, "dest.txt")
@@ -245,30 +246,31 @@ func TestFileManager_BackupExistingFile(t *testing.T) {
 	}
 
 	// Deploy files
-	_, err = fm.DeployFiles(files)
+	deployed, err := fm.DeployFiles(files)
 	if err != nil {
 		t.Fatalf("unexpected error during deployment: %v", err)
 	}
 
-	// Verify backup was created
-	backupPattern := destFile + ".backup.*"
-	matches, err := filepath.Glob(backupPattern)
-	if err != nil {
-		t.Fatalf("failed to search for backup files: %v", err)
+	// Verify backup was created in the backup store, not next to the file
+	if matches, _ := filepath.Glob(destFile + ".backup.*"); len(matches) != 0 {
+		t.Errorf("expected no sidecar backup, got %v", matches)
 	}
-	if len(matches) == 0 {
-		t.Error("no backup file was created")
+	backups := fm.ListBackups(deployed)
+	if len(backups) != 1 || backups[0].ID != deployed[0].BackupID || backups[0].FileName != "test-file" || backups[0].OriginalPath != destFile {
+		t.Fatalf("unexpected backups: %+v (deployed %+v)", backups, deployed)
 	}
 
 	// Verify backup contains original content
-	if len(matches) > 0 {
-		backupContent, err := os.ReadFile(matches[0])
-		if err != nil {
-			t.Fatalf("failed to read backup file: %v", err)
-		}
-		if string(backupContent) != string(existingContent) {
-			t.Errorf("backup content mismatch: got %s, expected %s", string(backupContent), string(existingContent))
-		}
+	entry, err := fm.backups.Get(deployed[0].BackupID)
+	if err != nil {
+		t.Fatalf("failed to find backup: %v", err)
+	}
+	backupContent, err := fm.backups.Content(entry)
+	if err != nil {
+		t.Fatalf("failed to read backup: %v", err)
+	}
+	if string(backupContent) != string(existingContent) {
+		t.Errorf("backup content mismatch: got %s, expected %s", string(backupContent), string(existingContent))
 	}
 
 	// Verify destination is now a symlink
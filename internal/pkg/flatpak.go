@@ -2,7 +2,12 @@ package pkg
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/bashfulrobot/configr/internal/config"
@@ -13,6 +18,8 @@ import (
 type FlatpakManager struct {
 	logger *log.Logger
 	dryRun bool
+
+	appliedOverrides map[string][]string // Override arguments recorded in state, by scope and application
 }
 
 // NewFlatpakManager creates a new Flatpak manager
@@ -57,8 +64,9 @@ func (fm *FlatpakManager) groupPackagesByFlags(packages []config.PackageEntry, p
 	flagGroups := make(map[string][]config.PackageEntry)
 
 	for _, pkg := range packages {
-		flags := fm.resolvePackageFlags(pkg, packageDefaults)
-		flagKey := strings.Join(flags, "|")
+		flags := fm.resolveInstallFlags(pkg, packageDefaults)
+		// Remote and branch are part of the install command, so they split groups too
+		flagKey := strings.Join(flags, "|") + "#" + pkg.Remote + "#" + pkg.Branch
 		flagGroups[flagKey] = append(flagGroups[flagKey], pkg)
 	}
 
@@ -86,46 +94,126 @@ func (fm *FlatpakManager) resolvePackageFlags(pkg config.PackageEntry, packageDe
 	return config.GetDefaultFlags("flatpak")
 }
 
+// resolveInstallFlags resolves the package flags and applies the per-package scope on top
+// A "scope:" setting replaces any --user/--system flag coming from the flag hierarchy
+func (fm *FlatpakManager) resolveInstallFlags(pkg config.PackageEntry, packageDefaults map[string][]string) []string {
+	flags := fm.resolvePackageFlags(pkg, packageDefaults)
+	if pkg.Scope == "" {
+		return flags
+	}
+
+	result := make([]string, 0, len(flags)+1)
+	for _, flag := range flags {
+		if flag != "--user" && flag != "--system" {
+			result = append(result, flag)
+		}
+	}
+	return append(result, "--"+pkg.Scope)
+}
+
+// scopeFromFlags returns the installation scope flag implied by a set of flags
+// Flatpak installs system-wide unless --user is given
+func scopeFromFlags(flags []string) string {
+	for _, flag := range flags {
+		if flag == "--user" {
+			return "--user"
+		}
+	}
+	return "--system"
+}
+
+// packageRef builds the ref passed to flatpak install, including the branch if set
+func packageRef(pkg config.PackageEntry) string {
+	if pkg.Branch == "" {
+		return pkg.Name
+	}
+	// Partial refs leave the architecture empty so flatpak picks the default one
+	return pkg.Name + "//" + pkg.Branch
+}
+
 // installPackageGroup installs a group of packages with the same flags
 func (fm *FlatpakManager) installPackageGroup(packages []config.PackageEntry, packageDefaults map[string][]string) error {
 	if len(packages) == 0 {
 		return nil
 	}
 
-	// Get flags from the first package (all packages in group have same flags)
-	flags := fm.resolvePackageFlags(packages[0], packageDefaults)
+	// Get flags, remote and branch from the first package (all packages in group share them)
+	flags := fm.resolveInstallFlags(packages[0], packageDefaults)
+	remote := packages[0].Remote
+	scope := scopeFromFlags(flags)
 
 	// Check if packages are already installed to avoid reinstalling
 	var packagesToInstall []string
+	var bundlesToInstall []config.PackageEntry
 	for _, pkg := range packages {
+		if pkg.Source != "" {
+			// Bundles and .flatpakref files are installed one by one from their file
+			if fm.dryRun {
+				bundlesToInstall = append(bundlesToInstall, pkg)
+			} else if installed, err := fm.isPackageInstalledForEntry(pkg); err != nil || !installed {
+				bundlesToInstall = append(bundlesToInstall, pkg)
+			} else {
+				fm.logger.Debug("Flatpak package already installed", "package", pkg.Name, "source", pkg.Source)
+			}
+			continue
+		}
+
 		if fm.dryRun {
 			// In dry-run, assume package needs installation
-			packagesToInstall = append(packagesToInstall, pkg.Name)
+			packagesToInstall = append(packagesToInstall, packageRef(pkg))
 		} else {
-			installed, err := fm.isPackageInstalled(pkg.Name)
+			installed, err := fm.isPackageInstalledForEntry(pkg)
 			if err != nil {
 				fm.logger.Warn("Failed to check if Flatpak package is installed", "package", pkg.Name, "error", err)
 				// Assume not installed and try to install
-				packagesToInstall = append(packagesToInstall, pkg.Name)
+				packagesToInstall = append(packagesToInstall, packageRef(pkg))
 			} else if !installed {
-				packagesToInstall = append(packagesToInstall, pkg.Name)
+				packagesToInstall = append(packagesToInstall, packageRef(pkg))
 			} else {
 				fm.logger.Debug("Flatpak package already installed", "package", pkg.Name)
 			}
 		}
 	}
 
-	if len(packagesToInstall) == 0 {
+	if len(packagesToInstall) == 0 && len(bundlesToInstall) == 0 {
 		fm.logger.Debug("All Flatpak packages in group already installed")
-		return nil
+	} else if len(packagesToInstall) > 0 {
+		if err := fm.runInstall(flags, remote, packagesToInstall); err != nil {
+			return err
+		}
+	}
+
+	for _, bundle := range bundlesToInstall {
+		if err := fm.installBundle(bundle, flags); err != nil {
+			return err
+		}
+	}
+
+	// Reconcile permission overrides for every package that manages them,
+	// including packages that were already installed
+	for _, pkg := range packages {
+		if pkg.Overrides == nil {
+			continue
+		}
+		if err := fm.ApplyOverrides(pkg.Name, scope, *pkg.Overrides, fm.previousOverrides(pkg.Name, scope)); err != nil {
+			return fmt.Errorf("failed to apply overrides for %s: %w", pkg.Name, err)
+		}
 	}
 
+	return nil
+}
+
+// runInstall runs flatpak install for a set of refs sharing the same flags and remote
+func (fm *FlatpakManager) runInstall(flags []string, remote string, refs []string) error {
 	// Build the flatpak install command
 	args := []string{"flatpak", "install"}
 	args = append(args, flags...)
-	args = append(args, packagesToInstall...)
+	if remote != "" {
+		args = append(args, remote)
+	}
+	args = append(args, refs...)
 
-	fm.logger.Info("Installing Flatpak packages", "packages", packagesToInstall, "flags", flags)
+	fm.logger.Info("Installing Flatpak packages", "packages", refs, "flags", flags, "remote", remote)
 
 	if fm.dryRun {
 		fm.logger.Info("  [DRY RUN] Would run:", "command", strings.Join(args, " "))
@@ -136,14 +224,165 @@ func (fm *FlatpakManager) installPackageGroup(packages []config.PackageEntry, pa
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
-		fm.logger.Error("Failed to install Flatpak packages", "packages", packagesToInstall, "error", err, "output", string(output))
+		fm.logger.Error("Failed to install Flatpak packages", "packages", refs, "error", err, "output", string(output))
 		return fmt.Errorf("flatpak install failed: %w", err)
 	}
 
-	fm.logger.Debug("Flatpak packages installed successfully", "packages", packagesToInstall, "output", string(output))
+	fm.logger.Debug("Flatpak packages installed successfully", "packages", refs, "output", string(output))
+	return nil
+}
+
+// installBundle installs a resolved .flatpakref or .flatpak bundle entry
+func (fm *FlatpakManager) installBundle(pkg config.PackageEntry, flags []string) error {
+	args := []string{"flatpak", "install"}
+	args = append(args, flags...)
+	if strings.HasSuffix(pkg.Source, ".flatpakref") {
+		args = append(args, "--from", pkg.Source)
+	} else {
+		args = append(args, "--bundle", pkg.Source)
+	}
+
+	fm.logger.Info("Installing Flatpak bundle", "package", pkg.Name, "file", pkg.Source, "flags", flags)
+
+	if fm.dryRun {
+		fm.logger.Info("  [DRY RUN] Would run:", "command", strings.Join(args, " "))
+		return nil
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		fm.logger.Error("Failed to install Flatpak bundle", "package", pkg.Name, "error", err, "output", string(output))
+		return fmt.Errorf("flatpak install failed for %s: %w", pkg.Source, err)
+	}
+
+	config.Success("Installed Flatpak bundle: %s (%s)", pkg.Name, filepath.Base(pkg.Source))
 	return nil
 }
 
+// isBundleFile checks if a package name refers to a .flatpakref or .flatpak bundle (local path or URL)
+func (fm *FlatpakManager) isBundleFile(packageName string) bool {
+	if !strings.HasSuffix(packageName, ".flatpakref") && !strings.HasSuffix(packageName, ".flatpak") {
+		return false
+	}
+	return strings.HasPrefix(packageName, "https://") || strings.Contains(packageName, "/")
+}
+
+// ResolveEntries resolves Flatpak bundles and .flatpakref files so the entries carry real app IDs
+func (fm *FlatpakManager) ResolveEntries(packages []config.PackageEntry, configDir string) ([]config.PackageEntry, error) {
+	return fm.ResolveBundleEntries(packages, configDir)
+}
+
+// ResolveBundleEntries replaces bundle and .flatpakref entries with their application IDs
+// Remote files are downloaded to the cache (except during a dry run) and local paths are resolved
+// relative to configDir. The resolved entry keeps the local file in Source, so installed detection, state tracking
+// and removal all work with the application ID.
+func (fm *FlatpakManager) ResolveBundleEntries(packages []config.PackageEntry, configDir string) ([]config.PackageEntry, error) {
+	resolved := make([]config.PackageEntry, 0, len(packages))
+
+	for _, pkg := range packages {
+		if pkg.Source != "" || !fm.isBundleFile(pkg.Name) {
+			resolved = append(resolved, pkg)
+			continue
+		}
+
+		// Remote bundles aren't downloaded during a dry run, so they keep their URL
+		if strings.HasPrefix(pkg.Name, "https://") && fm.dryRun {
+			fm.logger.Info("DRY RUN: Would download Flatpak bundle", "url", pkg.Name)
+			resolved = append(resolved, pkg)
+			continue
+		}
+
+		localPath := pkg.Name
+		if strings.HasPrefix(pkg.Name, "https://") {
+			fm.logger.Debug("Downloading Flatpak bundle", "url", pkg.Name)
+			downloaded, err := downloadToCache(pkg.Name, "flatpak-bundles", "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to download Flatpak bundle %s: %w", pkg.Name, err)
+			}
+			localPath = downloaded
+		} else if !filepath.IsAbs(localPath) {
+			localPath = filepath.Join(configDir, localPath)
+		}
+
+		appID, err := readBundleAppID(localPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read application ID from %s: %w", pkg.Name, err)
+		}
+
+		fm.logger.Debug("Resolved Flatpak bundle", "file", pkg.Name, "app_id", appID)
+		pkg.Source = localPath
+		pkg.Name = appID
+		resolved = append(resolved, pkg)
+	}
+
+	return resolved, nil
+}
+
+// bundleRefPattern matches the ref stored in the metadata header of a .flatpak bundle
+var bundleRefPattern = regexp.MustCompile(`(?:app|runtime)/([A-Za-z0-9_\-]+(?:\.[A-Za-z0-9_\-]+)+)/[A-Za-z0-9_]+/[A-Za-z0-9_.\-]+`)
+
+// readBundleAppID reads the application ID from a .flatpakref file or .flatpak bundle
+func readBundleAppID(bundlePath string) (string, error) {
+	file, err := os.Open(bundlePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer file.Close()
+
+	if strings.HasSuffix(bundlePath, ".flatpakref") {
+		data, err := io.ReadAll(io.LimitReader(file, 1<<20))
+		if err != nil {
+			return "", fmt.Errorf("failed to read .flatpakref: %w", err)
+		}
+		return parseFlatpakRefName(string(data))
+	}
+
+	// .flatpak bundles start with a GVariant metadata header that contains the full ref
+	// (e.g. "app/org.example.App/x86_64/stable"), so scanning the header is sufficient
+	header := make([]byte, 1<<20)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", fmt.Errorf("failed to read bundle header: %w", err)
+	}
+
+	match := bundleRefPattern.FindSubmatch(header[:n])
+	if match == nil {
+		return "", fmt.Errorf("no application ref found in bundle metadata")
+	}
+	return string(match[1]), nil
+}
+
+// parseFlatpakRefName extracts the Name key from the [Flatpak Ref] group of a .flatpakref file
+func parseFlatpakRefName(content string) (string, error) {
+	inRefGroup := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inRefGroup = line == "[Flatpak Ref]"
+			continue
+		}
+		if !inRefGroup {
+			continue
+		}
+		if key, value, found := strings.Cut(line, "="); found && strings.TrimSpace(key) == "Name" {
+			if name := strings.TrimSpace(value); name != "" {
+				return name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no Name found in [Flatpak Ref] group")
+}
+
+// isPackageInstalledForEntry checks installation in the entry's scope, or in both scopes if unset
+func (fm *FlatpakManager) isPackageInstalledForEntry(pkg config.PackageEntry) (bool, error) {
+	if pkg.Scope == "" {
+		return fm.isPackageInstalled(pkg.Name)
+	}
+	return fm.isPackageInstalledInScope(pkg.Name, "--"+pkg.Scope)
+}
+
 // isPackageInstalled checks if a Flatpak package is already installed
 func (fm *FlatpakManager) isPackageInstalled(packageName string) (bool, error) {
 	// Check both system and user installations
@@ -182,6 +421,23 @@ func (fm *FlatpakManager) isPackageInstalledInScope(packageName, scope string) (
 	return false, nil
 }
 
+// isPackageAvailable checks if a Flatpak application is available from any configured remote
+func (fm *FlatpakManager) isPackageAvailable(packageName string) (bool, error) {
+	cmd := exec.Command("flatpak", "search", "--columns=application", packageName)
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to search flatpak remotes for %s: %w", packageName, err)
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) == packageName {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // UninstallPackage removes a Flatpak application
 func (fm *FlatpakManager) UninstallPackage(packageName string, flags []string) error {
 	args := []string{"flatpak", "uninstall"}
@@ -236,7 +492,7 @@ func (fm *FlatpakManager) ListInstalledPackages() ([]string, error) {
 	return packages, nil
 }
 
-// UpdatePackages updates all installed Flatpak applications
+// UpdatePackages updates all installed Flatpak applications (or only the refs given after the flags)
 func (fm *FlatpakManager) UpdatePackages(flags []string) error {
 	args := []string{"flatpak", "update"}
 	args = append(args, flags...)
@@ -260,6 +516,317 @@ func (fm *FlatpakManager) UpdatePackages(flags []string) error {
 	return nil
 }
 
+// ListUpgrades returns installed applications and runtimes with updates available
+// Refs matching a `flatpak mask` pattern are marked as held
+func (fm *FlatpakManager) ListUpgrades() ([]UpgradeCandidate, error) {
+	output, err := exec.Command("flatpak", "remote-ls", "--updates", "--columns=application,version").Output()
+	if err != nil {
+		return nil, fmt.Errorf("flatpak remote-ls --updates failed: %w", err)
+	}
+	candidates := parseFlatpakColumns(string(output))
+
+	current := make(map[string]string)
+	if output, err := exec.Command("flatpak", "list", "--columns=application,version").Output(); err == nil {
+		for _, installed := range parseFlatpakColumns(string(output)) {
+			current[installed.Name] = installed.NewVersion
+		}
+	}
+
+	var masks []string
+	if output, err := exec.Command("flatpak", "mask").Output(); err == nil {
+		masks = parseFlatpakMasks(string(output))
+	} else {
+		fm.logger.Debug("Could not list masked Flatpak patterns", "error", err)
+	}
+
+	for i := range candidates {
+		candidates[i].CurrentVersion = current[candidates[i].Name]
+		candidates[i].Held = flatpakMasked(candidates[i].Name, masks)
+	}
+	return candidates, nil
+}
+
+// parseFlatpakColumns parses tab-separated "application<TAB>version" output; Version is stored in NewVersion
+func parseFlatpakColumns(output string) []UpgradeCandidate {
+	var candidates []UpgradeCandidate
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		name := strings.TrimSpace(fields[0])
+		if name == "" || name == "Application ID" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		candidate := UpgradeCandidate{Name: name}
+		if len(fields) > 1 {
+			candidate.NewVersion = strings.TrimSpace(fields[1])
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// parseFlatpakMasks parses the patterns printed by `flatpak mask`
+func parseFlatpakMasks(output string) []string {
+	var masks []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasSuffix(line, ":") || strings.Contains(line, " ") {
+			continue
+		}
+		masks = append(masks, line)
+	}
+	return masks
+}
+
+// flatpakMasked reports whether an application ID matches a mask pattern ("org.example.*" or a full ref)
+func flatpakMasked(appID string, masks []string) bool {
+	for _, mask := range masks {
+		if parts := strings.Split(mask, "/"); len(parts) > 1 {
+			mask = parts[1] // app/<id>/<arch>/<branch>
+		}
+		if matched, _ := filepath.Match(mask, appID); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Upgrade updates the given applications, or every installed ref when packageNames is empty
+// flatpak update skips masked refs itself
+func (fm *FlatpakManager) Upgrade(packageNames []string) error {
+	return fm.UpdatePackages(append([]string{"--assumeyes", "--noninteractive"}, packageNames...))
+}
+
+// ApplyOverrides reconciles the sandbox permission overrides of an application with the desired set
+// Only the differences are sent: missing entries are added, and entries configr applied before (applied)
+// that are no longer configured are revoked. Overrides set by other means are left alone.
+func (fm *FlatpakManager) ApplyOverrides(appID, scope string, overrides config.FlatpakOverrides, applied []string) error {
+	desired := buildOverrideArgs(overrides)
+
+	// Reading the current overrides changes nothing, so a dry run compares them too
+	current, err := fm.currentOverrides(appID, scope)
+	if err != nil && !fm.dryRun {
+		return err
+	}
+	known := err == nil
+	if !known {
+		fm.logger.Debug("Could not read Flatpak overrides, assuming they differ", "package", appID, "error", err)
+		current = nil
+	}
+
+	added := stringSliceDiff(desired, current)
+	revoked := stringSliceDiff(applied, desired)
+	if known {
+		revoked = stringSliceIntersect(revoked, current)
+	} else {
+		added = desired
+	}
+	if len(added) == 0 && len(revoked) == 0 {
+		fm.logger.Debug("Flatpak overrides already up to date", "package", appID)
+		return nil
+	}
+
+	fm.logger.Info("Updating Flatpak overrides", "package", appID, "scope", scope, "add", added, "revoke", revoked)
+
+	// Revocations come first, so an entry whose value changed is unset and then set again
+	if err := fm.runOverride(scope, append(revokeOverrideArgs(revoked), added...), appID); err != nil {
+		return err
+	}
+
+	if !fm.dryRun {
+		config.Success("Updated Flatpak overrides: %s", appID)
+	}
+	return nil
+}
+
+// ResetOverrides revokes the overrides configr applied to an application that no longer manages them
+// Overrides set by other means are left alone
+func (fm *FlatpakManager) ResetOverrides(appID, scope string, applied []string) error {
+	if len(applied) == 0 {
+		fm.logger.Warn("⚠ Flatpak overrides applied by an earlier configr version aren't recorded, leaving them in place", "package", appID, "scope", scope)
+		return nil
+	}
+
+	revoked := applied
+	if current, err := fm.currentOverrides(appID, scope); err == nil {
+		revoked = stringSliceIntersect(applied, current)
+	} else if !fm.dryRun {
+		return err
+	}
+	if len(revoked) == 0 {
+		fm.logger.Debug("Flatpak overrides already revoked", "package", appID)
+		return nil
+	}
+
+	fm.logger.Info("Revoking Flatpak overrides no longer in configuration", "package", appID, "scope", scope, "revoke", revoked)
+	return fm.runOverride(scope, revokeOverrideArgs(revoked), appID)
+}
+
+// previousOverrides returns the override arguments recorded in state for an application
+func (fm *FlatpakManager) previousOverrides(appID, scope string) []string {
+	if fm.appliedOverrides == nil {
+		fm.appliedOverrides = make(map[string][]string)
+		state, err := NewStateManager(fm.logger).LoadState()
+		if err != nil {
+			fm.logger.Debug("Could not load previously applied Flatpak overrides", "error", err)
+			return nil
+		}
+		for _, override := range state.FlatpakOverrides {
+			fm.appliedOverrides[override.Scope+" "+override.App] = override.Args
+		}
+	}
+	return fm.appliedOverrides[scope+" "+appID]
+}
+
+// revokeOverrideArgs converts override arguments into the arguments that revoke them
+func revokeOverrideArgs(args []string) []string {
+	var revoke []string
+	for _, arg := range args {
+		flag, value, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		switch flag {
+		case "filesystem":
+			// The access mode isn't part of the entry
+			for _, mode := range []string{":ro", ":rw", ":create"} {
+				value = strings.TrimSuffix(value, mode)
+			}
+			revoke = append(revoke, "--nofilesystem="+value)
+		case "socket":
+			revoke = append(revoke, "--nosocket="+value)
+		case "env":
+			name, _, _ := strings.Cut(value, "=")
+			revoke = append(revoke, "--unset-env="+name)
+		case "talk-name":
+			revoke = append(revoke, "--no-talk-name="+value)
+		}
+	}
+	return revoke
+}
+
+// runOverride runs a single flatpak override command (or logs it in dry-run mode)
+func (fm *FlatpakManager) runOverride(scope string, overrideArgs []string, appID string) error {
+	args := []string{"flatpak", "override", scope}
+	args = append(args, overrideArgs...)
+	args = append(args, appID)
+
+	if fm.dryRun {
+		fm.logger.Info("  [DRY RUN] Would run:", "command", strings.Join(args, " "))
+		return nil
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		fm.logger.Error("Failed to run flatpak override", "package", appID, "error", err, "output", string(output))
+		return fmt.Errorf("flatpak override failed: %w", err)
+	}
+
+	return nil
+}
+
+// currentOverrides reads the overrides currently applied to an application
+func (fm *FlatpakManager) currentOverrides(appID, scope string) ([]string, error) {
+	args := []string{"flatpak", "override", scope, "--show", appID}
+	cmd := exec.Command(args[0], args[1:]...)
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		return nil, fmt.Errorf("flatpak override --show failed: %w", err)
+	}
+
+	return parseOverrideShow(string(output)), nil
+}
+
+// buildOverrideArgs converts configured overrides into sorted flatpak override arguments
+func buildOverrideArgs(overrides config.FlatpakOverrides) []string {
+	var args []string
+	for _, fs := range overrides.Filesystems {
+		args = append(args, "--filesystem="+fs)
+	}
+	for _, socket := range overrides.Sockets {
+		args = append(args, "--socket="+socket)
+	}
+	for key, value := range overrides.Env {
+		args = append(args, "--env="+key+"="+value)
+	}
+	for _, name := range overrides.TalkNames {
+		args = append(args, "--talk-name="+name)
+	}
+	sort.Strings(args)
+	return args
+}
+
+// overrideContextKeys maps keyfile keys in the [Context] group to override flag names
+var overrideContextKeys = map[string]string{
+	"filesystems": "filesystem",
+	"sockets":     "socket",
+	"shared":      "share",
+	"devices":     "device",
+	"features":    "allow",
+	"persistent":  "persist",
+}
+
+// parseOverrideShow parses `flatpak override --show` keyfile output into sorted override arguments
+// so that current and desired overrides can be compared directly
+func parseOverrideShow(output string) []string {
+	var args []string
+	group := ""
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			group = strings.Trim(line, "[]")
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+
+		switch group {
+		case "Context":
+			flag, known := overrideContextKeys[key]
+			if !known {
+				continue
+			}
+			for _, item := range strings.Split(value, ";") {
+				if item == "" {
+					continue
+				}
+				// Negated entries ("!x11") are stored with a leading "!"
+				if strings.HasPrefix(item, "!") {
+					args = append(args, "--no"+flag+"="+strings.TrimPrefix(item, "!"))
+				} else {
+					args = append(args, "--"+flag+"="+item)
+				}
+			}
+		case "Session Bus Policy", "System Bus Policy":
+			prefix := "--"
+			if group == "System Bus Policy" {
+				prefix = "--system-"
+			}
+			switch value {
+			case "talk":
+				args = append(args, prefix+"talk-name="+key)
+			case "own":
+				args = append(args, prefix+"own-name="+key)
+			case "none":
+				args = append(args, prefix+"no-talk-name="+key)
+			}
+		case "Environment":
+			args = append(args, "--env="+key+"="+value)
+		}
+	}
+
+	sort.Strings(args)
+	return args
+}
+
 // checkFlatpakAvailable checks if flatpak command is available
 func (fm *FlatpakManager) checkFlatpakAvailable() error {
 	if _, err := exec.LookPath("flatpak"); err != nil {
@@ -324,6 +891,14 @@ func (fm *FlatpakManager) validatePackageName(packageName string) error {
 		return fmt.Errorf("package name cannot be empty")
 	}
 
+	// Bundles and .flatpakref files are resolved to their application ID at install time
+	if fm.isBundleFile(packageName) {
+		if strings.Contains(packageName, "..") {
+			return fmt.Errorf("bundle path cannot contain '..'")
+		}
+		return nil
+	}
+
 	// Basic validation for reverse domain notation
 	parts := strings.Split(packageName, ".")
 	if len(parts) < 2 {
@@ -362,7 +937,7 @@ func (fm *FlatpakManager) SearchPackages(searchTerm string) ([]string, error) {
 		return nil, fmt.Errorf("flatpak not available: %w", err)
 	}
 
-	args := []string{"flatpak", "search", searchTerm}
+	args := []string{"flatpak", "search", "--columns=application", searchTerm}
 	cmd := exec.Command(args[0], args[1:]...)
 	output, err := cmd.CombinedOutput()
 
@@ -371,23 +946,16 @@ func (fm *FlatpakManager) SearchPackages(searchTerm string) ([]string, error) {
 		return nil, fmt.Errorf("flatpak search failed: %w", err)
 	}
 
+	// One application ID per line; the same app is listed once per remote and branch
 	var packages []string
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Name") || strings.HasPrefix(line, "-----") {
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		appID := strings.TrimSpace(line)
+		if !strings.Contains(appID, ".") || strings.Contains(appID, " ") || seen[appID] { // Skips "No matches found"
 			continue
 		}
-		
-		// Flatpak search output is tabular: Name Description Application ID Version Branch Remote
-		fields := strings.Fields(line)
-		if len(fields) >= 3 {
-			// Application ID is usually the third field
-			appID := fields[2]
-			if strings.Contains(appID, ".") { // Basic check for reverse domain notation
-				packages = append(packages, appID)
-			}
-		}
+		seen[appID] = true
+		packages = append(packages, appID)
 	}
 
 	fm.logger.Debug("Found Flatpak packages", "search", searchTerm, "count", len(packages))
@@ -413,11 +981,19 @@ func (fm *FlatpakManager) GetPackageInfo(packageName string) (string, error) {
 	args := []string{"flatpak", "info", packageName}
 	cmd := exec.Command(args[0], args[1:]...)
 	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return string(output), nil
+	}
 
-	if err != nil {
-		fm.logger.Error("Failed to get Flatpak package info", "package", packageName, "error", err, "output", string(output))
-		return "", fmt.Errorf("flatpak info failed for package %s: %w", packageName, err)
+	// Not installed: describe the application from the first remote that has it
+	if remotes, remotesErr := exec.Command("flatpak", "remotes", "--columns=name").Output(); remotesErr == nil {
+		for _, remote := range strings.Fields(string(remotes)) {
+			if remoteInfo, err := exec.Command("flatpak", "remote-info", remote, packageName).Output(); err == nil {
+				return string(remoteInfo), nil
+			}
+		}
 	}
 
-	return string(output), nil
-}
\ No newline at end of file
+	fm.logger.Debug("Flatpak package info not found", "package", packageName, "error", err, "output", string(output))
+	return "", fmt.Errorf("flatpak info failed for package %s: %w", packageName, err)
+}
@@ -2,6 +2,9 @@ package pkg
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/bashfulrobot/configr/internal/config"
@@ -353,4 +356,349 @@ func TestFlatpakManager_isPackageInstalledInScope_DryRunSkip(t *testing.T) {
 	if installed {
 		t.Error("isPackageInstalledInScope should return false for nonexistent packages")
 	}
-}
\ No newline at end of file
+}
+func TestFlatpakManager_resolveInstallFlags(t *testing.T) {
+	logger := log.New(os.Stderr)
+	flatpakManager := NewFlatpakManager(logger, true)
+
+	tests := []struct {
+		name     string
+		pkg      config.PackageEntry
+		expected []string
+	}{
+		{
+			name:     "no scope keeps resolved flags",
+			pkg:      config.PackageEntry{Name: "org.mozilla.Firefox"},
+			expected: []string{"--system", "--assumeyes"},
+		},
+		{
+			name:     "scope replaces default scope flag",
+			pkg:      config.PackageEntry{Name: "org.mozilla.Firefox", Scope: "user"},
+			expected: []string{"--assumeyes", "--user"},
+		},
+		{
+			name:     "scope replaces explicit scope flag",
+			pkg:      config.PackageEntry{Name: "org.mozilla.Firefox", Flags: []string{"--user", "--or-update"}, Scope: "system"},
+			expected: []string{"--or-update", "--system"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := flatpakManager.resolveInstallFlags(tt.pkg, map[string][]string{})
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestFlatpakManager_groupPackagesByFlags_RemoteAndBranch(t *testing.T) {
+	logger := log.New(os.Stderr)
+	flatpakManager := NewFlatpakManager(logger, true)
+
+	packages := []config.PackageEntry{
+		{Name: "org.mozilla.Firefox"},
+		{Name: "org.gimp.GIMP", Remote: "flathub"},
+		{Name: "org.inkscape.Inkscape", Remote: "flathub"},
+		{Name: "org.blender.Blender", Remote: "flathub", Branch: "beta"},
+	}
+
+	result := flatpakManager.groupPackagesByFlags(packages, map[string][]string{})
+
+	if len(result) != 3 {
+		t.Errorf("expected 3 groups (default remote, flathub, flathub beta), got %d: %v", len(result), result)
+	}
+}
+
+func TestPackageRef(t *testing.T) {
+	if ref := packageRef(config.PackageEntry{Name: "org.mozilla.Firefox"}); ref != "org.mozilla.Firefox" {
+		t.Errorf("expected plain app ID, got %s", ref)
+	}
+	if ref := packageRef(config.PackageEntry{Name: "org.mozilla.Firefox", Branch: "beta"}); ref != "org.mozilla.Firefox//beta" {
+		t.Errorf("expected partial ref with branch, got %s", ref)
+	}
+}
+
+func TestBuildOverrideArgs(t *testing.T) {
+	overrides := config.FlatpakOverrides{
+		Filesystems: []string{"~/Projects", "xdg-download:ro"},
+		Sockets:     []string{"ssh-auth"},
+		Env:         map[string]string{"GTK_THEME": "Adwaita:dark"},
+		TalkNames:   []string{"org.freedesktop.secrets"},
+	}
+
+	expected := []string{
+		"--env=GTK_THEME=Adwaita:dark",
+		"--filesystem=xdg-download:ro",
+		"--filesystem=~/Projects",
+		"--socket=ssh-auth",
+		"--talk-name=org.freedesktop.secrets",
+	}
+
+	result := buildOverrideArgs(overrides)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestParseOverrideShow(t *testing.T) {
+	output := `[Context]
+filesystems=~/Projects;xdg-download:ro;!home;
+sockets=ssh-auth;
+
+[Session Bus Policy]
+org.freedesktop.secrets=talk
+org.example.Owner=own
+
+[Environment]
+GTK_THEME=Adwaita:dark
+`
+
+	expected := []string{
+		"--env=GTK_THEME=Adwaita:dark",
+		"--filesystem=xdg-download:ro",
+		"--filesystem=~/Projects",
+		"--nofilesystem=home",
+		"--own-name=org.example.Owner",
+		"--socket=ssh-auth",
+		"--talk-name=org.freedesktop.secrets",
+	}
+
+	result := parseOverrideShow(output)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	// Configured overrides round-trip through the parsed form, so unchanged overrides are not re-applied
+	desired := buildOverrideArgs(config.FlatpakOverrides{
+		Filesystems: []string{"~/Projects", "xdg-download:ro"},
+		Sockets:     []string{"ssh-auth"},
+		Env:         map[string]string{"GTK_THEME": "Adwaita:dark"},
+		TalkNames:   []string{"org.freedesktop.secrets"},
+	})
+	revoked := stringSliceDiff(result, desired)
+	if !reflect.DeepEqual(revoked, []string{"--nofilesystem=home", "--own-name=org.example.Owner"}) {
+		t.Errorf("expected unmanaged overrides to be revoked, got %v", revoked)
+	}
+}
+
+func TestFlatpakManager_ApplyOverrides_DryRun(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+	flatpakManager := NewFlatpakManager(logger, true)
+
+	overrides := config.FlatpakOverrides{Sockets: []string{"wayland"}}
+	if err := flatpakManager.ApplyOverrides("org.mozilla.Firefox", "--user", overrides, nil); err != nil {
+		t.Errorf("ApplyOverrides in dry-run mode should not error, got: %v", err)
+	}
+	if err := flatpakManager.ResetOverrides("org.mozilla.Firefox", "--user", []string{"--socket=wayland"}); err != nil {
+		t.Errorf("ResetOverrides in dry-run mode should not error, got: %v", err)
+	}
+}
+
+func TestFlatpakManager_ApplyOverrides_DryRunComparesCurrent(t *testing.T) {
+	binDir := t.TempDir()
+	// The home filesystem override was set by hand, not by configr
+	writeStubCommand(t, binDir, "flatpak", "if [ \"$3\" = --show ]; then printf '[Context]\\nsockets=wayland;\\nfilesystems=home;\\n'; fi\n")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var output strings.Builder
+	logger := log.New(&output)
+	flatpakManager := NewFlatpakManager(logger, true)
+	applied := []string{"--socket=wayland"}
+
+	// Overrides that are already applied aren't reported
+	if err := flatpakManager.ApplyOverrides("org.mozilla.Firefox", "--user", config.FlatpakOverrides{Sockets: []string{"wayland"}}, applied); err != nil {
+		t.Fatalf("ApplyOverrides failed: %v", err)
+	}
+	if strings.Contains(output.String(), "Would run") {
+		t.Errorf("expected no commands for unchanged overrides, got:\n%s", output.String())
+	}
+
+	// Changed overrides revoke what configr applied and add the new entries, leaving the rest alone
+	if err := flatpakManager.ApplyOverrides("org.mozilla.Firefox", "--user", config.FlatpakOverrides{Sockets: []string{"x11"}}, applied); err != nil {
+		t.Fatalf("ApplyOverrides failed: %v", err)
+	}
+	if expected := "flatpak override --user --nosocket=wayland --socket=x11 org.mozilla.Firefox"; !strings.Contains(output.String(), expected) {
+		t.Errorf("expected %q to be reported, got:\n%s", expected, output.String())
+	}
+	if strings.Contains(output.String(), "--reset") || strings.Contains(output.String(), "filesystem") {
+		t.Errorf("expected other overrides to be left alone, got:\n%s", output.String())
+	}
+}
+
+func TestFlatpakManager_ResetOverrides(t *testing.T) {
+	binDir := t.TempDir()
+	writeStubCommand(t, binDir, "flatpak", "if [ \"$3\" = --show ]; then printf '[Context]\\nsockets=wayland;\\nfilesystems=home;\\n'; fi\n")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var output strings.Builder
+	logger := log.New(&output)
+	flatpakManager := NewFlatpakManager(logger, true)
+
+	// Only applied entries that are still set are revoked
+	if err := flatpakManager.ResetOverrides("org.mozilla.Firefox", "--user", []string{"--socket=wayland", "--env=MOZ_ENABLE_WAYLAND=1"}); err != nil {
+		t.Fatalf("ResetOverrides failed: %v", err)
+	}
+	if expected := "flatpak override --user --nosocket=wayland org.mozilla.Firefox"; !strings.Contains(output.String(), expected) {
+		t.Errorf("expected %q to be reported, got:\n%s", expected, output.String())
+	}
+
+	// Without recorded arguments nothing is revoked
+	output.Reset()
+	if err := flatpakManager.ResetOverrides("org.mozilla.Firefox", "--user", nil); err != nil {
+		t.Fatalf("ResetOverrides failed: %v", err)
+	}
+	if strings.Contains(output.String(), "Would run") {
+		t.Errorf("expected no commands without recorded overrides, got:\n%s", output.String())
+	}
+}
+
+func TestRevokeOverrideArgs(t *testing.T) {
+	args := []string{"--filesystem=xdg-download:ro", "--filesystem=~/Documents", "--socket=x11", "--env=GTK_THEME=Adwaita:dark", "--talk-name=org.freedesktop.Flatpak"}
+	expected := []string{"--nofilesystem=xdg-download", "--nofilesystem=~/Documents", "--nosocket=x11", "--unset-env=GTK_THEME", "--no-talk-name=org.freedesktop.Flatpak"}
+	if result := revokeOverrideArgs(args); !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestFlatpakManager_isBundleFile(t *testing.T) {
+	logger := log.New(os.Stderr)
+	flatpakManager := NewFlatpakManager(logger, true)
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"./vendor/app.flatpakref", true},
+		{"/opt/bundles/tool.flatpak", true},
+		{"https://dl.example.com/app.flatpakref", true},
+		{"org.mozilla.Firefox", false},
+		{"app.flatpakref", false},
+	}
+
+	for _, tt := range tests {
+		if result := flatpakManager.isBundleFile(tt.name); result != tt.expected {
+			t.Errorf("isBundleFile(%s) = %v, expected %v", tt.name, result, tt.expected)
+		}
+	}
+}
+
+func TestParseFlatpakRefName(t *testing.T) {
+	content := `[Flatpak Ref]
+Title=Example App
+Name=com.example.App
+Branch=stable
+Url=https://dl.example.com/repo/
+IsRuntime=false
+`
+	name, err := parseFlatpakRefName(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "com.example.App" {
+		t.Errorf("expected com.example.App, got %s", name)
+	}
+
+	if _, err := parseFlatpakRefName("[Other]\nName=com.example.App\n"); err == nil {
+		t.Error("expected error when [Flatpak Ref] group is missing")
+	}
+}
+
+func TestFlatpakManager_ResolveBundleEntries(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+	flatpakManager := NewFlatpakManager(logger, true)
+
+	configDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(configDir, "apps"), 0755); err != nil {
+		t.Fatalf("failed to create apps dir: %v", err)
+	}
+
+	refPath := filepath.Join(configDir, "apps", "vendor.flatpakref")
+	if err := os.WriteFile(refPath, []byte("[Flatpak Ref]\nName=com.vendor.Tool\n"), 0644); err != nil {
+		t.Fatalf("failed to write .flatpakref: %v", err)
+	}
+
+	// Simulate a bundle header: binary GVariant data around the stored ref
+	bundlePath := filepath.Join(configDir, "apps", "editor.flatpak")
+	bundle := append([]byte("flatpak\x00\x01\x02"), []byte("app/org.example.Editor/x86_64/stable\x00")...)
+	bundle = append(bundle, []byte{0xff, 0x00, 0x10}...)
+	if err := os.WriteFile(bundlePath, bundle, 0644); err != nil {
+		t.Fatalf("failed to write bundle: %v", err)
+	}
+
+	packages := []config.PackageEntry{
+		{Name: "org.mozilla.Firefox"},
+		{Name: "./apps/vendor.flatpakref", Flags: []string{"--user"}},
+		{Name: bundlePath},
+	}
+
+	resolved, err := flatpakManager.ResolveBundleEntries(packages, configDir)
+	if err != nil {
+		t.Fatalf("ResolveBundleEntries failed: %v", err)
+	}
+
+	if resolved[0].Name != "org.mozilla.Firefox" || resolved[0].Source != "" {
+		t.Errorf("regular app ID should be unchanged, got %+v", resolved[0])
+	}
+	if resolved[1].Name != "com.vendor.Tool" || resolved[1].Source != refPath {
+		t.Errorf("expected .flatpakref resolved to com.vendor.Tool from %s, got %+v", refPath, resolved[1])
+	}
+	if !reflect.DeepEqual(resolved[1].Flags, []string{"--user"}) {
+		t.Errorf("flags should be preserved, got %v", resolved[1].Flags)
+	}
+	if resolved[2].Name != "org.example.Editor" || resolved[2].Source != bundlePath {
+		t.Errorf("expected bundle resolved to org.example.Editor, got %+v", resolved[2])
+	}
+
+	// Installing resolved entries in dry-run uses the bundle file
+	if err := flatpakManager.InstallPackages(resolved, map[string][]string{}); err != nil {
+		t.Errorf("InstallPackages with bundles in dry-run should not error, got: %v", err)
+	}
+}
+
+func TestFlatpakManager_ResolveBundleEntries_DryRunSkipsDownload(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var output strings.Builder
+	logger := log.New(&output)
+
+	// Nothing listens on port 1, so any download attempt fails
+	url := "https://127.0.0.1:1/vendor.flatpakref"
+	resolved, err := NewFlatpakManager(logger, true).ResolveBundleEntries([]config.PackageEntry{{Name: url}}, t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no download during a dry run, got: %v", err)
+	}
+	if len(resolved) != 1 || resolved[0].Name != url || resolved[0].Source != "" {
+		t.Errorf("expected the remote bundle entry to be unchanged, got %+v", resolved)
+	}
+	if !strings.Contains(output.String(), "Would download Flatpak bundle") {
+		t.Errorf("expected the download to be reported, got:\n%s", output.String())
+	}
+}
+
+func TestParseFlatpakUpgrades(t *testing.T) {
+	candidates := parseFlatpakColumns("org.mozilla.firefox\t130.0\norg.freedesktop.Platform\t\norg.gimp.GIMP\t2.10.38\n")
+	expected := []UpgradeCandidate{
+		{Name: "org.mozilla.firefox", NewVersion: "130.0"},
+		{Name: "org.freedesktop.Platform"},
+		{Name: "org.gimp.GIMP", NewVersion: "2.10.38"},
+	}
+	if !reflect.DeepEqual(candidates, expected) {
+		t.Errorf("expected %+v, got %+v", expected, candidates)
+	}
+
+	masks := parseFlatpakMasks("Masked patterns:\n  org.gimp.*\n  app/org.videolan.VLC/x86_64/stable\n")
+	tests := map[string]bool{
+		"org.gimp.GIMP":       true,
+		"org.videolan.VLC":    true,
+		"org.mozilla.firefox": false,
+	}
+	for appID, expected := range tests {
+		if got := flatpakMasked(appID, masks); got != expected {
+			t.Errorf("flatpakMasked(%q) = %v, expected %v (masks %v)", appID, got, expected, masks)
+		}
+	}
+}
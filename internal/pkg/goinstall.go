@@ -0,0 +1,307 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// GoInstallManager handles Go binaries installed with go install
+type GoInstallManager struct {
+	logger *log.Logger
+	dryRun bool
+}
+
+// NewGoInstallManager creates a new go install package manager
+func NewGoInstallManager(logger *log.Logger, dryRun bool) *GoInstallManager {
+	return &GoInstallManager{
+		logger: logger,
+		dryRun: dryRun,
+	}
+}
+
+// goPackagePattern matches a package path with an optional version: "golang.org/x/tools/gopls@v0.15.0"
+// The first path element must be a domain name, as go install requires for packages outside a module
+var goPackagePattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*(\.[A-Za-z0-9-]+)+(/[A-Za-z0-9._~+-]+)+)(@([A-Za-z0-9._+/-]+))?$`)
+
+// goSemverPattern matches a released module version, which `go version -m` reports unchanged
+var goSemverPattern = regexp.MustCompile(`^v[0-9]+\.[0-9]+\.[0-9]+([-+][0-9A-Za-z.+-]+)?$`)
+
+// goMajorVersionPattern matches a major version suffix element ("v2"), which is not part of the binary name
+var goMajorVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+func init() {
+	RegisterPackageManager(PackageManagerRegistration{
+		Name:    "go",
+		Command: "go",
+		Spec: &config.PackageManagerSpec{
+			Name:         "go",
+			DisplayName:  "Go",
+			DefaultFlags: []string{},
+			ValidateName: func(name string) bool {
+				return goPackagePattern.MatchString(name)
+			},
+			NameMessage: "go package must be a full package path with an optional @version",
+			NameHelp:    "use a path like 'golang.org/x/tools/gopls@v0.15.0' or 'github.com/junegunn/fzf@latest'",
+		},
+		New: func(opts ManagerOptions) PackageManager {
+			return NewGoInstallManager(opts.Logger, opts.DryRun)
+		},
+	})
+}
+
+// goPackagePath returns the package path of a "path@version" spec
+func goPackagePath(spec string) string {
+	if matches := goPackagePattern.FindStringSubmatch(spec); matches != nil {
+		return matches[1]
+	}
+	return spec
+}
+
+// goPinnedVersion returns the released version of a "path@version" spec, or "" for @latest, branches and commits
+func goPinnedVersion(spec string) string {
+	matches := goPackagePattern.FindStringSubmatch(spec)
+	if matches == nil || !goSemverPattern.MatchString(matches[5]) {
+		return ""
+	}
+	return matches[5]
+}
+
+// goBinaryName returns the name of the binary go install builds for a package path
+// Example: "github.com/foo/bar/v2" -> "bar"
+func goBinaryName(packagePath string) string {
+	name := path.Base(packagePath)
+	if goMajorVersionPattern.MatchString(name) && strings.Count(packagePath, "/") > 1 {
+		name = path.Base(path.Dir(packagePath))
+	}
+	return name
+}
+
+// ResolveEntries splits version specs so state tracks plain package paths
+// "golang.org/x/tools/gopls@v0.15.0" becomes Name "golang.org/x/tools/gopls" with Source the full spec
+func (gm *GoInstallManager) ResolveEntries(packages []config.PackageEntry, configDir string) ([]config.PackageEntry, error) {
+	resolved := make([]config.PackageEntry, len(packages))
+	for i, pkg := range packages {
+		if packagePath := goPackagePath(pkg.Name); pkg.Source == "" && packagePath != pkg.Name {
+			pkg.Source = pkg.Name
+			pkg.Name = packagePath
+		}
+		resolved[i] = pkg
+	}
+	return resolved, nil
+}
+
+// InstallPackages installs Go binaries with go install
+// Packages are skipped with a warning when the Go toolchain is not installed
+func (gm *GoInstallManager) InstallPackages(packages []config.PackageEntry, packageDefaults map[string][]string) error {
+	if len(packages) == 0 {
+		gm.logger.Debug("No go packages to install")
+		return nil
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		gm.logger.Warn("go command not found - skipping go packages", "count", len(packages))
+		return nil
+	}
+
+	gm.logger.Info("Managing go packages...", "count", len(packages))
+
+	binDir, err := goBinDir()
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range packages {
+		spec := pkg.Name
+		if pkg.Source != "" {
+			spec = pkg.Source
+		}
+		packagePath := goPackagePath(spec)
+		pinned := goPinnedVersion(spec)
+
+		version, isInstalled := installedGoBinary(filepath.Join(binDir, goBinaryName(packagePath)), packagePath)
+		if isInstalled {
+			if pinned == "" || pinned == version {
+				gm.logger.Debug("Go binary already installed", "package", packagePath, "version", version)
+				continue
+			}
+			gm.logger.Info("Reinstalling Go binary with pinned version", "package", packagePath, "installed", version, "pinned", pinned)
+		}
+
+		// go install needs a version for packages outside the current module
+		if !strings.Contains(spec, "@") {
+			spec += "@latest"
+		}
+
+		args := append([]string{"go", "install"}, gm.resolvePackageFlags(pkg, packageDefaults)...)
+		args = append(args, spec)
+		if err := gm.run(args); err != nil {
+			return fmt.Errorf("failed to install go package '%s': %w", packagePath, err)
+		}
+		config.Success("Installed Go binary: %s", goBinaryName(packagePath))
+	}
+
+	gm.logger.Info("✓ go packages processed successfully")
+	return nil
+}
+
+// resolvePackageFlags implements the three-tier flag resolution system
+func (gm *GoInstallManager) resolvePackageFlags(pkg config.PackageEntry, packageDefaults map[string][]string) []string {
+	// Tier 3: Per-package flags (highest priority)
+	if pkg.Flags != nil {
+		return pkg.Flags
+	}
+
+	// Tier 2: User package defaults
+	if userDefaults, exists := packageDefaults["go"]; exists {
+		return userDefaults
+	}
+
+	// Tier 1: Internal defaults
+	return config.GetDefaultFlags("go")
+}
+
+// run executes a go command (or logs it in dry-run mode)
+func (gm *GoInstallManager) run(args []string) error {
+	if gm.dryRun {
+		gm.logger.Info("  [DRY RUN] Would run:", "command", strings.Join(args, " "))
+		return nil
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		gm.logger.Error("Command failed", "command", strings.Join(args, " "), "error", err, "output", string(output))
+		return fmt.Errorf("%s failed: %w", strings.Join(args[:2], " "), err)
+	}
+
+	gm.logger.Debug("Command completed", "command", strings.Join(args, " "), "output", string(output))
+	return nil
+}
+
+// goBinDir returns the directory go install writes binaries to (GOBIN, or the first GOPATH entry's bin)
+func goBinDir() (string, error) {
+	output, err := exec.Command("go", "env", "GOBIN", "GOPATH").Output()
+	if err != nil {
+		return "", fmt.Errorf("go env failed: %w", err)
+	}
+
+	// Don't trim the output first: GOBIN is printed as an empty first line when it's unset
+	lines := strings.Split(string(output), "\n")
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) != "" {
+		return strings.TrimSpace(lines[0]), nil
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		gopath := filepath.SplitList(strings.TrimSpace(lines[1]))[0]
+		return filepath.Join(gopath, "bin"), nil
+	}
+	return "", fmt.Errorf("could not determine Go binary directory from GOBIN or GOPATH")
+}
+
+// installedGoBinary reports the module version of a binary if it was built from packagePath
+func installedGoBinary(binaryPath, packagePath string) (string, bool) {
+	if _, err := os.Stat(binaryPath); err != nil {
+		return "", false
+	}
+
+	output, err := exec.Command("go", "version", "-m", binaryPath).Output()
+	if err != nil {
+		return "", false
+	}
+
+	builtPath, version := parseGoVersionM(string(output))
+	if builtPath != packagePath {
+		return "", false
+	}
+	return version, true
+}
+
+// parseGoVersionM parses `go version -m` output, e.g.:
+//
+//	/home/user/go/bin/gopls: go1.22.0
+//		path	golang.org/x/tools/gopls
+//		mod	golang.org/x/tools/gopls	v0.15.0	h1:...
+func parseGoVersionM(output string) (string, string) {
+	var packagePath, version string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "path":
+			packagePath = fields[1]
+		case "mod":
+			if len(fields) >= 3 {
+				version = fields[2]
+			}
+		}
+	}
+	return packagePath, version
+}
+
+// isPackageInstalled checks if a binary built from the package path is in the Go binary directory
+func (gm *GoInstallManager) isPackageInstalled(packageName string) (bool, error) {
+	binDir, err := goBinDir()
+	if err != nil {
+		return false, err
+	}
+	packagePath := goPackagePath(packageName)
+	_, isInstalled := installedGoBinary(filepath.Join(binDir, goBinaryName(packagePath)), packagePath)
+	return isInstalled, nil
+}
+
+// isPackageAvailable reports whether a package can be installed
+// Module availability is only known at install time, so any valid package path is considered available
+func (gm *GoInstallManager) isPackageAvailable(packageName string) (bool, error) {
+	return goPackagePattern.MatchString(packageName), nil
+}
+
+// RemovePackages deletes binaries of packages that are no longer in the configuration
+// Only binaries that `go version -m` reports as built from the package path are deleted
+func (gm *GoInstallManager) RemovePackages(packagesToRemove []string) error {
+	if len(packagesToRemove) == 0 {
+		return nil
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		gm.logger.Warn("go command not found - cannot remove go packages", "packages", packagesToRemove)
+		return nil
+	}
+
+	gm.logger.Info("Removing go packages no longer in configuration", "packages", packagesToRemove)
+
+	binDir, err := goBinDir()
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range packagesToRemove {
+		packagePath := goPackagePath(pkg)
+		binaryPath := filepath.Join(binDir, goBinaryName(packagePath))
+
+		if _, isInstalled := installedGoBinary(binaryPath, packagePath); !isInstalled {
+			gm.logger.Debug("Go binary not installed, skipping removal", "package", packagePath, "path", binaryPath)
+			continue
+		}
+
+		if gm.dryRun {
+			gm.logger.Info("  [DRY RUN] Would remove:", "path", binaryPath)
+			continue
+		}
+
+		if err := os.Remove(binaryPath); err != nil {
+			return fmt.Errorf("failed to remove go package %s: %w", packagePath, err)
+		}
+		config.Success("Removed Go binary: %s", binaryPath)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// setupStubGo installs a stub go executable whose `go version -m` prints the contents of the binary
+// With unsetGOBIN, `go env GOBIN GOPATH` prints an empty GOBIN line and binaries go to $GOPATH/bin
+// Returns the command log path and the binary directory
+func setupStubGo(t *testing.T, unsetGOBIN bool) (string, string) {
+	t.Helper()
+	binDir := t.TempDir()
+	goBin := t.TempDir()
+	logPath := filepath.Join(t.TempDir(), "commands.log")
+
+	envOutput := `echo "` + goBin + `"; echo "/unused/gopath"`
+	if unsetGOBIN {
+		gopath := t.TempDir()
+		goBin = filepath.Join(gopath, "bin")
+		if err := os.MkdirAll(goBin, 0755); err != nil {
+			t.Fatalf("failed to create GOPATH bin: %v", err)
+		}
+		envOutput = `echo ""; echo "` + gopath + `"`
+	}
+
+	writeStubCommand(t, binDir, "go", `case "$1" in
+  env) `+envOutput+` ;;
+  version) cat "$3" ;;
+  *) echo "go $@" >> `+logPath+` ;;
+esac
+`)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	writeFakeGoBinary(t, goBin, "gopls", "golang.org/x/tools/gopls", "v0.15.0")
+
+	return logPath, goBin
+}
+
+// writeFakeGoBinary writes a file containing the `go version -m` output for a binary
+func writeFakeGoBinary(t *testing.T, dir, name, packagePath, version string) {
+	t.Helper()
+	content := filepath.Join(dir, name) + ": go1.22.0\n\tpath\t" + packagePath + "\n\tmod\t" + packagePath + "\t" + version + "\th1:abc=\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0755); err != nil {
+		t.Fatalf("failed to write fake binary: %v", err)
+	}
+}
+
+func TestGoPackageSpecParsing(t *testing.T) {
+	tests := []struct {
+		spec   string
+		path   string
+		pinned string
+		binary string
+	}{
+		{"golang.org/x/tools/gopls@v0.15.0", "golang.org/x/tools/gopls", "v0.15.0", "gopls"},
+		{"github.com/junegunn/fzf@latest", "github.com/junegunn/fzf", "", "fzf"},
+		{"mvdan.cc/gofumpt", "mvdan.cc/gofumpt", "", "gofumpt"},
+		{"github.com/foo/bar/v2@v2.1.0", "github.com/foo/bar/v2", "v2.1.0", "bar"},
+	}
+
+	for _, tt := range tests {
+		if got := goPackagePath(tt.spec); got != tt.path {
+			t.Errorf("goPackagePath(%q) = %q, expected %q", tt.spec, got, tt.path)
+		}
+		if got := goPinnedVersion(tt.spec); got != tt.pinned {
+			t.Errorf("goPinnedVersion(%q) = %q, expected %q", tt.spec, got, tt.pinned)
+		}
+		if got := goBinaryName(goPackagePath(tt.spec)); got != tt.binary {
+			t.Errorf("goBinaryName(%q) = %q, expected %q", tt.spec, got, tt.binary)
+		}
+	}
+}
+
+func TestGoInstallManager_InstallPackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	tests := []struct {
+		name     string
+		entry    config.PackageEntry
+		expected []string
+	}{
+		{"installed binary is skipped", config.PackageEntry{Name: "golang.org/x/tools/gopls"}, nil},
+		{"matching pin is skipped", config.PackageEntry{Name: "golang.org/x/tools/gopls", Source: "golang.org/x/tools/gopls@v0.15.0"}, nil},
+		{"pin mismatch is reinstalled", config.PackageEntry{Name: "golang.org/x/tools/gopls", Source: "golang.org/x/tools/gopls@v0.16.0"}, []string{"go install golang.org/x/tools/gopls@v0.16.0"}},
+		{"unversioned package installs latest", config.PackageEntry{Name: "mvdan.cc/gofumpt"}, []string{"go install mvdan.cc/gofumpt@latest"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logPath, _ := setupStubGo(t, false)
+
+			if err := NewGoInstallManager(logger, false).InstallPackages([]config.PackageEntry{tt.entry}, nil); err != nil {
+				t.Fatalf("InstallPackages failed: %v", err)
+			}
+
+			commands := readCommandLog(t, logPath)
+			if strings.Join(commands, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected commands %v, got %v", tt.expected, commands)
+			}
+		})
+	}
+}
+
+func TestGoInstallManager_RemovePackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	_, goBin := setupStubGo(t, false)
+	// A binary with the same name built from another package must not be removed
+	writeFakeGoBinary(t, goBin, "fzf", "example.com/other/fzf", "v1.0.0")
+
+	if err := NewGoInstallManager(logger, false).RemovePackages([]string{"golang.org/x/tools/gopls", "github.com/junegunn/fzf"}); err != nil {
+		t.Fatalf("RemovePackages failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(goBin, "gopls")); !os.IsNotExist(err) {
+		t.Error("expected gopls binary to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(goBin, "fzf")); err != nil {
+		t.Error("expected unrelated fzf binary to be kept")
+	}
+}
+
+func TestGoBinDir_UnsetGOBIN(t *testing.T) {
+	_, goBin := setupStubGo(t, true)
+
+	dir, err := goBinDir()
+	if err != nil {
+		t.Fatalf("goBinDir failed: %v", err)
+	}
+	if dir != goBin {
+		t.Errorf("expected %s, got %s", goBin, dir)
+	}
+
+	// The installed binary in $GOPATH/bin is found, so nothing is reinstalled
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+	logPath, _ := setupStubGo(t, true)
+	if err := NewGoInstallManager(logger, false).InstallPackages([]config.PackageEntry{{Name: "golang.org/x/tools/gopls"}}, nil); err != nil {
+		t.Fatalf("InstallPackages failed: %v", err)
+	}
+	if commands := readCommandLog(t, logPath); len(commands) != 0 {
+		t.Errorf("expected installed gopls to be skipped, got %v", commands)
+	}
+}
+
+func TestGoInstallManager_MissingToolchain(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	t.Setenv("PATH", t.TempDir())
+
+	if err := NewGoInstallManager(logger, false).InstallPackages([]config.PackageEntry{{Name: "mvdan.cc/gofumpt"}}, nil); err != nil {
+		t.Errorf("expected missing go toolchain to be skipped, got %v", err)
+	}
+}
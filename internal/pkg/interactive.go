@@ -34,6 +34,9 @@ const (
 	ResolutionBackup
 	ResolutionViewDiff
 	ResolutionQuit
+	ResolutionMerge
+	ResolutionEdit
+	ResolutionPullBack
 )
 
 // FileConflictInfo contains information about a file conflict
@@ -44,6 +47,8 @@ type FileConflictInfo struct {
 	ExistingInfo   os.FileInfo
 	IsSymlink      bool
 	BackupEnabled  bool
+	CanMerge       bool // The last deployed content is known, so local edits can be merged with the new version
+	CanPullBack    bool // The source is a plain file in the config repository the local version can be copied to
 }
 
 // PromptForConflictResolution prompts the user to resolve a file conflict
@@ -70,6 +75,13 @@ func (im *InteractiveManager) PromptForConflictResolution(conflict FileConflictI
 		fmt.Print("  [b] Backup existing file and overwrite\n")
 	}
 	fmt.Print("  [d] Show diff between files\n")
+	if conflict.CanMerge {
+		fmt.Print("  [m] Merge your changes with the new version\n")
+		fmt.Print("  [e] Merge and edit the result\n")
+	}
+	if conflict.CanPullBack {
+		fmt.Print("  [p] Pull your version back into the source\n")
+	}
 	fmt.Print("  [s] Skip this file\n")
 	fmt.Print("  [q] Quit configuration\n")
 	fmt.Print("\nChoice: ")
@@ -91,6 +103,24 @@ func (im *InteractiveManager) PromptForConflictResolution(conflict FileConflictI
 		return im.PromptForConflictResolution(conflict) // Ask again
 	case "d", "diff":
 		return ResolutionViewDiff, nil
+	case "m", "merge":
+		if conflict.CanMerge {
+			return ResolutionMerge, nil
+		}
+		im.logger.Warn("The last deployed version of this file is unknown, so it can't be merged")
+		return im.PromptForConflictResolution(conflict) // Ask again
+	case "e", "edit":
+		if conflict.CanMerge {
+			return ResolutionEdit, nil
+		}
+		im.logger.Warn("The last deployed version of this file is unknown, so it can't be merged")
+		return im.PromptForConflictResolution(conflict) // Ask again
+	case "p", "pull":
+		if conflict.CanPullBack {
+			return ResolutionPullBack, nil
+		}
+		im.logger.Warn("This file's source can't be updated from the local version")
+		return im.PromptForConflictResolution(conflict) // Ask again
 	case "s", "skip":
 		return ResolutionSkip, nil
 	case "q", "quit":
@@ -177,6 +207,43 @@ func (im *InteractiveManager) showBuiltinDiff(file1, file2 string) error {
 	return nil
 }
 
+// EditFile opens a file in $EDITOR (vi if unset) and waits for the editor to exit
+func (im *InteractiveManager) EditFile(path string) error {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", editor[0], err)
+	}
+	return nil
+}
+
+// ResolveMerge lets the user resolve a merge result in place
+// $MERGETOOL is run as a shell command with $BASE, $LOCAL, $REMOTE and $MERGED set to the file paths,
+// like git's mergetool commands; without it the merged file is opened in $EDITOR
+func (im *InteractiveManager) ResolveMerge(basePath, localPath, remotePath, mergedPath string) error {
+	mergeTool := os.Getenv("MERGETOOL")
+	if mergeTool == "" {
+		return im.EditFile(mergedPath)
+	}
+
+	cmd := exec.Command("sh", "-c", mergeTool)
+	cmd.Env = append(os.Environ(), "BASE="+basePath, "LOCAL="+localPath, "REMOTE="+remotePath, "MERGED="+mergedPath)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("merge tool failed: %w", err)
+	}
+	return nil
+}
+
 // PromptYesNo prompts the user for a yes/no question
 func (im *InteractiveManager) PromptYesNo(question string, defaultYes bool) (bool, error) {
 	defaultStr := "y/N"
@@ -288,11 +355,11 @@ func (im *InteractiveManager) ShowPreviewSummary(files map[string]config.File, c
 		fmt.Printf("Files to be deployed (%d):\n", len(files))
 		for name, file := range files {
 			action := "symlink"
-			if file.Copy {
+			if file.DeploysAsCopy() {
 				action = "copy"
 			}
 			
-			fmt.Printf("  • %s: %s → %s (%s)\n", name, file.Source, file.Destination, action)
+			fmt.Printf("  • %s: %s → %s (%s)\n", name, file.DisplaySource(), file.Destination, action)
 			
 			if file.Owner != "" || file.Group != "" {
 				fmt.Printf("    ownership: %s:%s\n", file.Owner, file.Group)
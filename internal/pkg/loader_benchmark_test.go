@@ -385,7 +385,9 @@ func loadTestConfiguration(configPath string) (*config.Config, error) {
 	return &config.Config{
 		Version: "1.0",
 		Packages: config.PackageManagement{
-			Apt: []config.PackageEntry{{Name: "test"}},
+			Managers: map[string][]config.PackageEntry{
+				"apt": {{Name: "test"}},
+			},
 		},
 	}, nil
 }
@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// LogicalPackageManager resolves logical packages from packages.any to concrete package entries
+type LogicalPackageManager struct {
+	logger   *log.Logger
+	dryRun   bool
+	managers map[string]PackageManager
+	settings map[string]map[string]string // package_settings of the configuration being resolved
+}
+
+// NewLogicalPackageManager creates a new logical package manager
+func NewLogicalPackageManager(logger *log.Logger, dryRun bool) *LogicalPackageManager {
+	return &LogicalPackageManager{
+		logger:   logger,
+		dryRun:   dryRun,
+		managers: make(map[string]PackageManager),
+	}
+}
+
+// manager returns the registered package manager used to check a candidate
+func (lm *LogicalPackageManager) manager(name string) (PackageManager, error) {
+	if manager, exists := lm.managers[name]; exists {
+		return manager, nil
+	}
+	manager, err := NewPackageManager(name, ManagerOptions{Logger: lm.logger, DryRun: lm.dryRun, Settings: lm.settings[name]})
+	if err != nil {
+		return nil, err
+	}
+	lm.managers[name] = manager
+	return manager, nil
+}
+
+// Resolve picks one candidate for every logical package and appends it to its manager's package list
+// A candidate that is already installed always wins (the previous choice is checked first);
+// otherwise the first available candidate in configuration order is selected
+func (lm *LogicalPackageManager) Resolve(cfg *config.Config, previous map[string]string) error {
+	if len(cfg.Packages.Any) == 0 {
+		return nil
+	}
+	lm.settings = cfg.PackageSettings
+
+	names := make([]string, 0, len(cfg.Packages.Any))
+	for name := range cfg.Packages.Any {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		candidates := make([]config.PackageCandidate, 0, len(cfg.Packages.Any[name]))
+		for _, spec := range cfg.Packages.Any[name] {
+			candidate, err := config.ParsePackageCandidate(spec)
+			if err != nil {
+				return fmt.Errorf("invalid candidate for logical package %s: %w", name, err)
+			}
+			candidates = append(candidates, candidate)
+		}
+
+		chosen, err := lm.chooseCandidate(name, candidates, previous[name])
+		if err != nil {
+			return err
+		}
+
+		addLogicalEntry(cfg, name, chosen)
+	}
+
+	return nil
+}
+
+// chooseCandidate selects the candidate to use for a logical package
+func (lm *LogicalPackageManager) chooseCandidate(name string, candidates []config.PackageCandidate, previous string) (config.PackageCandidate, error) {
+	// Check the previously chosen candidate first so an existing installation is kept
+	ordered := make([]config.PackageCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.String() == previous {
+			ordered = append(ordered, candidate)
+		}
+	}
+	for _, candidate := range candidates {
+		if candidate.String() != previous {
+			ordered = append(ordered, candidate)
+		}
+	}
+
+	usable := make([]config.PackageCandidate, 0, len(ordered))
+	for _, candidate := range ordered {
+		manager, err := lm.manager(candidate.Manager)
+		if err != nil {
+			return config.PackageCandidate{}, fmt.Errorf("invalid candidate for logical package %s: %w", name, err)
+		}
+		if _, err := exec.LookPath(PackageManagerCommand(candidate.Manager)); err != nil {
+			lm.logger.Debug("Package manager not available for candidate", "package", name, "candidate", candidate.String())
+			continue
+		}
+		usable = append(usable, candidate)
+
+		installed, err := manager.isPackageInstalled(candidate.Name)
+		if err != nil {
+			lm.logger.Debug("Could not check candidate installation", "candidate", candidate.String(), "error", err)
+			continue
+		}
+		if installed {
+			lm.logger.Debug("Logical package already installed", "package", name, "candidate", candidate.String())
+			return candidate, nil
+		}
+	}
+
+	// Nothing is installed: take the first available candidate in configuration order
+	for _, candidate := range candidates {
+		if !containsCandidate(usable, candidate) {
+			continue
+		}
+
+		manager, err := lm.manager(candidate.Manager)
+		if err != nil {
+			return config.PackageCandidate{}, fmt.Errorf("invalid candidate for logical package %s: %w", name, err)
+		}
+
+		available, err := manager.isPackageAvailable(candidate.Name)
+		if err != nil {
+			lm.logger.Debug("Could not check candidate availability", "candidate", candidate.String(), "error", err)
+			continue
+		}
+		if available {
+			lm.logger.Info("Selected package candidate", "package", name, "candidate", candidate.String())
+			return candidate, nil
+		}
+	}
+
+	return config.PackageCandidate{}, fmt.Errorf("no candidate is available for logical package %s", name)
+}
+
+// containsCandidate reports whether a candidate is in the list
+func containsCandidate(candidates []config.PackageCandidate, candidate config.PackageCandidate) bool {
+	for _, c := range candidates {
+		if c.String() == candidate.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// addLogicalEntry appends the chosen candidate to its manager's package list
+// If the package is already listed explicitly, that entry is marked instead
+func addLogicalEntry(cfg *config.Config, name string, candidate config.PackageCandidate) {
+	packages := cfg.Packages.Get(candidate.Manager)
+
+	for i := range packages {
+		if packages[i].Name == candidate.Name {
+			packages[i].Logical = name
+			return
+		}
+	}
+
+	entry := candidate.Entry()
+	entry.Logical = name
+	cfg.Packages.Set(candidate.Manager, append(packages, entry))
+}
@@ -0,0 +1,149 @@
+package pkg
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// setupCandidateStubs installs stub package manager commands and restricts PATH to them
+// installed and available list "<manager>:<package>" candidates reported by the stubs
+func setupCandidateStubs(t *testing.T, managers []string, installed, available []string) {
+	t.Helper()
+	binDir := t.TempDir()
+
+	has := func(list []string, manager string) string {
+		var names []string
+		for _, item := range list {
+			if m, name, _ := strings.Cut(item, ":"); m == manager {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, " ")
+	}
+
+	for _, manager := range managers {
+		switch manager {
+		case "apt":
+			writeStubCommand(t, binDir, "apt", "exit 0\n")
+			writeStubCommand(t, binDir, "dpkg", "for p in "+has(installed, "apt")+"; do [ \"$p\" = \"$2\" ] && echo 'Status: install ok installed' && exit 0; done\nexit 1\n")
+			writeStubCommand(t, binDir, "apt-cache", "for p in "+has(available, "apt")+"; do [ \"$p\" = \"$2\" ] && echo '  Candidate: 1.0' && exit 0; done\necho '  Candidate: (none)'\n")
+		case "snap":
+			writeStubCommand(t, binDir, "snap", "if [ \"$1\" = list ]; then for p in "+has(installed, "snap")+"; do [ \"$p\" = \"$2\" ] && echo \"$p 1.0\" && exit 0; done; exit 1; fi\n"+
+				"for p in "+has(available, "snap")+"; do [ \"$p\" = \"$2\" ] && exit 0; done\nexit 1\n")
+		case "flatpak":
+			writeStubCommand(t, binDir, "flatpak", "if [ \"$1\" = list ]; then for p in "+has(installed, "flatpak")+"; do echo \"$p\"; done; exit 0; fi\n"+
+				"for p in "+has(available, "flatpak")+"; do echo \"$p\"; done\n")
+		}
+	}
+
+	t.Setenv("PATH", binDir)
+}
+
+func TestLogicalPackageManager_Resolve(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	candidates := []string{"apt:code", "snap:code --classic", "flatpak:com.visualstudio.code"}
+
+	tests := []struct {
+		name      string
+		managers  []string
+		installed []string
+		available []string
+		previous  map[string]string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:      "first available candidate is chosen",
+			managers:  []string{"apt", "snap", "flatpak"},
+			available: []string{"snap:code", "flatpak:com.visualstudio.code"},
+			expected:  "snap:code",
+		},
+		{
+			name:      "installed candidate wins over an earlier available one",
+			managers:  []string{"apt", "snap", "flatpak"},
+			installed: []string{"flatpak:com.visualstudio.code"},
+			available: []string{"apt:code", "flatpak:com.visualstudio.code"},
+			expected:  "flatpak:com.visualstudio.code",
+		},
+		{
+			name:      "previous choice is kept when several are installed",
+			managers:  []string{"apt", "snap", "flatpak"},
+			installed: []string{"apt:code", "snap:code"},
+			previous:  map[string]string{"vscode": "snap:code"},
+			expected:  "snap:code",
+		},
+		{
+			name:      "missing package manager is skipped",
+			managers:  []string{"flatpak"},
+			available: []string{"flatpak:com.visualstudio.code"},
+			expected:  "flatpak:com.visualstudio.code",
+		},
+		{
+			name:      "no available candidate",
+			managers:  []string{"apt", "snap"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupCandidateStubs(t, tt.managers, tt.installed, tt.available)
+
+			cfg := &config.Config{
+				Packages: config.PackageManagement{
+					Any: map[string][]string{"vscode": candidates},
+				},
+			}
+
+			err := NewLogicalPackageManager(logger, true).Resolve(cfg, tt.previous)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error when no candidate is available")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve failed: %v", err)
+			}
+
+			choices := extractLogicalPackages(cfg)
+			if choices["vscode"] != tt.expected {
+				t.Errorf("expected %s, got %v", tt.expected, choices)
+			}
+		})
+	}
+}
+
+func TestLogicalPackageManager_ResolveKeepsFlags(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	setupCandidateStubs(t, []string{"snap"}, nil, []string{"snap:code"})
+
+	cfg := &config.Config{
+		Packages: config.PackageManagement{
+			Any: map[string][]string{"vscode": {"apt:code", "snap:code --classic"}},
+		},
+	}
+
+	if err := NewLogicalPackageManager(logger, true).Resolve(cfg, nil); err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if len(cfg.Packages.Get("snap")) != 1 {
+		t.Fatalf("expected 1 snap package, got %v", cfg.Packages.Get("snap"))
+	}
+	entry := cfg.Packages.Get("snap")[0]
+	if entry.Name != "code" || entry.Logical != "vscode" || len(entry.Flags) != 1 || entry.Flags[0] != "--classic" {
+		t.Errorf("unexpected snap entry: %+v", entry)
+	}
+	if len(cfg.Packages.Get("apt")) != 0 {
+		t.Errorf("expected no apt packages, got %v", cfg.Packages.Get("apt"))
+	}
+}
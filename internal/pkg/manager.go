@@ -0,0 +1,175 @@
+package pkg
+
+import (
+	"fmt"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// PackageManager is the common interface implemented by every package manager configr drives
+type PackageManager interface {
+	// InstallPackages installs packages, resolving flags with the three-tier flag hierarchy
+	InstallPackages(packages []config.PackageEntry, packageDefaults map[string][]string) error
+
+	// RemovePackages removes packages that are no longer in the configuration
+	RemovePackages(packagesToRemove []string) error
+
+	// isPackageInstalled checks if a single package is installed
+	isPackageInstalled(packageName string) (bool, error)
+
+	// isPackageAvailable checks if a package can be installed from the manager's sources
+	isPackageAvailable(packageName string) (bool, error)
+}
+
+// packageNameValidator is implemented by managers that validate package names before installing
+type packageNameValidator interface {
+	ValidatePackageNames(packages []config.PackageEntry) error
+}
+
+// entryResolver is implemented by managers that rewrite entries before state comparison
+// (e.g., downloading remote files and replacing them with the real package names)
+type entryResolver interface {
+	ResolveEntries(packages []config.PackageEntry, configDir string) ([]config.PackageEntry, error)
+}
+
+// ManagerOptions configures package manager instances created from the registry
+type ManagerOptions struct {
+	Logger          *log.Logger
+	DryRun          bool
+	UseOptimization bool              // Use the cache-optimized implementation where one exists
+	Settings        map[string]string // Manager-level settings from package_settings (e.g., npm prefix)
+}
+
+// PackageManagerFactory creates a package manager instance
+type PackageManagerFactory func(opts ManagerOptions) PackageManager
+
+// OnlineChecker reports whether each entry can be installed from the manager's sources, keyed by package name
+type OnlineChecker func(ov *OnlineValidator, entries []config.PackageEntry, cfg *config.Config) (map[string]bool, error)
+
+// PackageManagerRegistration describes a package manager implementation
+type PackageManagerRegistration struct {
+	Name    string                     // Key under packages: (e.g., "apt")
+	Command string                     // Command that must be on PATH for the manager to be usable
+	Spec    *config.PackageManagerSpec // Configuration spec; nil when internal/config already registers it
+	New     PackageManagerFactory      // Creates a manager instance
+
+	// OnlineCheck checks package availability for validate --online (optional; unchecked when nil)
+	OnlineCheck OnlineChecker
+}
+
+// packageManagers holds registered implementations keyed by manager name
+var packageManagers = make(map[string]PackageManagerRegistration)
+
+// RegisterPackageManager registers a package manager implementation (and its configuration spec, if given)
+// Adding a manager only requires a file in this package that calls RegisterPackageManager from init()
+func RegisterPackageManager(registration PackageManagerRegistration) {
+	if registration.Spec != nil {
+		config.RegisterPackageManager(*registration.Spec)
+	}
+	packageManagers[registration.Name] = registration
+}
+
+// RegisteredPackageManagers returns the managers that have an implementation, in registration order
+// Managers defined under custom_managers are included once config.RegisterCustomManagers has run
+func RegisteredPackageManagers() []string {
+	var names []string
+	for _, name := range config.RegisteredPackageManagers() {
+		if _, exists := packageManagers[name]; exists {
+			names = append(names, name)
+		} else if _, custom := customDefinition(name); custom {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// customDefinition returns the command templates of a manager registered from custom_managers
+func customDefinition(name string) (config.CustomManager, bool) {
+	spec, registered := config.LookupPackageManager(name)
+	if !registered || spec.Custom == nil {
+		return config.CustomManager{}, false
+	}
+	return *spec.Custom, true
+}
+
+// NewPackageManager creates a registered package manager
+func NewPackageManager(name string, opts ManagerOptions) (PackageManager, error) {
+	registration, exists := packageManagers[name]
+	if !exists {
+		if definition, custom := customDefinition(name); custom {
+			return NewCustomPackageManager(opts.Logger, opts.DryRun, name, definition), nil
+		}
+		return nil, fmt.Errorf("unsupported package manager: %s", name)
+	}
+	return registration.New(opts), nil
+}
+
+// onlineCheckedManagers returns the registered managers whose packages validate --online checks
+func onlineCheckedManagers() []string {
+	var names []string
+	for _, name := range RegisteredPackageManagers() {
+		if packageManagers[name].OnlineCheck != nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// PackageManagerCommand returns the command a registered package manager requires
+func PackageManagerCommand(name string) string {
+	if definition, custom := customDefinition(name); custom {
+		if _, exists := packageManagers[name]; !exists {
+			return customManagerCommand(definition)
+		}
+	}
+	return packageManagers[name].Command
+}
+
+// ValidatePackageNames validates package names with the manager's own rules, if it has any
+func ValidatePackageNames(manager PackageManager, packages []config.PackageEntry) error {
+	if validator, ok := manager.(packageNameValidator); ok {
+		return validator.ValidatePackageNames(packages)
+	}
+	return nil
+}
+
+// ResolveEntries lets a manager rewrite its entries before state comparison, if it needs to
+func ResolveEntries(manager PackageManager, packages []config.PackageEntry, configDir string) ([]config.PackageEntry, error) {
+	if resolver, ok := manager.(entryResolver); ok {
+		return resolver.ResolveEntries(packages, configDir)
+	}
+	return packages, nil
+}
+
+func init() {
+	RegisterPackageManager(PackageManagerRegistration{
+		Name:        "apt",
+		Command:     "apt",
+		OnlineCheck: (*OnlineValidator).checkApt,
+		New: func(opts ManagerOptions) PackageManager {
+			if opts.UseOptimization {
+				return NewOptimizedAptManager(opts.Logger, opts.DryRun, NewCacheManager(opts.Logger))
+			}
+			return NewAptManager(opts.Logger, opts.DryRun)
+		},
+	})
+
+	RegisterPackageManager(PackageManagerRegistration{
+		Name:        "flatpak",
+		Command:     "flatpak",
+		OnlineCheck: (*OnlineValidator).checkFlatpak,
+		New: func(opts ManagerOptions) PackageManager {
+			return NewFlatpakManager(opts.Logger, opts.DryRun)
+		},
+	})
+
+	RegisterPackageManager(PackageManagerRegistration{
+		Name:        "snap",
+		Command:     "snap",
+		OnlineCheck: (*OnlineValidator).checkSnap,
+		New: func(opts ManagerOptions) PackageManager {
+			return NewSnapManager(opts.Logger, opts.DryRun)
+		},
+	})
+}
@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+func TestNewPackageManager_BuiltIn(t *testing.T) {
+	logger := log.New(os.Stderr)
+
+	tests := []struct {
+		name     string
+		opts     ManagerOptions
+		expected string
+	}{
+		{name: "apt", opts: ManagerOptions{Logger: logger}, expected: "*pkg.AptManager"},
+		{name: "apt", opts: ManagerOptions{Logger: logger, UseOptimization: true}, expected: "*pkg.OptimizedAptManager"},
+		{name: "flatpak", opts: ManagerOptions{Logger: logger}, expected: "*pkg.FlatpakManager"},
+		{name: "snap", opts: ManagerOptions{Logger: logger}, expected: "*pkg.SnapManager"},
+	}
+
+	for _, tt := range tests {
+		manager, err := NewPackageManager(tt.name, tt.opts)
+		if err != nil {
+			t.Fatalf("NewPackageManager(%s) failed: %v", tt.name, err)
+		}
+		if got := fmt.Sprintf("%T", manager); got != tt.expected {
+			t.Errorf("NewPackageManager(%s) = %s, expected %s", tt.name, got, tt.expected)
+		}
+	}
+
+	if _, err := NewPackageManager("unknown", ManagerOptions{Logger: logger}); err == nil {
+		t.Error("expected error for unregistered package manager")
+	}
+
+	expected := []string{"apt", "flatpak", "snap"}
+	managers := RegisteredPackageManagers()
+	if len(managers) < len(expected) {
+		t.Fatalf("expected at least %v, got %v", expected, managers)
+	}
+	for i, name := range expected {
+		if managers[i] != name {
+			t.Errorf("expected manager %d to be %s, got %s", i, name, managers[i])
+		}
+	}
+}
+
+func TestManagedPackages_JSONRoundTrip(t *testing.T) {
+	packages := ManagedPackages{Managers: map[string][]string{"apt": {"curl"}}}
+	packages.Set("pipx", []string{"black"})
+
+	data, err := json.Marshal(packages)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var flat map[string][]string
+	if err := json.Unmarshal(data, &flat); err != nil {
+		t.Fatalf("state should be a flat manager map: %v", err)
+	}
+	if len(flat["pipx"]) != 1 || len(flat["apt"]) != 1 {
+		t.Errorf("expected apt and pipx keys, got %s", data)
+	}
+
+	var decoded ManagedPackages
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got := decoded.Get("apt"); len(got) != 1 || got[0] != "curl" {
+		t.Errorf("expected apt [curl], got %v", got)
+	}
+	if got := decoded.Get("pipx"); len(got) != 1 || got[0] != "black" {
+		t.Errorf("expected pipx [black], got %v", got)
+	}
+}
+
+func TestStateManager_GetPackagesToRemove_OtherManagers(t *testing.T) {
+	logger := log.New(os.Stderr)
+	sm := NewStateManagerWithPath(logger, filepath.Join(t.TempDir(), "state.json"))
+
+	previous := &config.Config{}
+	previous.Packages.Set("pipx", []config.PackageEntry{{Name: "black"}, {Name: "ruff"}})
+	if err := sm.UpdateStateWithBinaries(previous, []ManagedFile{}, []ManagedBinary{}); err != nil {
+		t.Fatalf("UpdateStateWithBinaries() failed: %v", err)
+	}
+
+	current := &config.Config{}
+	current.Packages.Set("pipx", []config.PackageEntry{{Name: "ruff"}})
+
+	toRemove, err := sm.GetPackagesToRemove(current)
+	if err != nil {
+		t.Fatalf("GetPackagesToRemove() failed: %v", err)
+	}
+	if got := toRemove.Get("pipx"); len(got) != 1 || got[0] != "black" {
+		t.Errorf("expected pipx [black] to be removed, got %v", got)
+	}
+}
+
+func TestOnlineCheckedManagers(t *testing.T) {
+	managers := onlineCheckedManagers()
+	for _, name := range []string{"apt", "flatpak", "snap"} {
+		if !slices.Contains(managers, name) {
+			t.Errorf("expected %s to be checked online, got %v", name, managers)
+		}
+	}
+	if slices.Contains(managers, "pipx") {
+		t.Errorf("expected pipx, which has no online check, to be skipped, got %v", managers)
+	}
+}
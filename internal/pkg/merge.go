@@ -0,0 +1,98 @@
+package pkg
+
+import (
+	"slices"
+	"strings"
+)
+
+// Conflict markers written around lines both sides changed, as in git
+const (
+	conflictStartMarker = "<<<<<<<"
+	conflictSplitMarker = "======="
+	conflictEndMarker   = ">>>>>>>"
+)
+
+// mergeLines merges the changes local and remote made to base (a line-level diff3)
+// Changes to different lines are combined; lines changed differently on both sides are written between
+// conflict markers labelled with localLabel and remoteLabel. Returns the merged lines and the number of conflicts
+func mergeLines(base, local, remote []string, localLabel, remoteLabel string) ([]string, int) {
+	localMatch := matchLines(base, local)
+	remoteMatch := matchLines(base, remote)
+
+	var merged []string
+	conflicts := 0
+	o, a, b := 0, 0, 0
+	for {
+		// Copy lines unchanged on both sides
+		for o < len(base) && localMatch[o] == a && remoteMatch[o] == b {
+			merged = append(merged, base[o])
+			o++
+			a++
+			b++
+		}
+		if o == len(base) && a == len(local) && b == len(remote) {
+			return merged, conflicts
+		}
+
+		// The changed region ends at the next base line both sides kept
+		nextO, nextA, nextB := len(base), len(local), len(remote)
+		for i := o; i < len(base); i++ {
+			if localMatch[i] >= 0 && remoteMatch[i] >= 0 {
+				nextO, nextA, nextB = i, localMatch[i], remoteMatch[i]
+				break
+			}
+		}
+
+		baseChunk, localChunk, remoteChunk := base[o:nextO], local[a:nextA], remote[b:nextB]
+		switch {
+		case slices.Equal(localChunk, baseChunk):
+			merged = append(merged, remoteChunk...)
+		case slices.Equal(remoteChunk, baseChunk), slices.Equal(localChunk, remoteChunk):
+			merged = append(merged, localChunk...)
+		default:
+			conflicts++
+			merged = append(merged, conflictStartMarker+" "+localLabel)
+			merged = append(merged, localChunk...)
+			merged = append(merged, conflictSplitMarker)
+			merged = append(merged, remoteChunk...)
+			merged = append(merged, conflictEndMarker+" "+remoteLabel)
+		}
+		o, a, b = nextO, nextA, nextB
+	}
+}
+
+// matchLines maps each base line to its index in other, or -1 if other removed or changed it
+func matchLines(base, other []string) []int {
+	match := make([]int, len(base))
+	i, j := 0, 0
+	for _, op := range diffLines(base, other) {
+		switch op.kind {
+		case ' ':
+			match[i] = j
+			i++
+			j++
+		case '-':
+			match[i] = -1
+			i++
+		case '+':
+			j++
+		}
+	}
+	return match
+}
+
+// hasConflictMarkers reports whether merged content still holds unresolved conflict markers
+func hasConflictMarkers(lines []string) bool {
+	start, split := false, false
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, conflictStartMarker+" "):
+			start = true
+		case line == conflictSplitMarker && start:
+			split = true
+		case strings.HasPrefix(line, conflictEndMarker+" ") && split:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeLines(t *testing.T) {
+	base := []string{"[user]", "name = Jane", "email = jane@example.com", "[core]", "editor = vim"}
+
+	tests := []struct {
+		name      string
+		local     []string
+		remote    []string
+		expected  []string
+		conflicts int
+	}{
+		{
+			name:     "changes to different lines",
+			local:    []string{"[user]", "name = Jane Doe", "email = jane@example.com", "[core]", "editor = vim"},
+			remote:   []string{"[user]", "name = Jane", "email = jane@example.com", "[core]", "editor = nvim", "pager = less"},
+			expected: []string{"[user]", "name = Jane Doe", "email = jane@example.com", "[core]", "editor = nvim", "pager = less"},
+		},
+		{
+			name:     "same change on both sides",
+			local:    []string{"[user]", "name = Jane", "email = jane@work.com", "[core]", "editor = vim"},
+			remote:   []string{"[user]", "name = Jane", "email = jane@work.com", "[core]", "editor = vim"},
+			expected: []string{"[user]", "name = Jane", "email = jane@work.com", "[core]", "editor = vim"},
+		},
+		{
+			name:     "local deletion and remote insertion",
+			local:    []string{"[user]", "name = Jane", "[core]", "editor = vim"},
+			remote:   []string{"# managed by configr", "[user]", "name = Jane", "email = jane@example.com", "[core]", "editor = vim"},
+			expected: []string{"# managed by configr", "[user]", "name = Jane", "[core]", "editor = vim"},
+		},
+		{
+			name:      "conflicting changes",
+			local:     []string{"[user]", "name = Jane", "email = jane@home.org", "[core]", "editor = vim"},
+			remote:    []string{"[user]", "name = Jane", "email = jane@work.com", "[core]", "editor = nvim"},
+			expected:  []string{"[user]", "name = Jane", "<<<<<<< local", "email = jane@home.org", "=======", "email = jane@work.com", ">>>>>>> new", "[core]", "editor = nvim"},
+			conflicts: 1,
+		},
+		{
+			name:      "both append",
+			local:     append(append([]string{}, base...), "autocrlf = input"),
+			remote:    append(append([]string{}, base...), "pager = less"),
+			expected:  append(append([]string{}, base...), "<<<<<<< local", "autocrlf = input", "=======", "pager = less", ">>>>>>> new"),
+			conflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := mergeLines(base, tt.local, tt.remote, "local", "new")
+			if strings.Join(merged, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("unexpected merge:\n%s", strings.Join(merged, "\n"))
+			}
+			if conflicts != tt.conflicts {
+				t.Errorf("expected %d conflicts, got %d", tt.conflicts, conflicts)
+			}
+			if hasConflictMarkers(merged) != (tt.conflicts > 0) {
+				t.Errorf("unexpected hasConflictMarkers result for:\n%s", strings.Join(merged, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// NpmManager handles globally installed Node.js packages with npm or pnpm
+type NpmManager struct {
+	logger *log.Logger
+	dryRun bool
+	tool   string // "npm" or "pnpm"
+	prefix string // npm global prefix (package_settings.npm.prefix); empty uses npm's configured prefix
+}
+
+// NewNpmManager creates a new npm package manager
+func NewNpmManager(logger *log.Logger, dryRun bool, prefix string) *NpmManager {
+	return &NpmManager{
+		logger: logger,
+		dryRun: dryRun,
+		tool:   "npm",
+		prefix: prefix,
+	}
+}
+
+// NewPnpmManager creates a new pnpm package manager
+// pnpm keeps global packages under PNPM_HOME, so no prefix is needed
+func NewPnpmManager(logger *log.Logger, dryRun bool) *NpmManager {
+	return &NpmManager{
+		logger: logger,
+		dryRun: dryRun,
+		tool:   "pnpm",
+	}
+}
+
+// npmPackagePattern matches a package name with an optional version or tag: "prettier", "@vercel/ncc@0.38.1", "typescript@^5"
+var npmPackagePattern = regexp.MustCompile(`^((@[a-z0-9][a-z0-9._~-]*/)?[a-z0-9][a-z0-9._~-]*)(@([^\s@]+))?$`)
+
+// npmExactVersionPattern matches a version that npm installs exactly
+var npmExactVersionPattern = regexp.MustCompile(`^=?v?([0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.-]+)?)$`)
+
+func init() {
+	RegisterPackageManager(PackageManagerRegistration{
+		Name:    "npm",
+		Command: "npm",
+		Spec:    npmPackageManagerSpec("npm", "npm", []string{"prefix"}),
+		New: func(opts ManagerOptions) PackageManager {
+			return NewNpmManager(opts.Logger, opts.DryRun, opts.Settings["prefix"])
+		},
+	})
+
+	RegisterPackageManager(PackageManagerRegistration{
+		Name:    "pnpm",
+		Command: "pnpm",
+		Spec:    npmPackageManagerSpec("pnpm", "pnpm", nil),
+		New: func(opts ManagerOptions) PackageManager {
+			return NewPnpmManager(opts.Logger, opts.DryRun)
+		},
+	})
+}
+
+// npmPackageManagerSpec returns the configuration spec shared by npm and pnpm
+func npmPackageManagerSpec(name, displayName string, settings []string) *config.PackageManagerSpec {
+	return &config.PackageManagerSpec{
+		Name:         name,
+		DisplayName:  displayName,
+		DefaultFlags: []string{},
+		ValidateName: func(name string) bool {
+			return npmPackagePattern.MatchString(name)
+		},
+		NameMessage: fmt.Sprintf("%s package must be a package name with an optional @version", displayName),
+		NameHelp:    "use a registry name like 'prettier', '@vercel/ncc' or 'typescript@5.4.5'",
+		Settings:    settings,
+	}
+}
+
+// npmPackageName returns the package name of a "name@version" spec
+func npmPackageName(spec string) string {
+	if matches := npmPackagePattern.FindStringSubmatch(spec); matches != nil {
+		return matches[1]
+	}
+	return spec
+}
+
+// npmPinnedVersion returns the exact version of a "name@version" spec, or "" for ranges and tags
+func npmPinnedVersion(spec string) string {
+	matches := npmPackagePattern.FindStringSubmatch(spec)
+	if matches == nil {
+		return ""
+	}
+	if version := npmExactVersionPattern.FindStringSubmatch(matches[4]); version != nil {
+		return version[1]
+	}
+	return ""
+}
+
+// ResolveEntries splits version specs so state tracks plain package names
+// "typescript@5.4.5" becomes Name "typescript" with Source "typescript@5.4.5"
+func (nm *NpmManager) ResolveEntries(packages []config.PackageEntry, configDir string) ([]config.PackageEntry, error) {
+	resolved := make([]config.PackageEntry, len(packages))
+	for i, pkg := range packages {
+		if name := npmPackageName(pkg.Name); pkg.Source == "" && name != pkg.Name {
+			pkg.Source = pkg.Name
+			pkg.Name = name
+		}
+		resolved[i] = pkg
+	}
+	return resolved, nil
+}
+
+// InstallPackages installs global packages, batching packages with the same flags into one command
+func (nm *NpmManager) InstallPackages(packages []config.PackageEntry, packageDefaults map[string][]string) error {
+	if len(packages) == 0 {
+		nm.logger.Debug(fmt.Sprintf("No %s packages to install", nm.tool))
+		return nil
+	}
+
+	if _, err := exec.LookPath(nm.tool); err != nil {
+		return fmt.Errorf("%s command not found - install Node.js to manage global packages", nm.tool)
+	}
+
+	nm.logger.Info(fmt.Sprintf("Managing %s packages...", nm.tool), "count", len(packages))
+
+	installed, err := nm.listInstalled()
+	if err != nil {
+		nm.logger.Warn("Failed to list global packages, proceeding anyway", "error", err)
+		installed = map[string]string{}
+	}
+
+	// Only packages that are missing or differ from their pinned version need installing
+	var pending []config.PackageEntry
+	for _, pkg := range packages {
+		spec := npmPackageSpec(pkg)
+		name := npmPackageName(spec)
+		pinned := npmPinnedVersion(spec)
+
+		if version, isInstalled := installed[name]; isInstalled {
+			if pinned == "" || pinned == version {
+				nm.logger.Debug("Global package already installed", "package", name, "version", version)
+				continue
+			}
+			nm.logger.Info("Reinstalling global package with pinned version", "package", name, "installed", version, "pinned", pinned)
+		}
+		pending = append(pending, pkg)
+	}
+
+	flagGroups := nm.groupPackagesByFlags(pending, packageDefaults)
+
+	flagKeys := make([]string, 0, len(flagGroups))
+	for flagsKey := range flagGroups {
+		flagKeys = append(flagKeys, flagsKey)
+	}
+	sort.Strings(flagKeys)
+
+	for _, flagsKey := range flagKeys {
+		var flags []string
+		if flagsKey != "" {
+			flags = strings.Split(flagsKey, "|")
+		}
+
+		specs := make([]string, 0, len(flagGroups[flagsKey]))
+		for _, pkg := range flagGroups[flagsKey] {
+			specs = append(specs, npmPackageSpec(pkg))
+		}
+
+		args := nm.globalArgs(nm.installCommand())
+		args = append(args, flags...)
+		args = append(args, specs...)
+		if err := nm.run(args); err != nil {
+			return fmt.Errorf("failed to install %s packages: %w", nm.tool, err)
+		}
+		for _, spec := range specs {
+			config.Success("Installed global package: %s", npmPackageName(spec))
+		}
+	}
+
+	nm.logger.Info(fmt.Sprintf("✓ %s packages processed successfully", nm.tool))
+	return nil
+}
+
+// npmPackageSpec returns what to install for an entry (the original "name@version" spec if it had one)
+func npmPackageSpec(pkg config.PackageEntry) string {
+	if pkg.Source != "" {
+		return pkg.Source
+	}
+	return pkg.Name
+}
+
+// groupPackagesByFlags groups packages with the same resolved flags together
+func (nm *NpmManager) groupPackagesByFlags(packages []config.PackageEntry, packageDefaults map[string][]string) map[string][]config.PackageEntry {
+	flagGroups := make(map[string][]config.PackageEntry)
+
+	for _, pkg := range packages {
+		flags := nm.resolvePackageFlags(pkg, packageDefaults)
+		flagsKey := strings.Join(flags, "|") // Use "|" as separator since it's not valid in flags
+		flagGroups[flagsKey] = append(flagGroups[flagsKey], pkg)
+	}
+
+	return flagGroups
+}
+
+// resolvePackageFlags implements the three-tier flag resolution system
+func (nm *NpmManager) resolvePackageFlags(pkg config.PackageEntry, packageDefaults map[string][]string) []string {
+	// Tier 3: Per-package flags (highest priority)
+	if pkg.Flags != nil {
+		return pkg.Flags
+	}
+
+	// Tier 2: User package defaults
+	if userDefaults, exists := packageDefaults[nm.tool]; exists {
+		return userDefaults
+	}
+
+	// Tier 1: Internal defaults
+	return config.GetDefaultFlags(nm.tool)
+}
+
+// installCommand returns the subcommand that installs packages ("install" for npm, "add" for pnpm)
+func (nm *NpmManager) installCommand() string {
+	if nm.tool == "pnpm" {
+		return "add"
+	}
+	return "install"
+}
+
+// globalArgs builds a global command line: "<tool> <subcommand> -g [--prefix <prefix>]"
+func (nm *NpmManager) globalArgs(subcommand string) []string {
+	args := []string{nm.tool, subcommand, "-g"}
+	if prefix := nm.resolvePrefix(); prefix != "" {
+		args = append(args, "--prefix", prefix)
+	}
+	return args
+}
+
+// resolvePrefix expands a leading ~/ in the configured prefix
+func (nm *NpmManager) resolvePrefix() string {
+	if strings.HasPrefix(nm.prefix, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, nm.prefix[2:])
+		}
+	}
+	return nm.prefix
+}
+
+// run executes an npm or pnpm command (or logs it in dry-run mode)
+func (nm *NpmManager) run(args []string) error {
+	if nm.dryRun {
+		nm.logger.Info("  [DRY RUN] Would run:", "command", strings.Join(args, " "))
+		return nil
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		nm.logger.Error("Command failed", "command", strings.Join(args, " "), "error", err, "output", string(output))
+		return fmt.Errorf("%s failed: %w", strings.Join(args[:2], " "), err)
+	}
+
+	nm.logger.Debug("Command completed", "command", strings.Join(args, " "), "output", string(output))
+	return nil
+}
+
+// listInstalled returns the installed global packages and their versions
+func (nm *NpmManager) listInstalled() (map[string]string, error) {
+	args := append(nm.globalArgs("ls"), "--json", "--depth=0")
+	output, err := exec.Command(args[0], args[1:]...).Output()
+	// npm ls exits non-zero for problems like extraneous packages but still prints the tree
+	if err != nil && len(output) == 0 {
+		return nil, fmt.Errorf("%s ls failed: %w", nm.tool, err)
+	}
+	return parseNpmListJSON(output)
+}
+
+// npmDependencies is the dependency tree printed by `npm ls --json` and `pnpm ls --json`
+type npmDependencies struct {
+	Dependencies map[string]struct {
+		Version string `json:"version"`
+	} `json:"dependencies"`
+}
+
+// parseNpmListJSON parses `npm ls -g --json` output (an object) or `pnpm ls -g --json` output (an array of objects)
+func parseNpmListJSON(data []byte) (map[string]string, error) {
+	var trees []npmDependencies
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(data, &trees); err != nil {
+			return nil, fmt.Errorf("failed to parse package list: %w", err)
+		}
+	} else {
+		var tree npmDependencies
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("failed to parse package list: %w", err)
+		}
+		trees = append(trees, tree)
+	}
+
+	installed := make(map[string]string)
+	for _, tree := range trees {
+		for name, dependency := range tree.Dependencies {
+			installed[name] = dependency.Version
+		}
+	}
+	return installed, nil
+}
+
+// isPackageInstalled checks if a package is installed globally
+func (nm *NpmManager) isPackageInstalled(packageName string) (bool, error) {
+	installed, err := nm.listInstalled()
+	if err != nil {
+		return false, err
+	}
+	_, isInstalled := installed[npmPackageName(packageName)]
+	return isInstalled, nil
+}
+
+// isPackageAvailable checks if a package exists in the registry
+func (nm *NpmManager) isPackageAvailable(packageName string) (bool, error) {
+	if _, err := exec.LookPath(nm.tool); err != nil {
+		return false, nil
+	}
+	cmd := exec.Command(nm.tool, "view", packageName, "name")
+	if err := cmd.Run(); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// RemovePackages uninstalls global packages that are no longer in the configuration
+func (nm *NpmManager) RemovePackages(packagesToRemove []string) error {
+	if len(packagesToRemove) == 0 {
+		return nil
+	}
+
+	if _, err := exec.LookPath(nm.tool); err != nil {
+		return fmt.Errorf("%s command not found", nm.tool)
+	}
+
+	nm.logger.Info(fmt.Sprintf("Removing %s packages no longer in configuration", nm.tool), "packages", packagesToRemove)
+
+	installed, err := nm.listInstalled()
+	if err != nil {
+		nm.logger.Warn("Could not list global packages, attempting removal anyway", "error", err)
+		installed = nil
+	}
+
+	var names []string
+	for _, pkg := range packagesToRemove {
+		name := npmPackageName(pkg)
+		if installed != nil {
+			if _, isInstalled := installed[name]; !isInstalled {
+				nm.logger.Debug("Global package not installed, skipping removal", "package", name)
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	subcommand := "uninstall"
+	if nm.tool == "pnpm" {
+		subcommand = "remove"
+	}
+	args := append(nm.globalArgs(subcommand), names...)
+	if err := nm.run(args); err != nil {
+		return fmt.Errorf("failed to remove %s packages: %w", nm.tool, err)
+	}
+	for _, name := range names {
+		config.Success("Removed global package: %s", name)
+	}
+
+	return nil
+}
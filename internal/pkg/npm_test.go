@@ -0,0 +1,163 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+const stubNpmListJSON = `{
+  "name": "lib",
+  "dependencies": {
+    "typescript": {"version": "5.4.5", "overridden": false},
+    "prettier": {"version": "3.2.5", "overridden": false},
+    "@vercel/ncc": {"version": "0.38.1", "overridden": false}
+  }
+}`
+
+const stubPnpmListJSON = `[{"path": "/home/user/.local/share/pnpm/global/5", "dependencies": {"typescript": {"version": "5.4.5"}}}]`
+
+// setupStubNpm installs stub npm and pnpm executables that record every command except ls
+func setupStubNpm(t *testing.T) string {
+	t.Helper()
+	binDir := t.TempDir()
+	logPath := filepath.Join(t.TempDir(), "commands.log")
+
+	for tool, listing := range map[string]string{"npm": stubNpmListJSON, "pnpm": stubPnpmListJSON} {
+		listPath := filepath.Join(binDir, tool+"-ls.json")
+		if err := os.WriteFile(listPath, []byte(listing), 0644); err != nil {
+			t.Fatalf("failed to write %s ls output: %v", tool, err)
+		}
+		writeStubCommand(t, binDir, tool, "if [ \"$1\" = ls ]; then cat "+listPath+"; exit 1; fi\necho \""+tool+" $@\" >> "+logPath+"\n")
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return logPath
+}
+
+func TestNpmSpecParsing(t *testing.T) {
+	tests := []struct {
+		spec   string
+		name   string
+		pinned string
+	}{
+		{"typescript", "typescript", ""},
+		{"typescript@5.4.5", "typescript", "5.4.5"},
+		{"@vercel/ncc", "@vercel/ncc", ""},
+		{"@vercel/ncc@0.38.1", "@vercel/ncc", "0.38.1"},
+		{"prettier@^3", "prettier", ""},
+		{"vercel@latest", "vercel", ""},
+	}
+
+	for _, tt := range tests {
+		if got := npmPackageName(tt.spec); got != tt.name {
+			t.Errorf("npmPackageName(%q) = %q, expected %q", tt.spec, got, tt.name)
+		}
+		if got := npmPinnedVersion(tt.spec); got != tt.pinned {
+			t.Errorf("npmPinnedVersion(%q) = %q, expected %q", tt.spec, got, tt.pinned)
+		}
+	}
+}
+
+func TestParseNpmListJSON(t *testing.T) {
+	installed, err := parseNpmListJSON([]byte(stubNpmListJSON))
+	if err != nil {
+		t.Fatalf("parseNpmListJSON failed: %v", err)
+	}
+	if installed["@vercel/ncc"] != "0.38.1" || installed["typescript"] != "5.4.5" {
+		t.Errorf("unexpected npm packages: %v", installed)
+	}
+
+	installed, err = parseNpmListJSON([]byte(stubPnpmListJSON))
+	if err != nil {
+		t.Fatalf("parseNpmListJSON failed for pnpm output: %v", err)
+	}
+	if installed["typescript"] != "5.4.5" {
+		t.Errorf("unexpected pnpm packages: %v", installed)
+	}
+}
+
+func TestNpmManager_InstallPackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	logPath := setupStubNpm(t)
+
+	packages := []config.PackageEntry{
+		{Name: "typescript"},                                  // installed, unpinned
+		{Name: "prettier", Source: "prettier@3.3.0"},          // installed, pin differs
+		{Name: "vercel"},                                      // missing
+		{Name: "eslint", Flags: []string{"--ignore-scripts"}}, // missing, own flags
+	}
+
+	if err := NewNpmManager(logger, false, "/opt/npm-global").InstallPackages(packages, nil); err != nil {
+		t.Fatalf("InstallPackages failed: %v", err)
+	}
+
+	expected := []string{
+		"npm install -g --prefix /opt/npm-global prettier@3.3.0 vercel",
+		"npm install -g --prefix /opt/npm-global --ignore-scripts eslint",
+	}
+	commands := readCommandLog(t, logPath)
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected commands %v, got %v", expected, commands)
+	}
+}
+
+func TestNpmManager_Pnpm(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	logPath := setupStubNpm(t)
+	pm := NewPnpmManager(logger, false)
+
+	if err := pm.InstallPackages([]config.PackageEntry{{Name: "typescript"}, {Name: "prettier"}}, nil); err != nil {
+		t.Fatalf("InstallPackages failed: %v", err)
+	}
+	if err := pm.RemovePackages([]string{"typescript", "prettier"}); err != nil {
+		t.Fatalf("RemovePackages failed: %v", err)
+	}
+
+	expected := []string{"pnpm add -g prettier", "pnpm remove -g typescript"}
+	commands := readCommandLog(t, logPath)
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected commands %v, got %v", expected, commands)
+	}
+}
+
+func TestNpmManager_RemovePackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	logPath := setupStubNpm(t)
+
+	if err := NewNpmManager(logger, false, "").RemovePackages([]string{"@vercel/ncc", "typescript", "not-installed"}); err != nil {
+		t.Fatalf("RemovePackages failed: %v", err)
+	}
+
+	expected := []string{"npm uninstall -g @vercel/ncc typescript"}
+	commands := readCommandLog(t, logPath)
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected commands %v, got %v", expected, commands)
+	}
+}
+
+func TestNewPackageManager_NpmPrefixSetting(t *testing.T) {
+	logger := log.New(os.Stderr)
+
+	manager, err := NewPackageManager("npm", ManagerOptions{Logger: logger, Settings: map[string]string{"prefix": "~/.npm-global"}})
+	if err != nil {
+		t.Fatalf("NewPackageManager failed: %v", err)
+	}
+
+	home, _ := os.UserHomeDir()
+	args := manager.(*NpmManager).globalArgs("install")
+	expected := []string{"npm", "install", "-g", "--prefix", filepath.Join(home, ".npm-global")}
+	if strings.Join(args, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+}
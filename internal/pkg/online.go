@@ -0,0 +1,409 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// aptSourcePartsDir holds the system's APT source files copied into the simulated sources (overridden in tests)
+var aptSourcePartsDir = "/etc/apt/sources.list.d"
+
+// PackageAvailability is the result of checking a package against its package manager's sources
+type PackageAvailability struct {
+	Available   bool      `json:"available"`
+	Suggestions []string  `json:"suggestions,omitempty"` // Similar package names from search results
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// OnlineValidator checks that configured packages can be installed from their package managers' sources
+type OnlineValidator struct {
+	logger        *log.Logger
+	cacheManager  *CacheManager
+	searchManager *SearchManager
+}
+
+// NewOnlineValidator creates a new online package validator
+func NewOnlineValidator(logger *log.Logger, cacheManager *CacheManager) *OnlineValidator {
+	return &OnlineValidator{
+		logger:        logger,
+		cacheManager:  cacheManager,
+		searchManager: NewSearchManager(logger),
+	}
+}
+
+// Validate checks the packages of every manager with an online check and adds an error for each package that is not available
+// APT packages are checked against the system sources plus the repositories in the configuration
+func (ov *OnlineValidator) Validate(cfg *config.Config, configPath string, result *config.ValidationResult) {
+	configPos, _ := config.ParseConfigWithPosition(configPath)
+	cache := ov.cacheManager.LoadPackageAvailabilityCache()
+
+	for _, manager := range onlineCheckedManagers() {
+		entries := onlineCheckableEntries(manager, cfg.Packages.Get(manager))
+		if len(entries) == 0 {
+			continue
+		}
+
+		command := PackageManagerCommand(manager)
+		if _, err := exec.LookPath(command); err != nil {
+			result.Add(config.ValidationError{
+				Type:    "warning",
+				Title:   "package availability not checked",
+				File:    configPath,
+				Field:   fmt.Sprintf("packages.%s", manager),
+				Message: fmt.Sprintf("%s not found in PATH, so %s packages can't be checked online", command, config.PackageManagerDisplayName(manager)),
+				Help:    fmt.Sprintf("run validate --online on a system with %s installed", command),
+			})
+			continue
+		}
+
+		sourcesKey := ov.sourcesKey(manager, cfg)
+		var unchecked []config.PackageEntry
+		for _, entry := range entries {
+			if _, cached := cache.Packages[availabilityKey(manager, sourcesKey, entry)]; !cached {
+				unchecked = append(unchecked, entry)
+			}
+		}
+
+		if len(unchecked) > 0 {
+			checked, err := ov.check(manager, unchecked, cfg)
+			if err != nil {
+				result.Add(config.ValidationError{
+					Type:    "warning",
+					Title:   "package availability not checked",
+					File:    configPath,
+					Field:   fmt.Sprintf("packages.%s", manager),
+					Message: err.Error(),
+					Help:    "check your network connection and package sources, then run validate --online again",
+				})
+				continue
+			}
+			for _, entry := range unchecked {
+				availability := PackageAvailability{Available: checked[entry.Name], CheckedAt: time.Now()}
+				if !availability.Available {
+					availability.Suggestions = ov.suggestions(manager, entry.Name)
+				}
+				cache.Packages[availabilityKey(manager, sourcesKey, entry)] = availability
+			}
+		}
+
+		for _, entry := range entries {
+			availability := cache.Packages[availabilityKey(manager, sourcesKey, entry)]
+			if availability.Available {
+				continue
+			}
+			ov.addUnavailableError(manager, entry, availability, configPath, configPos, result)
+		}
+	}
+
+	if err := ov.cacheManager.SavePackageAvailabilityCache(cache); err != nil {
+		ov.logger.Debug("Could not save package availability cache", "error", err)
+	}
+}
+
+// onlineCheckableEntries returns the entries that name packages in the manager's sources
+// Local files, URLs and Flatpak bundles are skipped, as are names that already fail syntax validation
+func onlineCheckableEntries(manager string, packages []config.PackageEntry) []config.PackageEntry {
+	spec, registered := config.LookupPackageManager(manager)
+	var entries []config.PackageEntry
+	for _, entry := range packages {
+		if strings.Contains(entry.Name, "/") || strings.HasSuffix(entry.Name, ".deb") ||
+			strings.HasSuffix(entry.Name, ".flatpak") || strings.HasSuffix(entry.Name, ".flatpakref") {
+			continue
+		}
+		if registered && spec.ValidateName != nil && !spec.ValidateName(entry.Name) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// sourcesKey identifies the package sources a result was checked against, so cached APT results
+// are not reused after the configured repositories change
+func (ov *OnlineValidator) sourcesKey(manager string, cfg *config.Config) string {
+	if manager != "apt" || len(cfg.Repositories.Apt) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(cfg.Repositories.Apt)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// availabilityKey is the cache key of a package availability result
+func availabilityKey(manager, sourcesKey string, entry config.PackageEntry) string {
+	return strings.Join([]string{manager, sourcesKey, entry.Remote, entry.Name}, ":")
+}
+
+// check returns the availability of each entry, keyed by package name
+func (ov *OnlineValidator) check(manager string, entries []config.PackageEntry, cfg *config.Config) (map[string]bool, error) {
+	checker := packageManagers[manager].OnlineCheck
+	if checker == nil {
+		return nil, fmt.Errorf("online checks are not supported for %s", manager)
+	}
+	return checker(ov, entries, cfg)
+}
+
+// checkApt looks up each package with apt-cache policy; a package is available when it has an installation candidate
+// With repositories in the configuration, apt runs against a temporary copy of the sources that includes them
+func (ov *OnlineValidator) checkApt(entries []config.PackageEntry, cfg *config.Config) (map[string]bool, error) {
+	repos := cfg.Repositories.Apt
+	var options []string
+	if len(repos) > 0 {
+		dir, err := os.MkdirTemp("", "configr-apt-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create simulated APT sources: %w", err)
+		}
+		defer os.RemoveAll(dir)
+
+		if options, err = ov.simulateAptSources(dir, repos); err != nil {
+			return nil, err
+		}
+	}
+
+	args := append(append([]string{}, options...), "policy")
+	for _, entry := range entries {
+		name, _, _ := strings.Cut(entry.Name, "=") // Drop version pins
+		args = append(args, name)
+	}
+
+	output, err := exec.Command("apt-cache", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("apt-cache policy failed: %w", err)
+	}
+
+	candidates := parseAptPolicy(string(output))
+	available := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		name, _, _ := strings.Cut(entry.Name, "=")
+		candidate, found := candidates[name]
+		available[entry.Name] = found && candidate != "(none)"
+	}
+	return available, nil
+}
+
+// simulateAptSources sets up sources, lists and cache directories in dir holding the system sources and the
+// configured repositories, downloads their indexes, and returns the apt options that select them
+func (ov *OnlineValidator) simulateAptSources(dir string, repos []config.AptRepository) ([]string, error) {
+	sourceParts := filepath.Join(dir, "sources.list.d")
+	for _, sub := range []string{sourceParts, filepath.Join(dir, "lists", "partial"), filepath.Join(dir, "cache", "archives", "partial")} {
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create simulated APT sources: %w", err)
+		}
+	}
+
+	systemSources, _ := os.ReadDir(aptSourcePartsDir)
+	for _, source := range systemSources {
+		if source.IsDir() || (!strings.HasSuffix(source.Name(), ".list") && !strings.HasSuffix(source.Name(), ".sources")) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(aptSourcePartsDir, source.Name()))
+		if err != nil {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(sourceParts, source.Name()), content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to copy APT source %s: %w", source.Name(), err)
+		}
+	}
+
+	// Configured repositories replace system files of the same name
+	if err := NewRepositoryManager(ov.logger, false).writeSimulatedAptSources(repos, sourceParts); err != nil {
+		return nil, err
+	}
+
+	options := []string{
+		"-o", "Dir::Etc::SourceParts=" + sourceParts,
+		"-o", "Dir::State::Lists=" + filepath.Join(dir, "lists"),
+		"-o", "Dir::Cache=" + filepath.Join(dir, "cache"),
+		"-o", "Debug::NoLocking=true",
+	}
+
+	ov.logger.Info("Downloading APT package indexes for the configured repositories...")
+	args := append(append([]string{}, options...), "update", "-q")
+	if output, err := exec.Command("apt-get", args...).CombinedOutput(); err != nil {
+		ov.logger.Debug("Simulated apt-get update failed", "error", err, "output", string(output))
+		return nil, fmt.Errorf("apt-get update with the configured repositories failed: %w", err)
+	}
+	return options, nil
+}
+
+// parseAptPolicy parses `apt-cache policy` output into each package's candidate version
+// Packages apt doesn't know are absent; "(none)" means there is no installation candidate
+func parseAptPolicy(output string) map[string]string {
+	candidates := make(map[string]string)
+	current := ""
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && strings.HasSuffix(line, ":") {
+			current = strings.TrimSuffix(line, ":")
+			continue
+		}
+		if value, found := strings.CutPrefix(strings.TrimSpace(line), "Candidate:"); found && current != "" {
+			candidates[current] = strings.TrimSpace(value)
+		}
+	}
+	return candidates
+}
+
+// checkFlatpak looks up each application with flatpak remote-info in its remote, or in every configured remote
+func (ov *OnlineValidator) checkFlatpak(entries []config.PackageEntry, _ *config.Config) (map[string]bool, error) {
+	output, err := exec.Command("flatpak", "remotes", "--columns=name").Output()
+	if err != nil {
+		return nil, fmt.Errorf("flatpak remotes failed: %w", err)
+	}
+	remotes := strings.Fields(string(output))
+
+	available := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		candidates := remotes
+		if entry.Remote != "" {
+			candidates = []string{entry.Remote}
+		}
+		for _, remote := range candidates {
+			if exec.Command("flatpak", "remote-info", remote, entry.Name).Run() == nil {
+				available[entry.Name] = true
+				break
+			}
+		}
+	}
+	return available, nil
+}
+
+// checkSnap looks up each snap with snap info
+func (ov *OnlineValidator) checkSnap(entries []config.PackageEntry, _ *config.Config) (map[string]bool, error) {
+	available := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		output, err := exec.Command("snap", "info", entry.Name).CombinedOutput()
+		if err != nil && !strings.Contains(string(output), "no snap found") {
+			// Anything but a missing snap (e.g., the store is unreachable) leaves the result unknown
+			return nil, fmt.Errorf("snap info %s failed: %s", entry.Name, strings.TrimSpace(string(output)))
+		}
+		available[entry.Name] = err == nil
+	}
+	return available, nil
+}
+
+// suggestions searches the package manager for names close to a missing package
+func (ov *OnlineValidator) suggestions(manager, packageName string) []string {
+	searcher, err := ov.searchManager.Searcher(manager)
+	if err != nil {
+		return nil
+	}
+
+	// Search by prefix so a typo near the end still finds the intended package
+	term := packageName
+	if prefix := len(packageName) * 2 / 3; prefix >= 3 {
+		term = packageName[:prefix]
+	}
+	results, err := searcher.SearchPackages(term)
+	if err != nil {
+		ov.logger.Debug("Search for suggestions failed", "manager", manager, "term", term, "error", err)
+		return nil
+	}
+	return closestMatches(packageName, results, 3)
+}
+
+// closestMatches returns up to limit names within a small edit distance of name, closest first
+func closestMatches(name string, candidates []string, limit int) []string {
+	maxDistance := len(name) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	distances := make(map[string]int)
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(candidate)); distance <= maxDistance {
+			distances[candidate] = distance
+		}
+	}
+
+	matches := make([]string, 0, len(distances))
+	for candidate := range distances {
+		matches = append(matches, candidate)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if distances[matches[i]] != distances[matches[j]] {
+			return distances[matches[i]] < distances[matches[j]]
+		}
+		return matches[i] < matches[j]
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// addUnavailableError reports a package its package manager can't install
+func (ov *OnlineValidator) addUnavailableError(manager string, entry config.PackageEntry, availability PackageAvailability, configPath string, configPos *config.ConfigWithPosition, result *config.ValidationResult) {
+	displayName := config.PackageManagerDisplayName(manager)
+
+	line, column := 0, 0
+	if configPos != nil {
+		line, column = configPos.FindPackagePosition(manager, entry.Name)
+	}
+
+	sources := fmt.Sprintf("the configured %s sources", displayName)
+	help := "check the spelling, or add the repository that provides it"
+	switch {
+	case manager == "apt":
+		sources = "the APT sources (including repositories in this configuration)"
+	case manager == "flatpak" && entry.Remote != "":
+		sources = fmt.Sprintf("the Flatpak remote '%s'", entry.Remote)
+		help = "check the spelling and the remote; remotes from this configuration must be added before they can be checked"
+	case manager == "snap":
+		sources = "the Snap Store"
+		help = "check the spelling with 'snap find'"
+	}
+
+	validationError := config.ValidationError{
+		Type:    "error",
+		Title:   "package not found",
+		File:    configPath,
+		Line:    line,
+		Column:  column,
+		Field:   fmt.Sprintf("packages.%s", manager),
+		Value:   entry.Name,
+		Message: fmt.Sprintf("%s package '%s' is not available from %s", displayName, entry.Name, sources),
+		Help:    help,
+	}
+	if len(availability.Suggestions) > 0 {
+		validationError.Suggestion = fmt.Sprintf("did you mean \"%s\"?", strings.Join(availability.Suggestions, "\", \""))
+	}
+	result.Add(validationError)
+}
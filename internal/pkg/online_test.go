@@ -0,0 +1,159 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+func TestParseAptPolicy(t *testing.T) {
+	output := `git:
+  Installed: 1:2.39.5-0+deb12u2
+  Candidate: 1:2.39.5-0+deb12u2
+  Version table:
+ *** 1:2.39.5-0+deb12u2 500
+        500 http://deb.debian.org/debian bookworm/main amd64 Packages
+mail-transport-agent:
+  Installed: (none)
+  Candidate: (none)
+  Version table:
+`
+	candidates := parseAptPolicy(output)
+	if candidates["git"] != "1:2.39.5-0+deb12u2" {
+		t.Errorf("unexpected git candidate: %q", candidates["git"])
+	}
+	if candidates["mail-transport-agent"] != "(none)" {
+		t.Errorf("unexpected virtual package candidate: %q", candidates["mail-transport-agent"])
+	}
+	if _, found := candidates["neovin"]; found {
+		t.Error("expected unknown packages to be absent")
+	}
+}
+
+func TestClosestMatches(t *testing.T) {
+	candidates := []string{"neovim-qt", "neovim", "neovim-runtime", "vim", "neovin"}
+	matches := closestMatches("neovin", candidates, 3)
+	if strings.Join(matches, " ") != "neovim" {
+		t.Errorf("unexpected matches: %v", matches)
+	}
+	if matches := closestMatches("ripgrp", []string{"grep", "sed"}, 3); len(matches) != 0 {
+		t.Errorf("expected no matches, got %v", matches)
+	}
+}
+
+// setupOnlineStubs puts stub apt, apt-cache and snap executables on an otherwise empty PATH
+// apt knows git and neovim; the Snap Store knows code
+func setupOnlineStubs(t *testing.T) string {
+	t.Helper()
+	binDir := t.TempDir()
+
+	writeStubCommand(t, binDir, "apt-cache", `for name in "$@"; do
+  case "$name" in git|neovim) printf '%s:\n  Installed: (none)\n  Candidate: 1.0\n' "$name" ;; esac
+done
+`)
+	writeStubCommand(t, binDir, "apt", `case "$1" in
+  search) printf 'Sorting...\nneovim/stable 1.0 amd64\n  Vim-fork focused on extensibility\n' ;;
+esac
+`)
+	writeStubCommand(t, binDir, "snap", `case "$1 $2" in
+  "info code") exit 0 ;;
+  info*) echo "error: no snap found for \"$2\"" >&2; exit 1 ;;
+  find*) printf 'Name  Version  Publisher  Notes    Summary\ncode  1.0      vscode     classic  Code editing\n' ;;
+esac
+`)
+	t.Setenv("PATH", binDir)
+	return binDir
+}
+
+func TestOnlineValidator_Validate(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	binDir := setupOnlineStubs(t)
+	cacheManager := NewCacheManagerWithPath(logger, t.TempDir())
+
+	cfg := &config.Config{Version: "1.0"}
+	cfg.Packages.Set("apt", []config.PackageEntry{{Name: "git"}, {Name: "neovin"}, {Name: "./local.deb"}})
+	cfg.Packages.Set("snap", []config.PackageEntry{{Name: "code"}, {Name: "codee"}})
+	cfg.Packages.Set("flatpak", []config.PackageEntry{{Name: "org.mozilla.firefox"}})
+
+	result := &config.ValidationResult{Valid: true}
+	NewOnlineValidator(logger, cacheManager).Validate(cfg, "configr.yaml", result)
+
+	expected := map[string]string{
+		"neovin": `did you mean "neovim"?`,
+		"codee":  `did you mean "code"?`,
+	}
+	if len(result.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %+v", len(expected), result.Errors)
+	}
+	for _, err := range result.Errors {
+		if err.Title != "package not found" || expected[err.Value] != err.Suggestion {
+			t.Errorf("unexpected error: %+v", err)
+		}
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Field != "packages.flatpak" {
+		t.Errorf("expected a warning that flatpak could not be checked, got %+v", result.Warnings)
+	}
+
+	// Results are cached: a second run doesn't need the package managers
+	for _, command := range []string{"apt", "apt-cache", "snap"} {
+		writeStubCommand(t, binDir, command, "exit 1\n")
+	}
+	result = &config.ValidationResult{Valid: true}
+	NewOnlineValidator(logger, cacheManager).Validate(cfg, "configr.yaml", result)
+	if len(result.Errors) != len(expected) || len(result.Warnings) != 1 {
+		t.Errorf("expected cached results, got errors %+v and warnings %+v", result.Errors, result.Warnings)
+	}
+}
+
+func TestOnlineValidator_SimulatesConfiguredAptRepositories(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	binDir := setupOnlineStubs(t)
+	logPath := filepath.Join(t.TempDir(), "commands.log")
+
+	// apt-get records the source files it was pointed at; apt-cache only knows "code" with those sources
+	writeStubCommand(t, binDir, "apt-get", `for arg in "$@"; do
+  case "$arg" in Dir::Etc::SourceParts=*)
+    for file in "${arg#Dir::Etc::SourceParts=}"/*; do echo "${file##*/}" >> `+logPath+`; done ;;
+  esac
+done
+`)
+	writeStubCommand(t, binDir, "apt-cache", `case "$*" in
+  *Dir::Etc::SourceParts=*) printf 'code:\n  Installed: (none)\n  Candidate: 1.0\n' ;;
+esac
+`)
+
+	systemSources := t.TempDir()
+	if err := os.WriteFile(filepath.Join(systemSources, "debian.sources"), []byte("Types: deb\n"), 0644); err != nil {
+		t.Fatalf("failed to write system sources: %v", err)
+	}
+	original := aptSourcePartsDir
+	aptSourcePartsDir = systemSources
+	t.Cleanup(func() { aptSourcePartsDir = original })
+
+	cfg := &config.Config{Version: "1.0"}
+	cfg.Repositories.Apt = []config.AptRepository{{
+		Name:       "vscode",
+		URIs:       []string{"https://packages.microsoft.com/repos/code"},
+		Suites:     []string{"stable"},
+		Components: []string{"main"},
+	}}
+	cfg.Packages.Set("apt", []config.PackageEntry{{Name: "code"}})
+
+	result := &config.ValidationResult{Valid: true}
+	NewOnlineValidator(logger, NewCacheManagerWithPath(logger, t.TempDir())).Validate(cfg, "configr.yaml", result)
+
+	if len(result.Errors) != 0 || len(result.Warnings) != 0 {
+		t.Errorf("expected code to be available from the configured repository, got errors %+v and warnings %+v", result.Errors, result.Warnings)
+	}
+	if sources := strings.Join(readCommandLog(t, logPath), " "); sources != "debian.sources vscode.sources" {
+		t.Errorf("expected system and configured sources, got %q", sources)
+	}
+}
@@ -22,6 +22,11 @@ func NewOptimizedAptManager(logger *log.Logger, dryRun bool, cache *CacheManager
 	}
 }
 
+// InstallPackages installs APT packages using the cache-optimized path
+func (oam *OptimizedAptManager) InstallPackages(packages []config.PackageEntry, packageDefaults map[string][]string) error {
+	return oam.InstallPackagesOptimized(packages, packageDefaults)
+}
+
 // InstallPackagesOptimized installs APT packages with cache optimization
 func (oam *OptimizedAptManager) InstallPackagesOptimized(packages []config.PackageEntry, packageDefaults map[string][]string) error {
 	if len(packages) == 0 {
@@ -40,7 +45,7 @@ func (oam *OptimizedAptManager) InstallPackagesOptimized(packages []config.Packa
 	systemCache, err := oam.cache.LoadSystemStateCache()
 	if err != nil {
 		oam.logger.Warn("Failed to load system cache, falling back to standard mode", "error", err)
-		return oam.InstallPackages(packages, packageDefaults)
+		return oam.AptManager.InstallPackages(packages, packageDefaults)
 	}
 
 	// Use cached state if available, otherwise build new cache
@@ -127,6 +132,12 @@ func (oam *OptimizedAptManager) filterPackagesForInstallation(packages []config.
 	cacheUpdates := make(map[string]PackageCacheEntry)
 
 	for _, pkg := range packages {
+		// Downloaded .deb files are checked by version at install time, not by the cache
+		if pkg.Source != "" {
+			packagesToInstall = append(packagesToInstall, pkg)
+			continue
+		}
+
 		// Check cache first
 		if cachedEntry, exists := aptCache[pkg.Name]; exists {
 			// If cached as installed and cache is recent, skip
@@ -169,6 +180,12 @@ func (oam *OptimizedAptManager) filterPackagesForInstallation(packages []config.
 
 // installPackageGroupOptimized installs a group of packages with optimizations
 func (oam *OptimizedAptManager) installPackageGroupOptimized(packages []config.PackageEntry, flags []string) error {
+	// Downloaded .deb files are version-checked and installed from their local file
+	downloadedDebs, packages := splitDownloadedDebs(packages)
+	if err := oam.installDownloadedDebFiles(downloadedDebs, flags); err != nil {
+		return err
+	}
+
 	packageNames := make([]string, len(packages))
 	localDebFiles := make([]string, 0)
 	
@@ -0,0 +1,426 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// PipxManager handles Python CLI tools installed into isolated environments with pipx or uv tool
+type PipxManager struct {
+	logger *log.Logger
+	dryRun bool
+}
+
+// NewPipxManager creates a new pipx package manager
+func NewPipxManager(logger *log.Logger, dryRun bool) *PipxManager {
+	return &PipxManager{
+		logger: logger,
+		dryRun: dryRun,
+	}
+}
+
+// pipxRequirementPattern matches a Python requirement: name, optional extras and optional version specifiers
+var pipxRequirementPattern = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?)(\[[A-Za-z0-9._,-]+\])?\s*((===|==|>=|<=|~=|!=|>|<)\s*[A-Za-z0-9.*+!_-]+(\s*,\s*(===|==|>=|<=|~=|!=|>|<)\s*[A-Za-z0-9.*+!_-]+)*)?$`)
+
+func init() {
+	RegisterPackageManager(PackageManagerRegistration{
+		Name:    "pipx",
+		Command: "pipx",
+		Spec: &config.PackageManagerSpec{
+			Name:         "pipx",
+			DisplayName:  "pipx",
+			DefaultFlags: []string{},
+			ValidateName: func(name string) bool {
+				return pipxRequirementPattern.MatchString(name)
+			},
+			NameMessage:   "pipx requirement must be a Python package name with optional extras and version specifiers",
+			NameHelp:      "use a PyPI name like 'ruff', 'poetry==1.8.3' or 'black[jupyter]>=24.0'",
+			Options:       []string{"backend", "inject"},
+			ValidateEntry: validatePipxEntry,
+		},
+		New: func(opts ManagerOptions) PackageManager {
+			return NewPipxManager(opts.Logger, opts.DryRun)
+		},
+	})
+}
+
+// validatePipxEntry validates the pipx-specific backend and inject options
+func validatePipxEntry(pkg config.PackageEntry, field string) []config.ValidationError {
+	var errors []config.ValidationError
+
+	if backend := pkg.OptionString("backend"); pkg.Options["backend"] != nil && backend != "pipx" && backend != "uv" {
+		errors = append(errors, config.ValidationError{
+			Type:    "error",
+			Title:   "invalid pipx backend",
+			Field:   fmt.Sprintf("%s[\"%s\"].backend", field, pkg.Name),
+			Value:   fmt.Sprint(pkg.Options["backend"]),
+			Message: "backend must be 'pipx' or 'uv'",
+			Help:    "omit backend to use pipx, or set 'backend: uv' to install with 'uv tool'",
+		})
+	}
+
+	for _, injected := range pkg.OptionStrings("inject") {
+		if !pipxRequirementPattern.MatchString(injected) {
+			errors = append(errors, config.ValidationError{
+				Type:    "error",
+				Title:   "invalid injected package",
+				Field:   fmt.Sprintf("%s[\"%s\"].inject", field, pkg.Name),
+				Value:   injected,
+				Message: "injected packages must be Python requirements",
+				Help:    "use a PyPI name like 'pre-commit-hooks' or 'poetry-plugin-export==1.8.0'",
+			})
+		}
+	}
+
+	return errors
+}
+
+// requirementName returns the normalized distribution name of a requirement (PEP 503)
+// Example: "Poetry_Core[extra]==1.0" -> "poetry-core"
+func requirementName(requirement string) string {
+	name := requirement
+	if matches := pipxRequirementPattern.FindStringSubmatch(requirement); matches != nil {
+		name = matches[1]
+	}
+	return strings.ToLower(regexp.MustCompile(`[-_.]+`).ReplaceAllString(name, "-"))
+}
+
+// pinnedVersion returns the exact version of a "==" requirement, or "" if the version is not pinned
+func pinnedVersion(requirement string) string {
+	matches := pipxRequirementPattern.FindStringSubmatch(requirement)
+	if matches == nil || matches[5] != "==" || strings.Contains(matches[4], ",") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(matches[4], "=="))
+}
+
+// pipxBackend returns the backend used for a package entry ("pipx" or "uv")
+func pipxBackend(pkg config.PackageEntry) string {
+	if pkg.OptionString("backend") == "uv" {
+		return "uv"
+	}
+	return "pipx"
+}
+
+// pipxTool describes an installed tool environment
+type pipxTool struct {
+	Version  string
+	Injected map[string]bool
+}
+
+// ResolveEntries splits requirement specs so state tracks plain package names
+// "poetry==1.8.3" becomes Name "poetry" with Source "poetry==1.8.3"
+func (pm *PipxManager) ResolveEntries(packages []config.PackageEntry, configDir string) ([]config.PackageEntry, error) {
+	resolved := make([]config.PackageEntry, len(packages))
+	for i, pkg := range packages {
+		if name := requirementName(pkg.Name); pkg.Source == "" && name != pkg.Name {
+			pkg.Source = pkg.Name
+			pkg.Name = name
+		}
+		resolved[i] = pkg
+	}
+	return resolved, nil
+}
+
+// InstallPackages installs Python tools with pipx or uv tool
+func (pm *PipxManager) InstallPackages(packages []config.PackageEntry, packageDefaults map[string][]string) error {
+	if len(packages) == 0 {
+		pm.logger.Debug("No pipx packages to install")
+		return nil
+	}
+
+	pm.logger.Info("Managing pipx packages...", "count", len(packages))
+
+	installed := map[string]map[string]pipxTool{}
+	for _, pkg := range packages {
+		backend := pipxBackend(pkg)
+		if _, loaded := installed[backend]; loaded {
+			continue
+		}
+		if _, err := exec.LookPath(backend); err != nil {
+			return fmt.Errorf("%s command not found - install it to manage Python tools", backend)
+		}
+		tools, err := pm.listInstalled(backend)
+		if err != nil {
+			pm.logger.Warn("Failed to list installed tools, proceeding anyway", "backend", backend, "error", err)
+			tools = map[string]pipxTool{}
+		}
+		installed[backend] = tools
+	}
+
+	for _, pkg := range packages {
+		backend := pipxBackend(pkg)
+		if err := pm.installPackage(pkg, pm.resolvePackageFlags(pkg, packageDefaults), installed[backend]); err != nil {
+			return fmt.Errorf("failed to install pipx package '%s': %w", pkg.Name, err)
+		}
+	}
+
+	pm.logger.Info("✓ pipx packages processed successfully")
+	return nil
+}
+
+// resolvePackageFlags implements the three-tier flag resolution system
+func (pm *PipxManager) resolvePackageFlags(pkg config.PackageEntry, packageDefaults map[string][]string) []string {
+	// Tier 3: Per-package flags (highest priority)
+	if pkg.Flags != nil {
+		return pkg.Flags
+	}
+
+	// Tier 2: User package defaults
+	if userDefaults, exists := packageDefaults["pipx"]; exists {
+		return userDefaults
+	}
+
+	// Tier 1: Internal defaults
+	return config.GetDefaultFlags("pipx")
+}
+
+// installPackage installs (or reinstalls) one tool and injects any missing packages
+func (pm *PipxManager) installPackage(pkg config.PackageEntry, flags []string, installed map[string]pipxTool) error {
+	requirement := pkg.Name
+	if pkg.Source != "" {
+		requirement = pkg.Source
+	}
+	name := requirementName(requirement)
+	backend := pipxBackend(pkg)
+	inject := pkg.OptionStrings("inject")
+
+	tool, isInstalled := installed[name]
+	pinned := pinnedVersion(requirement)
+	missing := missingInjected(tool, inject)
+
+	// uv only adds packages to a tool's environment when installing it, so missing ones need a reinstall
+	reinject := backend == "uv" && len(missing) > 0
+
+	if isInstalled && (pinned == "" || pinned == tool.Version) && !reinject {
+		pm.logger.Debug("Python tool already installed", "package", name, "version", tool.Version, "backend", backend)
+	} else {
+		args := []string{backend}
+		if backend == "uv" {
+			args = append(args, "tool")
+		}
+		args = append(args, "install")
+		args = append(args, flags...)
+		if isInstalled {
+			if pinned != "" && pinned != tool.Version {
+				pm.logger.Info("Reinstalling Python tool with pinned version", "package", name, "installed", tool.Version, "pinned", pinned)
+			} else {
+				pm.logger.Info("Reinstalling Python tool with injected packages", "package", name, "missing", missing)
+			}
+			args = append(args, "--force")
+		}
+		args = append(args, requirement)
+		if backend == "uv" {
+			for _, extra := range inject {
+				args = append(args, "--with", extra)
+			}
+		}
+
+		if err := pm.run(args); err != nil {
+			return err
+		}
+		config.Success("Installed Python tool: %s", name)
+
+		// uv installs injected packages together with the tool; pipx needs all of them injected
+		if backend == "uv" {
+			return nil
+		}
+		missing = inject
+	}
+
+	if backend == "uv" || len(missing) == 0 {
+		return nil
+	}
+
+	args := append([]string{"pipx", "inject", name}, missing...)
+	if err := pm.run(args); err != nil {
+		return err
+	}
+	config.Success("Injected into %s: %s", name, strings.Join(missing, ", "))
+	return nil
+}
+
+// missingInjected returns the packages to inject that aren't in the tool's environment yet
+func missingInjected(tool pipxTool, inject []string) []string {
+	var missing []string
+	for _, extra := range inject {
+		if !tool.Injected[requirementName(extra)] {
+			missing = append(missing, extra)
+		}
+	}
+	return missing
+}
+
+// run executes a pipx or uv command (or logs it in dry-run mode)
+func (pm *PipxManager) run(args []string) error {
+	if pm.dryRun {
+		pm.logger.Info("  [DRY RUN] Would run:", "command", strings.Join(args, " "))
+		return nil
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		pm.logger.Error("Command failed", "command", strings.Join(args, " "), "error", err, "output", string(output))
+		return fmt.Errorf("%s failed: %w", strings.Join(args[:2], " "), err)
+	}
+
+	pm.logger.Debug("Command completed", "command", strings.Join(args, " "), "output", string(output))
+	return nil
+}
+
+// listInstalled returns the tools installed with a backend, keyed by normalized name
+func (pm *PipxManager) listInstalled(backend string) (map[string]pipxTool, error) {
+	if backend == "uv" {
+		output, err := exec.Command("uv", "tool", "list", "--show-with").Output()
+		if err != nil {
+			return nil, fmt.Errorf("uv tool list failed: %w", err)
+		}
+		return parseUvToolList(string(output)), nil
+	}
+
+	output, err := exec.Command("pipx", "list", "--json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("pipx list failed: %w", err)
+	}
+	return parsePipxListJSON(output)
+}
+
+// parsePipxListJSON parses `pipx list --json` output
+func parsePipxListJSON(data []byte) (map[string]pipxTool, error) {
+	var list struct {
+		Venvs map[string]struct {
+			Metadata struct {
+				MainPackage struct {
+					Package        string `json:"package"`
+					PackageVersion string `json:"package_version"`
+				} `json:"main_package"`
+				InjectedPackages map[string]struct {
+					PackageVersion string `json:"package_version"`
+				} `json:"injected_packages"`
+			} `json:"metadata"`
+		} `json:"venvs"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse pipx list output: %w", err)
+	}
+
+	tools := make(map[string]pipxTool, len(list.Venvs))
+	for venv, info := range list.Venvs {
+		name := info.Metadata.MainPackage.Package
+		if name == "" {
+			name = venv
+		}
+		tool := pipxTool{
+			Version:  info.Metadata.MainPackage.PackageVersion,
+			Injected: make(map[string]bool),
+		}
+		for injected := range info.Metadata.InjectedPackages {
+			tool.Injected[requirementName(injected)] = true
+		}
+		tools[requirementName(name)] = tool
+	}
+
+	return tools, nil
+}
+
+// parseUvToolList parses `uv tool list --show-with` output, e.g.:
+//
+//	ruff v0.5.0
+//	- ruff
+//	httpie v3.2.2 [with: httpie-jwt-auth]
+//	- http
+func parseUvToolList(output string) map[string]pipxTool {
+	tools := make(map[string]pipxTool)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(line, "-") || !strings.HasPrefix(fields[1], "v") {
+			continue
+		}
+		tool := pipxTool{
+			Version:  strings.TrimPrefix(fields[1], "v"),
+			Injected: make(map[string]bool),
+		}
+		if _, with, found := strings.Cut(line, "[with: "); found {
+			with, _, _ = strings.Cut(with, "]")
+			for _, extra := range strings.Split(with, ",") {
+				tool.Injected[requirementName(strings.TrimSpace(extra))] = true
+			}
+		}
+		tools[requirementName(fields[0])] = tool
+	}
+	return tools
+}
+
+// isPackageInstalled checks if a tool is installed with pipx or uv tool
+func (pm *PipxManager) isPackageInstalled(packageName string) (bool, error) {
+	name := requirementName(packageName)
+	for _, backend := range []string{"pipx", "uv"} {
+		if _, err := exec.LookPath(backend); err != nil {
+			continue
+		}
+		tools, err := pm.listInstalled(backend)
+		if err != nil {
+			return false, err
+		}
+		if _, installed := tools[name]; installed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isPackageAvailable reports whether a tool can be installed
+// PyPI availability is only known at install time, so any valid requirement is considered available
+func (pm *PipxManager) isPackageAvailable(packageName string) (bool, error) {
+	return pipxRequirementPattern.MatchString(packageName), nil
+}
+
+// RemovePackages uninstalls tools that are no longer in the configuration from whichever backend has them
+func (pm *PipxManager) RemovePackages(packagesToRemove []string) error {
+	if len(packagesToRemove) == 0 {
+		return nil
+	}
+
+	pm.logger.Info("Removing pipx packages no longer in configuration", "packages", packagesToRemove)
+
+	installed := map[string]map[string]pipxTool{}
+	for _, backend := range []string{"pipx", "uv"} {
+		if _, err := exec.LookPath(backend); err != nil {
+			continue
+		}
+		tools, err := pm.listInstalled(backend)
+		if err != nil {
+			pm.logger.Warn("Could not list installed tools", "backend", backend, "error", err)
+			continue
+		}
+		installed[backend] = tools
+	}
+
+	for _, pkg := range packagesToRemove {
+		name := requirementName(pkg)
+
+		var args []string
+		if _, ok := installed["pipx"][name]; ok {
+			args = []string{"pipx", "uninstall", name}
+		} else if _, ok := installed["uv"][name]; ok {
+			args = []string{"uv", "tool", "uninstall", name}
+		} else {
+			pm.logger.Debug("Python tool not installed, skipping removal", "package", name)
+			continue
+		}
+
+		if err := pm.run(args); err != nil {
+			return fmt.Errorf("failed to remove pipx package %s: %w", name, err)
+		}
+		config.Success("Removed Python tool: %s", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+const stubPipxListJSON = `{
+  "pipx_spec_version": "0.1",
+  "venvs": {
+    "ruff": {
+      "metadata": {
+        "main_package": {"package": "ruff", "package_version": "0.5.0"},
+        "injected_packages": {}
+      }
+    },
+    "poetry": {
+      "metadata": {
+        "main_package": {"package": "poetry", "package_version": "1.8.2"},
+        "injected_packages": {"poetry-plugin-export": {"package_version": "1.8.0"}}
+      }
+    }
+  }
+}`
+
+// setupStubPipx installs stub pipx and uv executables that record their invocations
+func setupStubPipx(t *testing.T) string {
+	t.Helper()
+	binDir := t.TempDir()
+	logPath := filepath.Join(t.TempDir(), "commands.log")
+
+	listPath := filepath.Join(binDir, "pipx-list.json")
+	if err := os.WriteFile(listPath, []byte(stubPipxListJSON), 0644); err != nil {
+		t.Fatalf("failed to write pipx list output: %v", err)
+	}
+
+	writeStubCommand(t, binDir, "pipx", "if [ \"$1\" = list ]; then cat "+listPath+"; exit 0; fi\necho \"pipx $@\" >> "+logPath+"\n")
+	writeStubCommand(t, binDir, "uv", "if [ \"$2\" = list ]; then printf 'black v24.4.0 [with: tokenize-rt]\\n- black\\n- blackd\\n'; exit 0; fi\necho \"uv $@\" >> "+logPath+"\n")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return logPath
+}
+
+func readCommandLog(t *testing.T, logPath string) []string {
+	t.Helper()
+	data, err := os.ReadFile(logPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("failed to read command log: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestRequirementParsing(t *testing.T) {
+	tests := []struct {
+		requirement string
+		name        string
+		pinned      string
+	}{
+		{"ruff", "ruff", ""},
+		{"poetry==1.8.3", "poetry", "1.8.3"},
+		{"black[jupyter]>=24.0", "black", ""},
+		{"Pre_Commit==3.7.1", "pre-commit", "3.7.1"},
+		{"httpie>=3.0,<4", "httpie", ""},
+	}
+
+	for _, tt := range tests {
+		if got := requirementName(tt.requirement); got != tt.name {
+			t.Errorf("requirementName(%q) = %q, expected %q", tt.requirement, got, tt.name)
+		}
+		if got := pinnedVersion(tt.requirement); got != tt.pinned {
+			t.Errorf("pinnedVersion(%q) = %q, expected %q", tt.requirement, got, tt.pinned)
+		}
+	}
+}
+
+func TestParsePipxListJSON(t *testing.T) {
+	tools, err := parsePipxListJSON([]byte(stubPipxListJSON))
+	if err != nil {
+		t.Fatalf("parsePipxListJSON failed: %v", err)
+	}
+	if tools["ruff"].Version != "0.5.0" {
+		t.Errorf("expected ruff 0.5.0, got %+v", tools["ruff"])
+	}
+	if !tools["poetry"].Injected["poetry-plugin-export"] {
+		t.Errorf("expected poetry-plugin-export to be injected, got %+v", tools["poetry"])
+	}
+}
+
+func TestPipxManager_InstallPackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	tests := []struct {
+		name     string
+		entry    config.PackageEntry
+		expected []string
+	}{
+		{
+			name:     "installed tool is skipped",
+			entry:    config.PackageEntry{Name: "ruff"},
+			expected: nil,
+		},
+		{
+			name:     "new tool is installed",
+			entry:    config.PackageEntry{Name: "pre-commit"},
+			expected: []string{"pipx install pre-commit"},
+		},
+		{
+			name:     "pinned version mismatch is reinstalled and reinjected",
+			entry:    config.PackageEntry{Name: "poetry", Source: "poetry==1.8.3", Options: map[string]interface{}{"inject": []interface{}{"poetry-plugin-export"}}},
+			expected: []string{"pipx install --force poetry==1.8.3", "pipx inject poetry poetry-plugin-export"},
+		},
+		{
+			name:     "missing injected package is added",
+			entry:    config.PackageEntry{Name: "ruff", Options: map[string]interface{}{"inject": "ruff-lsp"}},
+			expected: []string{"pipx inject ruff ruff-lsp"},
+		},
+		{
+			name:     "uv backend installs with injected packages",
+			entry:    config.PackageEntry{Name: "httpie", Options: map[string]interface{}{"backend": "uv", "inject": []interface{}{"httpie-jwt-auth"}}},
+			expected: []string{"uv tool install httpie --with httpie-jwt-auth"},
+		},
+		{
+			name:     "uv backend skips installed tool",
+			entry:    config.PackageEntry{Name: "black", Options: map[string]interface{}{"backend": "uv", "inject": "tokenize-rt"}},
+			expected: nil,
+		},
+		{
+			name:     "uv backend reinstalls installed tool with missing injected packages",
+			entry:    config.PackageEntry{Name: "black", Options: map[string]interface{}{"backend": "uv", "inject": []interface{}{"tokenize-rt", "black-macchiato"}}},
+			expected: []string{"uv tool install --force black --with tokenize-rt --with black-macchiato"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logPath := setupStubPipx(t)
+
+			if err := NewPipxManager(logger, false).InstallPackages([]config.PackageEntry{tt.entry}, nil); err != nil {
+				t.Fatalf("InstallPackages failed: %v", err)
+			}
+
+			commands := readCommandLog(t, logPath)
+			if strings.Join(commands, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected commands %v, got %v", tt.expected, commands)
+			}
+		})
+	}
+}
+
+func TestPipxManager_RemovePackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	logPath := setupStubPipx(t)
+
+	if err := NewPipxManager(logger, false).RemovePackages([]string{"ruff", "black", "not-installed"}); err != nil {
+		t.Fatalf("RemovePackages failed: %v", err)
+	}
+
+	expected := []string{"pipx uninstall ruff", "uv tool uninstall black"}
+	commands := readCommandLog(t, logPath)
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected commands %v, got %v", expected, commands)
+	}
+}
+
+func TestPipxManager_ResolveEntries(t *testing.T) {
+	logger := log.New(os.Stderr)
+
+	resolved, err := NewPipxManager(logger, true).ResolveEntries([]config.PackageEntry{
+		{Name: "ruff"},
+		{Name: "poetry==1.8.3"},
+	}, "")
+	if err != nil {
+		t.Fatalf("ResolveEntries failed: %v", err)
+	}
+
+	if resolved[0].Name != "ruff" || resolved[0].Source != "" {
+		t.Errorf("expected ruff to be unchanged, got %+v", resolved[0])
+	}
+	if resolved[1].Name != "poetry" || resolved[1].Source != "poetry==1.8.3" {
+		t.Errorf("expected poetry with source poetry==1.8.3, got %+v", resolved[1])
+	}
+}
+
+func TestValidate_PipxPackages(t *testing.T) {
+	tests := []struct {
+		name          string
+		entry         config.PackageEntry
+		expectedTitle string
+	}{
+		{"valid pinned tool", config.PackageEntry{Name: "poetry==1.8.3", Options: map[string]interface{}{"inject": []interface{}{"poetry-plugin-export"}}}, ""},
+		{"invalid requirement", config.PackageEntry{Name: "not a package"}, "invalid package name"},
+		{"invalid backend", config.PackageEntry{Name: "ruff", Options: map[string]interface{}{"backend": "conda"}}, "invalid pipx backend"},
+		{"invalid injected package", config.PackageEntry{Name: "ruff", Options: map[string]interface{}{"inject": "ruff lsp"}}, "invalid injected package"},
+		{"unknown option", config.PackageEntry{Name: "ruff", Options: map[string]interface{}{"python": "3.12"}}, "unknown package option"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Version: "1.0"}
+			cfg.Packages.Set("pipx", []config.PackageEntry{tt.entry})
+
+			result := config.Validate(cfg, "test.yaml")
+
+			found := false
+			for _, issue := range append(result.Errors, result.Warnings...) {
+				if issue.Title == tt.expectedTitle {
+					found = true
+				}
+			}
+			if tt.expectedTitle == "" {
+				if len(result.Errors) > 0 || len(result.Warnings) > 0 {
+					t.Errorf("expected no issues, got errors %+v warnings %+v", result.Errors, result.Warnings)
+				}
+			} else if !found {
+				t.Errorf("expected issue %q, got errors %+v warnings %+v", tt.expectedTitle, result.Errors, result.Warnings)
+			}
+		})
+	}
+}
@@ -14,27 +14,43 @@ import (
 	"github.com/charmbracelet/log"
 )
 
-// RepositoryManager handles repository management operations for APT and Flatpak
+// yumReposDir is the directory DNF repository files are written to (overridden in tests)
+var yumReposDir = "/etc/yum.repos.d"
+
+// RepositoryManager handles repository management operations for APT, DNF and Flatpak
 type RepositoryManager struct {
-	logger *log.Logger
-	dryRun bool
+	logger    *log.Logger
+	dryRun    bool
+	osRelease config.OSRelease // Distribution used to skip repositories of unavailable package managers
 }
 
 // NewRepositoryManager creates a new repository manager
 func NewRepositoryManager(logger *log.Logger, dryRun bool) *RepositoryManager {
+	osRelease, _ := config.DetectOSRelease() // Unknown distribution allows all repository types
 	return &RepositoryManager{
-		logger: logger,
-		dryRun: dryRun,
+		logger:    logger,
+		dryRun:    dryRun,
+		osRelease: osRelease,
 	}
 }
 
-// AddRepositories adds both APT and Flatpak repositories
+// AddRepositories adds APT, DNF and Flatpak repositories
+// APT and DNF repositories are skipped with a warning on distributions without that package manager
 func (rm *RepositoryManager) AddRepositories(repositories config.RepositoryManagement) error {
 	// Add APT repositories first (they may be needed for package installations)
-	if err := rm.addAptRepositories(repositories.Apt); err != nil {
+	if len(repositories.Apt) > 0 && !config.PackageManagerSupported("apt", rm.osRelease) {
+		rm.logger.Warn("Skipping APT repositories: APT is not available on this distribution", "distro", rm.osRelease.DisplayName(), "count", len(repositories.Apt))
+	} else if err := rm.addAptRepositories(repositories.Apt); err != nil {
 		return fmt.Errorf("failed to add APT repositories: %w", err)
 	}
 
+	// Add DNF repositories
+	if len(repositories.Dnf) > 0 && !config.PackageManagerSupported("dnf", rm.osRelease) {
+		rm.logger.Warn("Skipping DNF repositories: DNF is not available on this distribution", "distro", rm.osRelease.DisplayName(), "count", len(repositories.Dnf))
+	} else if err := rm.addDnfRepositories(repositories.Dnf); err != nil {
+		return fmt.Errorf("failed to add DNF repositories: %w", err)
+	}
+
 	// Add Flatpak repositories
 	if err := rm.addFlatpakRepositories(repositories.Flatpak); err != nil {
 		return fmt.Errorf("failed to add Flatpak repositories: %w", err)
@@ -386,6 +402,25 @@ func (rm *RepositoryManager) generateDEB822Content(repo config.AptRepository) st
 	return content.String()
 }
 
+// writeSimulatedAptSources writes the configured APT repositories as DEB822 files into dir for a simulated apt
+// Their keys aren't installed yet, so the repositories are marked trusted; nothing is written to /etc/apt
+func (rm *RepositoryManager) writeSimulatedAptSources(repos []config.AptRepository, dir string) error {
+	for _, repo := range repos {
+		repo, err := rm.convertLegacyToRepository(repo)
+		if err != nil {
+			return fmt.Errorf("failed to convert repository '%s': %w", repo.Name, err)
+		}
+		repo.SignedBy = ""
+		repo.Trusted = true
+
+		path := filepath.Join(dir, strings.ReplaceAll(repo.Name, "_", "-")+".sources")
+		if err := os.WriteFile(path, []byte(rm.generateDEB822Content(repo)), 0644); err != nil {
+			return fmt.Errorf("failed to write simulated sources file %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
 // Helper methods for system compatibility and information
 
 // checkUbuntuVersionCompatibility checks if running on Ubuntu 24.04+
@@ -529,6 +564,120 @@ func (rm *RepositoryManager) addFlatpakRepository(repo config.FlatpakRepository)
 	return nil
 }
 
+// addDnfRepositories handles DNF repository management using .repo files
+func (rm *RepositoryManager) addDnfRepositories(repos []config.DnfRepository) error {
+	if len(repos) == 0 {
+		rm.logger.Debug("No DNF repositories to add")
+		return nil
+	}
+
+	rm.logger.Info("Managing DNF repositories...", "count", len(repos))
+
+	for _, repo := range repos {
+		if err := rm.addDnfRepository(repo); err != nil {
+			return fmt.Errorf("failed to add DNF repository '%s': %w", repo.Name, err)
+		}
+	}
+
+	rm.logger.Info("✓ DNF repositories processed successfully")
+	return nil
+}
+
+// addDnfRepository imports the repository GPG key and writes its .repo file
+func (rm *RepositoryManager) addDnfRepository(repo config.DnfRepository) error {
+	// Import the GPG key first so the first install from the repository doesn't prompt for it
+	if repo.GPGKey != "" {
+		if err := rm.importRPMKey(repo); err != nil {
+			return err
+		}
+	}
+
+	repoPath := filepath.Join(yumReposDir, repo.Name+".repo")
+	content := rm.generateDnfRepoContent(repo)
+
+	rm.logger.Info("Creating DNF repository file", "name", repo.Name, "path", repoPath)
+
+	if rm.dryRun {
+		rm.logger.Info("  [DRY RUN] Would create file:", "path", repoPath)
+		rm.logger.Info("  [DRY RUN] File content:\n" + content)
+		return nil
+	}
+
+	if existing, err := os.ReadFile(repoPath); err == nil && string(existing) == content {
+		rm.logger.Debug("DNF repository file up to date", "path", repoPath)
+		return nil
+	}
+
+	if err := os.WriteFile(repoPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to create repository file %s: %w", repoPath, err)
+	}
+
+	rm.logger.Debug("DNF repository file created successfully", "path", repoPath)
+	return nil
+}
+
+// importRPMKey imports a repository signing key into the RPM database
+func (rm *RepositoryManager) importRPMKey(repo config.DnfRepository) error {
+	key := strings.TrimPrefix(repo.GPGKey, "file://")
+	args := []string{"rpm", "--import", key}
+
+	rm.logger.Info("Importing GPG key", "name", repo.Name, "key", repo.GPGKey)
+
+	if rm.dryRun {
+		rm.logger.Info("  [DRY RUN] Would run:", "command", strings.Join(args, " "))
+		return nil
+	}
+
+	output, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		rm.logger.Error("Failed to import GPG key", "name", repo.Name, "error", err, "output", string(output))
+		return fmt.Errorf("rpm --import failed: %w", err)
+	}
+
+	rm.logger.Debug("GPG key imported successfully", "name", repo.Name, "output", string(output))
+	return nil
+}
+
+// generateDnfRepoContent generates the content of a .repo file
+func (rm *RepositoryManager) generateDnfRepoContent(repo config.DnfRepository) string {
+	var content strings.Builder
+
+	description := repo.Description
+	if description == "" {
+		description = repo.Name
+	}
+
+	enabled := repo.Enabled == nil || *repo.Enabled
+	gpgCheck := repo.GPGCheck == nil || *repo.GPGCheck
+
+	content.WriteString(fmt.Sprintf("[%s]\n", repo.Name))
+	content.WriteString(fmt.Sprintf("name=%s\n", description))
+	if repo.BaseURL != "" {
+		content.WriteString(fmt.Sprintf("baseurl=%s\n", repo.BaseURL))
+	}
+	if repo.Metalink != "" {
+		content.WriteString(fmt.Sprintf("metalink=%s\n", repo.Metalink))
+	}
+	if repo.MirrorList != "" {
+		content.WriteString(fmt.Sprintf("mirrorlist=%s\n", repo.MirrorList))
+	}
+	content.WriteString(fmt.Sprintf("enabled=%d\n", boolToInt(enabled)))
+	content.WriteString(fmt.Sprintf("gpgcheck=%d\n", boolToInt(gpgCheck)))
+	if repo.GPGKey != "" {
+		content.WriteString(fmt.Sprintf("gpgkey=%s\n", repo.GPGKey))
+	}
+
+	return content.String()
+}
+
+// boolToInt converts a boolean to the 1/0 form used by .repo files
+func boolToInt(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
 // checkGPGAvailable checks if gpg command is available for key management
 func (rm *RepositoryManager) checkGPGAvailable() error {
 	if _, err := exec.LookPath("gpg"); err != nil {
@@ -0,0 +1,66 @@
+package pkg
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/charmbracelet/log"
+)
+
+// PackageSearcher is implemented by package managers that can search their sources and describe packages
+type PackageSearcher interface {
+	// SearchPackages returns the names of packages matching a search term
+	SearchPackages(searchTerm string) ([]string, error)
+
+	// GetPackageInfo returns the manager's description of a package
+	GetPackageInfo(packageName string) (string, error)
+}
+
+// SearchManager searches packages across package managers
+type SearchManager struct {
+	logger *log.Logger
+}
+
+// NewSearchManager creates a new search manager
+func NewSearchManager(logger *log.Logger) *SearchManager {
+	return &SearchManager{
+		logger: logger,
+	}
+}
+
+// SearchableManagers returns the registered package managers that support search, in registration order
+func SearchableManagers() []string {
+	var names []string
+	for _, name := range RegisteredPackageManagers() {
+		if _, ok := newSearcher(name, ManagerOptions{}); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// newSearcher creates the searcher of a package manager, if it supports search
+func newSearcher(manager string, opts ManagerOptions) (PackageSearcher, bool) {
+	packageManager, err := NewPackageManager(manager, opts)
+	if err != nil {
+		return nil, false
+	}
+	searcher, ok := packageManager.(PackageSearcher)
+	return searcher, ok
+}
+
+// Searcher returns the searcher of a package manager
+// An error is returned when the manager doesn't support search or its command is not installed
+func (sm *SearchManager) Searcher(manager string) (PackageSearcher, error) {
+	// Searching never changes the system, so managers are created outside dry-run mode
+	searcher, ok := newSearcher(manager, ManagerOptions{Logger: sm.logger})
+	if !ok {
+		return nil, fmt.Errorf("package manager '%s' does not support search (supported: %v)", manager, SearchableManagers())
+	}
+	if command := PackageManagerCommand(manager); command != "" {
+		if _, err := exec.LookPath(command); err != nil {
+			return nil, fmt.Errorf("%s not found in PATH", command)
+		}
+	}
+	return searcher, nil
+}
@@ -0,0 +1,60 @@
+package pkg
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestSearchableManagers(t *testing.T) {
+	searchable := make(map[string]bool)
+	for _, manager := range SearchableManagers() {
+		searchable[manager] = true
+	}
+	for _, expected := range []string{"apt", "flatpak", "snap", "dnf"} {
+		if !searchable[expected] {
+			t.Errorf("expected %s to support search, got %v", expected, SearchableManagers())
+		}
+	}
+	if searchable["cargo"] {
+		t.Errorf("expected cargo not to support search, got %v", SearchableManagers())
+	}
+}
+
+func TestSearchManager_Searcher(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	sm := NewSearchManager(logger)
+
+	if _, err := sm.Searcher("cargo"); err == nil || !strings.Contains(err.Error(), "does not support search") {
+		t.Errorf("expected an unsupported error for cargo, got %v", err)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := sm.Searcher("flatpak"); err == nil || !strings.Contains(err.Error(), "not found in PATH") {
+		t.Errorf("expected a missing command error for flatpak, got %v", err)
+	}
+}
+
+func TestFlatpakManager_SearchPackages(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	binDir := t.TempDir()
+	writeStubCommand(t, binDir, "flatpak", `case "$1" in
+  search) printf 'org.gimp.GIMP\norg.gimp.GIMP\norg.gimp.GIMP.Manual\n' ;;
+esac
+`)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	packages, err := NewFlatpakManager(logger, false).SearchPackages("gimp")
+	if err != nil {
+		t.Fatalf("SearchPackages failed: %v", err)
+	}
+	if strings.Join(packages, " ") != "org.gimp.GIMP org.gimp.GIMP.Manual" {
+		t.Errorf("unexpected packages: %v", packages)
+	}
+}
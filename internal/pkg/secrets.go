@@ -0,0 +1,494 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/charmbracelet/log"
+)
+
+const (
+	// AgeIdentityEnv names the environment variable holding an age identity file path, or the identity itself
+	AgeIdentityEnv = "CONFIGR_AGE_IDENTITY"
+
+	// AgeRecipientsFile lists the recipients of the encrypted files in its directory and below, one per line
+	AgeRecipientsFile = ".age-recipients"
+
+	// redactedSecret replaces decrypted values in log output
+	redactedSecret = "[REDACTED]"
+
+	// minRedactLength is the shortest decrypted value that is redacted; shorter values would
+	// mangle unrelated log output
+	minRedactLength = 4
+
+	// fingerprintKeySize is the length of the local key secret file fingerprints are keyed with
+	fingerprintKeySize = 32
+)
+
+// SecretManager decrypts age-encrypted file sources and vars with the user's identity
+// Decrypted values are kept in memory only, and remembered so they can be redacted from output
+type SecretManager struct {
+	logger         *log.Logger
+	identities     []age.Identity
+	plaintexts     []string
+	fingerprintKey []byte
+}
+
+// NewSecretManager creates a new SecretManager; the identity is loaded on first use
+func NewSecretManager(logger *log.Logger) *SecretManager {
+	return &SecretManager{logger: logger}
+}
+
+// IsEncryptedValue reports whether a var value is an armored age ciphertext
+func IsEncryptedValue(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), armor.Header)
+}
+
+// DefaultAgeIdentityPath returns ~/.config/configr/age.key
+func DefaultAgeIdentityPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".config", "configr", "age.key")
+	}
+	return filepath.Join(homeDir, ".config", "configr", "age.key")
+}
+
+// DefaultFingerprintKeyPath returns ~/.config/configr/fingerprint.key
+func DefaultFingerprintKeyPath() string {
+	return filepath.Join(filepath.Dir(DefaultAgeIdentityPath()), "fingerprint.key")
+}
+
+// loadIdentities reads the age identities from CONFIGR_AGE_IDENTITY or ~/.config/configr/age.key
+func (sm *SecretManager) loadIdentities() ([]age.Identity, error) {
+	if sm.identities != nil {
+		return sm.identities, nil
+	}
+
+	var source io.Reader
+	identityPath := DefaultAgeIdentityPath()
+	if value := os.Getenv(AgeIdentityEnv); strings.HasPrefix(strings.TrimSpace(value), "AGE-SECRET-KEY-") {
+		identityPath = "$" + AgeIdentityEnv
+		source = strings.NewReader(value)
+	} else {
+		if value != "" {
+			identityPath = value
+		}
+		data, err := os.ReadFile(identityPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read age identity (set %s or create %s): %w", AgeIdentityEnv, DefaultAgeIdentityPath(), err)
+		}
+		source = bytes.NewReader(data)
+	}
+
+	identities, err := age.ParseIdentities(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse age identity %s: %w", identityPath, err)
+	}
+
+	sm.logger.Debug("Loaded age identity", "source", identityPath, "count", len(identities))
+	sm.identities = identities
+	return identities, nil
+}
+
+// Decrypt decrypts binary or armored age ciphertext
+func (sm *SecretManager) Decrypt(ciphertext []byte) ([]byte, error) {
+	identities, err := sm.loadIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	var source io.Reader = bytes.NewReader(ciphertext)
+	if IsEncryptedValue(string(ciphertext)) {
+		source = armor.NewReader(bytes.NewReader(bytes.TrimSpace(ciphertext)))
+	}
+
+	reader, err := age.Decrypt(source, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	plaintext, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Encrypt encrypts plaintext to the recipients of the loaded identities, optionally armored
+func (sm *SecretManager) Encrypt(plaintext []byte, armored bool) ([]byte, error) {
+	recipients, err := sm.ownRecipients()
+	if err != nil {
+		return nil, err
+	}
+	return encryptTo(plaintext, armored, recipients)
+}
+
+// EncryptFile encrypts the new content of an encrypted file to the recipients in the nearest .age-recipients file
+// A new file without one is encrypted to the loaded identities; an existing file isn't, as re-encrypting a shared
+// secret to a single key would lock every other recipient out
+func (sm *SecretManager) EncryptFile(path string, plaintext []byte, armored bool) ([]byte, error) {
+	recipients, err := sm.fileRecipients(path)
+	if err != nil {
+		return nil, err
+	}
+	return encryptTo(plaintext, armored, recipients)
+}
+
+// fileRecipients returns the recipients an encrypted file is encrypted to
+func (sm *SecretManager) fileRecipients(path string) ([]age.Recipient, error) {
+	recipientsPath, err := findRecipientsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if recipientsPath == "" {
+		if _, err := os.Stat(path); err == nil {
+			return nil, fmt.Errorf("no recipients configured for %s: list every recipient in %s next to it (for a secret only you use: age-keygen -y %s > %s)", path, AgeRecipientsFile, DefaultAgeIdentityPath(), AgeRecipientsFile)
+		}
+		return sm.ownRecipients()
+	}
+
+	data, err := os.ReadFile(recipientsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", recipientsPath, err)
+	}
+	recipients, err := age.ParseRecipients(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", recipientsPath, err)
+	}
+	sm.logger.Debug("Loaded age recipients", "source", recipientsPath, "count", len(recipients))
+	return recipients, nil
+}
+
+// findRecipientsFile returns the .age-recipients file closest to an encrypted file, in its directory or a parent,
+// or "" if there is none
+func findRecipientsFile(path string) (string, error) {
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	for {
+		candidate := filepath.Join(dir, AgeRecipientsFile)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// ownRecipients returns the recipients of the loaded identities
+func (sm *SecretManager) ownRecipients() ([]age.Recipient, error) {
+	identities, err := sm.loadIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []age.Recipient
+	for _, identity := range identities {
+		if x25519, ok := identity.(*age.X25519Identity); ok {
+			recipients = append(recipients, x25519.Recipient())
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("age identity has no X25519 key to encrypt to")
+	}
+	return recipients, nil
+}
+
+// encryptTo encrypts plaintext to recipients, optionally armored
+func encryptTo(plaintext []byte, armored bool, recipients []age.Recipient) ([]byte, error) {
+	var ciphertext bytes.Buffer
+	var output io.Writer = &ciphertext
+	var armorWriter io.WriteCloser
+	if armored {
+		armorWriter = armor.NewWriter(&ciphertext)
+		output = armorWriter
+	}
+
+	writer, err := age.Encrypt(output, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if _, err := writer.Write(plaintext); err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if armorWriter != nil {
+		if err := armorWriter.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encrypt: %w", err)
+		}
+		ciphertext.WriteByte('\n')
+	}
+	return ciphertext.Bytes(), nil
+}
+
+// DecryptVars returns a copy of vars with every armored age value decrypted
+// The configuration itself keeps the ciphertext, so cached configurations never hold plaintext
+func (sm *SecretManager) DecryptVars(vars map[string]interface{}) (map[string]interface{}, error) {
+	if vars == nil {
+		return nil, nil
+	}
+	decrypted, err := sm.decryptValue("vars", vars)
+	if err != nil {
+		return nil, err
+	}
+	return decrypted.(map[string]interface{}), nil
+}
+
+// decryptValue decrypts armored strings within a var value
+func (sm *SecretManager) decryptValue(path string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if !IsEncryptedValue(v) {
+			return v, nil
+		}
+		plaintext, err := sm.Decrypt([]byte(v))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		secret := strings.TrimSuffix(string(plaintext), "\n")
+		sm.remember(secret)
+		return secret, nil
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			decrypted, err := sm.decryptValue(path+"."+key, item)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = decrypted
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			decrypted, err := sm.decryptValue(fmt.Sprintf("%s[%d]", path, i), item)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = decrypted
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+// remember records a decrypted value, so output containing it is recognized and redacted
+func (sm *SecretManager) remember(secret string) {
+	if secret == "" {
+		return
+	}
+	sm.plaintexts = append(sm.plaintexts, secret)
+	// Longest first, so a secret containing another one is redacted whole
+	sort.Slice(sm.plaintexts, func(i, j int) bool { return len(sm.plaintexts[i]) > len(sm.plaintexts[j]) })
+}
+
+// ContainsSecret reports whether content includes a decrypted var
+func (sm *SecretManager) ContainsSecret(content []byte) bool {
+	for _, secret := range sm.plaintexts {
+		if bytes.Contains(content, []byte(secret)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fingerprint returns an HMAC-SHA256 of decrypted content keyed with a local key
+// It tells whether a secret file changed since it was deployed, without state revealing anything about its content
+func (sm *SecretManager) Fingerprint(content []byte) (string, error) {
+	if sm.fingerprintKey == nil {
+		key, err := loadFingerprintKey(DefaultFingerprintKeyPath())
+		if err != nil {
+			return "", err
+		}
+		sm.fingerprintKey = key
+	}
+
+	mac := hmac.New(sha256.New, sm.fingerprintKey)
+	mac.Write(content)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// loadFingerprintKey reads the fingerprint key, generating it on first use
+func loadFingerprintKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err == nil {
+		if len(key) != fingerprintKeySize {
+			return nil, fmt.Errorf("invalid fingerprint key %s: expected %d bytes, got %d", path, fingerprintKeySize, len(key))
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read fingerprint key: %w", err)
+	}
+
+	key = make([]byte, fingerprintKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate fingerprint key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create fingerprint key directory: %w", err)
+	}
+	// Created exclusively, so a key written concurrently by another run is never overwritten
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return loadFingerprintKey(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fingerprint key: %w", err)
+	}
+	if _, err := f.Write(key); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write fingerprint key: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write fingerprint key: %w", err)
+	}
+	return key, nil
+}
+
+// Redact replaces decrypted vars in a string
+func (sm *SecretManager) Redact(s string) string {
+	for _, secret := range sm.plaintexts {
+		if len(secret) < minRedactLength {
+			continue
+		}
+		s = strings.ReplaceAll(s, secret, redactedSecret)
+		// Multi-line secrets are also redacted line by line, as output may quote single lines
+		if strings.Contains(secret, "\n") {
+			scanner := bufio.NewScanner(strings.NewReader(secret))
+			for scanner.Scan() {
+				if line := strings.TrimSpace(scanner.Text()); len(line) >= minRedactLength {
+					s = strings.ReplaceAll(s, line, redactedSecret)
+				}
+			}
+		}
+	}
+	return s
+}
+
+// EditEncryptedFile decrypts an age-encrypted file into a private temporary file, runs the editor on it,
+// and re-encrypts the result in place; a missing file is created
+// The plaintext lives in memory-backed /dev/shm where available and is removed afterwards
+// Returns false if the content wasn't changed
+func (sm *SecretManager) EditEncryptedFile(path string, editor []string) (bool, error) {
+	if len(editor) == 0 {
+		return false, fmt.Errorf("no editor configured")
+	}
+
+	var plaintext []byte
+	armored := false
+	mode := os.FileMode(0644)
+	ciphertext, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		armored = IsEncryptedValue(string(ciphertext))
+		if plaintext, err = sm.Decrypt(ciphertext); err != nil {
+			return false, fmt.Errorf("failed to decrypt %s: %w", path, err)
+		}
+		if info, statErr := os.Stat(path); statErr == nil {
+			mode = info.Mode().Perm()
+		}
+	case os.IsNotExist(err):
+		// A missing file is created
+	default:
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	// Make sure the file can be encrypted again before opening the editor
+	if _, err := sm.fileRecipients(path); err != nil {
+		return false, err
+	}
+
+	tmpDir, err := os.MkdirTemp(secretEditDir(), "configr-secret-*")
+	if err != nil {
+		return false, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Keep the inner extension so editors pick the right syntax (npmrc.age -> npmrc)
+	tmpPath := filepath.Join(tmpDir, strings.TrimSuffix(filepath.Base(path), ".age"))
+	if err := os.WriteFile(tmpPath, plaintext, 0600); err != nil {
+		return false, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	cmd := exec.Command(editor[0], append(editor[1:], tmpPath)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return false, fmt.Errorf("editor %s failed: %w", editor[0], err)
+	}
+
+	edited, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read edited file: %w", err)
+	}
+	if ciphertext != nil && bytes.Equal(edited, plaintext) {
+		return false, nil
+	}
+
+	encrypted, err := sm.EncryptFile(path, edited, armored)
+	if err != nil {
+		return false, err
+	}
+
+	// Replace the file atomically, so an interrupted write never loses the secret
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create directory: %w", err)
+	}
+	partialPath := path + ".partial"
+	if err := os.WriteFile(partialPath, encrypted, mode); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(partialPath, path); err != nil {
+		os.Remove(partialPath)
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// secretEditDir returns the directory decrypted files are edited in: /dev/shm if available, so the
+// plaintext stays in memory, otherwise the default temporary directory
+func secretEditDir() string {
+	if info, err := os.Stat("/dev/shm"); err == nil && info.IsDir() {
+		return "/dev/shm"
+	}
+	return ""
+}
+
+// RedactingWriter wraps a writer so decrypted vars never reach it, e.g. for log output
+func (sm *SecretManager) RedactingWriter(w io.Writer) io.Writer {
+	return &redactingWriter{secrets: sm, writer: w}
+}
+
+// redactingWriter redacts each write; log lines are written whole
+type redactingWriter struct {
+	secrets *SecretManager
+	writer  io.Writer
+}
+
+func (rw *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.writer, rw.secrets.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,376 @@
+package pkg
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+// setupAgeIdentity generates an identity and points CONFIGR_AGE_IDENTITY at it
+// HOME is moved to a temporary directory too, so the fingerprint key isn't written to the real one
+func setupAgeIdentity(t *testing.T) *age.X25519Identity {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "age.key")
+	if err := os.WriteFile(path, []byte(identity.String()+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write identity: %v", err)
+	}
+	t.Setenv(AgeIdentityEnv, path)
+	return identity
+}
+
+func TestSecretManager_EncryptDecrypt(t *testing.T) {
+	identity := setupAgeIdentity(t)
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	sm := NewSecretManager(logger)
+	for _, armored := range []bool{false, true} {
+		ciphertext, err := sm.Encrypt([]byte("token=abc123\n"), armored)
+		if err != nil {
+			t.Fatalf("Encrypt failed: %v", err)
+		}
+		if IsEncryptedValue(string(ciphertext)) != armored {
+			t.Errorf("expected armored=%v, got:\n%s", armored, ciphertext)
+		}
+		plaintext, err := sm.Decrypt(ciphertext)
+		if err != nil {
+			t.Fatalf("Decrypt failed: %v", err)
+		}
+		if string(plaintext) != "token=abc123\n" {
+			t.Errorf("unexpected plaintext: %q", plaintext)
+		}
+	}
+
+	// The variable may hold the key itself instead of a path
+	t.Setenv(AgeIdentityEnv, identity.String())
+	ciphertext, err := sm.Encrypt([]byte("secret"), true)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if plaintext, err := NewSecretManager(logger).Decrypt(ciphertext); err != nil || string(plaintext) != "secret" {
+		t.Errorf("expected the inline identity to decrypt, got %q, %v", plaintext, err)
+	}
+
+	// A missing identity is reported
+	t.Setenv(AgeIdentityEnv, filepath.Join(t.TempDir(), "missing.key"))
+	if _, err := NewSecretManager(logger).Decrypt(ciphertext); err == nil || !strings.Contains(err.Error(), AgeIdentityEnv) {
+		t.Errorf("expected a missing identity error, got %v", err)
+	}
+}
+
+func TestSecretManager_DecryptVars(t *testing.T) {
+	setupAgeIdentity(t)
+	var output strings.Builder
+	logger := log.New(&output)
+
+	sm := NewSecretManager(logger)
+	token, err := sm.Encrypt([]byte("ghp_supersecret\n"), true)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	vars := map[string]interface{}{
+		"user":   "me",
+		"github": map[string]interface{}{"token": string(token)},
+		"keys":   []interface{}{string(token), 42},
+	}
+	decrypted, err := sm.DecryptVars(vars)
+	if err != nil {
+		t.Fatalf("DecryptVars failed: %v", err)
+	}
+
+	if got := decrypted["github"].(map[string]interface{})["token"]; got != "ghp_supersecret" {
+		t.Errorf("unexpected nested value: %v", got)
+	}
+	if keys := decrypted["keys"].([]interface{}); keys[0] != "ghp_supersecret" || keys[1] != 42 {
+		t.Errorf("unexpected list values: %v", keys)
+	}
+	if decrypted["user"] != "me" {
+		t.Errorf("expected plain values to be kept, got %v", decrypted["user"])
+	}
+	if !IsEncryptedValue(vars["github"].(map[string]interface{})["token"].(string)) {
+		t.Error("expected the original vars to keep the ciphertext")
+	}
+
+	if !sm.ContainsSecret([]byte("auth=ghp_supersecret")) || sm.ContainsSecret([]byte("auth=other")) {
+		t.Error("unexpected ContainsSecret result")
+	}
+	if got := sm.Redact("token ghp_supersecret failed"); got != "token [REDACTED] failed" {
+		t.Errorf("unexpected redaction: %q", got)
+	}
+
+	logger.SetOutput(sm.RedactingWriter(&output))
+	logger.Error("request failed", "token", "ghp_supersecret")
+	if strings.Contains(output.String(), "ghp_supersecret") {
+		t.Errorf("expected the secret to be redacted from logs, got:\n%s", output.String())
+	}
+
+	// Vars that can't be decrypted fail with the var path
+	if _, err := sm.DecryptVars(map[string]interface{}{"broken": "-----BEGIN AGE ENCRYPTED FILE-----\ngarbage\n"}); err == nil || !strings.Contains(err.Error(), "vars.broken") {
+		t.Errorf("expected an error naming the var, got %v", err)
+	}
+}
+
+func TestFileManager_DeployFiles_Encrypted(t *testing.T) {
+	setupAgeIdentity(t)
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	sm := NewSecretManager(logger)
+	ciphertext, err := sm.Encrypt([]byte("//registry.npmjs.org/:_authToken=abc123\n"), false)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tempDir, "secrets"), 0755); err != nil {
+		t.Fatalf("failed to create secrets dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "secrets", "npmrc.age"), ciphertext, 0644); err != nil {
+		t.Fatalf("failed to write encrypted source: %v", err)
+	}
+
+	destFile := filepath.Join(tempDir, ".npmrc")
+	files := map[string]config.File{
+		"npmrc": {Source: "secrets/npmrc.age", Destination: destFile, Mode: "644"},
+	}
+
+	// Dry runs leave the destination alone
+	if _, err := NewFileManager(logger, true, tempDir).DeployFiles(files); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if _, err := os.Stat(destFile); !os.IsNotExist(err) {
+		t.Fatalf("dry run created the destination: %v", err)
+	}
+
+	fm := NewFileManager(logger, false, tempDir)
+	deployed, err := fm.DeployFiles(files)
+	if err != nil {
+		t.Fatalf("DeployFiles failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(destFile)
+	if string(content) != "//registry.npmjs.org/:_authToken=abc123\n" {
+		t.Errorf("unexpected decrypted content: %q", content)
+	}
+	if info, _ := os.Lstat(destFile); info.Mode()&os.ModeSymlink != 0 || info.Mode().Perm() != 0600 {
+		t.Errorf("expected a 0600 copy, got %v", info.Mode())
+	}
+	if len(deployed) != 1 || !deployed[0].Secret || deployed[0].ContentHash != "" {
+		t.Errorf("expected a secret without a content hash, got %+v", deployed)
+	}
+
+	// A second run leaves the up-to-date file in place
+	info, _ := os.Stat(destFile)
+	if _, err := fm.DeployFiles(files); err != nil {
+		t.Fatalf("DeployFiles failed: %v", err)
+	}
+	if again, _ := os.Stat(destFile); !again.ModTime().Equal(info.ModTime()) {
+		t.Error("expected an unchanged secret file not to be rewritten")
+	}
+
+	// A changed file is replaced without keeping the old plaintext in the backup store
+	if err := os.WriteFile(destFile, []byte("//registry.npmjs.org/:_authToken=old\n"), 0600); err != nil {
+		t.Fatalf("failed to change destination: %v", err)
+	}
+	store := NewBackupStoreWithPath(logger, filepath.Join(tempDir, "backups"))
+	fm.SetBackupStore(store)
+	files["npmrc"] = config.File{Source: "secrets/npmrc.age", Destination: destFile, Backup: true}
+	if _, err := fm.DeployFiles(files); err != nil {
+		t.Fatalf("DeployFiles failed: %v", err)
+	}
+	if entries, _ := store.List(); len(entries) != 0 {
+		t.Errorf("expected no backup of the secret file, got %+v", entries)
+	}
+}
+
+func TestFileManager_RemoveFiles_Secret(t *testing.T) {
+	setupAgeIdentity(t)
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	plaintext := "//registry.npmjs.org/:_authToken=abc123\n"
+	ciphertext, err := NewSecretManager(logger).Encrypt([]byte(plaintext), false)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "npmrc.age"), ciphertext, 0644); err != nil {
+		t.Fatalf("failed to write encrypted source: %v", err)
+	}
+	destFile := filepath.Join(tempDir, ".npmrc")
+	files := map[string]config.File{
+		"npmrc": {Source: "npmrc.age", Destination: destFile},
+	}
+	// Deployed long ago, so only the fingerprint can tell the file wasn't edited
+	old := time.Now().Add(-time.Hour)
+
+	deployed, err := NewFileManager(logger, false, tempDir).DeployFiles(files)
+	if err != nil {
+		t.Fatalf("DeployFiles failed: %v", err)
+	}
+	if len(deployed) != 1 || deployed[0].Fingerprint == "" {
+		t.Fatalf("expected a fingerprinted secret, got %+v", deployed)
+	}
+	if want, _ := NewSecretManager(logger).Fingerprint([]byte(plaintext)); deployed[0].Fingerprint != want {
+		t.Errorf("expected the fingerprint to be stable across runs, got %q and %q", deployed[0].Fingerprint, want)
+	}
+	if err := os.Chtimes(destFile, old, old); err != nil {
+		t.Fatalf("failed to age destination: %v", err)
+	}
+
+	// An unchanged secret is removed
+	fm := NewFileManager(logger, false, tempDir)
+	if err := fm.RemoveFiles(deployed); err != nil {
+		t.Fatalf("RemoveFiles failed: %v", err)
+	}
+	if _, err := os.Stat(destFile); !os.IsNotExist(err) {
+		t.Errorf("expected the unchanged secret file to be removed: %v", err)
+	}
+	if len(fm.KeptFiles()) != 0 {
+		t.Errorf("expected no kept files, got %+v", fm.KeptFiles())
+	}
+
+	// An edited secret is left in place and stays tracked, without failing the removal
+	deployed, err = NewFileManager(logger, false, tempDir).DeployFiles(files)
+	if err != nil {
+		t.Fatalf("DeployFiles failed: %v", err)
+	}
+	if err := os.WriteFile(destFile, []byte("//registry.npmjs.org/:_authToken=refreshed\n"), 0600); err != nil {
+		t.Fatalf("failed to edit destination: %v", err)
+	}
+	if err := os.Chtimes(destFile, old, old); err != nil {
+		t.Fatalf("failed to age destination: %v", err)
+	}
+	fm = NewFileManager(logger, false, tempDir)
+	if err := fm.RemoveFiles(deployed); err != nil {
+		t.Fatalf("RemoveFiles failed: %v", err)
+	}
+	if _, err := os.Stat(destFile); err != nil {
+		t.Errorf("expected the edited secret file to be kept: %v", err)
+	}
+	if kept := fm.KeptFiles(); len(kept) != 1 || kept[0].Destination != destFile {
+		t.Errorf("expected the edited secret file to stay tracked, got %+v", kept)
+	}
+}
+
+func TestFileManager_DeployFiles_TemplateWithSecretVar(t *testing.T) {
+	setupAgeIdentity(t)
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	sm := NewSecretManager(logger)
+	token, err := sm.Encrypt([]byte("s3cr3t-token"), true)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	vars, err := sm.DecryptVars(map[string]interface{}{"token": string(token), "user": "me"})
+	if err != nil {
+		t.Fatalf("DecryptVars failed: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, "netrc.tmpl"), []byte("login {{ .Vars.user }} password {{ .Vars.token }}\n"), 0644); err != nil {
+		t.Fatalf("failed to create template: %v", err)
+	}
+
+	fm := NewFileManager(logger, false, tempDir)
+	fm.SetSecretManager(sm)
+	fm.SetTemplateVars(vars)
+
+	destFile := filepath.Join(tempDir, ".netrc")
+	deployed, err := fm.DeployFiles(map[string]config.File{
+		"netrc": {Source: "netrc.tmpl", Destination: destFile, Template: true},
+	})
+	if err != nil {
+		t.Fatalf("DeployFiles failed: %v", err)
+	}
+
+	if content, _ := os.ReadFile(destFile); string(content) != "login me password s3cr3t-token\n" {
+		t.Errorf("unexpected rendered content: %q", content)
+	}
+	if info, _ := os.Stat(destFile); info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600 for rendered secrets, got %v", info.Mode().Perm())
+	}
+	if len(deployed) != 1 || !deployed[0].Secret {
+		t.Errorf("expected the rendered file to be tracked as a secret, got %+v", deployed)
+	}
+}
+
+func TestSecretManager_EditEncryptedFile(t *testing.T) {
+	identity := setupAgeIdentity(t)
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	writeStubCommand(t, tempDir, "append-editor", `echo "token=abc" >> "$1"`+"\n")
+	writeStubCommand(t, tempDir, "noop-editor", "exit 0\n")
+
+	sm := NewSecretManager(logger)
+	secretPath := filepath.Join(tempDir, "secrets", "npmrc.age")
+
+	// A missing file is created and encrypted
+	changed, err := sm.EditEncryptedFile(secretPath, []string{filepath.Join(tempDir, "append-editor")})
+	if err != nil || !changed {
+		t.Fatalf("expected the file to be created, got changed=%v err=%v", changed, err)
+	}
+	ciphertext, _ := os.ReadFile(secretPath)
+	if strings.Contains(string(ciphertext), "token=abc") {
+		t.Fatal("expected the file to be encrypted")
+	}
+	if plaintext, err := sm.Decrypt(ciphertext); err != nil || string(plaintext) != "token=abc\n" {
+		t.Fatalf("unexpected content: %q, %v", plaintext, err)
+	}
+
+	// An existing file isn't re-encrypted without recipients, so other recipients aren't locked out
+	if _, err := sm.EditEncryptedFile(secretPath, []string{filepath.Join(tempDir, "noop-editor")}); err == nil || !strings.Contains(err.Error(), "no recipients configured") {
+		t.Fatalf("expected a missing recipients error, got %v", err)
+	}
+	teammate, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	recipients := "# Team\n" + identity.Recipient().String() + "\n" + teammate.Recipient().String() + "\n"
+	if err := os.WriteFile(filepath.Join(tempDir, AgeRecipientsFile), []byte(recipients), 0644); err != nil {
+		t.Fatalf("failed to write recipients: %v", err)
+	}
+
+	// An unchanged file is not rewritten
+	changed, err = sm.EditEncryptedFile(secretPath, []string{filepath.Join(tempDir, "noop-editor")})
+	if err != nil || changed {
+		t.Errorf("expected no changes, got changed=%v err=%v", changed, err)
+	}
+	if again, _ := os.ReadFile(secretPath); string(again) != string(ciphertext) {
+		t.Error("expected an unchanged secret to keep its ciphertext")
+	}
+
+	// Edits are encrypted to every recipient in the .age-recipients file of a parent directory
+	if _, err := sm.EditEncryptedFile(secretPath, []string{filepath.Join(tempDir, "append-editor")}); err != nil {
+		t.Fatalf("EditEncryptedFile failed: %v", err)
+	}
+	ciphertext, _ = os.ReadFile(secretPath)
+	reader, err := age.Decrypt(bytes.NewReader(ciphertext), teammate)
+	if err != nil {
+		t.Fatalf("expected the teammate to be able to decrypt: %v", err)
+	}
+	if plaintext, _ := io.ReadAll(reader); string(plaintext) != "token=abc\ntoken=abc\n" {
+		t.Errorf("unexpected content: %q", plaintext)
+	}
+
+	// A failing editor leaves the file alone
+	if _, err := sm.EditEncryptedFile(secretPath, []string{"false"}); err == nil {
+		t.Error("expected an error for a failing editor")
+	}
+}
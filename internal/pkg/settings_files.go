@@ -0,0 +1,596 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// ApplySettingsFiles sets the configured keys in each settings file, leaving all other keys untouched
+// previous holds the settings of the last apply: the values keys had before configr managed them are
+// carried over, and managed keys changed outside configr since then are reported as drift
+func (fm *FileManager) ApplySettingsFiles(files map[string]config.SettingsFile, previous []ManagedSetting) ([]ManagedSetting, error) {
+	known := make(map[string]ManagedSetting, len(previous))
+	for _, setting := range previous {
+		known[setting.id()] = setting
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var applied []ManagedSetting
+	for _, name := range names {
+		settingsFile := files[name]
+
+		var settings []ManagedSetting
+		err := fm.editFile(name, settingsFile.Path, settingsFile.Create, settingsFile.Backup, func(current []string) ([]string, error) {
+			updated, records, err := fm.applySettings(name, settingsFile, current, known)
+			settings = records
+			return updated, err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply settings file '%s': %w", name, err)
+		}
+		applied = append(applied, settings...)
+	}
+	return applied, nil
+}
+
+// RevertSettings restores settings that are no longer in the configuration to their previous values,
+// or removes them if configr added them
+func (fm *FileManager) RevertSettings(settings []ManagedSetting) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	fm.logger.Info("Reverting settings no longer in configuration", "count", len(settings))
+
+	// Group by file so each file is rewritten once
+	var paths []string
+	byPath := make(map[string][]ManagedSetting)
+	for _, setting := range settings {
+		if _, exists := byPath[setting.Path]; !exists {
+			paths = append(paths, setting.Path)
+		}
+		byPath[setting.Path] = append(byPath[setting.Path], setting)
+	}
+
+	for _, path := range paths {
+		fileSettings := byPath[path]
+		name := fileSettings[0].File
+
+		err := fm.editFile(name, path, false, false, func(current []string) ([]string, error) {
+			return revertSettings(fileSettings, current)
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			fm.logger.Debug("Settings file no longer exists", "name", name, "path", path)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to revert settings in '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// applySettings sets the keys of a settings file in its current lines and returns the updated lines
+// along with the state of every managed key
+func (fm *FileManager) applySettings(name string, settingsFile config.SettingsFile, current []string, known map[string]ManagedSetting) ([]string, []ManagedSetting, error) {
+	format := settingsFile.FileFormat()
+	doc, err := parseSettingsDocument(format, joinLines(current))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := make([]string, 0, len(settingsFile.Settings))
+	for key := range settingsFile.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	changed := false
+	settings := make([]ManagedSetting, 0, len(keys))
+	for _, key := range keys {
+		path, err := config.ParseSettingsKey(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		desired := normalizeSettingValue(format, settingsFile.Settings[key])
+		value, exists, err := doc.get(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read key '%s': %w", key, err)
+		}
+
+		setting := ManagedSetting{
+			File:     name,
+			Path:     settingsFile.Path,
+			Format:   format,
+			Key:      key,
+			Value:    desired,
+			Previous: value,
+			Existed:  exists,
+		}
+
+		// Keep the value from before configr managed the key, and report changes made since the last apply
+		if last, ok := known[setting.id()]; ok {
+			setting.Previous, setting.Existed = last.Previous, last.Existed
+			drifted := !exists || !settingValuesEqual(value, last.Value)
+			if drifted && !(exists && settingValuesEqual(value, desired)) {
+				found := "(missing)"
+				if exists {
+					found = fmt.Sprint(value)
+				}
+				fm.logger.Warn("⚠ Managed setting changed outside configr", "file", name, "key", key, "expected", last.Value, "found", found)
+			}
+		}
+		settings = append(settings, setting)
+
+		if exists && settingValuesEqual(value, desired) {
+			continue
+		}
+		if err := doc.set(path, desired); err != nil {
+			return nil, nil, fmt.Errorf("failed to set key '%s': %w", key, err)
+		}
+		changed = true
+	}
+
+	if !changed {
+		return current, settings, nil
+	}
+	data, err := doc.encode()
+	if err != nil {
+		return nil, nil, err
+	}
+	return splitLines(string(data)), settings, nil
+}
+
+// revertSettings restores the previous values of settings in the current lines of their file
+func revertSettings(settings []ManagedSetting, current []string) ([]string, error) {
+	doc, err := parseSettingsDocument(settings[0].Format, joinLines(current))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, setting := range settings {
+		path, err := config.ParseSettingsKey(setting.Key)
+		if err != nil {
+			return nil, err
+		}
+		if !setting.Existed {
+			if err := doc.remove(path); err != nil {
+				return nil, fmt.Errorf("failed to remove key '%s': %w", setting.Key, err)
+			}
+			continue
+		}
+		previous := normalizeSettingValue(setting.Format, setting.Previous)
+		// A key already holding its previous value is left as written
+		if value, exists, err := doc.get(path); err == nil && exists && settingValuesEqual(value, previous) {
+			continue
+		}
+		if err := doc.set(path, previous); err != nil {
+			return nil, fmt.Errorf("failed to restore key '%s': %w", setting.Key, err)
+		}
+	}
+
+	data, err := doc.encode()
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(string(data)), nil
+}
+
+// joinLines is the inverse of splitLines
+func joinLines(lines []string) []byte {
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// normalizeSettingValue converts a value to the representation used for comparisons and state:
+// JSON-compatible types with integers kept as int64, and strings for INI files
+func normalizeSettingValue(format string, value interface{}) interface{} {
+	value = normalizeValue(value)
+	if format == config.SettingsFormatINI && value != nil {
+		return iniValue(value)
+	}
+	return value
+}
+
+// normalizeValue round-trips a value through JSON, so values read from YAML, TOML, JSON and
+// the state file compare equal
+func normalizeValue(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var normalized interface{}
+	if err := decoder.Decode(&normalized); err != nil {
+		return value
+	}
+	return convertNumbers(normalized)
+}
+
+// convertNumbers replaces json.Number values with int64 or float64
+func convertNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = convertNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertNumbers(item)
+		}
+	}
+	return value
+}
+
+// settingValuesEqual reports whether two setting values are equal after normalization
+func settingValuesEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizeValue(a), normalizeValue(b))
+}
+
+// settingsDocument is a parsed settings file whose keys can be read and changed
+type settingsDocument interface {
+	get(path []string) (interface{}, bool, error)
+	set(path []string, value interface{}) error
+	remove(path []string) error
+	encode() ([]byte, error)
+}
+
+// parseSettingsDocument parses settings file content in the given format; empty content is an empty document
+func parseSettingsDocument(format string, data []byte) (settingsDocument, error) {
+	switch format {
+	case config.SettingsFormatJSON:
+		return parseJSONDocument(data)
+	case config.SettingsFormatYAML:
+		return parseNodeDocument(data)
+	case config.SettingsFormatTOML:
+		return parseTOMLDocument(data)
+	case config.SettingsFormatINI:
+		return parseINIDocument(data), nil
+	}
+	return nil, fmt.Errorf("unsupported settings format: %q", format)
+}
+
+// nodeDocument edits YAML files through YAML nodes, which keeps their key order and comments
+type nodeDocument struct {
+	document *yaml.Node
+	indent   string
+}
+
+func parseNodeDocument(data []byte) (*nodeDocument, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if document.Kind == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("top level of the file is not an object")
+	}
+	return &nodeDocument{document: &document, indent: detectIndent(data)}, nil
+}
+
+func (d *nodeDocument) get(path []string) (interface{}, bool, error) {
+	node := d.document.Content[0]
+	for _, segment := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil, false, nil
+		}
+		i := mappingIndex(node, segment)
+		if i < 0 {
+			return nil, false, nil
+		}
+		node = node.Content[i+1]
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+	}
+
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, false, err
+	}
+	return normalizeValue(value), true, nil
+}
+
+func (d *nodeDocument) set(path []string, value interface{}) error {
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return err
+	}
+
+	node := d.document.Content[0]
+	for i, segment := range path {
+		index := mappingIndex(node, segment)
+		if i == len(path)-1 {
+			if index < 0 {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: segment}, &valueNode)
+			} else {
+				// Keep comments attached to the old value
+				old := node.Content[index+1]
+				valueNode.HeadComment, valueNode.LineComment = old.HeadComment, old.LineComment
+				node.Content[index+1] = &valueNode
+			}
+			return nil
+		}
+
+		if index < 0 {
+			child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: segment}, child)
+			node = child
+			continue
+		}
+		node = node.Content[index+1]
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not an object", strings.Join(path[:i+1], "."))
+		}
+	}
+	return nil
+}
+
+func (d *nodeDocument) remove(path []string) error {
+	parents := []*yaml.Node{d.document.Content[0]}
+	for _, segment := range path[:len(path)-1] {
+		parent := parents[len(parents)-1]
+		index := mappingIndex(parent, segment)
+		if index < 0 || parent.Content[index+1].Kind != yaml.MappingNode {
+			return nil
+		}
+		parents = append(parents, parent.Content[index+1])
+	}
+
+	// Remove the key, then any parent objects left empty by removing it
+	for i := len(parents) - 1; i >= 0; i-- {
+		parent := parents[i]
+		index := mappingIndex(parent, path[i])
+		if index < 0 {
+			return nil
+		}
+		if i < len(parents)-1 && len(parent.Content[index+1].Content) > 0 {
+			return nil
+		}
+		parent.Content = append(parent.Content[:index], parent.Content[index+2:]...)
+	}
+	return nil
+}
+
+func (d *nodeDocument) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(max(len(strings.ReplaceAll(d.indent, "\t", "  ")), 2))
+	if err := encoder.Encode(d.document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mappingIndex returns the index of a key in a mapping node's content, or -1
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// detectIndent returns the indentation of the first indented line, defaulting to two spaces
+func detectIndent(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
+
+// iniDocument edits INI files line by line, so comments, order and formatting are kept
+// Keys are "key" (before the first section) or "section.key"
+type iniDocument struct {
+	lines []string
+}
+
+func parseINIDocument(data []byte) *iniDocument {
+	return &iniDocument{lines: splitLines(string(data))}
+}
+
+// iniSection returns the section name of a header line
+func iniSection(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+		return strings.TrimSpace(trimmed[1 : len(trimmed)-1]), true
+	}
+	return "", false
+}
+
+// iniKey returns the key and value of a key line
+func iniKey(line string) (string, string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#") {
+		return "", "", false
+	}
+	key, value, found := strings.Cut(trimmed, "=")
+	if !found {
+		return "", "", false
+	}
+	return strings.TrimSpace(key), strings.TrimSpace(value), true
+}
+
+// splitINIPath returns the section and key of a path
+func splitINIPath(path []string) (string, string) {
+	if len(path) == 1 {
+		return "", path[0]
+	}
+	return path[0], path[1]
+}
+
+// find returns the line index of a key, and the index after the last line of its section
+// (-1 if the section doesn't exist)
+func (d *iniDocument) find(section, key string) (int, int) {
+	keyIndex, end := -1, -1
+	current := ""
+	if section == "" {
+		end = 0
+	}
+	for i, line := range d.lines {
+		if name, ok := iniSection(line); ok {
+			current = name
+			continue
+		}
+		if current != section {
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			end = i + 1
+		}
+		if name, _, ok := iniKey(line); ok && name == key {
+			keyIndex = i
+		}
+	}
+	if end < 0 {
+		// The section may exist without any lines
+		for i, line := range d.lines {
+			if name, ok := iniSection(line); ok && name == section {
+				end = i + 1
+			}
+		}
+	}
+	return keyIndex, end
+}
+
+func (d *iniDocument) get(path []string) (interface{}, bool, error) {
+	section, key := splitINIPath(path)
+	index, _ := d.find(section, key)
+	if index < 0 {
+		return nil, false, nil
+	}
+	_, value, _ := iniKey(d.lines[index])
+	return value, true, nil
+}
+
+func (d *iniDocument) set(path []string, value interface{}) error {
+	section, key := splitINIPath(path)
+	line := key + d.separator() + iniValue(value)
+
+	index, end := d.find(section, key)
+	switch {
+	case index >= 0:
+		// Keep the existing spacing around "="
+		existing := d.lines[index]
+		separator := strings.Index(existing, "=") + 1
+		for separator < len(existing) && existing[separator] == ' ' {
+			separator++
+		}
+		d.lines[index] = existing[:separator] + iniValue(value)
+	case end >= 0:
+		d.lines = append(d.lines[:end], append([]string{line}, d.lines[end:]...)...)
+	default:
+		if len(d.lines) > 0 && strings.TrimSpace(d.lines[len(d.lines)-1]) != "" {
+			d.lines = append(d.lines, "")
+		}
+		d.lines = append(d.lines, "["+section+"]", line)
+	}
+	return nil
+}
+
+func (d *iniDocument) remove(path []string) error {
+	section, key := splitINIPath(path)
+	index, _ := d.find(section, key)
+	if index < 0 {
+		return nil
+	}
+	d.lines = append(d.lines[:index], d.lines[index+1:]...)
+
+	// Remove the section too if removing the key left it empty
+	if section == "" {
+		return nil
+	}
+	header := -1
+	for i, line := range d.lines {
+		name, ok := iniSection(line)
+		if ok && name == section {
+			header = i
+			continue
+		}
+		if header < 0 {
+			continue
+		}
+		if ok {
+			break
+		}
+		if strings.TrimSpace(line) != "" {
+			return nil
+		}
+	}
+	if header < 0 {
+		return nil
+	}
+	end := header + 1
+	for end < len(d.lines) && strings.TrimSpace(d.lines[end]) == "" {
+		end++
+	}
+	// The blank line before the last section goes with it
+	if end == len(d.lines) && header > 0 && strings.TrimSpace(d.lines[header-1]) == "" {
+		header--
+	}
+	d.lines = append(d.lines[:header], d.lines[end:]...)
+	return nil
+}
+
+func (d *iniDocument) encode() ([]byte, error) {
+	return joinLines(d.lines), nil
+}
+
+// separator returns the "=" spacing used by the file
+func (d *iniDocument) separator() string {
+	for _, line := range d.lines {
+		if _, _, ok := iniKey(line); ok {
+			if strings.Contains(line, " = ") {
+				return " = "
+			}
+			return "="
+		}
+	}
+	return "="
+}
+
+// iniValue formats a value for an INI file
+func iniValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,370 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bashfulrobot/configr/internal/config"
+	"github.com/charmbracelet/log"
+)
+
+func TestFileManager_ApplySettingsFiles_JSON(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	settingsPath := filepath.Join(tempDir, "settings.json")
+	original := "{\n\t\"workbench.colorTheme\": \"Default Dark+\",\n\t\"editor.fontSize\": 12,\n\t\"editor.rulers\": [80, 120],\n\t\"zoom\": 1.0\n}\n"
+	if err := os.WriteFile(settingsPath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+
+	files := map[string]config.SettingsFile{
+		"vscode": {
+			Path: settingsPath,
+			Settings: map[string]interface{}{
+				"/editor.fontSize":    14,
+				"/files.autoSave":     "onFocusChange",
+				"terminal.integrated": map[string]interface{}{"fontSize": 13},
+			},
+		},
+	}
+
+	// Dry runs leave the file alone
+	if _, err := NewFileManager(logger, true, tempDir).ApplySettingsFiles(files, nil); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if content, _ := os.ReadFile(settingsPath); string(content) != original {
+		t.Fatalf("dry run changed the file:\n%s", content)
+	}
+
+	fm := NewFileManager(logger, false, tempDir)
+	applied, err := fm.ApplySettingsFiles(files, nil)
+	if err != nil {
+		t.Fatalf("ApplySettingsFiles failed: %v", err)
+	}
+
+	expected := "{\n\t\"workbench.colorTheme\": \"Default Dark+\",\n\t\"editor.fontSize\": 14,\n\t\"editor.rulers\": [80, 120],\n\t\"zoom\": 1.0,\n\t\"files.autoSave\": \"onFocusChange\",\n\t\"terminal\": {\n\t\t\"integrated\": {\n\t\t\t\"fontSize\": 13\n\t\t}\n\t}\n}\n"
+	if content, _ := os.ReadFile(settingsPath); string(content) != expected {
+		t.Fatalf("unexpected content:\n%s", content)
+	}
+
+	if len(applied) != 3 {
+		t.Fatalf("expected 3 managed settings, got %+v", applied)
+	}
+	if applied[0].Key != "/editor.fontSize" || !applied[0].Existed || !settingValuesEqual(applied[0].Previous, 12) {
+		t.Errorf("expected the previous font size to be recorded, got %+v", applied[0])
+	}
+	if applied[1].Key != "/files.autoSave" || applied[1].Existed {
+		t.Errorf("expected autoSave to be recorded as added, got %+v", applied[1])
+	}
+
+	// A second apply is a no-op and keeps the original previous values
+	info, _ := os.Stat(settingsPath)
+	again, err := fm.ApplySettingsFiles(files, applied)
+	if err != nil {
+		t.Fatalf("ApplySettingsFiles failed: %v", err)
+	}
+	if stat, _ := os.Stat(settingsPath); !stat.ModTime().Equal(info.ModTime()) {
+		t.Error("expected an unchanged file not to be rewritten")
+	}
+	if !settingValuesEqual(again[0].Previous, 12) {
+		t.Errorf("expected the original previous value to be kept, got %+v", again[0])
+	}
+
+	// Reverting restores changed keys and removes added ones
+	if err := fm.RevertSettings(again); err != nil {
+		t.Fatalf("RevertSettings failed: %v", err)
+	}
+	if content, _ := os.ReadFile(settingsPath); string(content) != original {
+		t.Errorf("unexpected content after reverting:\n%s", content)
+	}
+}
+
+func TestFileManager_ApplySettingsFiles_JSONC(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	// VS Code's settings.json allows comments and trailing commas
+	settingsPath := filepath.Join(tempDir, "settings.json")
+	original := `// Settings synced from the laptop
+{
+  // Appearance
+  "workbench.colorTheme": "Default Dark+", // was "Monokai"
+  "editor.fontSize": 12,
+  /* https://example.com/fonts */
+  "editor.fontFamily": "Fira Code",
+  "[go]": { // Go only
+    "editor.tabSize": 4,
+    // more later
+  },
+}
+`
+	if err := os.WriteFile(settingsPath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+
+	files := map[string]config.SettingsFile{
+		"vscode": {
+			Path: settingsPath,
+			Settings: map[string]interface{}{
+				"/workbench.colorTheme": "Solarized Light",
+				"/files.autoSave":       "onFocusChange",
+			},
+		},
+	}
+	if _, err := NewFileManager(logger, false, tempDir).ApplySettingsFiles(files, nil); err != nil {
+		t.Fatalf("ApplySettingsFiles failed: %v", err)
+	}
+
+	expected := `// Settings synced from the laptop
+{
+  // Appearance
+  "workbench.colorTheme": "Solarized Light", // was "Monokai"
+  "editor.fontSize": 12,
+  /* https://example.com/fonts */
+  "editor.fontFamily": "Fira Code",
+  "[go]": { // Go only
+    "editor.tabSize": 4,
+    // more later
+  },
+  "files.autoSave": "onFocusChange",
+}
+`
+	if content, _ := os.ReadFile(settingsPath); string(content) != expected {
+		t.Fatalf("unexpected content:\n%s", content)
+	}
+}
+
+func TestFileManager_ApplySettingsFiles_JSONLayout(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	// Only the changed value is rewritten: escapes, inline values and spacing elsewhere stay as they are
+	settingsPath := filepath.Join(tempDir, "settings.json")
+	original := `{
+    "http.proxy": "http:\/\/proxy.local",
+    "editor.rulers": [80, 120],
+    "editor.tokenColorCustomizations": {"comments": "#888"},
+    "window.zoomLevel":   0
+}
+`
+	if err := os.WriteFile(settingsPath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+
+	fm := NewFileManager(logger, false, tempDir)
+	applied, err := fm.ApplySettingsFiles(map[string]config.SettingsFile{
+		"vscode": {
+			Path: settingsPath,
+			Settings: map[string]interface{}{
+				"/window.zoomLevel":                        1,
+				"/editor.tokenColorCustomizations/strings": "#0a0",
+				"/http.proxy":                              "http://proxy.local",
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("ApplySettingsFiles failed: %v", err)
+	}
+
+	expected := `{
+    "http.proxy": "http:\/\/proxy.local",
+    "editor.rulers": [80, 120],
+    "editor.tokenColorCustomizations": {"comments": "#888", "strings": "#0a0"},
+    "window.zoomLevel":   1
+}
+`
+	if content, _ := os.ReadFile(settingsPath); string(content) != expected {
+		t.Fatalf("unexpected content:\n%s", content)
+	}
+	if applied[1].Key != "/http.proxy" || !applied[1].Existed || !settingValuesEqual(applied[1].Previous, "http://proxy.local") {
+		t.Errorf("expected the escaped proxy to be read as set, got %+v", applied[1])
+	}
+
+	if err := fm.RevertSettings(applied); err != nil {
+		t.Fatalf("RevertSettings failed: %v", err)
+	}
+	if content, _ := os.ReadFile(settingsPath); string(content) != original {
+		t.Errorf("unexpected content after reverting:\n%s", content)
+	}
+}
+
+func TestFileManager_ApplySettingsFiles_Drift(t *testing.T) {
+	tempDir := t.TempDir()
+	var output strings.Builder
+	logger := log.New(&output)
+
+	settingsPath := filepath.Join(tempDir, "config.yaml")
+	if err := os.WriteFile(settingsPath, []byte("# Alacritty\nfont:\n  size: 11 # points\nwindow:\n  opacity: 0.9\n"), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+
+	files := map[string]config.SettingsFile{
+		"alacritty": {Path: settingsPath, Settings: map[string]interface{}{"font.size": 13}},
+	}
+
+	fm := NewFileManager(logger, false, tempDir)
+	applied, err := fm.ApplySettingsFiles(files, nil)
+	if err != nil {
+		t.Fatalf("ApplySettingsFiles failed: %v", err)
+	}
+
+	expected := "# Alacritty\nfont:\n  size: 13 # points\nwindow:\n  opacity: 0.9\n"
+	if content, _ := os.ReadFile(settingsPath); string(content) != expected {
+		t.Fatalf("unexpected content:\n%s", content)
+	}
+	if strings.Contains(output.String(), "changed outside configr") {
+		t.Fatalf("unexpected drift report on first apply:\n%s", output.String())
+	}
+
+	// The application changes the managed key
+	if err := os.WriteFile(settingsPath, []byte(strings.Replace(expected, "13", "15", 1)), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	if _, err := fm.ApplySettingsFiles(files, applied); err != nil {
+		t.Fatalf("ApplySettingsFiles failed: %v", err)
+	}
+	if !strings.Contains(output.String(), "Managed setting changed outside configr") {
+		t.Errorf("expected drift to be reported, got:\n%s", output.String())
+	}
+	if content, _ := os.ReadFile(settingsPath); string(content) != expected {
+		t.Errorf("expected the managed value to be restored, got:\n%s", content)
+	}
+}
+
+func TestFileManager_ApplySettingsFiles_TOML(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	settingsPath := filepath.Join(tempDir, "starship.toml")
+	original := `# Starship prompt
+"$schema" = 'https://starship.rs/config-schema.json'
+add_newline = false # keep it compact
+
+[character]
+success_symbol = '>'
+format = """
+$symbol """
+
+[directory]
+truncation_length = 3
+`
+	if err := os.WriteFile(settingsPath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+
+	fm := NewFileManager(logger, false, tempDir)
+	applied, err := fm.ApplySettingsFiles(map[string]config.SettingsFile{
+		"starship": {
+			Path: settingsPath,
+			Settings: map[string]interface{}{
+				"git_status.disabled":  true,
+				"add_newline":          true,
+				"character.format":     "$all",
+				"character.vimcmd":     "<",
+				"directory.substitute": map[string]interface{}{"~/src": "src"},
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("ApplySettingsFiles failed: %v", err)
+	}
+
+	expected := `# Starship prompt
+"$schema" = 'https://starship.rs/config-schema.json'
+add_newline = true # keep it compact
+
+[character]
+success_symbol = '>'
+format = "$all"
+vimcmd = "<"
+
+[directory]
+truncation_length = 3
+substitute = { "~/src" = "src" }
+
+[git_status]
+disabled = true
+`
+	if content, _ := os.ReadFile(settingsPath); string(content) != expected {
+		t.Fatalf("unexpected content:\n%s", content)
+	}
+
+	if err := fm.RevertSettings(applied); err != nil {
+		t.Fatalf("RevertSettings failed: %v", err)
+	}
+	expected = strings.Replace(original, "format = \"\"\"\n$symbol \"\"\"", "format = \"$symbol \"", 1)
+	if content, _ := os.ReadFile(settingsPath); string(content) != expected {
+		t.Errorf("unexpected content after reverting:\n%s", content)
+	}
+}
+
+func TestFileManager_ApplySettingsFiles_TOMLInlineTable(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	// A key inside an inline table can't be edited line by line, so the file is left alone
+	settingsPath := filepath.Join(tempDir, "starship.toml")
+	original := "# Starship prompt\ncharacter = { success_symbol = '>' }\n"
+	if err := os.WriteFile(settingsPath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+
+	_, err := NewFileManager(logger, false, tempDir).ApplySettingsFiles(map[string]config.SettingsFile{
+		"starship": {Path: settingsPath, Settings: map[string]interface{}{"character.error_symbol": "x"}},
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "without rewriting the file") {
+		t.Fatalf("expected the change to be refused, got %v", err)
+	}
+	if content, _ := os.ReadFile(settingsPath); string(content) != original {
+		t.Errorf("expected the file to be left alone, got:\n%s", content)
+	}
+}
+
+func TestFileManager_ApplySettingsFiles_INI(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.ErrorLevel) // Suppress output during tests
+
+	settingsPath := filepath.Join(tempDir, "app.ini")
+	original := "; app settings\nlanguage=en\n\n[General]\ntheme=light\nfont_size = 10\n\n[Window]\nmaximized=false\n"
+	if err := os.WriteFile(settingsPath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+
+	fm := NewFileManager(logger, false, tempDir)
+	applied, err := fm.ApplySettingsFiles(map[string]config.SettingsFile{
+		"app": {
+			Path: settingsPath,
+			Settings: map[string]interface{}{
+				"language":          "de",
+				"General.font_size": 12,
+				"General.animate":   false,
+				"Sync.enabled":      true,
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("ApplySettingsFiles failed: %v", err)
+	}
+
+	expected := "; app settings\nlanguage=de\n\n[General]\ntheme=light\nfont_size = 12\nanimate=false\n\n[Window]\nmaximized=false\n\n[Sync]\nenabled=true\n"
+	if content, _ := os.ReadFile(settingsPath); string(content) != expected {
+		t.Fatalf("unexpected content:\n%s", content)
+	}
+
+	if err := fm.RevertSettings(applied); err != nil {
+		t.Fatalf("RevertSettings failed: %v", err)
+	}
+	expected = "; app settings\nlanguage=en\n\n[General]\ntheme=light\nfont_size = 10\n\n[Window]\nmaximized=false\n"
+	if content, _ := os.ReadFile(settingsPath); string(content) != expected {
+		t.Errorf("unexpected content after reverting:\n%s", content)
+	}
+}
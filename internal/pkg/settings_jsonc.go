@@ -0,0 +1,476 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonDocument edits JSON and JSONC files (JSON with // and /* */ comments and trailing commas, e.g.
+// VS Code's settings.json) in place: a change splices only the bytes of the value it affects, so
+// comments, formatting and the layout of untouched values are kept as they are
+type jsonDocument struct {
+	data   []byte
+	root   *jsonValue // nil for an empty file
+	indent string
+}
+
+// jsonValue is a value parsed from a JSON file, with its position in the file
+type jsonValue struct {
+	start, end int // Byte range of the value
+	value      interface{}
+	members    []jsonMember // Members of an object, nil for other values
+	object     bool
+}
+
+// jsonMember is a key and value of an object
+type jsonMember struct {
+	key      string
+	keyStart int
+	value    *jsonValue
+	comma    int // Offset of the comma after the value, -1 if there is none
+}
+
+func parseJSONDocument(data []byte) (*jsonDocument, error) {
+	doc := &jsonDocument{data: data, indent: detectIndent(data)}
+	if err := doc.parse(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// parse parses the document's data again after a change
+func (d *jsonDocument) parse() error {
+	p := &jsonParser{data: d.data}
+	p.skip()
+	if p.pos == len(p.data) {
+		d.root = nil
+		return nil
+	}
+	root, err := p.parseValue()
+	if err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if p.skip(); p.pos != len(p.data) {
+		return fmt.Errorf("failed to parse JSON: %s", p.unexpected())
+	}
+	if !root.object {
+		return fmt.Errorf("top level of the file is not an object")
+	}
+	d.root = root
+	return nil
+}
+
+// lookup returns the value at a path and the object holding it, or nil if the path doesn't exist
+func (d *jsonDocument) lookup(path []string) (*jsonValue, *jsonValue, int) {
+	parent := d.root
+	for i, segment := range path {
+		if parent == nil || !parent.object {
+			return nil, nil, -1
+		}
+		index := parent.memberIndex(segment)
+		if index < 0 {
+			return nil, nil, -1
+		}
+		if i == len(path)-1 {
+			return parent.members[index].value, parent, index
+		}
+		parent = parent.members[index].value
+	}
+	return nil, nil, -1
+}
+
+func (d *jsonDocument) get(path []string) (interface{}, bool, error) {
+	value, _, _ := d.lookup(path)
+	if value == nil {
+		return nil, false, nil
+	}
+	return normalizeValue(value.value), true, nil
+}
+
+func (d *jsonDocument) set(path []string, value interface{}) error {
+	if d.root == nil {
+		d.data = append(d.data[:len(bytes.TrimRight(d.data, " \t\r\n"))], "{}\n"...)
+		if err := d.parse(); err != nil {
+			return err
+		}
+	}
+
+	// Find the deepest existing object on the path; the missing rest is created as nested objects
+	parent := d.root
+	depth := 0
+	for ; depth < len(path)-1; depth++ {
+		index := parent.memberIndex(path[depth])
+		if index < 0 {
+			break
+		}
+		child := parent.members[index].value
+		if !child.object {
+			return fmt.Errorf("%s is not an object", strings.Join(path[:depth+1], "."))
+		}
+		parent = child
+	}
+	for i := len(path) - 1; i > depth; i-- {
+		value = map[string]interface{}{path[i]: value}
+	}
+
+	if index := parent.memberIndex(path[depth]); index >= 0 {
+		old := parent.members[index].value
+		encoded, err := encodeJSONValue(value, lineIndent(d.data, parent.members[index].keyStart), d.indent)
+		if err != nil {
+			return err
+		}
+		return d.splice(old.start, old.end, encoded)
+	}
+	return d.insert(parent, path[depth], value)
+}
+
+// insert adds a member at the end of an object, matching the layout of its existing members
+func (d *jsonDocument) insert(object *jsonValue, key string, value interface{}) error {
+	encodedKey, err := encodeJSONValue(key, "", "")
+	if err != nil {
+		return err
+	}
+
+	if len(object.members) == 0 {
+		parentIndent := lineIndent(d.data, object.start)
+		indent := parentIndent + d.indent
+		encoded, err := encodeJSONValue(value, indent, d.indent)
+		if err != nil {
+			return err
+		}
+		// Anything inside the empty braces (e.g. a comment) stays before the closing brace
+		inner := strings.TrimRight(string(d.data[object.start+1:object.end-1]), " \t\r\n")
+		member := "\n" + indent + encodedKey + ": " + encoded
+		if inner != "" {
+			member = inner + member
+		}
+		return d.splice(object.start+1, object.end-1, member+"\n"+parentIndent)
+	}
+
+	last := object.members[len(object.members)-1]
+	lastEnd := last.value.end
+	if last.comma >= 0 {
+		lastEnd = last.comma + 1
+	}
+
+	// An object on a single line gets the new member on the same line
+	closing := object.end - 1
+	if !bytes.ContainsRune(d.data[lastEnd:closing], '\n') {
+		encoded, err := encodeJSONValue(value, "", "")
+		if err != nil {
+			return err
+		}
+		if last.comma >= 0 {
+			return d.splice(lastEnd, lastEnd, " "+encodedKey+": "+encoded+",")
+		}
+		return d.splice(lastEnd, lastEnd, ", "+encodedKey+": "+encoded)
+	}
+
+	indent := lineIndent(d.data, last.keyStart)
+	encoded, err := encodeJSONValue(value, indent, d.indent)
+	if err != nil {
+		return err
+	}
+	// The new member goes on its own line after the last one, after any comment ending that line
+	lineEnd := lastEnd + bytes.IndexByte(d.data[lastEnd:], '\n')
+	member := "\n" + indent + encodedKey + ": " + encoded
+	if last.comma >= 0 {
+		// Keep the file's trailing comma style
+		return d.splice(lineEnd, lineEnd, member+",")
+	}
+	return d.splice(last.value.end, lineEnd, ","+string(d.data[last.value.end:lineEnd])+member)
+}
+
+func (d *jsonDocument) remove(path []string) error {
+	for len(path) > 0 {
+		_, parent, index := d.lookup(path)
+		if parent == nil {
+			return nil
+		}
+		if err := d.removeMember(parent, index); err != nil {
+			return err
+		}
+
+		// Remove any parent object left empty by removing the key
+		path = path[:len(path)-1]
+		if value, _, _ := d.lookup(path); len(path) == 0 || value == nil || len(value.members) > 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+// removeMember removes a member of an object along with the comma separating it from its neighbours
+func (d *jsonDocument) removeMember(object *jsonValue, index int) error {
+	member := object.members[index]
+	start, end := member.keyStart, member.value.end
+	if member.comma >= 0 {
+		end = member.comma + 1
+	}
+
+	// A member on lines of its own is removed with its lines, including a comment ending its last line
+	lineStart := bytes.LastIndexByte(d.data[:start], '\n') + 1
+	lineEnd := end + bytes.IndexByte(d.data[end:], '\n') + 1
+	ownLines := strings.TrimSpace(string(d.data[lineStart:start])) == "" && lineEnd > end &&
+		isJSONCommentOrSpace(d.data[end:lineEnd-1]) && lineEnd <= object.end-1
+	if ownLines {
+		start, end = lineStart, lineEnd
+	} else if member.comma >= 0 {
+		end = skipSpaces(d.data, end)
+	}
+
+	// The last member's removal leaves the comma after the one before it dangling
+	if index == len(object.members)-1 && member.comma < 0 && index > 0 {
+		previous := object.members[index-1]
+		if previous.comma >= 0 {
+			if !ownLines {
+				start = previous.comma
+			} else {
+				return d.splice(previous.comma, end, string(d.data[previous.comma+1:start]))
+			}
+		}
+	}
+	return d.splice(start, end, "")
+}
+
+func (d *jsonDocument) encode() ([]byte, error) {
+	return d.data, nil
+}
+
+// splice replaces a byte range of the document and parses it again
+func (d *jsonDocument) splice(start, end int, text string) error {
+	data := make([]byte, 0, len(d.data)-(end-start)+len(text))
+	data = append(data, d.data[:start]...)
+	data = append(data, text...)
+	data = append(data, d.data[end:]...)
+	d.data = data
+	return d.parse()
+}
+
+// memberIndex returns the index of the last member with a key, or -1
+// (JSON parsers keep the last of duplicate keys)
+func (v *jsonValue) memberIndex(key string) int {
+	for i := len(v.members) - 1; i >= 0; i-- {
+		if v.members[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// encodeJSONValue encodes a value as JSON; objects and arrays are indented to continue a line with the given indentation
+func encodeJSONValue(value interface{}, prefix, indent string) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if indent != "" {
+		encoder.SetIndent(prefix, indent)
+	}
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// lineIndent returns the indentation of the line containing an offset
+func lineIndent(data []byte, offset int) string {
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	line := data[lineStart:]
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+// skipSpaces returns the offset of the first non-space byte on the same line from an offset
+func skipSpaces(data []byte, offset int) int {
+	for offset < len(data) && (data[offset] == ' ' || data[offset] == '\t') {
+		offset++
+	}
+	return offset
+}
+
+// isJSONCommentOrSpace reports whether the rest of a line holds nothing but spaces and comments
+func isJSONCommentOrSpace(data []byte) bool {
+	p := &jsonParser{data: data}
+	p.skip()
+	return p.pos == len(data)
+}
+
+// jsonParser parses JSONC, recording where each value is in the file
+type jsonParser struct {
+	data []byte
+	pos  int
+}
+
+// skip moves past whitespace and comments
+func (p *jsonParser) skip() {
+	for p.pos < len(p.data) {
+		switch c := p.data[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			p.pos++
+		case bytes.HasPrefix(p.data[p.pos:], []byte("//")):
+			end := bytes.IndexByte(p.data[p.pos:], '\n')
+			if end < 0 {
+				p.pos = len(p.data)
+				return
+			}
+			p.pos += end
+		case bytes.HasPrefix(p.data[p.pos:], []byte("/*")):
+			end := bytes.Index(p.data[p.pos+2:], []byte("*/"))
+			if end < 0 {
+				// Unterminated comment, reported as unexpected input
+				return
+			}
+			p.pos += end + 4
+		default:
+			return
+		}
+	}
+}
+
+// unexpected describes the input at the current position for an error message
+func (p *jsonParser) unexpected() string {
+	line := bytes.Count(p.data[:p.pos], []byte("\n")) + 1
+	if p.pos >= len(p.data) {
+		return "unexpected end of file"
+	}
+	token := p.data[p.pos:min(p.pos+10, len(p.data))]
+	if i := bytes.IndexByte(token, '\n'); i >= 0 {
+		token = token[:i]
+	}
+	return fmt.Sprintf("unexpected %q on line %d", token, line)
+}
+
+func (p *jsonParser) parseValue() (*jsonValue, error) {
+	if p.pos >= len(p.data) {
+		return nil, fmt.Errorf("%s", p.unexpected())
+	}
+	switch p.data[p.pos] {
+	case '{':
+		return p.parseObject()
+	case '[':
+		return p.parseArray()
+	case '"':
+		start := p.pos
+		text, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &jsonValue{start: start, end: p.pos, value: text}, nil
+	}
+
+	// A number, true, false or null
+	start := p.pos
+	for p.pos < len(p.data) && !bytes.ContainsRune([]byte(",:{}[]\"/ \t\r\n"), rune(p.data[p.pos])) {
+		p.pos++
+	}
+	token := p.data[start:p.pos]
+	decoder := json.NewDecoder(bytes.NewReader(token))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.InputOffset() != int64(len(token)) {
+		p.pos = start
+		return nil, fmt.Errorf("%s", p.unexpected())
+	}
+	return &jsonValue{start: start, end: p.pos, value: convertNumbers(value)}, nil
+}
+
+func (p *jsonParser) parseString() (string, error) {
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.data) && p.data[p.pos] != '"' {
+		if p.data[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.data) {
+		p.pos = start
+		return "", fmt.Errorf("unterminated string on line %d", bytes.Count(p.data[:start], []byte("\n"))+1)
+	}
+	p.pos++
+
+	var text string
+	if err := json.Unmarshal(p.data[start:p.pos], &text); err != nil {
+		p.pos = start
+		return "", fmt.Errorf("%s: %w", p.unexpected(), err)
+	}
+	return text, nil
+}
+
+func (p *jsonParser) parseObject() (*jsonValue, error) {
+	object := &jsonValue{start: p.pos, object: true, members: []jsonMember{}}
+	values := make(map[string]interface{})
+	p.pos++
+	for {
+		p.skip()
+		if p.pos < len(p.data) && p.data[p.pos] == '}' {
+			break
+		}
+		if p.pos >= len(p.data) || p.data[p.pos] != '"' {
+			return nil, fmt.Errorf("%s", p.unexpected())
+		}
+		keyStart := p.pos
+		key, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		if p.skip(); p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			return nil, fmt.Errorf("%s", p.unexpected())
+		}
+		p.pos++
+		p.skip()
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		member := jsonMember{key: key, keyStart: keyStart, value: value, comma: -1}
+		values[key] = value.value
+
+		p.skip()
+		if p.pos < len(p.data) && p.data[p.pos] == ',' {
+			member.comma = p.pos
+			p.pos++
+		}
+		object.members = append(object.members, member)
+		if member.comma < 0 {
+			if p.skip(); p.pos >= len(p.data) || p.data[p.pos] != '}' {
+				return nil, fmt.Errorf("%s", p.unexpected())
+			}
+			break
+		}
+	}
+	p.pos++
+	object.end, object.value = p.pos, values
+	return object, nil
+}
+
+func (p *jsonParser) parseArray() (*jsonValue, error) {
+	array := &jsonValue{start: p.pos}
+	values := []interface{}{}
+	p.pos++
+	for {
+		p.skip()
+		if p.pos < len(p.data) && p.data[p.pos] == ']' {
+			break
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value.value)
+
+		p.skip()
+		if p.pos < len(p.data) && p.data[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if p.pos >= len(p.data) || p.data[p.pos] != ']' {
+			return nil, fmt.Errorf("%s", p.unexpected())
+		}
+		break
+	}
+	p.pos++
+	array.end, array.value = p.pos, values
+	return array, nil
+}
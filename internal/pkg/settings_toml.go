@@ -0,0 +1,464 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// bareTOMLKey matches keys that don't need quoting
+var bareTOMLKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tomlDocument edits TOML files line by line, so comments, key order and formatting are kept
+// Every change is checked by parsing the result; a change that can't be made in place (e.g. a key
+// inside an inline table) is refused rather than rewriting the file
+type tomlDocument struct {
+	lines  []string
+	values map[string]interface{}
+}
+
+func parseTOMLDocument(data []byte) (*tomlDocument, error) {
+	values := make(map[string]interface{})
+	if err := toml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse TOML: %w", err)
+	}
+	return &tomlDocument{lines: splitLines(string(data)), values: values}, nil
+}
+
+func (d *tomlDocument) get(path []string) (interface{}, bool, error) {
+	current := d.values
+	for i, segment := range path {
+		value, exists := current[segment]
+		if !exists {
+			return nil, false, nil
+		}
+		if i == len(path)-1 {
+			return normalizeValue(value), true, nil
+		}
+		if current, exists = value.(map[string]interface{}); !exists {
+			return nil, false, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (d *tomlDocument) set(path []string, value interface{}) error {
+	expected, _ := normalizeValue(d.values).(map[string]interface{})
+	current := expected
+	for i, segment := range path[:len(path)-1] {
+		child, exists := current[segment]
+		if !exists {
+			child = make(map[string]interface{})
+			current[segment] = child
+		}
+		table, ok := child.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not a table", strings.Join(path[:i+1], "."))
+		}
+		current = table
+	}
+	current[path[len(path)-1]] = normalizeValue(value)
+
+	encoded, err := tomlValue(value)
+	if err != nil {
+		return err
+	}
+
+	original := append([]string(nil), d.lines...)
+	if line, start, found := d.findKey(path); found {
+		endLine, end := tomlValueEnd(d.lines, line, start)
+		replaced := d.lines[line][:start] + encoded + d.lines[endLine][end:]
+		d.lines = append(d.lines[:line], append([]string{replaced}, d.lines[endLine+1:]...)...)
+	} else {
+		table, key := path[:len(path)-1], path[len(path)-1]
+		entry := formatTOMLKey([]string{key}) + " = " + encoded
+		if end := d.tableEnd(table); end >= 0 {
+			entries := []string{entry}
+			if end < len(d.lines) {
+				// A key added to a root table without keys is kept apart from the first header
+				if _, header := parseTOMLHeader(strings.TrimSpace(d.lines[end])); header {
+					entries = append(entries, "")
+				}
+			}
+			d.lines = append(d.lines[:end], append(entries, d.lines[end:]...)...)
+		} else {
+			if len(d.lines) > 0 && strings.TrimSpace(d.lines[len(d.lines)-1]) != "" {
+				d.lines = append(d.lines, "")
+			}
+			d.lines = append(d.lines, "["+formatTOMLKey(table)+"]", entry)
+		}
+	}
+	return d.check(original, expected, path)
+}
+
+func (d *tomlDocument) remove(path []string) error {
+	if _, exists, _ := d.get(path); !exists {
+		return nil
+	}
+	line, start, found := d.findKey(path)
+	if !found {
+		return fmt.Errorf("%s can't be removed without rewriting the file", strings.Join(path, "."))
+	}
+
+	original := append([]string(nil), d.lines...)
+	endLine, _ := tomlValueEnd(d.lines, line, start)
+	d.lines = append(d.lines[:line], d.lines[endLine+1:]...)
+
+	expected, _ := normalizeValue(d.values).(map[string]interface{})
+	tables := []map[string]interface{}{expected}
+	for _, segment := range path[:len(path)-1] {
+		tables = append(tables, tables[len(tables)-1][segment].(map[string]interface{}))
+	}
+	delete(tables[len(tables)-1], path[len(path)-1])
+
+	// Remove the tables left empty by removing the key, unless their header has comments to keep
+	for i := len(tables) - 1; i > 0 && len(tables[i]) == 0; i-- {
+		if !d.removeEmptyTable(path[:i]) {
+			break
+		}
+		delete(tables[i-1], path[i-1])
+	}
+	return d.check(original, expected, path)
+}
+
+func (d *tomlDocument) encode() ([]byte, error) {
+	return joinLines(d.lines), nil
+}
+
+// check parses the edited lines and makes sure they hold the expected values; otherwise the
+// edit is undone and refused
+func (d *tomlDocument) check(original []string, expected map[string]interface{}, path []string) error {
+	values := make(map[string]interface{})
+	err := toml.Unmarshal(joinLines(d.lines), &values)
+	if err != nil || !reflect.DeepEqual(normalizeValue(values), normalizeValue(expected)) {
+		d.lines = original
+		return fmt.Errorf("%s can't be changed without rewriting the file", strings.Join(path, "."))
+	}
+	d.values = values
+	return nil
+}
+
+// findKey returns the line of a key and the offset of its value
+func (d *tomlDocument) findKey(path []string) (int, int, bool) {
+	var table []string
+	arrayTable := false
+	for i := 0; i < len(d.lines); i++ {
+		line := d.lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if header, ok := parseTOMLHeader(trimmed); ok {
+			table, arrayTable = header, header == nil
+			continue
+		}
+
+		separator := tomlKeyEnd(line)
+		if separator < 0 {
+			continue
+		}
+		start := separator + 1
+		for start < len(line) && (line[start] == ' ' || line[start] == '\t') {
+			start++
+		}
+		if key, ok := parseTOMLKey(line[:separator]); ok && !arrayTable && slices.Equal(append(slices.Clone(table), key...), path) {
+			return i, start, true
+		}
+		// Skip the lines of a multi-line value
+		i, _ = tomlValueEnd(d.lines, i, start)
+	}
+	return -1, 0, false
+}
+
+// tableEnd returns the index after the last non-blank line of a table, or -1 if it has no header
+// The root table ends before the first header
+func (d *tomlDocument) tableEnd(table []string) int {
+	end := -1
+	if len(table) == 0 {
+		end = 0
+	}
+	inTable := len(table) == 0
+	for i, line := range d.lines {
+		trimmed := strings.TrimSpace(line)
+		if header, ok := parseTOMLHeader(trimmed); ok {
+			if len(table) == 0 {
+				return end
+			}
+			inTable = header != nil && slices.Equal(header, table)
+			if inTable {
+				end = i + 1
+			}
+			continue
+		}
+		if inTable && trimmed != "" {
+			end = i + 1
+		}
+	}
+	return end
+}
+
+// removeEmptyTable removes the header of a table with nothing but blank lines under it
+// Returns false if the table has a header that was kept
+func (d *tomlDocument) removeEmptyTable(table []string) bool {
+	header := -1
+	for i, line := range d.lines {
+		name, ok := parseTOMLHeader(strings.TrimSpace(line))
+		if !ok {
+			if header >= 0 && strings.TrimSpace(line) != "" {
+				return false
+			}
+			continue
+		}
+		if header >= 0 {
+			break
+		}
+		if name != nil && slices.Equal(name, table) {
+			header = i
+		}
+	}
+	if header < 0 {
+		return true
+	}
+
+	end := header + 1
+	for end < len(d.lines) {
+		if _, ok := parseTOMLHeader(strings.TrimSpace(d.lines[end])); ok {
+			break
+		}
+		end++
+	}
+	// The blank line before the last table goes with it
+	if end == len(d.lines) && header > 0 && strings.TrimSpace(d.lines[header-1]) == "" {
+		header--
+	}
+	d.lines = append(d.lines[:header], d.lines[end:]...)
+	return true
+}
+
+// parseTOMLHeader returns the name of a table header line; array tables ([[name]]) and names that
+// can't be parsed are nil
+func parseTOMLHeader(trimmed string) ([]string, bool) {
+	if !strings.HasPrefix(trimmed, "[") {
+		return nil, false
+	}
+	if strings.HasPrefix(trimmed, "[[") {
+		return nil, true
+	}
+	end := tomlStringAware(trimmed, 1, func(c byte) bool { return c == ']' })
+	if end < 0 {
+		return nil, true
+	}
+	name, ok := parseTOMLKey(trimmed[1:end])
+	if !ok {
+		return nil, true
+	}
+	return name, true
+}
+
+// parseTOMLKey splits a (possibly dotted and quoted) key into its parts
+func parseTOMLKey(key string) ([]string, bool) {
+	var parts []string
+	for {
+		end := tomlStringAware(key, 0, func(c byte) bool { return c == '.' })
+		part := key
+		if end >= 0 {
+			part = key[:end]
+		}
+		part = strings.TrimSpace(part)
+		switch {
+		case strings.HasPrefix(part, `"`):
+			unquoted, err := strconv.Unquote(part)
+			if err != nil {
+				return nil, false
+			}
+			part = unquoted
+		case strings.HasPrefix(part, "'"):
+			if len(part) < 2 || !strings.HasSuffix(part, "'") {
+				return nil, false
+			}
+			part = part[1 : len(part)-1]
+		case !bareTOMLKey.MatchString(part):
+			return nil, false
+		}
+		parts = append(parts, part)
+		if end < 0 {
+			return parts, true
+		}
+		key = key[end+1:]
+	}
+}
+
+// formatTOMLKey formats a dotted key, quoting the parts that need it
+func formatTOMLKey(path []string) string {
+	parts := make([]string, len(path))
+	for i, part := range path {
+		if bareTOMLKey.MatchString(part) {
+			parts[i] = part
+			continue
+		}
+		parts[i], _ = encodeJSONValue(part, "", "")
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlKeyEnd returns the offset of the "=" separating a key line's key from its value, or -1
+func tomlKeyEnd(line string) int {
+	return tomlStringAware(line, 0, func(c byte) bool { return c == '=' })
+}
+
+// tomlStringAware returns the offset of the first byte from start outside quotes that matches, or -1
+func tomlStringAware(s string, start int, match func(byte) bool) int {
+	for i := start; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\'':
+			i = tomlStringEnd(s, i) - 1
+		case match(c):
+			return i
+		}
+	}
+	return -1
+}
+
+// tomlStringEnd returns the offset after a single-line string starting at an offset
+func tomlStringEnd(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		if s[i] == '\\' && quote == '"' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// tomlValueEnd returns where a value starting at a line and offset ends: its last line, and the offset
+// after its last character there (before any comment and trailing spaces)
+func tomlValueEnd(lines []string, line, col int) (int, int) {
+	depth, end := 0, col
+	for line < len(lines) {
+		text := lines[line]
+		if col >= len(text) || text[col] == '#' {
+			if depth <= 0 || line == len(lines)-1 {
+				return line, end
+			}
+			line, col, end = line+1, 0, 0
+			continue
+		}
+
+		switch c := text[col]; {
+		case c == ' ' || c == '\t':
+			col++
+			continue
+		case strings.HasPrefix(text[col:], `"""`) || strings.HasPrefix(text[col:], "'''"):
+			line, col = tomlMultilineEnd(lines, line, col)
+		case c == '"' || c == '\'':
+			col = tomlStringEnd(text, col)
+		case c == '[' || c == '{':
+			depth++
+			col++
+		case c == ']' || c == '}':
+			depth--
+			col++
+		default:
+			col++
+		}
+		end = col
+	}
+	return len(lines) - 1, end
+}
+
+// tomlMultilineEnd returns the line and offset after a multi-line string starting at a line and offset
+func tomlMultilineEnd(lines []string, line, col int) (int, int) {
+	delimiter := lines[line][col : col+3]
+	col += 3
+	for ; line < len(lines); line, col = line+1, 0 {
+		text := lines[line]
+		for i := col; i < len(text); i++ {
+			if text[i] == '\\' && delimiter == `"""` {
+				i++
+				continue
+			}
+			if strings.HasPrefix(text[i:], delimiter) {
+				// Up to two quotes right before the delimiter belong to the string
+				end := i + 3
+				for end < len(text) && end < i+5 && text[end] == delimiter[0] {
+					end++
+				}
+				return line, end
+			}
+		}
+	}
+	return len(lines) - 1, len(lines[len(lines)-1])
+}
+
+// tomlValue formats a value for a TOML file
+func tomlValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", fmt.Errorf("TOML has no null values")
+	case string:
+		return encodeJSONValue(v, "", "")
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "nan", nil
+		case math.IsInf(v, 1):
+			return "inf", nil
+		case math.IsInf(v, -1):
+			return "-inf", nil
+		}
+		formatted := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(formatted, ".e") {
+			formatted += ".0"
+		}
+		return formatted, nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			formatted, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = formatted
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "{}", nil
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			formatted, err := tomlValue(v[key])
+			if err != nil {
+				return "", err
+			}
+			items[i] = formatTOMLKey([]string{key}) + " = " + formatted
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+	}
+	if normalized := normalizeValue(value); reflect.TypeOf(normalized) != reflect.TypeOf(value) {
+		return tomlValue(normalized)
+	}
+	return "", fmt.Errorf("unsupported TOML value: %v", value)
+}
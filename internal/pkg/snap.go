@@ -180,6 +180,16 @@ func (sm *SnapManager) isPackageInstalled(packageName string) (bool, error) {
 	return false, nil
 }
 
+// isPackageAvailable checks if a Snap package exists in the store
+func (sm *SnapManager) isPackageAvailable(packageName string) (bool, error) {
+	cmd := exec.Command("snap", "info", packageName)
+	if err := cmd.Run(); err != nil {
+		// snap info returns non-zero exit code for unknown snaps
+		return false, nil
+	}
+	return true, nil
+}
+
 // UninstallPackage removes a Snap package
 func (sm *SnapManager) UninstallPackage(packageName string, flags []string) error {
 	args := []string{"snap", "remove"}
@@ -425,14 +435,61 @@ func (sm *SnapManager) GetPackageInfo(packageName string) (string, error) {
 	return sm.InfoPackage(packageName)
 }
 
-// UpgradePackages is an alias for RefreshPackages to maintain consistency with other managers
+// UpgradePackages refreshes the given snaps, or all snaps when no names are given
 func (sm *SnapManager) UpgradePackages(packageNames []string, flags []string) error {
-	if len(packageNames) == 0 {
-		// Refresh all packages
-		return sm.RefreshPackages(flags)
-	}
-	
-	// Snap doesn't support upgrading specific packages, so refresh all
-	sm.logger.Info("Snap doesn't support upgrading specific packages, refreshing all packages", "requested", packageNames)
-	return sm.RefreshPackages(flags)
-}
\ No newline at end of file
+	return sm.RefreshPackages(append(append([]string{}, flags...), packageNames...))
+}
+
+// ListUpgrades returns snaps with refreshes available; snaps held with `snap refresh --hold` are marked as held
+func (sm *SnapManager) ListUpgrades() ([]UpgradeCandidate, error) {
+	output, err := exec.Command("snap", "refresh", "--list").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("snap refresh --list failed: %w", err)
+	}
+	candidates := parseSnapTable(string(output))
+
+	installed := make(map[string]UpgradeCandidate)
+	if output, err := exec.Command("snap", "list").Output(); err == nil {
+		for _, snap := range parseSnapTable(string(output)) {
+			installed[snap.Name] = snap
+		}
+	} else {
+		sm.logger.Warn("Could not list installed snaps", "error", err)
+	}
+
+	for i := range candidates {
+		current := installed[candidates[i].Name]
+		candidates[i].CurrentVersion = current.NewVersion
+		candidates[i].Held = current.Held
+	}
+	return candidates, nil
+}
+
+// parseSnapTable parses `snap list` and `snap refresh --list` tables (Name  Version  Rev  ...  Notes)
+// Version is stored in NewVersion; a "held" note marks the snap as held
+func parseSnapTable(output string) []UpgradeCandidate {
+	var snaps []UpgradeCandidate
+	lines := strings.Split(output, "\n")
+	if len(lines) == 0 || !strings.HasPrefix(strings.TrimSpace(lines[0]), "Name") {
+		return snaps // "All snaps up to date." and other messages have no table
+	}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		held := false
+		for _, note := range strings.Split(fields[len(fields)-1], ",") {
+			if note == "held" {
+				held = true
+			}
+		}
+		snaps = append(snaps, UpgradeCandidate{Name: fields[0], NewVersion: fields[1], Held: held})
+	}
+	return snaps
+}
+
+// Upgrade refreshes the given snaps, or all snaps (snapd skips held snaps)
+func (sm *SnapManager) Upgrade(packageNames []string) error {
+	return sm.UpgradePackages(packageNames, nil)
+}
@@ -445,4 +445,25 @@ func TestSnapManager_isPackageInstalled_DryRunSkip(t *testing.T) {
 	if installed {
 		t.Error("isPackageInstalled should return false for nonexistent packages")
 	}
-}
\ No newline at end of file
+}
+func TestParseSnapTable(t *testing.T) {
+	list := `Name      Version    Rev    Tracking       Publisher   Notes
+core22    20240823   1621   latest/stable  canonical✓  base
+firefox   129.0-2    4793   latest/stable  mozilla✓    held
+lxd       5.21.2     29619  5.21/stable    canonical✓  -
+`
+	snaps := parseSnapTable(list)
+	if len(snaps) != 3 {
+		t.Fatalf("expected 3 snaps, got %+v", snaps)
+	}
+	if snaps[1].Name != "firefox" || snaps[1].NewVersion != "129.0-2" || !snaps[1].Held {
+		t.Errorf("expected firefox 129.0-2 to be held, got %+v", snaps[1])
+	}
+	if snaps[0].Held || snaps[2].Held {
+		t.Errorf("expected only firefox to be held, got %+v", snaps)
+	}
+
+	if upToDate := parseSnapTable("All snaps up to date.\n"); len(upToDate) != 0 {
+		t.Errorf("expected no snaps, got %+v", upToDate)
+	}
+}
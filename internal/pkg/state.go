@@ -3,8 +3,11 @@ package pkg
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/bashfulrobot/configr/internal/config"
@@ -24,13 +27,72 @@ type PackageState struct {
 	Packages    ManagedPackages   `json:"packages"`
 	Files       []ManagedFile     `json:"files"`
 	Binaries    []ManagedBinary   `json:"binaries"`
+	FlatpakOverrides []ManagedFlatpakOverride `json:"flatpak_overrides,omitempty"`
+	LogicalPackages  map[string]string        `json:"logical_packages,omitempty"` // Logical package -> chosen "<manager>:<package>"
+	Blocks           []ManagedBlock           `json:"blocks,omitempty"`
+	Settings         []ManagedSetting         `json:"settings,omitempty"`
+	EditBackups      map[string]string        `json:"edit_backups,omitempty"` // Backup of each file edited by lines, blocks and settings files, by configured path
 }
 
-// ManagedPackages tracks packages by manager type
+// ManagedPackages tracks packages by package manager, stored under each manager's name
 type ManagedPackages struct {
-	Apt     []string `json:"apt"`
-	Flatpak []string `json:"flatpak"`
-	Snap    []string `json:"snap"`
+	Managers map[string][]string `json:"-"` // Tracked packages keyed by manager name (e.g., "apt")
+}
+
+// Get returns the packages tracked for a package manager
+func (mp *ManagedPackages) Get(manager string) []string {
+	return mp.Managers[manager]
+}
+
+// Set replaces the packages tracked for a package manager
+func (mp *ManagedPackages) Set(manager string, packages []string) {
+	if mp.Managers == nil {
+		mp.Managers = make(map[string][]string)
+	}
+	mp.Managers[manager] = packages
+}
+
+// ManagerNames returns the registered package managers followed by any other managers with tracked
+// packages (sorted), e.g. a custom manager that was removed from the configuration
+func (mp *ManagedPackages) ManagerNames() []string {
+	names := RegisteredPackageManagers()
+
+	var others []string
+	for name := range mp.Managers {
+		if !slices.Contains(names, name) {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	return append(names, others...)
+}
+
+// Count returns the total number of tracked packages across all package managers
+func (mp *ManagedPackages) Count() int {
+	total := 0
+	for _, packages := range mp.Managers {
+		total += len(packages)
+	}
+	return total
+}
+
+// MarshalJSON stores packages of every manager under the manager name
+func (mp ManagedPackages) MarshalJSON() ([]byte, error) {
+	flat := make(map[string][]string, len(mp.Managers))
+	maps.Copy(flat, mp.Managers)
+	return json.Marshal(flat)
+}
+
+// UnmarshalJSON reads packages of every manager from their manager name keys
+func (mp *ManagedPackages) UnmarshalJSON(data []byte) error {
+	var flat map[string][]string
+	if err := json.Unmarshal(data, &flat); err != nil {
+		return err
+	}
+	for manager, packages := range flat {
+		mp.Set(manager, packages)
+	}
+	return nil
 }
 
 // ManagedFile represents a file managed by configr
@@ -38,7 +100,45 @@ type ManagedFile struct {
 	Name        string `json:"name"`        // File identifier from YAML
 	Destination string `json:"destination"` // Where the file was deployed
 	IsSymlink   bool   `json:"is_symlink"`  // Whether it was deployed as symlink or copy
-	BackupPath  string `json:"backup_path,omitempty"` // Path to backup file if created
+	BackupPath  string `json:"backup_path,omitempty"` // Path to a sidecar backup made before the backup store
+	BackupID    string `json:"backup_id,omitempty"`   // Backup store entry of the file it replaced
+	Template    bool   `json:"template,omitempty"`    // Whether the source was rendered as a template
+	ContentHash string `json:"content_hash,omitempty"` // SHA256 of the deployed content (copies only)
+	Root        string `json:"root,omitempty"`        // Destination of the directory tree the file belongs to
+	Secret      bool   `json:"secret,omitempty"`      // Whether the file holds decrypted content (no content hash is kept)
+	Fingerprint string `json:"fingerprint,omitempty"` // Keyed HMAC of a secret file's content (secrets only)
+	Immutable   bool   `json:"immutable,omitempty"`   // Whether the immutable flag was set with chattr
+	AppendOnly  bool   `json:"append_only,omitempty"` // Whether the append-only flag was set with chattr
+}
+
+// ManagedFlatpakOverride represents a Flatpak application whose permission overrides are managed by configr
+type ManagedFlatpakOverride struct {
+	App   string   `json:"app"`            // Application ID
+	Scope string   `json:"scope"`          // Override scope ("--user" or "--system")
+	Args  []string `json:"args,omitempty"` // Override arguments configr applied, so only these are revoked
+}
+
+// ManagedBlock represents a marker-delimited block configr maintains in a file it doesn't own
+type ManagedBlock struct {
+	Name   string `json:"name"`             // Block identifier from YAML
+	Path   string `json:"path"`             // Target file as configured
+	Marker string `json:"marker,omitempty"` // Custom marker, empty for the default marker
+}
+
+// ManagedSetting represents a key configr manages in a settings file it doesn't own
+type ManagedSetting struct {
+	File     string      `json:"file"`               // Settings file identifier from YAML
+	Path     string      `json:"path"`               // Target file as configured
+	Format   string      `json:"format"`             // File format (json, yaml, toml or ini)
+	Key      string      `json:"key"`                // Dotted path or JSON pointer
+	Value    interface{} `json:"value"`              // Value configr last set
+	Previous interface{} `json:"previous,omitempty"` // Value before configr managed the key
+	Existed  bool        `json:"existed"`            // Whether the key existed before configr managed it
+}
+
+// id identifies a setting by file and key
+func (s ManagedSetting) id() string {
+	return s.Path + "\x00" + s.Key
 }
 
 // NewStateManager creates a new state manager
@@ -93,8 +193,7 @@ func (sm *StateManager) LoadState() (*PackageState, error) {
 		return nil, fmt.Errorf("failed to parse state file: %w", err)
 	}
 	
-	sm.logger.Debug("Loaded package state", "apt_count", len(state.Packages.Apt), 
-		"flatpak_count", len(state.Packages.Flatpak), "snap_count", len(state.Packages.Snap),
+	sm.logger.Debug("Loaded package state", "packages_count", state.Packages.Count(),
 		"files_count", len(state.Files), "binaries_count", len(state.Binaries))
 	
 	return &state, nil
@@ -132,15 +231,25 @@ func (sm *StateManager) UpdateState(cfg *config.Config, deployedFiles []ManagedF
 
 // UpdateStateWithBinaries updates the state with current configuration packages, files, and binaries
 func (sm *StateManager) UpdateStateWithBinaries(cfg *config.Config, deployedFiles []ManagedFile, deployedBinaries []ManagedBinary) error {
+	return sm.UpdateStateWithSettings(cfg, deployedFiles, deployedBinaries, []ManagedSetting{}, nil)
+}
+
+// UpdateStateWithSettings updates the state with current configuration packages, files, binaries, and settings
+func (sm *StateManager) UpdateStateWithSettings(cfg *config.Config, deployedFiles []ManagedFile, deployedBinaries []ManagedBinary, appliedSettings []ManagedSetting, editBackups map[string]string) error {
 	state, err := sm.LoadState()
 	if err != nil {
 		return fmt.Errorf("failed to load current state: %w", err)
 	}
 	
-	// Extract package names from configuration
-	state.Packages.Apt = extractPackageNames(cfg.Packages.Apt)
-	state.Packages.Flatpak = extractPackageNames(cfg.Packages.Flatpak)
-	state.Packages.Snap = extractPackageNames(cfg.Packages.Snap)
+	// Extract package names from configuration for every package manager
+	state.Packages = ManagedPackages{}
+	for _, manager := range cfg.Packages.ManagerNames() {
+		state.Packages.Set(manager, extractPackageNames(cfg.Packages.Get(manager)))
+	}
+	
+	state.FlatpakOverrides = sm.extractFlatpakOverrides(cfg)
+	state.Blocks = extractBlocks(cfg)
+	state.LogicalPackages = extractLogicalPackages(cfg)
 	
 	// Update file state
 	state.Files = deployedFiles
@@ -148,6 +257,10 @@ func (sm *StateManager) UpdateStateWithBinaries(cfg *config.Config, deployedFile
 	// Update binary state
 	state.Binaries = deployedBinaries
 	
+	// Update settings state
+	state.Settings = appliedSettings
+	state.EditBackups = extractEditBackups(cfg, state.EditBackups, editBackups)
+	
 	return sm.SaveState(state)
 }
 
@@ -156,6 +269,25 @@ func (sm *StateManager) UpdatePackageState(cfg *config.Config) error {
 	return sm.UpdateState(cfg, []ManagedFile{})
 }
 
+// AddManagedFile records a single file as managed, replacing any entry with the same name
+// Used when a file comes under management outside an apply, e.g. by configr adopt
+func (sm *StateManager) AddManagedFile(file ManagedFile) error {
+	state, err := sm.LoadState()
+	if err != nil {
+		return fmt.Errorf("failed to load current state: %w", err)
+	}
+
+	files := make([]ManagedFile, 0, len(state.Files)+1)
+	for _, existing := range state.Files {
+		if existing.Name != file.Name {
+			files = append(files, existing)
+		}
+	}
+	state.Files = append(files, file)
+
+	return sm.SaveState(state)
+}
+
 // GetPackagesToRemove compares current state with new configuration and returns packages to remove
 func (sm *StateManager) GetPackagesToRemove(cfg *config.Config) (*ManagedPackages, error) {
 	currentState, err := sm.LoadState()
@@ -163,34 +295,191 @@ func (sm *StateManager) GetPackagesToRemove(cfg *config.Config) (*ManagedPackage
 		return nil, fmt.Errorf("failed to load current state: %w", err)
 	}
 	
-	// Get package names from new configuration
-	newApt := extractPackageNames(cfg.Packages.Apt)
-	newFlatpak := extractPackageNames(cfg.Packages.Flatpak)
-	newSnap := extractPackageNames(cfg.Packages.Snap)
+	// Find packages to remove (in old state but not in new config) for every tracked manager
+	toRemove := &ManagedPackages{}
+	for _, manager := range currentState.Packages.ManagerNames() {
+		newPackages := extractPackageNames(cfg.Packages.Get(manager))
+		removed := stringSliceDiff(currentState.Packages.Get(manager), newPackages)
+		if len(removed) == 0 {
+			continue
+		}
+		
+		toRemove.Set(manager, removed)
+		sm.logger.Debug(fmt.Sprintf("%s packages to remove", config.PackageManagerDisplayName(manager)), "packages", removed)
+	}
 	
-	// Find packages to remove (in old state but not in new config)
-	toRemove := &ManagedPackages{
-		Apt:     stringSliceDiff(currentState.Packages.Apt, newApt),
-		Flatpak: stringSliceDiff(currentState.Packages.Flatpak, newFlatpak),
-		Snap:    stringSliceDiff(currentState.Packages.Snap, newSnap),
+	return toRemove, nil
+}
+
+// GetLogicalPackageChoices returns the candidate chosen for each logical package on the previous apply
+func (sm *StateManager) GetLogicalPackageChoices() (map[string]string, error) {
+	currentState, err := sm.LoadState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current state: %w", err)
 	}
 	
-	sm.logger.Debug("Determined packages to remove", 
-		"apt", len(toRemove.Apt), "flatpak", len(toRemove.Flatpak), "snap", len(toRemove.Snap))
+	if currentState.LogicalPackages == nil {
+		return map[string]string{}, nil
+	}
+	return currentState.LogicalPackages, nil
+}
+
+// extractLogicalPackages records which manager and package was chosen for each logical package
+func extractLogicalPackages(cfg *config.Config) map[string]string {
+	choices := make(map[string]string)
+	for _, manager := range cfg.Packages.ManagerNames() {
+		for _, pkg := range cfg.Packages.Get(manager) {
+			if pkg.Logical != "" {
+				choices[pkg.Logical] = manager + ":" + pkg.Name
+			}
+		}
+	}
 	
-	if len(toRemove.Apt) > 0 {
-		sm.logger.Debug("APT packages to remove", "packages", toRemove.Apt)
+	if len(choices) == 0 {
+		return nil
 	}
-	if len(toRemove.Flatpak) > 0 {
-		sm.logger.Debug("Flatpak packages to remove", "packages", toRemove.Flatpak)
+	return choices
+}
+
+// GetFlatpakOverridesToReset returns applications whose overrides were managed previously
+// but are no longer managed in the new configuration (overrides block or package removed)
+func (sm *StateManager) GetFlatpakOverridesToReset(cfg *config.Config) ([]ManagedFlatpakOverride, error) {
+	currentState, err := sm.LoadState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current state: %w", err)
 	}
-	if len(toRemove.Snap) > 0 {
-		sm.logger.Debug("Snap packages to remove", "packages", toRemove.Snap)
+	
+	managed := make(map[string]bool)
+	for _, override := range sm.extractFlatpakOverrides(cfg) {
+		managed[override.Scope+" "+override.App] = true
+	}
+	
+	var toReset []ManagedFlatpakOverride
+	for _, override := range currentState.FlatpakOverrides {
+		if !managed[override.Scope+" "+override.App] {
+			toReset = append(toReset, override)
+		}
 	}
 	
+	sm.logger.Debug("Determined Flatpak overrides to reset", "count", len(toReset))
+	return toReset, nil
+}
+
+// extractFlatpakOverrides returns the Flatpak applications with managed overrides in the configuration
+func (sm *StateManager) extractFlatpakOverrides(cfg *config.Config) []ManagedFlatpakOverride {
+	flatpakManager := NewFlatpakManager(sm.logger, false)
+	
+	var overrides []ManagedFlatpakOverride
+	for _, pkg := range cfg.Packages.Get("flatpak") {
+		if pkg.Overrides == nil {
+			continue
+		}
+		flags := flatpakManager.resolveInstallFlags(pkg, cfg.PackageDefaults)
+		overrides = append(overrides, ManagedFlatpakOverride{
+			App:   pkg.Name,
+			Scope: scopeFromFlags(flags),
+			Args:  buildOverrideArgs(*pkg.Overrides),
+		})
+	}
+	return overrides
+}
+
+// GetManagedSettings returns the settings recorded on the previous apply
+func (sm *StateManager) GetManagedSettings() ([]ManagedSetting, error) {
+	currentState, err := sm.LoadState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current state: %w", err)
+	}
+	return currentState.Settings, nil
+}
+
+// GetSettingsToRevert returns settings in the previous state whose key is no longer in the configuration
+func (sm *StateManager) GetSettingsToRevert(cfg *config.Config) ([]ManagedSetting, error) {
+	currentState, err := sm.LoadState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current state: %w", err)
+	}
+	
+	managed := make(map[string]bool)
+	for _, settingsFile := range cfg.SettingsFiles {
+		for key := range settingsFile.Settings {
+			managed[ManagedSetting{Path: settingsFile.Path, Key: key}.id()] = true
+		}
+	}
+	
+	var toRevert []ManagedSetting
+	for _, setting := range currentState.Settings {
+		if !managed[setting.id()] {
+			toRevert = append(toRevert, setting)
+		}
+	}
+	
+	sm.logger.Debug("Determined settings to revert", "count", len(toRevert))
+	return toRevert, nil
+}
+
+// GetBlocksToRemove returns blocks in the previous state that are no longer in the configuration
+// A block whose path or marker changed is removed from its old location
+func (sm *StateManager) GetBlocksToRemove(cfg *config.Config) ([]ManagedBlock, error) {
+	currentState, err := sm.LoadState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current state: %w", err)
+	}
+	
+	managed := make(map[ManagedBlock]bool)
+	for _, block := range extractBlocks(cfg) {
+		managed[block] = true
+	}
+	
+	var toRemove []ManagedBlock
+	for _, block := range currentState.Blocks {
+		if !managed[block] {
+			toRemove = append(toRemove, block)
+		}
+	}
+	
+	sm.logger.Debug("Determined blocks to remove", "count", len(toRemove))
 	return toRemove, nil
 }
 
+// extractBlocks returns the blocks managed by the configuration, sorted by name
+func extractBlocks(cfg *config.Config) []ManagedBlock {
+	var blocks []ManagedBlock
+	for name, block := range cfg.Blocks {
+		blocks = append(blocks, ManagedBlock{Name: name, Path: block.Path, Marker: block.Marker})
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].Name < blocks[j].Name })
+	return blocks
+}
+
+// extractEditBackups returns the backups of files lines, blocks and settings files still edit
+// A backup made in this run replaces the one recorded before
+func extractEditBackups(cfg *config.Config, previous, current map[string]string) map[string]string {
+	var paths []string
+	for _, line := range cfg.Lines {
+		paths = append(paths, line.Path)
+	}
+	for _, block := range cfg.Blocks {
+		paths = append(paths, block.Path)
+	}
+	for _, settingsFile := range cfg.SettingsFiles {
+		paths = append(paths, settingsFile.Path)
+	}
+
+	backups := make(map[string]string)
+	for _, path := range paths {
+		if id := current[path]; id != "" {
+			backups[path] = id
+		} else if id := previous[path]; id != "" {
+			backups[path] = id
+		}
+	}
+	if len(backups) == 0 {
+		return nil
+	}
+	return backups
+}
+
 // GetFilesToRemove compares current state with new configuration and returns files to remove
 func (sm *StateManager) GetFilesToRemove(cfg *config.Config) ([]ManagedFile, error) {
 	currentState, err := sm.LoadState()
@@ -281,4 +570,20 @@ func stringSliceDiff(slice1, slice2 []string) []string {
 	}
 	
 	return diff
+}
+
+// stringSliceIntersect returns the elements of slice1 that are also in slice2
+func stringSliceIntersect(slice1, slice2 []string) []string {
+	set2 := make(map[string]bool, len(slice2))
+	for _, item := range slice2 {
+		set2[item] = true
+	}
+
+	var both []string
+	for _, item := range slice1 {
+		if set2[item] {
+			both = append(both, item)
+		}
+	}
+	return both
 }
\ No newline at end of file
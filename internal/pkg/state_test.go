@@ -3,6 +3,7 @@ package pkg
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -29,7 +30,7 @@ func TestStateManager_LoadState_NewState(t *testing.T) {
 		t.Errorf("Expected version 1.0, got %s", state.Version)
 	}
 
-	if len(state.Packages.Apt) != 0 || len(state.Packages.Flatpak) != 0 || len(state.Packages.Snap) != 0 {
+	if len(state.Packages.Get("apt")) != 0 || len(state.Packages.Get("flatpak")) != 0 || len(state.Packages.Get("snap")) != 0 {
 		t.Errorf("Expected empty packages, got %+v", state.Packages)
 	}
 }
@@ -47,9 +48,11 @@ func TestStateManager_SaveAndLoadState(t *testing.T) {
 		Version:     "1.0",
 		LastUpdated: time.Now().Truncate(time.Second), // Truncate for comparison
 		Packages: ManagedPackages{
-			Apt:     []string{"vim", "git", "curl"},
-			Flatpak: []string{"org.mozilla.Firefox", "com.spotify.Client"},
-			Snap:    []string{"code", "discord"},
+			Managers: map[string][]string{
+				"apt":     {"vim", "git", "curl"},
+				"flatpak": {"org.mozilla.Firefox", "com.spotify.Client"},
+				"snap":    {"code", "discord"},
+			},
 		},
 	}
 
@@ -74,16 +77,16 @@ func TestStateManager_SaveAndLoadState(t *testing.T) {
 		t.Errorf("Version mismatch: expected %s, got %s", originalState.Version, loadedState.Version)
 	}
 
-	if !stringSlicesEqual(loadedState.Packages.Apt, originalState.Packages.Apt) {
-		t.Errorf("APT packages mismatch: expected %v, got %v", originalState.Packages.Apt, loadedState.Packages.Apt)
+	if !stringSlicesEqual(loadedState.Packages.Get("apt"), originalState.Packages.Get("apt")) {
+		t.Errorf("APT packages mismatch: expected %v, got %v", originalState.Packages.Get("apt"), loadedState.Packages.Get("apt"))
 	}
 
-	if !stringSlicesEqual(loadedState.Packages.Flatpak, originalState.Packages.Flatpak) {
-		t.Errorf("Flatpak packages mismatch: expected %v, got %v", originalState.Packages.Flatpak, loadedState.Packages.Flatpak)
+	if !stringSlicesEqual(loadedState.Packages.Get("flatpak"), originalState.Packages.Get("flatpak")) {
+		t.Errorf("Flatpak packages mismatch: expected %v, got %v", originalState.Packages.Get("flatpak"), loadedState.Packages.Get("flatpak"))
 	}
 
-	if !stringSlicesEqual(loadedState.Packages.Snap, originalState.Packages.Snap) {
-		t.Errorf("Snap packages mismatch: expected %v, got %v", originalState.Packages.Snap, loadedState.Packages.Snap)
+	if !stringSlicesEqual(loadedState.Packages.Get("snap"), originalState.Packages.Get("snap")) {
+		t.Errorf("Snap packages mismatch: expected %v, got %v", originalState.Packages.Get("snap"), loadedState.Packages.Get("snap"))
 	}
 }
 
@@ -98,15 +101,17 @@ func TestStateManager_UpdatePackageState(t *testing.T) {
 	// Create test configuration
 	cfg := &config.Config{
 		Packages: config.PackageManagement{
-			Apt: []config.PackageEntry{
-				{Name: "vim"},
-				{Name: "git"},
-			},
-			Flatpak: []config.PackageEntry{
-				{Name: "org.mozilla.Firefox"},
-			},
-			Snap: []config.PackageEntry{
-				{Name: "code"},
+			Managers: map[string][]config.PackageEntry{
+				"apt": {
+					{Name: "vim"},
+					{Name: "git"},
+				},
+				"flatpak": {
+					{Name: "org.mozilla.Firefox"},
+				},
+				"snap": {
+					{Name: "code"},
+				},
 			},
 		},
 	}
@@ -126,16 +131,16 @@ func TestStateManager_UpdatePackageState(t *testing.T) {
 	expectedFlatpak := []string{"org.mozilla.Firefox"}
 	expectedSnap := []string{"code"}
 
-	if !stringSlicesEqual(state.Packages.Apt, expectedApt) {
-		t.Errorf("APT packages mismatch: expected %v, got %v", expectedApt, state.Packages.Apt)
+	if !stringSlicesEqual(state.Packages.Get("apt"), expectedApt) {
+		t.Errorf("APT packages mismatch: expected %v, got %v", expectedApt, state.Packages.Get("apt"))
 	}
 
-	if !stringSlicesEqual(state.Packages.Flatpak, expectedFlatpak) {
-		t.Errorf("Flatpak packages mismatch: expected %v, got %v", expectedFlatpak, state.Packages.Flatpak)
+	if !stringSlicesEqual(state.Packages.Get("flatpak"), expectedFlatpak) {
+		t.Errorf("Flatpak packages mismatch: expected %v, got %v", expectedFlatpak, state.Packages.Get("flatpak"))
 	}
 
-	if !stringSlicesEqual(state.Packages.Snap, expectedSnap) {
-		t.Errorf("Snap packages mismatch: expected %v, got %v", expectedSnap, state.Packages.Snap)
+	if !stringSlicesEqual(state.Packages.Get("snap"), expectedSnap) {
+		t.Errorf("Snap packages mismatch: expected %v, got %v", expectedSnap, state.Packages.Get("snap"))
 	}
 }
 
@@ -152,9 +157,11 @@ func TestStateManager_GetPackagesToRemove(t *testing.T) {
 		Version:     "1.0",
 		LastUpdated: time.Now(),
 		Packages: ManagedPackages{
-			Apt:     []string{"vim", "git", "curl", "removed-package"},
-			Flatpak: []string{"org.mozilla.Firefox", "com.spotify.Client", "org.removed.App"},
-			Snap:    []string{"code", "discord", "removed-snap"},
+			Managers: map[string][]string{
+				"apt":     {"vim", "git", "curl", "removed-package"},
+				"flatpak": {"org.mozilla.Firefox", "com.spotify.Client", "org.removed.App"},
+				"snap":    {"code", "discord", "removed-snap"},
+			},
 		},
 	}
 
@@ -166,18 +173,20 @@ func TestStateManager_GetPackagesToRemove(t *testing.T) {
 	// Create new configuration (missing some packages)
 	newCfg := &config.Config{
 		Packages: config.PackageManagement{
-			Apt: []config.PackageEntry{
-				{Name: "vim"},
-				{Name: "git"},
-				// "curl" and "removed-package" are missing
-			},
-			Flatpak: []config.PackageEntry{
-				{Name: "org.mozilla.Firefox"},
-				// "com.spotify.Client" and "org.removed.App" are missing
-			},
-			Snap: []config.PackageEntry{
-				{Name: "code"},
-				// "discord" and "removed-snap" are missing
+			Managers: map[string][]config.PackageEntry{
+				"apt": {
+					{Name: "vim"},
+					{Name: "git"},
+					// "curl" and "removed-package" are missing
+				},
+				"flatpak": {
+					{Name: "org.mozilla.Firefox"},
+					// "com.spotify.Client" and "org.removed.App" are missing
+				},
+				"snap": {
+					{Name: "code"},
+					// "discord" and "removed-snap" are missing
+				},
 			},
 		},
 	}
@@ -193,16 +202,16 @@ func TestStateManager_GetPackagesToRemove(t *testing.T) {
 	expectedFlatpakRemove := []string{"com.spotify.Client", "org.removed.App"}
 	expectedSnapRemove := []string{"discord", "removed-snap"}
 
-	if !stringSlicesEqualUnordered(toRemove.Apt, expectedAptRemove) {
-		t.Errorf("APT packages to remove mismatch: expected %v, got %v", expectedAptRemove, toRemove.Apt)
+	if !stringSlicesEqualUnordered(toRemove.Get("apt"), expectedAptRemove) {
+		t.Errorf("APT packages to remove mismatch: expected %v, got %v", expectedAptRemove, toRemove.Get("apt"))
 	}
 
-	if !stringSlicesEqualUnordered(toRemove.Flatpak, expectedFlatpakRemove) {
-		t.Errorf("Flatpak packages to remove mismatch: expected %v, got %v", expectedFlatpakRemove, toRemove.Flatpak)
+	if !stringSlicesEqualUnordered(toRemove.Get("flatpak"), expectedFlatpakRemove) {
+		t.Errorf("Flatpak packages to remove mismatch: expected %v, got %v", expectedFlatpakRemove, toRemove.Get("flatpak"))
 	}
 
-	if !stringSlicesEqualUnordered(toRemove.Snap, expectedSnapRemove) {
-		t.Errorf("Snap packages to remove mismatch: expected %v, got %v", expectedSnapRemove, toRemove.Snap)
+	if !stringSlicesEqualUnordered(toRemove.Get("snap"), expectedSnapRemove) {
+		t.Errorf("Snap packages to remove mismatch: expected %v, got %v", expectedSnapRemove, toRemove.Get("snap"))
 	}
 }
 
@@ -266,8 +275,10 @@ func TestStateManager_UpdateStateWithFiles(t *testing.T) {
 	// Create test configuration
 	cfg := &config.Config{
 		Packages: config.PackageManagement{
-			Apt: []config.PackageEntry{
-				{Name: "vim"},
+			Managers: map[string][]config.PackageEntry{
+				"apt": {
+					{Name: "vim"},
+				},
 			},
 		},
 		Files: map[string]config.File{
@@ -298,8 +309,8 @@ func TestStateManager_UpdateStateWithFiles(t *testing.T) {
 
 	// Check packages
 	expectedApt := []string{"vim"}
-	if !stringSlicesEqual(state.Packages.Apt, expectedApt) {
-		t.Errorf("APT packages mismatch: expected %v, got %v", expectedApt, state.Packages.Apt)
+	if !stringSlicesEqual(state.Packages.Get("apt"), expectedApt) {
+		t.Errorf("APT packages mismatch: expected %v, got %v", expectedApt, state.Packages.Get("apt"))
 	}
 
 	// Check files
@@ -871,4 +882,207 @@ func TestStateManager_BinaryStateUpdate_UpdateExisting(t *testing.T) {
 	if !state.LastUpdated.After(initialState.LastUpdated) {
 		t.Error("State timestamp should be updated after binary deployment")
 	}
-}
\ No newline at end of file
+}
+func TestStateManager_GetFlatpakOverridesToReset(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+
+	logger := log.New(os.Stderr)
+	sm := NewStateManagerWithPath(logger, statePath)
+
+	// First apply: two applications with managed overrides
+	initialCfg := &config.Config{
+		Packages: config.PackageManagement{
+			Managers: map[string][]config.PackageEntry{
+				"flatpak": {
+					{Name: "org.mozilla.Firefox", Overrides: &config.FlatpakOverrides{Sockets: []string{"wayland"}}},
+					{Name: "com.visualstudio.code", Scope: "user", Overrides: &config.FlatpakOverrides{Filesystems: []string{"home"}}},
+					{Name: "org.gimp.GIMP"},
+				},
+			},
+		},
+	}
+	if err := sm.UpdateStateWithBinaries(initialCfg, []ManagedFile{}, []ManagedBinary{}); err != nil {
+		t.Fatalf("UpdateStateWithBinaries() failed: %v", err)
+	}
+
+	state, err := sm.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState() failed: %v", err)
+	}
+	if len(state.FlatpakOverrides) != 2 {
+		t.Fatalf("expected 2 managed overrides in state, got %v", state.FlatpakOverrides)
+	}
+
+	// Second apply: Firefox drops its overrides block
+	newCfg := &config.Config{
+		Packages: config.PackageManagement{
+			Managers: map[string][]config.PackageEntry{
+				"flatpak": {
+					{Name: "org.mozilla.Firefox"},
+					{Name: "com.visualstudio.code", Scope: "user", Overrides: &config.FlatpakOverrides{Filesystems: []string{"home"}}},
+				},
+			},
+		},
+	}
+
+	toReset, err := sm.GetFlatpakOverridesToReset(newCfg)
+	if err != nil {
+		t.Fatalf("GetFlatpakOverridesToReset() failed: %v", err)
+	}
+
+	expected := []ManagedFlatpakOverride{{App: "org.mozilla.Firefox", Scope: "--system", Args: []string{"--socket=wayland"}}}
+	if !reflect.DeepEqual(toReset, expected) {
+		t.Errorf("expected %v, got %v", expected, toReset)
+	}
+}
+
+func TestStateManager_GetBlocksToRemove(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+
+	logger := log.New(os.Stderr)
+	sm := NewStateManagerWithPath(logger, statePath)
+
+	// First apply: two managed blocks
+	initialCfg := &config.Config{
+		Blocks: map[string]config.Block{
+			"aliases": {Path: "~/.bashrc", Content: "alias g=git"},
+			"ssh":     {Path: "~/.ssh/config", Content: "Host *\n  AddKeysToAgent yes", Marker: "# {mark} ssh agent"},
+		},
+	}
+	if err := sm.UpdateStateWithBinaries(initialCfg, []ManagedFile{}, []ManagedBinary{}); err != nil {
+		t.Fatalf("UpdateStateWithBinaries() failed: %v", err)
+	}
+
+	// Second apply: the aliases block moves to another file and the ssh block is dropped
+	newCfg := &config.Config{
+		Blocks: map[string]config.Block{
+			"aliases": {Path: "~/.zshrc", Content: "alias g=git"},
+		},
+	}
+
+	toRemove, err := sm.GetBlocksToRemove(newCfg)
+	if err != nil {
+		t.Fatalf("GetBlocksToRemove() failed: %v", err)
+	}
+
+	expected := []ManagedBlock{
+		{Name: "aliases", Path: "~/.bashrc"},
+		{Name: "ssh", Path: "~/.ssh/config", Marker: "# {mark} ssh agent"},
+	}
+	if len(toRemove) != len(expected) || toRemove[0] != expected[0] || toRemove[1] != expected[1] {
+		t.Errorf("expected %v, got %v", expected, toRemove)
+	}
+}
+
+func TestStateManager_GetSettingsToRevert(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+
+	logger := log.New(os.Stderr)
+	sm := NewStateManagerWithPath(logger, statePath)
+
+	// First apply: two managed keys in VS Code's settings
+	cfg := &config.Config{
+		SettingsFiles: map[string]config.SettingsFile{
+			"vscode": {Path: "~/.config/Code/User/settings.json", Settings: map[string]interface{}{"/editor.fontSize": 14, "/files.autoSave": "onFocusChange"}},
+		},
+	}
+	applied := []ManagedSetting{
+		{File: "vscode", Path: "~/.config/Code/User/settings.json", Format: "json", Key: "/editor.fontSize", Value: 14, Previous: 12, Existed: true},
+		{File: "vscode", Path: "~/.config/Code/User/settings.json", Format: "json", Key: "/files.autoSave", Value: "onFocusChange"},
+	}
+	if err := sm.UpdateStateWithSettings(cfg, []ManagedFile{}, []ManagedBinary{}, applied, nil); err != nil {
+		t.Fatalf("UpdateStateWithSettings() failed: %v", err)
+	}
+
+	// Second apply: autoSave is no longer managed
+	delete(cfg.SettingsFiles["vscode"].Settings, "/files.autoSave")
+
+	toRevert, err := sm.GetSettingsToRevert(cfg)
+	if err != nil {
+		t.Fatalf("GetSettingsToRevert() failed: %v", err)
+	}
+	if len(toRevert) != 1 || toRevert[0].Key != "/files.autoSave" || toRevert[0].Existed {
+		t.Errorf("expected /files.autoSave to be reverted, got %+v", toRevert)
+	}
+
+	settings, err := sm.GetManagedSettings()
+	if err != nil {
+		t.Fatalf("GetManagedSettings() failed: %v", err)
+	}
+	if len(settings) != 2 || !settingValuesEqual(settings[0].Previous, 12) {
+		t.Errorf("expected the managed settings to be loaded from state, got %+v", settings)
+	}
+}
+
+func TestStateManager_LogicalPackageChoices(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+
+	logger := log.New(os.Stderr)
+	sm := NewStateManagerWithPath(logger, statePath)
+
+	cfg := &config.Config{
+		Packages: config.PackageManagement{
+			Managers: map[string][]config.PackageEntry{
+				"apt":  {{Name: "curl"}},
+				"snap": {{Name: "code", Flags: []string{"--classic"}, Logical: "vscode"}},
+			},
+		},
+	}
+	if err := sm.UpdateStateWithBinaries(cfg, []ManagedFile{}, []ManagedBinary{}); err != nil {
+		t.Fatalf("UpdateStateWithBinaries() failed: %v", err)
+	}
+
+	choices, err := sm.GetLogicalPackageChoices()
+	if err != nil {
+		t.Fatalf("GetLogicalPackageChoices() failed: %v", err)
+	}
+	if len(choices) != 1 || choices["vscode"] != "snap:code" {
+		t.Errorf("expected vscode -> snap:code, got %v", choices)
+	}
+
+	// The chosen package is tracked under its manager, so dropping the logical package removes it there
+	toRemove, err := sm.GetPackagesToRemove(&config.Config{
+		Packages: config.PackageManagement{Managers: map[string][]config.PackageEntry{"apt": {{Name: "curl"}}}},
+	})
+	if err != nil {
+		t.Fatalf("GetPackagesToRemove() failed: %v", err)
+	}
+	if len(toRemove.Get("snap")) != 1 || toRemove.Get("snap")[0] != "code" {
+		t.Errorf("expected snap package 'code' to be removed, got %v", toRemove.Get("snap"))
+	}
+}
+
+func TestStateManager_AddManagedFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+
+	logger := log.New(os.Stderr)
+	sm := NewStateManagerWithPath(logger, statePath)
+
+	cfg := &config.Config{Packages: config.PackageManagement{Managers: map[string][]config.PackageEntry{"apt": {{Name: "git"}}}}}
+	if err := sm.UpdateState(cfg, []ManagedFile{{Name: "vimrc", Destination: "/home/user/.vimrc", IsSymlink: true}}); err != nil {
+		t.Fatalf("UpdateState() failed: %v", err)
+	}
+
+	if err := sm.AddManagedFile(ManagedFile{Name: "gitconfig", Destination: "/home/user/.gitconfig", IsSymlink: true}); err != nil {
+		t.Fatalf("AddManagedFile() failed: %v", err)
+	}
+	if err := sm.AddManagedFile(ManagedFile{Name: "gitconfig", Destination: "/home/user/.config/git/config", IsSymlink: true}); err != nil {
+		t.Fatalf("AddManagedFile() failed: %v", err)
+	}
+
+	state, err := sm.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState() failed: %v", err)
+	}
+	if len(state.Files) != 2 || state.Files[0].Name != "vimrc" || state.Files[1].Destination != "/home/user/.config/git/config" {
+		t.Errorf("unexpected files: %+v", state.Files)
+	}
+	if apt := state.Packages.Get("apt"); len(apt) != 1 || apt[0] != "git" {
+		t.Errorf("expected package state to be kept, got %v", apt)
+	}
+}
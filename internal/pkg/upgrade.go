@@ -0,0 +1,143 @@
+package pkg
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/charmbracelet/log"
+)
+
+// UpgradeCandidate is an installed package with a newer version available
+type UpgradeCandidate struct {
+	Name           string // Package name as tracked in state (APT package, Flatpak app ID, snap name, ...)
+	CurrentVersion string // Installed version (empty if the manager doesn't report it)
+	NewVersion     string // Version that would be installed
+	Held           bool   // Held back by the package manager (apt-mark hold, flatpak mask, snap refresh --hold)
+}
+
+// Upgrader is implemented by package managers that can upgrade installed packages
+type Upgrader interface {
+	// ListUpgrades returns the packages with updates available, marking held packages
+	ListUpgrades() ([]UpgradeCandidate, error)
+
+	// Upgrade upgrades the given packages, or every package when packageNames is empty
+	// Upgrading everything leaves holds and pins to the package manager itself
+	Upgrade(packageNames []string) error
+}
+
+// indexRefresher is implemented by upgraders whose package index must be refreshed before listing upgrades
+type indexRefresher interface {
+	RefreshIndex() error
+}
+
+// UpgradePlan describes what an upgrade would change for one package manager
+type UpgradePlan struct {
+	Manager   string
+	Upgrades  []UpgradeCandidate // Packages that will be upgraded
+	Held      []UpgradeCandidate // Packages with updates that are held back
+	Unmanaged int                // Upgradable packages skipped because configr doesn't manage them (--only-managed)
+}
+
+// UpgradeManager plans and runs package upgrades across package managers
+type UpgradeManager struct {
+	logger *log.Logger
+	dryRun bool
+}
+
+// NewUpgradeManager creates a new upgrade manager
+func NewUpgradeManager(logger *log.Logger, dryRun bool) *UpgradeManager {
+	return &UpgradeManager{
+		logger: logger,
+		dryRun: dryRun,
+	}
+}
+
+// UpgradableManagers returns the registered package managers that support upgrades, in registration order
+func UpgradableManagers() []string {
+	var names []string
+	for _, name := range RegisteredPackageManagers() {
+		if _, ok := newUpgrader(name, ManagerOptions{}); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// newUpgrader creates the upgrader of a package manager, if it supports upgrades
+func newUpgrader(manager string, opts ManagerOptions) (Upgrader, bool) {
+	packageManager, err := NewPackageManager(manager, opts)
+	if err != nil {
+		return nil, false
+	}
+	upgrader, ok := packageManager.(Upgrader)
+	return upgrader, ok
+}
+
+// Upgrader returns the upgrader of a package manager
+// An error is returned when the manager doesn't support upgrades or its command is not installed
+func (um *UpgradeManager) Upgrader(manager string) (Upgrader, error) {
+	upgrader, ok := newUpgrader(manager, ManagerOptions{Logger: um.logger, DryRun: um.dryRun})
+	if !ok {
+		return nil, fmt.Errorf("package manager '%s' does not support upgrades (supported: %v)", manager, UpgradableManagers())
+	}
+	if command := PackageManagerCommand(manager); command != "" {
+		if _, err := exec.LookPath(command); err != nil {
+			return nil, fmt.Errorf("%s not found in PATH", command)
+		}
+	}
+	return upgrader, nil
+}
+
+// Plan lists the upgrades of a package manager
+// With onlyManaged, only packages in managed (the packages configr tracks for this manager) are upgraded
+func (um *UpgradeManager) Plan(manager string, upgrader Upgrader, managed []string, onlyManaged bool) (*UpgradePlan, error) {
+	if refresher, ok := upgrader.(indexRefresher); ok {
+		if err := refresher.RefreshIndex(); err != nil {
+			um.logger.Warn("Failed to refresh package index, listing upgrades from the current index", "manager", manager, "error", err)
+		}
+	}
+
+	candidates, err := upgrader.ListUpgrades()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upgrades: %w", err)
+	}
+
+	managedSet := make(map[string]bool, len(managed))
+	for _, name := range managed {
+		managedSet[name] = true
+	}
+
+	plan := &UpgradePlan{Manager: manager}
+	for _, candidate := range candidates {
+		if onlyManaged && !managedSet[candidate.Name] {
+			plan.Unmanaged++
+			continue
+		}
+		if candidate.Held {
+			plan.Held = append(plan.Held, candidate)
+			continue
+		}
+		plan.Upgrades = append(plan.Upgrades, candidate)
+	}
+
+	um.logger.Debug("Planned upgrades", "manager", manager, "upgrades", len(plan.Upgrades), "held", len(plan.Held), "unmanaged", plan.Unmanaged)
+	return plan, nil
+}
+
+// Apply runs the upgrades of a plan
+// With onlyManaged the planned packages are upgraded by name; otherwise the manager upgrades everything
+func (um *UpgradeManager) Apply(upgrader Upgrader, plan *UpgradePlan, onlyManaged bool) error {
+	if len(plan.Upgrades) == 0 {
+		return nil
+	}
+
+	if !onlyManaged {
+		return upgrader.Upgrade(nil)
+	}
+
+	names := make([]string, len(plan.Upgrades))
+	for i, candidate := range plan.Upgrades {
+		names[i] = candidate.Name
+	}
+	return upgrader.Upgrade(names)
+}
@@ -0,0 +1,132 @@
+package pkg
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+// fakeUpgrader records upgrade calls for planner tests
+type fakeUpgrader struct {
+	candidates []UpgradeCandidate
+	upgraded   [][]string
+}
+
+func (f *fakeUpgrader) ListUpgrades() ([]UpgradeCandidate, error) {
+	return f.candidates, nil
+}
+
+func (f *fakeUpgrader) Upgrade(packageNames []string) error {
+	f.upgraded = append(f.upgraded, packageNames)
+	return nil
+}
+
+func TestUpgradeManager_PlanAndApply(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	candidates := []UpgradeCandidate{
+		{Name: "firefox", NewVersion: "130.0"},
+		{Name: "linux-generic", NewVersion: "6.8.0.45", Held: true},
+		{Name: "libc6", NewVersion: "2.39"},
+		{Name: "git", NewVersion: "2.45"},
+	}
+	managed := []string{"firefox", "linux-generic", "git"}
+
+	tests := []struct {
+		name             string
+		onlyManaged      bool
+		expectedUpgrades []string
+		expectedHeld     []string
+		expectedSkipped  int
+		expectedCalls    [][]string
+	}{
+		{
+			name:             "all packages",
+			onlyManaged:      false,
+			expectedUpgrades: []string{"firefox", "libc6", "git"},
+			expectedHeld:     []string{"linux-generic"},
+			expectedCalls:    [][]string{nil}, // the manager upgrades everything and honors holds itself
+		},
+		{
+			name:             "only managed packages",
+			onlyManaged:      true,
+			expectedUpgrades: []string{"firefox", "git"},
+			expectedHeld:     []string{"linux-generic"},
+			expectedSkipped:  1,
+			expectedCalls:    [][]string{{"firefox", "git"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upgrader := &fakeUpgrader{candidates: candidates}
+			um := NewUpgradeManager(logger, false)
+
+			plan, err := um.Plan("apt", upgrader, managed, tt.onlyManaged)
+			if err != nil {
+				t.Fatalf("Plan failed: %v", err)
+			}
+			if got := candidateNames(plan.Upgrades); !reflect.DeepEqual(got, tt.expectedUpgrades) {
+				t.Errorf("expected upgrades %v, got %v", tt.expectedUpgrades, got)
+			}
+			if got := candidateNames(plan.Held); !reflect.DeepEqual(got, tt.expectedHeld) {
+				t.Errorf("expected held %v, got %v", tt.expectedHeld, got)
+			}
+			if plan.Unmanaged != tt.expectedSkipped {
+				t.Errorf("expected %d unmanaged, got %d", tt.expectedSkipped, plan.Unmanaged)
+			}
+
+			if err := um.Apply(upgrader, plan, tt.onlyManaged); err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			if !reflect.DeepEqual(upgrader.upgraded, tt.expectedCalls) {
+				t.Errorf("expected upgrade calls %v, got %v", tt.expectedCalls, upgrader.upgraded)
+			}
+		})
+	}
+}
+
+func TestUpgradeManager_ApplyNothingToUpgrade(t *testing.T) {
+	logger := log.New(os.Stderr)
+	logger.SetLevel(log.FatalLevel) // Silence logs during tests
+
+	upgrader := &fakeUpgrader{candidates: []UpgradeCandidate{{Name: "linux-generic", Held: true}}}
+	um := NewUpgradeManager(logger, false)
+
+	plan, err := um.Plan("apt", upgrader, nil, false)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if err := um.Apply(upgrader, plan, false); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if len(upgrader.upgraded) != 0 {
+		t.Errorf("expected no upgrade when only held packages have updates, got %v", upgrader.upgraded)
+	}
+}
+
+func TestUpgradableManagers(t *testing.T) {
+	managers := UpgradableManagers()
+	for _, expected := range []string{"apt", "flatpak", "snap", "dnf"} {
+		found := false
+		for _, manager := range managers {
+			if manager == expected {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected %s to support upgrades, got %v", expected, managers)
+		}
+	}
+}
+
+func candidateNames(candidates []UpgradeCandidate) []string {
+	var names []string
+	for _, candidate := range candidates {
+		names = append(names, candidate.Name)
+	}
+	return names
+}
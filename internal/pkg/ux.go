@@ -3,6 +3,7 @@ package pkg
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -350,7 +351,7 @@ func (ux *UXManager) ShowConfigPreview(cfg *config.Config) string {
 	preview.WriteString("\n")
 	
 	// Repositories with enhanced details
-	totalRepos := len(cfg.Repositories.Apt) + len(cfg.Repositories.Flatpak)
+	totalRepos := len(cfg.Repositories.Apt) + len(cfg.Repositories.Flatpak) + len(cfg.Repositories.Dnf)
 	if totalRepos > 0 {
 		preview.WriteString(ux.warningStyle.Render("📦 Repositories ") + fmt.Sprintf("(%d total)", totalRepos) + "\n")
 		
@@ -385,45 +386,52 @@ func (ux *UXManager) ShowConfigPreview(cfg *config.Config) string {
 				preview.WriteString("\n")
 			}
 		}
+		
+		if len(cfg.Repositories.Dnf) > 0 {
+			preview.WriteString(ux.noteStyle.Render("  DNF:") + "\n")
+			for _, repo := range cfg.Repositories.Dnf {
+				location := repo.BaseURL
+				if location == "" {
+					location = repo.Metalink + repo.MirrorList
+				}
+				preview.WriteString(fmt.Sprintf("    • %s: %s", repo.Name, location))
+				if repo.GPGKey != "" {
+					preview.WriteString(ux.lineNumberStyle.Render(" (with GPG key)"))
+				}
+				preview.WriteString("\n")
+			}
+		}
 		preview.WriteString("\n")
 	}
 	
 	// Packages with enhanced breakdown
-	totalPackages := len(cfg.Packages.Apt) + len(cfg.Packages.Flatpak) + len(cfg.Packages.Snap)
+	totalPackages := cfg.Packages.Count()
 	if totalPackages > 0 {
 		preview.WriteString(ux.warningStyle.Render("📱 Packages ") + fmt.Sprintf("(%d total)", totalPackages) + "\n")
 		
-		if len(cfg.Packages.Apt) > 0 {
-			preview.WriteString(fmt.Sprintf("  • APT: %s packages", ux.successStyle.Render(fmt.Sprintf("%d", len(cfg.Packages.Apt)))))
-			debFiles := 0
-			for _, pkg := range cfg.Packages.Apt {
-				if strings.HasSuffix(pkg.Name, ".deb") {
-					debFiles++
-				}
-			}
-			if debFiles > 0 {
-				preview.WriteString(fmt.Sprintf(" (%d local .deb files)", debFiles))
+		for _, manager := range cfg.Packages.ManagerNames() {
+			packages := cfg.Packages.Get(manager)
+			if len(packages) == 0 {
+				continue
 			}
-			preview.WriteString("\n")
-		}
-		
-		if len(cfg.Packages.Flatpak) > 0 {
-			preview.WriteString(fmt.Sprintf("  • Flatpak: %s packages\n", ux.successStyle.Render(fmt.Sprintf("%d", len(cfg.Packages.Flatpak)))))
-		}
-		
-		if len(cfg.Packages.Snap) > 0 {
-			preview.WriteString(fmt.Sprintf("  • Snap: %s packages", ux.successStyle.Render(fmt.Sprintf("%d", len(cfg.Packages.Snap)))))
-			classicSnaps := 0
-			for _, pkg := range cfg.Packages.Snap {
-				for _, flag := range pkg.Flags {
-					if flag == "--classic" {
-						classicSnaps++
-						break
-					}
+			preview.WriteString(fmt.Sprintf("  • %s: %s packages", config.PackageManagerDisplayName(manager), ux.successStyle.Render(fmt.Sprintf("%d", len(packages)))))
+
+			packageFiles := 0
+			classicPackages := 0
+			for _, pkg := range packages {
+				// Local .deb files, Flatpak bundles and remote URLs
+				if strings.Contains(pkg.Name, "/") || strings.HasSuffix(pkg.Name, ".deb") {
+					packageFiles++
 				}
+				if slices.Contains(pkg.Flags, "--classic") {
+					classicPackages++
+				}
+			}
+			if packageFiles > 0 {
+				preview.WriteString(fmt.Sprintf(" (%d package files)", packageFiles))
 			}
-			if classicSnaps > 0 {
-				preview.WriteString(fmt.Sprintf(" (%d classic mode)", classicSnaps))
+			if classicPackages > 0 {
+				preview.WriteString(fmt.Sprintf(" (%d classic mode)", classicPackages))
 			}
 			preview.WriteString("\n")
 		}
@@ -440,7 +448,7 @@ func (ux *UXManager) ShowConfigPreview(cfg *config.Config) string {
 		
 		for name, file := range cfg.Files {
 			mode := ux.noteStyle.Render("symlink")
-			if file.Copy {
+			if file.DeploysAsCopy() {
 				mode = ux.warningStyle.Render("copy")
 				copyCount++
 			} else {
@@ -452,7 +460,7 @@ func (ux *UXManager) ShowConfigPreview(cfg *config.Config) string {
 			}
 			
 			preview.WriteString(fmt.Sprintf("  • %s: %s → %s (%s)", 
-				name, file.Source, file.Destination, mode))
+				name, file.DisplaySource(), file.Destination, mode))
 			
 			if file.Mode != "" || file.Owner != "" || file.Group != "" {
 				permissions := []string{}
@@ -117,9 +117,11 @@ func createTestConfigForValidation(packageCount, fileCount int) *config.Config {
 	cfg := &config.Config{
 		Version: "1.0",
 		Packages: config.PackageManagement{
-			Apt:     make([]config.PackageEntry, packageCount/3),
-			Flatpak: make([]config.PackageEntry, packageCount/3),
-			Snap:    make([]config.PackageEntry, packageCount/3),
+			Managers: map[string][]config.PackageEntry{
+				"apt":     make([]config.PackageEntry, packageCount/3),
+				"flatpak": make([]config.PackageEntry, packageCount/3),
+				"snap":    make([]config.PackageEntry, packageCount/3),
+			},
 		},
 		// Skip files for validation benchmarks to avoid file existence checks
 		Files: make(map[string]config.File),
@@ -127,21 +129,21 @@ func createTestConfigForValidation(packageCount, fileCount int) *config.Config {
 
 	// Add APT packages
 	for i := 0; i < packageCount/3; i++ {
-		cfg.Packages.Apt[i] = config.PackageEntry{
+		cfg.Packages.Get("apt")[i] = config.PackageEntry{
 			Name: fmt.Sprintf("apt-package-%d", i),
 		}
 	}
 
 	// Add Flatpak packages
 	for i := 0; i < packageCount/3; i++ {
-		cfg.Packages.Flatpak[i] = config.PackageEntry{
+		cfg.Packages.Get("flatpak")[i] = config.PackageEntry{
 			Name: fmt.Sprintf("com.example.App%d", i),
 		}
 	}
 
 	// Add Snap packages
 	for i := 0; i < packageCount/3; i++ {
-		cfg.Packages.Snap[i] = config.PackageEntry{
+		cfg.Packages.Get("snap")[i] = config.PackageEntry{
 			Name: fmt.Sprintf("snap-package-%d", i),
 		}
 	}